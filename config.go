@@ -0,0 +1,498 @@
+package main
+
+//
+// Project configuration file (walkngo.json or .walkngo.toml)
+//
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// the configuration files looked up in the project root, in order
+var configFiles = []string{"walkngo.json", ".walkngo.json", "walkngo.toml", ".walkngo.toml"}
+
+// Options are the translation settings that can be set globally or per language
+type Options struct {
+	Lang        string            `json:"lang,omitempty"`
+	Outdir      string            `json:"outdir,omitempty"`
+	Debug       *bool             `json:"debug,omitempty"`
+	SortStructs *bool             `json:"sort_structs,omitempty"`
+	JavaGroup   string            `json:"java_group,omitempty"`
+	Include     []string          `json:"include,omitempty"`   // globs of the files to convert (relative to the input folder)
+	Exclude     []string          `json:"exclude,omitempty"`   // globs of the files/folders to skip
+	Packages    map[string]string `json:"packages,omitempty"`  // package folder -> output folder (relative to outdir)
+	Idents      map[string]string `json:"idents,omitempty"`    // identifier -> replacement
+	Selectors   map[string]string `json:"selectors,omitempty"` // pkg.Name -> replacement
+	Mappings    []string          `json:"mappings,omitempty"`  // library mapping files (relative to the configuration file)
+}
+
+// Config is the content of the configuration file
+type Config struct {
+	Options
+	Languages map[string]Options `json:"languages,omitempty"` // per language defaults (keys as in --lang)
+}
+
+// findConfig looks for a configuration file in dir and returns its path (or an empty string)
+func findConfig(dir string) string {
+	for _, name := range configFiles {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// loadConfig reads a JSON or TOML configuration file
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseConfig(path, string(data))
+}
+
+// parseConfig parses the content of a configuration file (TOML if path ends in .toml, JSON otherwise)
+func parseConfig(path, content string) (*Config, error) {
+	data := []byte(content)
+
+	if strings.HasSuffix(path, ".toml") {
+		m, err := parseTOML(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+
+		// the TOML document has the same structure as the JSON one
+		if data, err = json.Marshal(m); err != nil {
+			return nil, err
+		}
+	}
+
+	var cfg Config
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// ForLang returns the options for the specified language (the global options, overridden by the language ones).
+// The language options are looked up by any of the language names (i.e. "c" or "cc")
+func (c *Config) ForLang(names ...string) Options {
+	opts := c.Options
+
+	var lo Options
+	var ok bool
+
+	for _, name := range names {
+		if lo, ok = c.Languages[name]; ok {
+			break
+		}
+	}
+
+	if !ok {
+		return opts
+	}
+
+	if len(lo.Outdir) > 0 {
+		opts.Outdir = lo.Outdir
+	}
+	if lo.Debug != nil {
+		opts.Debug = lo.Debug
+	}
+	if lo.SortStructs != nil {
+		opts.SortStructs = lo.SortStructs
+	}
+	if len(lo.JavaGroup) > 0 {
+		opts.JavaGroup = lo.JavaGroup
+	}
+	if lo.Include != nil {
+		opts.Include = lo.Include
+	}
+	if lo.Exclude != nil {
+		opts.Exclude = lo.Exclude
+	}
+	if lo.Mappings != nil {
+		opts.Mappings = append(opts.Mappings, lo.Mappings...)
+	}
+
+	opts.Packages = mergeMap(opts.Packages, lo.Packages)
+	opts.Idents = mergeMap(opts.Idents, lo.Idents)
+	opts.Selectors = mergeMap(opts.Selectors, lo.Selectors)
+	return opts
+}
+
+func mergeMap(a, b map[string]string) map[string]string {
+	if len(b) == 0 {
+		return a
+	}
+
+	m := map[string]string{}
+	for k, v := range a {
+		m[k] = v
+	}
+	for k, v := range b {
+		m[k] = v
+	}
+
+	return m
+}
+
+// Glob is a path pattern, where "*" matches any sequence of characters except "/",
+// "**" matches any sequence of characters and "?" matches a single character
+type Glob struct {
+	re *regexp.Regexp
+}
+
+func compileGlobs(patterns []string) ([]Glob, error) {
+	globs := make([]Glob, 0, len(patterns))
+
+	for _, p := range patterns {
+		var re strings.Builder
+
+		re.WriteString("^")
+		for i := 0; i < len(p); i++ {
+			switch c := p[i]; c {
+			case '*':
+				if i+1 < len(p) && p[i+1] == '*' {
+					i++
+					if i+1 < len(p) && p[i+1] == '/' { // "**/" also matches no folder
+						i++
+						re.WriteString("(.*/)?")
+					} else {
+						re.WriteString(".*")
+					}
+				} else {
+					re.WriteString("[^/]*")
+				}
+
+			case '?':
+				re.WriteString("[^/]")
+
+			default:
+				re.WriteString(regexp.QuoteMeta(string(c)))
+			}
+		}
+		re.WriteString("$")
+
+		r, err := regexp.Compile(re.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", p, err)
+		}
+
+		globs = append(globs, Glob{r})
+	}
+
+	return globs, nil
+}
+
+// matchAny returns true if the (slash separated) path matches one of the globs
+func matchAny(globs []Glob, path string) bool {
+	for _, g := range globs {
+		if g.re.MatchString(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//
+// A parser for the subset of TOML used by the configuration file:
+// tables, dotted table names, quoted and bare keys, strings, booleans, numbers,
+// arrays and inline tables.
+//
+
+type tomlParser struct {
+	s    string
+	pos  int
+	line int
+}
+
+func parseTOML(s string) (map[string]interface{}, error) {
+	p := &tomlParser{s: s, line: 1}
+	root := map[string]interface{}{}
+	current := root
+
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return root, nil
+		}
+
+		if p.peek() == '[' {
+			p.pos++
+			keys, err := p.parseKeys()
+			if err != nil {
+				return nil, err
+			}
+			if !p.consume(']') {
+				return nil, p.errorf("expected ]")
+			}
+
+			if current, err = p.table(root, keys); err != nil {
+				return nil, err
+			}
+		} else {
+			if err := p.parseKeyValue(current); err != nil {
+				return nil, err
+			}
+		}
+
+		p.skipSpace(false)
+		if !p.eof() && !p.consume('\n') {
+			return nil, p.errorf("expected end of line")
+		}
+		p.line++
+	}
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.s[p.pos]
+}
+
+func (p *tomlParser) consume(c byte) bool {
+	if p.peek() == c {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+// skipSpace skips spaces and comments (and newlines, if nl is true)
+func (p *tomlParser) skipSpace(nl bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+
+		case c == '\n' && nl:
+			p.line++
+			p.pos++
+
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+
+		default:
+			return
+		}
+	}
+}
+
+// table returns the (nested) table for keys, creating it if needed
+func (p *tomlParser) table(m map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, k := range keys {
+		v, ok := m[k]
+		if !ok {
+			t := map[string]interface{}{}
+			m[k] = t
+			m = t
+			continue
+		}
+
+		t, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, p.errorf("%q is not a table", k)
+		}
+		m = t
+	}
+
+	return m, nil
+}
+
+func (p *tomlParser) parseKeys() (keys []string, err error) {
+	for {
+		p.skipSpace(false)
+
+		var key string
+
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			if key, err = p.parseString(); err != nil {
+				return nil, err
+			}
+
+		default:
+			start := p.pos
+			for !p.eof() {
+				c := rune(p.peek())
+				if !(unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '-') {
+					break
+				}
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("expected key")
+			}
+			key = p.s[start:p.pos]
+		}
+
+		keys = append(keys, key)
+
+		p.skipSpace(false)
+		if !p.consume('.') {
+			return keys, nil
+		}
+	}
+}
+
+func (p *tomlParser) parseKeyValue(m map[string]interface{}) error {
+	keys, err := p.parseKeys()
+	if err != nil {
+		return err
+	}
+
+	if !p.consume('=') {
+		return p.errorf("expected =")
+	}
+
+	t, err := p.table(m, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+
+	key := keys[len(keys)-1]
+	if _, ok := t[key]; ok {
+		return p.errorf("duplicate key %q", key)
+	}
+
+	p.skipSpace(false)
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	t[key] = v
+	return nil
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.parseString()
+
+	case c == '[':
+		p.pos++
+		arr := []interface{}{}
+		for {
+			p.skipSpace(true)
+			if p.consume(']') {
+				return arr, nil
+			}
+
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+
+			p.skipSpace(true)
+			if !p.consume(',') {
+				p.skipSpace(true)
+				if !p.consume(']') {
+					return nil, p.errorf("expected , or ]")
+				}
+				return arr, nil
+			}
+		}
+
+	case c == '{':
+		p.pos++
+		t := map[string]interface{}{}
+		for {
+			p.skipSpace(false)
+			if p.consume('}') {
+				return t, nil
+			}
+
+			if err := p.parseKeyValue(t); err != nil {
+				return nil, err
+			}
+
+			p.skipSpace(false)
+			if !p.consume(',') {
+				if !p.consume('}') {
+					return nil, p.errorf("expected , or }")
+				}
+				return t, nil
+			}
+		}
+
+	default:
+		start := p.pos
+		for !p.eof() && strings.IndexByte(" \t\r\n,]}#", p.peek()) < 0 {
+			p.pos++
+		}
+
+		v := p.s[start:p.pos]
+
+		switch v {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+
+		if n, err := strconv.ParseFloat(strings.Replace(v, "_", "", -1), 64); err == nil {
+			return n, nil
+		}
+
+		return nil, p.errorf("invalid value %q", v)
+	}
+}
+
+func (p *tomlParser) parseString() (string, error) {
+	quote := p.s[p.pos]
+	start := p.pos
+	p.pos++
+
+	for !p.eof() && p.peek() != quote && p.peek() != '\n' {
+		if quote == '"' && p.peek() == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+
+	if !p.consume(quote) {
+		return "", p.errorf("unterminated string")
+	}
+
+	if quote == '\'' { // literal string
+		return p.s[start+1 : p.pos-1], nil
+	}
+
+	s, err := strconv.Unquote(p.s[start:p.pos])
+	if err != nil {
+		return "", p.errorf("invalid string %s", p.s[start:p.pos])
+	}
+
+	return s, nil
+}
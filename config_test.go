@@ -0,0 +1,207 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]interface{}
+	}{
+		{"empty", "", map[string]interface{}{}},
+		{"comments", "# a comment\n\n  # another one\n", map[string]interface{}{}},
+		{"string", `lang = "c"`, map[string]interface{}{"lang": "c"}},
+		{"escapes", `s = "a\tb\"c"`, map[string]interface{}{"s": "a\tb\"c"}},
+		{"literal string", `s = 'C:\dir'`, map[string]interface{}{"s": `C:\dir`}},
+		{"booleans", "a = true\nb = false", map[string]interface{}{"a": true, "b": false}},
+		{"numbers", "a = 42\nb = -1.5\nc = 1_000", map[string]interface{}{"a": 42.0, "b": -1.5, "c": 1000.0}},
+		{"trailing comment", `lang = "c" # the language`, map[string]interface{}{"lang": "c"}},
+		{"quoted key", `"fmt.Println" = "print"`, map[string]interface{}{"fmt.Println": "print"}},
+		{"dotted key", `a.b = 1`, map[string]interface{}{"a": map[string]interface{}{"b": 1.0}}},
+		{"array", `include = ["*.go", 'cmd/**']`,
+			map[string]interface{}{"include": []interface{}{"*.go", "cmd/**"}}},
+		{"multiline array", "include = [\n  \"a\", # first\n  \"b\",\n]\nlang = \"c\"",
+			map[string]interface{}{"include": []interface{}{"a", "b"}, "lang": "c"}},
+		{"empty array", `exclude = []`, map[string]interface{}{"exclude": []interface{}{}}},
+		{"inline table", `idents = { new = "new_", "a b" = "c" }`,
+			map[string]interface{}{"idents": map[string]interface{}{"new": "new_", "a b": "c"}}},
+		{"tables", "lang = \"c\"\n[idents]\nfoo = \"bar\"\n\n[languages.python]\noutdir = \"py\"\n",
+			map[string]interface{}{
+				"lang":   "c",
+				"idents": map[string]interface{}{"foo": "bar"},
+				"languages": map[string]interface{}{
+					"python": map[string]interface{}{"outdir": "py"},
+				},
+			}},
+		{"reopened table", "[languages.c]\noutdir = \"c\"\n[languages.go]\noutdir = \"go\"\n",
+			map[string]interface{}{
+				"languages": map[string]interface{}{
+					"c":  map[string]interface{}{"outdir": "c"},
+					"go": map[string]interface{}{"outdir": "go"},
+				},
+			}},
+		{"crlf", "a = 1\r\nb = 2\r\n", map[string]interface{}{"a": 1.0, "b": 2.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML(tt.src)
+			if err != nil {
+				t.Fatalf("parseTOML(%q): %v", tt.src, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTOML(%q) = %#v, want %#v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"missing value", "a = ", `line 1: invalid value ""`},
+		{"missing =", "a 1", "line 1: expected ="},
+		{"missing key", "= 1", "line 1: expected key"},
+		{"duplicate key", "a = 1\na = 2", `line 2: duplicate key "a"`},
+		{"unterminated string", "\n\na = \"b", "line 3: unterminated string"},
+		{"unterminated table", "[a", "line 1: expected ]"},
+		{"unterminated array", "a = [1, 2", "line 1: expected , or ]"},
+		{"unterminated inline table", "a = { b = 1", "line 1: expected , or }"},
+		{"two values", "a = 1 2", "line 1: expected end of line"},
+		{"not a table", "a = 1\n[a]", `line 2: "a" is not a table`},
+		{"invalid value", "a = yes", `line 1: invalid value "yes"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML(tt.src)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("parseTOML(%q) error = %v, want %q", tt.src, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGlobs(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		nomatch []string
+	}{
+		{"*.go", []string{"a.go", "main.go"}, []string{"dir/a.go", "a.gox", "a_go"}},
+		{"cmd/*", []string{"cmd/a", "cmd/a.go"}, []string{"cmd", "cmd/a/b.go", "xcmd/a"}},
+		{"**/*_gen.go", []string{"a_gen.go", "x/a_gen.go", "x/y/a_gen.go"}, []string{"a.go", "x/a_gen.gox"}},
+		{"vendor/**", []string{"vendor/a", "vendor/a/b.go"}, []string{"vendor", "x/vendor/a"}},
+		{"a?c.go", []string{"abc.go", "a.c.go"}, []string{"ac.go", "a/c.go"}},
+		{"internal", []string{"internal"}, []string{"internal/a.go", "x/internal"}},
+		{"a+b(c).go", []string{"a+b(c).go"}, []string{"aab(c).go", "a+bc.go"}},
+	}
+
+	for _, tt := range tests {
+		globs, err := compileGlobs([]string{tt.pattern})
+		if err != nil {
+			t.Fatalf("compileGlobs(%q): %v", tt.pattern, err)
+		}
+
+		for _, path := range tt.match {
+			if !matchAny(globs, path) {
+				t.Errorf("%q should match %q", tt.pattern, path)
+			}
+		}
+		for _, path := range tt.nomatch {
+			if matchAny(globs, path) {
+				t.Errorf("%q should not match %q", tt.pattern, path)
+			}
+		}
+	}
+
+	globs, err := compileGlobs([]string{"*.c", "*.h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matchAny(globs, "a.h") || matchAny(globs, "a.go") || matchAny(nil, "a.h") {
+		t.Errorf("matchAny with more patterns")
+	}
+}
+
+func TestForLang(t *testing.T) {
+	cfg, err := parseConfig("walkngo.toml", strings.Join([]string{
+		`outdir = "out"`,
+		`exclude = ["vendor/**"]`,
+		`mappings = ["base.json"]`,
+		`[idents]`,
+		`a = "b"`,
+		`[languages.java]`,
+		`java_group = "com.example"`,
+		`[languages.c]`,
+		`outdir = "cc"`,
+		`mappings = ["c.json"]`,
+		`idents = { c = "d" }`,
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := cfg.ForLang("c", "cc")
+	if opts.Outdir != "cc" || !reflect.DeepEqual(opts.Exclude, []string{"vendor/**"}) ||
+		!reflect.DeepEqual(opts.Mappings, []string{"base.json", "c.json"}) ||
+		!reflect.DeepEqual(opts.Idents, map[string]string{"a": "b", "c": "d"}) {
+		t.Errorf("ForLang(c) = %+v", opts)
+	}
+
+	if opts := cfg.ForLang("python"); opts.Outdir != "out" || len(opts.Idents) != 1 || len(opts.JavaGroup) > 0 {
+		t.Errorf("ForLang(python) = %+v", opts)
+	}
+
+	if opts := cfg.ForLang("java"); opts.JavaGroup != "com.example" {
+		t.Errorf("ForLang(java) = %+v", opts)
+	}
+
+	if _, err := parseConfig("walkngo.json", `{"unknown": 1}`); err == nil {
+		t.Errorf("unknown fields should be rejected")
+	}
+}
+
+// the example in the README must be a valid configuration file
+func TestREADMEConfig(t *testing.T) {
+	data, err := os.ReadFile("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readme := string(data)
+
+	start := strings.Index(readme, "\nConfiguration file:\n")
+	if start < 0 {
+		t.Fatal("no configuration section in README.md")
+	}
+
+	// the first indented block of the section
+	var lines []string
+	for _, line := range strings.Split(readme[start:], "\n") {
+		if strings.HasPrefix(line, "    ") {
+			lines = append(lines, line[4:])
+		} else if len(lines) > 0 && len(line) > 0 {
+			break
+		}
+	}
+
+	cfg, err := parseConfig("walkngo.toml", strings.Join(lines, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Lang != "c" || !reflect.DeepEqual(cfg.Mappings, []string{"mappings.json"}) ||
+		cfg.Selectors["strings.Join"] != "join" || cfg.Languages["c"].Outdir != "out/c" ||
+		cfg.Languages["java"].JavaGroup != "com.example" {
+		t.Errorf("README configuration = %+v", cfg)
+	}
+}
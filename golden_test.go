@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raff/walkngo/printer"
+	"github.com/raff/walkngo/walker"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// TestGolden converts the programs in testdata/golden to C++ and compares them with the .cc files.
+// If g++ is available (and not in -short mode) the converted programs are also built with the runtime
+// and their output is compared with the .out files (the output of go run).
+func TestGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "golden", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no golden programs")
+	}
+
+	gxx, _ := exec.LookPath("g++")
+
+	for _, src := range files {
+		name := strings.TrimSuffix(filepath.Base(src), ".go")
+		base := strings.TrimSuffix(src, ".go")
+
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w := walkngo.NewWalker(&printer.CPrinter{}, &buf, false)
+			w.SetPackageFiles(false)
+			if err := w.WalkFile(src); err != nil {
+				t.Fatal(err)
+			}
+
+			if *update {
+				if err := os.WriteFile(base+".cc", buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(base + ".cc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("%s.cc doesn't match (run go test -run TestGolden -update to update it):\n%s", name, got)
+			}
+
+			if len(gxx) == 0 || testing.Short() {
+				return
+			}
+
+			bin := filepath.Join(t.TempDir(), name)
+			build := exec.Command(gxx, "-std=c++17", "-pthread", "-I"+filepath.Join("runtime", "c"), "-o", bin, "-x", "c++", "-")
+			build.Stdin = bytes.NewReader(buf.Bytes())
+			if out, err := build.CombinedOutput(); err != nil {
+				t.Fatalf("build failed: %v\n%s", err, out)
+			}
+
+			got, err := exec.Command(bin).CombinedOutput()
+			if err != nil {
+				t.Fatalf("run failed: %v\n%s", err, got)
+			}
+
+			want, err = os.ReadFile(base + ".out")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("output doesn't match %s.out:\n%s", name, got)
+			}
+		})
+	}
+}
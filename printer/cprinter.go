@@ -3,10 +3,23 @@ package printer
 import (
 	"fmt"
 	"io"
+	gopath "path"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// cReserved are the C++ reserved words (and names used by the generated code)
+var cReserved = NewReservedWords(`
+	alignas alignof and and_eq asm auto bitand bitor bool break case catch char char8_t char16_t char32_t
+	class compl concept const consteval constexpr constinit const_cast continue co_await co_return co_yield
+	decltype default delete do double dynamic_cast else enum explicit export extern false float for friend
+	goto if inline int long mutable namespace new noexcept not not_eq nullptr operator or or_eq private
+	protected public register reinterpret_cast requires return short signed sizeof static static_assert
+	static_cast struct switch template this thread_local throw true try typedef typeid typename union
+	unsigned using virtual void volatile wchar_t while xor xor_eq NULL std`)
+
 const (
 	NULLP = "nullptr"
 )
@@ -19,9 +32,39 @@ type CPrinter struct {
 	sameline bool
 	w        io.Writer
 
+	imports importMap // package name -> import path, for the library mappings
+
+	// Header enables the split mode: the types, prototypes and package variables go in a header
+	// per package (see DeclPrinter), the converted files only contain the definitions
+	Header bool
+	decl   bool // printing the header
+
+	// SharedPointers enables the reference counted pointers (Ptr in go.h, see HeapAllocator)
+	SharedPointers bool
+
+	blank int // used to generate unique names for the ignored values
+
+	pkg     string    // the current package
+	fields  []cField  // the names of the struct fields being printed
+	methods []cMethod // the methods of the interfaces being printed
+
 	ctx *CContext
 }
 
+// cField is a struct field, at the indentation level of its struct
+type cField struct {
+	level int
+	name  string
+}
+
+// cMethod is an interface method, at the indentation level of its interface
+type cMethod struct {
+	level   int
+	name    string
+	results string
+	params  string
+}
+
 // CContext is the context for a (function) block
 type CContext struct {
 	context ContextType
@@ -31,18 +74,38 @@ type CContext struct {
 	deferred int // used to generate unique names for "defer" callbacks
 
 	receiver        string // the name of the receiver, to be converted to "this"
+	this            string // the receiver object: "this" for a pointer receiver, "(*this)" for a value
 	ret_definitions string // used to define return variables
 	ret_values      string // used to "fill" empty returns
 
 	fall_through bool // fall through next case in switch
 
-	caseType string // the object of a switch type assertion
+	fn *cFunc // the current function (shared by the nested contexts)
 
 	next *CContext
 }
 
+// cFunc describes the function being printed
+type cFunc struct {
+	results  string // the return type ("" for void)
+	hasDefer bool   // the function has deferred calls (see Defers in go.h)
+	depth    int    // the nesting level of the code blocks (1 for the function body)
+	main     bool   // the program entry point, that sets the command line arguments
+}
+
+// inFunc returns true if the context is inside a function
+func (ctx *CContext) inFunc() bool {
+	for ; ctx != nil; ctx = ctx.next {
+		if ctx.context == FUNCONTEXT {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (ctx *CContext) Selector(s string) string {
-	if ctx != nil && ctx.receiver == s {
+	if ctx != nil && len(ctx.receiver) > 0 && s == ctx.this {
 		return "this->"
 	}
 
@@ -53,12 +116,19 @@ func (p *CPrinter) Reset() {
 	p.level = 0
 	p.sameline = false
 
+	p.imports = nil
 	p.ctx = nil
+	p.blank = 0
+	p.fields = nil
+	p.methods = nil
 }
 
 func (p *CPrinter) PushContext(c ContextType) {
 	if p.ctx == nil {
 		p.ctx = &CContext{context: c}
+		if c == FUNCONTEXT {
+			p.ctx.fn = &cFunc{}
+		}
 		return
 	}
 
@@ -67,11 +137,20 @@ func (p *CPrinter) PushContext(c ContextType) {
 		iota_count:      p.ctx.iota_count,
 		deferred:        p.ctx.deferred,
 		receiver:        p.ctx.receiver,
+		this:            p.ctx.this,
 		ret_definitions: p.ctx.ret_definitions,
 		ret_values:      p.ctx.ret_values,
 		fall_through:    p.ctx.fall_through,
+		fn:              p.ctx.fn,
 		next:            p.ctx,
 	}
+
+	if c == FUNCONTEXT {
+		// a new function (or function literal), with its own results
+		p.ctx.ret_definitions = ""
+		p.ctx.ret_values = ""
+		p.ctx.fn = &cFunc{}
+	}
 }
 
 func (p *CPrinter) PopContext() {
@@ -138,6 +217,49 @@ func (p *CPrinter) PrintBlockStart(b BlockType, empty bool) {
 		p.PrintLevel(NL, p.ctx.ret_definitions)
 		p.ctx.ret_definitions = "" // this gets printed only once
 	}
+
+	fn := p.ctx.funcBody(b, UP)
+	if fn != nil && fn.main {
+		p.PrintLevel(SEMI, "os::Args = os::detail::args(argc, argv)")
+	}
+
+	if fn != nil && fn.hasDefer {
+		// the deferred calls run when the function returns, panics or recovers
+		p.PrintLevel(SEMI, "Defers _defers")
+		if len(fn.results) > 0 && len(p.ctx.ret_values) == 0 {
+			p.PrintLevel(SEMI, fn.results+" _result{}")
+		}
+		p.PrintLevel(NL, "try {")
+		p.UpdateLevel(UP)
+		p.PrintLevel(NL, "{")
+		p.UpdateLevel(UP)
+	}
+}
+
+// funcBody tracks the code blocks of the current function and returns it
+// if the block is the function body
+func (ctx *CContext) funcBody(b BlockType, delta int) *cFunc {
+	if b != CODE || ctx == nil || ctx.fn == nil {
+		return nil
+	}
+
+	fn := ctx.fn
+
+	if delta == DOWN {
+		fn.depth--
+	}
+
+	depth := fn.depth
+
+	if delta == UP {
+		fn.depth++
+	}
+
+	if depth == 0 {
+		return fn
+	}
+
+	return nil
 }
 
 func (p *CPrinter) PrintBlockEnd(b BlockType) {
@@ -150,34 +272,95 @@ func (p *CPrinter) PrintBlockEnd(b BlockType) {
 		close = "}"
 	}
 
+	if fn := p.ctx.funcBody(b, DOWN); fn != nil && fn.hasDefer {
+		p.UpdateLevel(DOWN)
+		p.PrintLevel(NL, "}")
+		p.PrintLevel(NL, "_return:")
+		p.PrintLevel(SEMI, "_defers.Run()")
+		p.UpdateLevel(DOWN)
+		p.PrintLevel(NL, "} catch (...) {")
+		p.UpdateLevel(UP)
+		p.PrintLevel(SEMI, "_defers.Recover()")
+		p.UpdateLevel(DOWN)
+		p.PrintLevel(NL, "}")
+
+		if len(p.ctx.ret_values) > 0 {
+			p.PrintReturn("", false)
+		} else if len(fn.results) > 0 {
+			p.PrintLevel(SEMI, "return _result")
+		}
+	}
+
 	p.UpdateLevel(DOWN)
 	p.PrintLevel(NONE, close)
 }
 
 func (p *CPrinter) PrintPackage(name string) {
 	p.PrintLevel(NL, "//package", name)
-	p.PrintLevel(NL, "#include <go.h>")
+	p.pkg = name
+
+	if p.Header {
+		p.PrintLevel(NL, fmt.Sprintf("#include %q", name+".h"))
+	} else {
+		p.PrintLevel(NL, "#include <go.h>")
+	}
 }
 
 func (p *CPrinter) PrintImport(name, path string) {
 	p.PrintLevel(NL, "//import", name, path)
 
-	switch path {
-	case `"fmt"`:
-		p.PrintLevel(NL, "#include <fmt.h>")
+	m := p.imports.add("c", name, path)
+	if m != nil {
+		for _, line := range m.Imports {
+			p.PrintLevel(NL, line)
+		}
+	}
 
-	case `"sync"`:
-		p.PrintLevel(NL, "#include <sync.h>")
+	if m == nil || len(m.Package) == 0 {
+		if ipath, err := strconv.Unquote(path); err == nil {
+			// the package namespace is the last element of the import path
+			p.printNamespaceAlias(name, cNamespace(gopath.Base(ipath)))
+		}
+	}
+}
+
+// PrintLocalImport includes the header of a converted package (see Header)
+func (p *CPrinter) PrintLocalImport(imp LocalImport) {
+	if !p.Header {
+		// there are no package headers to include
+		p.PrintImport(imp.Name, imp.Path)
+		return
+	}
+
+	p.PrintLevel(NL, "//import", imp.Name, imp.Path)
+	p.PrintLevel(NL, fmt.Sprintf("#include %q", gopath.Join(imp.Dir, imp.Package+".h")))
 
-	case `"errors"`:
-		p.PrintLevel(NL, "#include <errors.h>")
+	p.imports.add("c", imp.LocalName(), imp.Path)
+	p.printNamespaceAlias(imp.Name, cNamespace(imp.Package))
+}
 
-	case `"time"`:
-		p.PrintLevel(NL, "#include <go_time.h>")
+// printNamespaceAlias makes the namespace ns available with the import name
+func (p *CPrinter) printNamespaceAlias(name, ns string) {
+	switch name {
+	case "", "_", ns:
+	case ".":
+		p.PrintLevel(SEMI, "using namespace", ns)
+	default:
+		p.PrintLevel(SEMI, "namespace", name, "=", ns)
 	}
 }
 
 func (p *CPrinter) PrintType(name, typedef string) {
+	if p.Header && !p.decl && !p.ctx.inFunc() {
+		return // in the header
+	}
+
+	if !p.ctx.inFunc() && strings.HasPrefix(typedef, "struct _"+name+" ") {
+		// the typedef is in the forward declarations
+		p.PrintLevel(SEMI, typedef)
+		return
+	}
+
 	if strings.Contains(typedef, "%") {
 		// FuncType
 		p.PrintLevel(SEMI, "typedef", fmt.Sprintf(typedef, "("+name+")"))
@@ -187,6 +370,17 @@ func (p *CPrinter) PrintType(name, typedef string) {
 }
 
 func (p *CPrinter) PrintValue(vtype, typedef, names, values string, ntuple, vtuple bool) {
+	if p.Header && !p.ctx.inFunc() {
+		if !p.decl {
+			return // in the header
+		}
+
+		if vtype == "var" {
+			// an inline variable is defined only once, even if the header is included by several files
+			vtype = "inline"
+		}
+	}
+
 	if vtype == "var" {
 		vtype = ""
 	} else if vtype == "const" && len(values) == 0 {
@@ -199,10 +393,17 @@ func (p *CPrinter) PrintValue(vtype, typedef, names, values string, ntuple, vtup
 		i := strings.Index(typedef, "[")
 		names += typedef[i:]
 		typedef = typedef[:i]
+	} else if strings.HasPrefix(typedef, "*") {
+		// *T -> T*
+		i := strings.LastIndex(typedef, "*") + 1
+		typedef = typedef[i:] + typedef[:i]
 	}
 
 	if ntuple && len(values) > 0 {
 		names = fmt.Sprintf("std::tie(%s)", names)
+	} else if len(values) == 0 && len(typedef) > 0 {
+		// the zero value
+		names = strings.ReplaceAll(names, ", ", "{}, ") + "{}"
 	}
 
 	p.PrintLevel(NONE, vtype, typedef, names)
@@ -224,12 +425,34 @@ func (p *CPrinter) PrintStmt(stmt, expr string) {
 		p.PrintLevel(SEMI, "// fallthrough")
 
 	case stmt == "go":
-		// start a goroutine (or a thread)
-		p.PrintLevel(SEMI, fmt.Sprintf("Goroutine([](){ %s; })", expr))
+		// start a goroutine (see Scheduler in go.h), with the function and the arguments evaluated now
+		fun, args := cBindCall(expr)
+		if len(args) > 0 {
+			fun += ", " + args
+		}
+		p.PrintLevel(SEMI, "Goroutine("+fun+")")
 
 	case stmt == "defer":
-		p.PrintLevel(SEMI, fmt.Sprintf("Deferred defer%d([](){ %s; })", p.ctx.deferred, expr))
-		p.ctx.deferred++
+		// the arguments are evaluated now, the call when the function returns (see Defers in go.h)
+		if p.ctx.fn != nil && p.ctx.fn.depth == 1 {
+			fun, args := cSplitCall(expr)
+
+			call := "[&]() { " + fun + "(); }"
+			if len(args) > 0 {
+				call = "[&](auto... _a) { " + fun + "(_a...); }, " + args
+			}
+
+			p.PrintLevel(SEMI, fmt.Sprintf("Deferred _defer%d(_defers, ", p.ctx.deferred)+call+")")
+			p.ctx.deferred++
+		} else {
+			// in a nested block the variables may be gone when the call runs
+			fun, args := cBindCall(expr)
+			if len(args) > 0 {
+				fun += ", " + args
+			}
+
+			p.PrintLevel(SEMI, "_defers.Defer("+fun+")")
+		}
 
 	case len(stmt) > 0:
 		p.PrintLevel(SEMI, stmt, expr)
@@ -240,41 +463,223 @@ func (p *CPrinter) PrintStmt(stmt, expr string) {
 }
 
 func (p *CPrinter) PrintReturn(expr string, tuple bool) {
+	if fn := p.ctx.fn; fn != nil && fn.hasDefer && fn.depth > 0 {
+		// set the results and run the deferred calls, that can change them
+		if len(expr) > 0 {
+			if tuple {
+				expr = fmt.Sprintf("std::make_tuple(%s)", expr)
+			}
+
+			if names := p.Chop(p.ctx.ret_values); strings.Contains(names, ", ") {
+				p.PrintLevel(SEMI, fmt.Sprintf("std::tie(%s) = %s", names, expr))
+			} else if len(names) > 0 {
+				p.PrintLevel(SEMI, fmt.Sprintf("%s = %s", names, expr))
+			} else {
+				p.PrintLevel(SEMI, fmt.Sprintf("_result = %s", expr))
+			}
+		}
+
+		p.PrintLevel(SEMI, "goto _return")
+		return
+	}
+
 	if len(expr) == 0 && len(p.ctx.ret_values) > 0 {
 		expr = p.Chop(p.ctx.ret_values)
 		tuple = strings.Contains(expr, ", ")
 	}
 
 	if tuple {
-		expr = fmt.Sprintf("make_tuple(%s)", expr)
+		expr = fmt.Sprintf("std::make_tuple(%s)", expr)
 	}
 
 	p.PrintStmt("return", expr)
 }
 
 func (p *CPrinter) PrintFunc(receiver, name, params, results string) {
+	if p.ctx.fn != nil {
+		p.ctx.fn.results = IfTrue(cResults(results), len(results) > 0)
+	}
+
 	if len(receiver) == 0 && len(params) == 0 && len(results) == 0 && name == "main" {
 		// the "main"
 		results = "int"
 		params = "int argc, char **argv"
-	} else {
-		if len(results) == 0 {
-			results = "void"
-		} else if IsMultiValue(results) {
-			results = fmt.Sprintf("std::tuple<%s>", results)
+
+		if p.ctx.fn != nil {
+			p.ctx.fn.main = true
 		}
+	} else {
+		results = cResults(results)
 
 		if len(receiver) > 0 {
+			// type name (the receiver name is optional)
 			parts := strings.SplitN(receiver, " ", 2)
-			receiver = "/* " + parts[1] + " */ " + strings.TrimRight(parts[0], "*") + "::"
+			receiver = strings.TrimRight(parts[0], "*") + "::"
 
-			p.ctx.receiver = parts[1]
+			if len(parts) > 1 {
+				receiver = "/* " + parts[1] + " */ " + receiver
+
+				p.ctx.receiver = parts[1]
+				p.ctx.this = "(*this)"
+				if strings.HasSuffix(parts[0], "*") {
+					p.ctx.this = "this"
+				}
+			}
 		}
 	}
 
 	fmt.Fprintf(p.w, "%s %s%s(%s) ", results, receiver, name, params)
 }
 
+// SetDefer is called before printing the body of a function with deferred calls
+func (p *CPrinter) SetDefer(hasDefer bool) {
+	if p.ctx != nil && p.ctx.fn != nil {
+		p.ctx.fn.hasDefer = hasDefer
+	}
+}
+
+// PrintDeferScope declares the Deferred that runs the calls deferred in the blocks of the next statement
+func (p *CPrinter) PrintDeferScope() {
+	p.PrintLevel(SEMI, fmt.Sprintf("Deferred _defer%d(_defers)", p.ctx.deferred))
+	p.ctx.deferred++
+}
+
+// FormatDivisor checks the divisor of an integer division (checkDivisor in go.h panics if it's zero)
+func (p *CPrinter) FormatDivisor(expr string) string {
+	return fmt.Sprintf("checkDivisor(%s)", expr)
+}
+
+// cSplitCall splits a call expression in function and arguments
+func cSplitCall(call string) (fun, args string) {
+	if !strings.HasSuffix(call, ")") {
+		return call, ""
+	}
+
+	depth := 0
+	for i := len(call) - 1; i >= 0; i-- {
+		switch call[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return call[:i], call[i+1 : len(call)-1]
+			}
+		}
+	}
+
+	return call, ""
+}
+
+// cBindCall splits a call expression in a lambda that makes the call and the arguments to pass to it,
+// for the calls that run later (go and defer). The function is evaluated now, as the arguments:
+// the receiver of a method is taken by address (or by value if it's not addressable) and
+// a function literal is copied, so that it keeps sharing the variables it captures.
+func cBindCall(call string) (fun, args string) {
+	fun, args = cSplitCall(call)
+
+	if recv, op, name := cSplitSelector(fun); len(op) == 0 {
+		if cIsName(fun) {
+			// a function, or a variable with a function value
+			fun = fmt.Sprintf("[=](auto... _a) { %s(_a...); }", fun)
+		} else {
+			fun = fmt.Sprintf("[_f = %s](auto... _a) { _f(_a...); }", fun)
+		}
+	} else if op == "->" {
+		fun = fmt.Sprintf("[_r = %s](auto... _a) { _r->%s(_a...); }", recv, name)
+	} else if cIsAddressable(recv) {
+		fun = fmt.Sprintf("[_r = &%s](auto... _a) { _r->%s(_a...); }", recv, name)
+	} else {
+		fun = fmt.Sprintf("[_r = %s](auto... _a) mutable { _r.%s(_a...); }", recv, name)
+	}
+
+	return fun, args
+}
+
+// cSplitSelector splits a selector expression (x.name or x->name), returning op == "" if it's not a selector
+func cSplitSelector(expr string) (recv, op, name string) {
+	depth := 0
+	quote := byte(0)
+
+	for i := len(expr) - 1; i > 0; i-- {
+		c := expr[i]
+
+		switch {
+		case quote != 0:
+			if c == quote && expr[i-1] != '\\' {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ')' || c == ']' || c == '}':
+			depth++
+		case c == '(' || c == '[' || c == '{':
+			depth--
+		case depth == 0 && c == '.':
+			recv, op, name = expr[:i], ".", expr[i+1:]
+		case depth == 0 && c == '>' && expr[i-1] == '-':
+			recv, op, name = expr[:i-1], "->", expr[i+1:]
+		default:
+			continue
+		}
+
+		if len(op) > 0 {
+			if !cIsName(name) {
+				// i.e. the result type of a lambda
+				break
+			}
+
+			return
+		}
+	}
+
+	return expr, "", ""
+}
+
+// cIsName returns true if expr is a (qualified) name
+func cIsName(expr string) bool {
+	return len(expr) > 0 && strings.IndexFunc(expr, func(r rune) bool {
+		return !(r == '_' || r == ':' || r == '<' || r == '>' || r == ',' || r == ' ' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	}) < 0
+}
+
+// cIsAddressable returns true if expr is a variable, a field or an element (a name, x.f, x->f, x[i])
+func cIsAddressable(expr string) bool {
+	expr = strings.TrimPrefix(expr, "(*this)")
+
+	depth := 0
+	for _, r := range expr {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case depth > 0:
+		case r == '_' || r == ':' || r == '.' || r == '-' || r == '>' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		default:
+			return false
+		}
+	}
+
+	return len(expr) > 0 && depth == 0
+}
+
+// cNamespace returns the namespace for a package
+func cNamespace(pkg string) string {
+	return cReserved.Escape(Identifier(pkg))
+}
+
+// cResults returns the return type for a function
+func cResults(results string) string {
+	if len(results) == 0 {
+		return "void"
+	} else if IsMultiValue(results) {
+		return fmt.Sprintf("std::tuple<%s>", results)
+	}
+
+	return results
+}
+
 func (p *CPrinter) PrintFor(init, cond, post string) {
 	init = strings.TrimRight(init, SEMI)
 	post = strings.TrimRight(post, SEMI)
@@ -303,18 +708,31 @@ func (p *CPrinter) PrintFor(init, cond, post string) {
 }
 
 func (p *CPrinter) PrintRange(key, value, expr string) {
-	// for maps a std::pair is returned where key is p.first and value is p.second
-	if key == "_" {
-		key, value = value, ""
-	}
+	// Range (in go.h) returns (key, value) pairs for slices, arrays and maps,
+	// and (value, index) pairs for channels (where only the key is allowed)
+	p.printRange("Range", key, value, expr)
+}
 
-	p.PrintLevel(NONE, "for (auto", key)
+// PrintStringRange iterates over the runes of a string, with RangeRunes (in go.h)
+func (p *CPrinter) PrintStringRange(key, value, expr string) {
+	p.printRange("RangeRunes", key, value, expr)
+}
 
-	if len(value) > 0 {
-		p.Print(",", value)
+func (p *CPrinter) printRange(fn, key, value, expr string) {
+	if key == "std::ignore" {
+		key = ""
 	}
 
-	p.Print(":", expr, ") ")
+	switch {
+	case len(key) > 0 && len(value) > 0:
+		p.PrintLevel(NONE, fmt.Sprintf("for (auto [%s, %s] : %s(%s)) ", key, value, fn, expr))
+	case len(key) > 0:
+		p.PrintLevel(NONE, fmt.Sprintf("for (auto [%s, _] : %s(%s)) ", key, fn, expr))
+	case len(value) > 0:
+		p.PrintLevel(NONE, fmt.Sprintf("for (auto [_, %s] : %s(%s)) ", value, fn, expr))
+	default:
+		p.PrintLevel(NONE, fmt.Sprintf("for (auto _ : %s(%s)) ", fn, expr))
+	}
 }
 
 func (p *CPrinter) PrintSwitch(init, expr string) {
@@ -327,33 +745,113 @@ func (p *CPrinter) PrintSwitch(init, expr string) {
 func (p *CPrinter) PrintCase(expr string) {
 	p.ctx.fall_through = false
 
-	if p.ctx.caseType != "" {
-		if len(expr) > 0 {
-			p.PrintLevel(NL,
-				fmt.Sprintf("std::tie(v, ok) = typeAssert<%v>(%v); if (ok) {", p.ctx.caseType, expr))
+	if len(expr) > 0 {
+		p.PrintLevel(COLON, "case", expr)
+	} else {
+		p.PrintLevel(NL, "default:")
+	}
+}
+
+func (p *CPrinter) PrintEndCase() {
+	if !p.ctx.fall_through {
+		p.PrintLevel(SEMI, "break")
+	}
+}
+
+// PrintSelect prints a select as a switch on the case chosen by Select (see go.h),
+// that evaluates the channels and the values to send when it's created
+func (p *CPrinter) PrintSelect(chans, values []string, hasDefault bool) []string {
+	cases := make([]string, len(chans))
+	received := make([]string, len(chans))
+
+	for i, ch := range chans {
+		if len(values[i]) > 0 {
+			cases[i] = fmt.Sprintf("SelectSend(%s, %s)", ch, values[i])
 		} else {
-			p.PrintLevel(NL, "} else {")
+			cases[i] = fmt.Sprintf("SelectRecv(%s)", ch)
 		}
 
-		return
+		received[i] = fmt.Sprintf("std::get<%d>(_select)", i)
 	}
 
-	if len(expr) > 0 {
-		p.PrintLevel(COLON, "case", expr)
+	p.PrintLevel(NONE, fmt.Sprintf("switch (auto _select = Select{%s}; _select.Wait(%v))", strings.Join(cases, ", "), hasDefault))
+	return received
+}
+
+// PrintSelectCase opens a block for the case, that can declare the received values
+func (p *CPrinter) PrintSelectCase(index int) {
+	if index < 0 {
+		p.PrintLevel(NL, "default: {")
 	} else {
-		p.PrintLevel(NL, "default:")
+		p.PrintLevel(NL, fmt.Sprintf("case %d: {", index))
 	}
 }
 
-func (p *CPrinter) PrintEndCase() {
-	if p.ctx.caseType != "" {
-		p.PrintLevel(NONE, "}")
+func (p *CPrinter) PrintEndSelectCase() {
+	p.PrintLevel(SEMI, "break")
+	p.PrintLevelIn(NL, "}")
+}
+
+// PrintTypeSwitch prints a type switch as a switch on the case chosen by typeCase (see go.h),
+// where each case is a std::tuple of its types
+func (p *CPrinter) PrintTypeSwitch(init, expr string, cases [][]string) {
+	if len(init) > 0 {
+		p.PrintLevel(SEMI, init)
+	}
+
+	tuples := make([]string, len(cases))
+	for i, types := range cases {
+		tuples[i] = fmt.Sprintf("std::tuple<%s>", strings.Join(cTypeCase(types), ", "))
+	}
+
+	p.PrintLevel(NONE, fmt.Sprintf("switch (const auto& _switch = %s; typeCase<%s>(_switch))", expr, strings.Join(tuples, ", ")))
+}
+
+// PrintTypeCase opens a block for the case, that declares the switch variable
+func (p *CPrinter) PrintTypeCase(index int, types []string, name string) {
+	if index < 0 {
+		p.PrintLevel(NL, "default: {")
+	} else {
+		p.PrintLevel(NL, fmt.Sprintf("case %d: {", index))
+	}
+
+	if len(name) == 0 {
 		return
 	}
 
-	if !p.ctx.fall_through {
-		p.PrintLevel(SEMI, "break")
+	p.UpdateLevel(UP)
+	if len(types) == 1 && types[0] != "nil" {
+		p.PrintLevel(SEMI, fmt.Sprintf("auto %s = typeAssert<%s>(_switch)", name, cPointerType(types[0])))
+	} else {
+		p.PrintLevel(SEMI, fmt.Sprintf("auto %s = _switch", name))
 	}
+	p.UpdateLevel(DOWN)
+}
+
+func (p *CPrinter) PrintEndTypeCase() {
+	p.PrintLevel(SEMI, "break")
+	p.PrintLevelIn(NL, "}")
+}
+
+// cTypeCase returns the types of a case of a type switch, where nil is the type of nullptr
+func cTypeCase(types []string) []string {
+	ctypes := make([]string, len(types))
+	for i, t := range types {
+		if t == "nil" {
+			t = "std::nullptr_t"
+		}
+		ctypes[i] = cPointerType(t)
+	}
+	return ctypes
+}
+
+// cPointerType returns the C++ type for a pointer type expression (*T -> T*)
+func cPointerType(t string) string {
+	if strings.HasPrefix(t, "*") {
+		i := strings.LastIndex(t, "*") + 1
+		t = t[i:] + t[:i]
+	}
+	return t
 }
 
 func (p *CPrinter) PrintIf(init, cond string) {
@@ -374,6 +872,24 @@ func (p *CPrinter) PrintEmpty() {
 }
 
 func (p *CPrinter) PrintAssignment(lhs, op, rhs string, ltuple, rtuple bool) {
+	if op == ":=" && ltuple {
+		// a structured binding, where the ignored values need a name
+		names := strings.Split(lhs, ", ")
+		for i, n := range names {
+			if n == "std::ignore" {
+				names[i] = fmt.Sprintf("_%d", p.blank)
+				p.blank++
+			}
+		}
+
+		if rtuple {
+			rhs = fmt.Sprintf("std::make_tuple(%s)", rhs)
+		}
+
+		p.PrintLevel(SEMI, fmt.Sprintf("auto [%s] = %s", strings.Join(names, ", "), rhs))
+		return
+	}
+
 	if op == ":=" {
 		// := means there are new variables to be declared (but of course I don't know the real type)
 		rtype, rvalue := cGuessType(rhs)
@@ -393,6 +909,31 @@ func (p *CPrinter) PrintAssignment(lhs, op, rhs string, ltuple, rtuple bool) {
 	p.PrintLevel(SEMI, lhs, op, rhs)
 }
 
+// PrintRedeclare unpacks the values in temporaries, since a structured binding can only declare new variables
+func (p *CPrinter) PrintRedeclare(lhs []string, isNew []bool, rhs string, rtuple bool) {
+	if rtuple {
+		rhs = fmt.Sprintf("std::make_tuple(%s)", rhs)
+	}
+
+	tmps := make([]string, len(lhs))
+	for i := range lhs {
+		tmps[i] = fmt.Sprintf("_%d", p.blank)
+		p.blank++
+	}
+
+	p.PrintLevel(SEMI, fmt.Sprintf("auto [%s] = %s", strings.Join(tmps, ", "), rhs))
+
+	for i, n := range lhs {
+		switch {
+		case n == "std::ignore":
+		case isNew[i]:
+			p.PrintLevel(SEMI, fmt.Sprintf("auto %s = %s", n, tmps[i]))
+		default:
+			p.PrintLevel(SEMI, fmt.Sprintf("%s = %s", n, tmps[i]))
+		}
+	}
+}
+
 func (p *CPrinter) PrintSend(ch, value string) {
 	p.PrintLevel(SEMI, fmt.Sprintf("%s.Send(%s)", ch, value))
 }
@@ -409,6 +950,9 @@ func (p *CPrinter) FormatIdent(id, itype string) (ret string) {
 	case "string":
 		ret = "std::string"
 
+	case "any":
+		ret = "std::any"
+
 	case "_":
 		ret = "std::ignore"
 
@@ -425,27 +969,82 @@ func (p *CPrinter) FormatIdent(id, itype string) (ret string) {
 		return "int"
 
 	default:
+		if p.ctx != nil && len(p.ctx.receiver) > 0 && id == p.ctx.receiver {
+			// the receiver is the object the method is called on
+			return p.ctx.this
+		}
+
 		ret = id
 	}
 
 	return
 }
 
+// the escapes for a raw string in a C++ string literal
+var cRawString = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
 func (p *CPrinter) FormatLiteral(lit string) string {
 	if len(lit) == 0 {
 		return lit
 	}
 
-	if lit[0] == '`' {
-		lit = strings.Replace(lit[1:len(lit)-1], `"`, `\\"`, -1)
-		lit = strings.Replace(lit, "\n", "\\n", -1)
+	switch lit[0] {
+	case '`':
+		// a raw string (the carriage returns are discarded, as in Go)
+		lit = cRawString.Replace(lit[1 : len(lit)-1])
 		lit = `"` + lit + `"`
+
+	case '"':
+		if s, err := strconv.Unquote(lit); err == nil && strings.ContainsRune(s, 0) {
+			// a std::string literal, that can contain '\0'
+			return cSplitHexEscapes(lit) + "s"
+		}
+
+		lit = cSplitHexEscapes(lit)
+
+	case '\'':
+		// a rune that is not a C++ char is a number
+		if r, _, tail, err := strconv.UnquoteChar(lit[1:len(lit)-1], '\''); err == nil && len(tail) == 0 && r >= utf8.RuneSelf {
+			lit = strconv.Itoa(int(r))
+		}
 	}
 
 	return lit
 }
 
+// cSplitHexEscapes splits a string literal after the \x escapes followed by a hex digit
+// (that in C++ would be part of the escape)
+func cSplitHexEscapes(lit string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(lit); i++ {
+		b.WriteByte(lit[i])
+
+		if lit[i] != '\\' || i+1 >= len(lit) {
+			continue
+		}
+
+		i++
+		b.WriteByte(lit[i])
+
+		if lit[i] == 'x' && i+3 < len(lit) && isHexDigit(lit[i+3]) {
+			b.WriteString(lit[i+1:i+3] + `" "`)
+			i += 2
+		}
+	}
+
+	return b.String()
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
 func (p *CPrinter) FormatCompositeLit(typedef, elt string) string {
+	if len(elt) == 0 && strings.HasPrefix(typedef, "Map<") {
+		return typedef + "(0)" // not a nil map
+	}
+
 	return fmt.Sprintf("%s{%s}", typedef, elt)
 }
 
@@ -457,18 +1056,37 @@ func (p *CPrinter) FormatStar(expr string) string {
 	return "*" + expr
 }
 
+// FormatRuneString encodes a rune as UTF-8 (runeString in go.h), since std::string(r) is not a conversion
+func (p *CPrinter) FormatRuneString(expr string) string {
+	return fmt.Sprintf("runeString(%s)", expr)
+}
+
+// FormatStringByte returns s[i] as a byte (a char is signed)
+func (p *CPrinter) FormatStringByte(expr, index string) string {
+	return fmt.Sprintf("byte(%s[%s])", expr, index)
+}
+
 func (p *CPrinter) FormatParen(expr string) string {
 	return fmt.Sprintf("(%s)", expr)
 }
 
 func (p *CPrinter) FormatUnary(op, operand string) string {
 	if op == "<-" {
-		return fmt.Sprintf("%s.Receive()", operand)
+		return p.FormatReceive(operand, false)
 	}
 
 	return fmt.Sprintf("%s%s", op, operand)
 }
 
+// FormatReceive receives from a channel, returning (value, ok) for the comma-ok form
+func (p *CPrinter) FormatReceive(ch string, check bool) string {
+	if check {
+		return fmt.Sprintf("%s.Lookup()", ch)
+	}
+
+	return fmt.Sprintf("%s.Receive()", ch)
+}
+
 func (p *CPrinter) FormatBinary(lhs, op, rhs string) string {
 	if op == "&^" {
 		// AND NOT
@@ -506,20 +1124,25 @@ func (p *CPrinter) FormatPair(v Pair, t FieldType) (ret string) {
 	if t == METHOD {
 		if len(name) == 0 {
 			ret = fmt.Sprintf("// extends %s", value)
+		} else if results, params, ok := cSplitFuncType(value); ok {
+			p.methods = append(p.methods, cMethod{p.level, name, results, params})
+			ret = fmt.Sprintf("Method<%s(%s)> %s", results, params, name)
 		} else {
-			ret = "virtual " + fmt.Sprintf(value, name)
+			ret = "virtual " + value + " " + name
 		}
 	} else if t == RESULT && len(name) > 0 {
 		ret = fmt.Sprintf("%s /* %s */", value, name)
 		if p.ctx != nil {
-			p.ctx.ret_definitions += fmt.Sprintf("%s %s;", value, name)
+			p.ctx.ret_definitions += fmt.Sprintf("%s %s{};", value, name)
 			p.ctx.ret_values += fmt.Sprintf("%s, ", name)
 		}
-	} else if t == PARAM && strings.Contains(value, "%s") {
-		ret = fmt.Sprintf(value, name)
 	} else if t == FIELD && len(name) == 0 {
+		p.fields = append(p.fields, cField{p.level, value})
 		ret = SplitAny(value, "<[")[0] + " " + value
 	} else if len(name) > 0 && len(value) > 0 {
+		if t == FIELD {
+			p.fields = append(p.fields, cField{p.level, SplitAny(name, "[")[0]})
+		}
 		ret = value + " " + name
 	} else {
 		ret = value + name
@@ -536,7 +1159,7 @@ func (p *CPrinter) FormatPair(v Pair, t FieldType) (ret string) {
 
 func (p *CPrinter) FormatArray(alen, elt string) string {
 	if alen == "" { // slice
-		return fmt.Sprintf("std::vector<%v>", elt)
+		return fmt.Sprintf("Slice<%v>", elt)
 	} else {
 		return fmt.Sprintf("%s[%s]", elt, alen)
 	}
@@ -546,20 +1169,39 @@ func (p *CPrinter) FormatArrayIndex(array, index, rtype string) string {
 	return fmt.Sprintf("%s[%s]", array, index)
 }
 
+// FormatMapIndex reads a map element, without inserting it (see Map in go.h)
 func (p *CPrinter) FormatMapIndex(array, index, rtype string, check bool) string {
-	return fmt.Sprintf("%s[%s]", array, index)
+	if check {
+		return fmt.Sprintf("%s.Lookup(%s)", array, index)
+	}
+
+	return fmt.Sprintf("%s.Get(%s)", array, index)
+}
+
+// FormatMapStore returns a reference to a map element, to be assigned
+func (p *CPrinter) FormatMapStore(m, key string) string {
+	return fmt.Sprintf("%s[%s]", m, key)
 }
 
 func (p *CPrinter) FormatSlice(slice, low, high, max string) string {
-	if max == "" {
-		return fmt.Sprintf("%s[%s:%s]", slice, low, high)
-	} else {
-		return fmt.Sprintf("%s[%s:%s:%s]", slice, low, high, max)
+	if len(low) == 0 {
+		low = "0"
+	}
+
+	args := []string{slice, low}
+	if len(high) > 0 {
+		args = append(args, high)
+	}
+	if len(max) > 0 {
+		args = append(args, max)
 	}
+
+	// SliceExpr (in go.h) works on slices, arrays and strings
+	return fmt.Sprintf("SliceExpr(%s)", strings.Join(args, ", "))
 }
 
 func (p *CPrinter) FormatMap(key, elt string) string {
-	return fmt.Sprintf("std::map<%s, %s>", key, elt)
+	return fmt.Sprintf("Map<%s, %s>", key, elt)
 }
 
 func (p *CPrinter) FormatKeyValue(key, value string, isMap bool) string {
@@ -572,20 +1214,82 @@ func (p *CPrinter) FormatKeyValue(key, value string, isMap bool) string {
 }
 
 func (p *CPrinter) FormatStruct(name, fields string) string {
+	// the fields of this struct are the last ones at the current level
+	i := len(p.fields)
+	for i > 0 && p.fields[i-1].level == p.level {
+		i--
+	}
+	names := p.fields[i:]
+	p.fields = p.fields[:i]
+
+	if len(fields) > 0 && len(name) > 0 && !p.ctx.inFunc() {
+		// the type name and a visitor for the fields, used by fmt (a local class can't have them)
+		// with the Go names (not the escaped ones)
+		fields += p.indent() + fmt.Sprintf("static constexpr const char *_type = %q;\n", p.pkg+"."+cReserved.Unescape(name))
+		fields += p.indent() + "template<class F> void _fields(F f) {"
+		for _, f := range names {
+			fields += fmt.Sprintf(" f(%q, %s);", cReserved.Unescape(f.name), f.name)
+		}
+		fields += " }\n"
+	}
+
+	if len(names) > 0 && len(name) > 0 {
+		// == and != compare the fields, < orders the map keys (see cmp_detail in go.h)
+		ties := make([]string, len(names))
+		for i, f := range names {
+			ties[i] = f.name
+		}
+		fields += p.indent() + fmt.Sprintf("auto _tie() const { return std::tie(%s); }\n", strings.Join(ties, ", "))
+		fields += p.indent() + fmt.Sprintf("bool operator==(const _%s& o) const { return cmp_detail::equal(_tie(), o._tie()); }\n", name)
+		fields += p.indent() + fmt.Sprintf("bool operator!=(const _%s& o) const { return !(*this == o); }\n", name)
+		fields += p.indent() + fmt.Sprintf("bool operator<(const _%s& o) const { return cmp_detail::less(_tie(), o._tie()); }\n", name)
+	}
+
 	if len(fields) > 0 {
 		return fmt.Sprintf("struct _%s {\n%s}", name, fields)
 	} else {
-		return "struct{}"
+		return "EmptyStruct" // see go.h
 	}
 }
 
+// FormatInterface returns an interface with methods as a class derived from Interface (see go.h),
+// with a Method member for each method, bound to the dynamic value by the converting constructor
 func (p *CPrinter) FormatInterface(name, methods string) string {
-	if len(methods) > 0 {
-		name = "_" + name
-		return fmt.Sprintf("/* abstract */ struct %s {\n ~%s(){};\n%s}", name, name, methods)
-	} else {
+	// the methods of this interface are the last ones at the current level
+	i := len(p.methods)
+	for i > 0 && p.methods[i-1].level == p.level {
+		i--
+	}
+	mlist := p.methods[i:]
+	p.methods = p.methods[:i]
+
+	if len(methods) == 0 {
 		return "std::any"
 	}
+
+	name = "_" + name
+
+	if len(name) == 1 || p.ctx.inFunc() {
+		// a local class can't have the member templates: only the declarations
+		methods = ""
+		for _, m := range mlist {
+			methods += p.indent() + fmt.Sprintf("virtual %s %s(%s);\n", m.results, m.name, m.params)
+		}
+		return fmt.Sprintf("/* abstract */ struct %s {\n ~%s(){};\n%s}", name, name, methods)
+	}
+
+	methods = p.indent() + fmt.Sprintf("static constexpr const char *_type = %q;\n", p.pkg+"."+cReserved.Unescape(name[1:])) +
+		p.indent() + fmt.Sprintf("static inline const bool _registered = Interface::_register<%s>();\n", name) + methods
+	methods += "\n"
+	methods += p.indent() + fmt.Sprintf("%s() {}\n", name)
+	methods += p.indent() + fmt.Sprintf("%s(std::nullptr_t) {}\n", name)
+	methods += p.indent() + fmt.Sprintf("template<class T, iface_detail::if_value<T, %s> = 0> %s(const T& v) : Interface(v) {\n", name, name)
+	for _, m := range mlist {
+		methods += p.indent() + fmt.Sprintf("  %s = _bind(v, [](auto& _v, auto... _a) { return _v.%s(_a...); });\n", m.name, m.name)
+	}
+	methods += p.indent() + "}\n"
+
+	return fmt.Sprintf("struct %s : Interface {\n%s}", name, methods)
 }
 
 func (p *CPrinter) FormatChan(chdir, mtype string) string {
@@ -604,67 +1308,74 @@ func (p *CPrinter) FormatChan(chdir, mtype string) string {
 }
 
 func (p *CPrinter) FormatCall(fun, args string, isFuncLit bool) string {
-	if strings.HasPrefix(fun, "time::") {
-		// need to rename, to avoid conflicts with C/C++ "time" :(
-		fun = "go_" + fun
-	}
-
 	if isFuncLit {
-		return fmt.Sprintf("[](%s)->%s", args, fun)
-	} else if fun == "len" {
-		return fmt.Sprintf("%v.size()", args)
-
-		//} else if fun == "make" {
-		//	return cFormatMake(args)
+		return fmt.Sprintf("%s(%s)", fun, args)
+	} else if fun == "make" {
+		// make(T, args) -> T(args)
+		targs := cSplitArgs(args)
+		if len(targs) == 1 && (strings.HasPrefix(targs[0], "Map<") || strings.HasPrefix(targs[0], "Chan<")) {
+			targs = append(targs, "0") // not a nil map, an unbuffered channel
+		}
+		return fmt.Sprintf("%s(%s)", targs[0], strings.Join(targs[1:], ", "))
+	} else if fun == "new" {
+		// new(T) is a zero value
+		if p.SharedPointers {
+			return fmt.Sprintf("New<%s>()", args)
+		}
+		return fmt.Sprintf("new %s()", cPointerType(args))
+	} else if fun == "close" {
+		return fmt.Sprintf("%s.Close()", args)
+	} else if fun == "delete" {
+		// delete(m, k)
+		if targs := cSplitArgs(args); len(targs) == 2 {
+			return fmt.Sprintf("%s.Delete(%s)", targs[0], targs[1])
+		}
+		return fmt.Sprintf("%s(%s)", fun, args)
+	} else if fun == "append" && strings.HasSuffix(args, "...") {
+		// append(s, t...)
+		return fmt.Sprintf("appendSlice(%s)", strings.TrimSuffix(args, "..."))
 	} else {
 		return fmt.Sprintf("%s(%s)", fun, args)
 	}
 }
 
 func (p *CPrinter) FormatFuncType(params, results string, withFunc bool) string {
-	if len(results) == 0 {
-		results = "void"
-	} else if IsMultiValue(results) {
-		results = fmt.Sprintf("std::tuple<%s>", results)
+	if p.ctx != nil && p.ctx.fn != nil && p.ctx.fn.depth == 0 {
+		// the type of a function literal
+		p.ctx.fn.results = IfTrue(cResults(results), len(results) > 0)
 	}
 
-	// add %%s only if withFunc ?
-	return fmt.Sprintf("%s %%s(%s)", results, params)
-}
-
-func (p *CPrinter) FormatFuncLit(ftype, body string) string {
-	return fmt.Sprintf(ftype+"%s", "", body)
+	// a std::function, that can hold the function literals (lambdas) as well as the functions
+	return fmt.Sprintf("std::function<%s(%s)>", cResults(results), params)
 }
 
-func (p *CPrinter) FormatSelector(pname, sel string, isObject bool) string {
-	switch {
-	case pname == "io" && sel == "ReadSeeker":
-		pname = "std"
-		sel = "istream"
-
-	case pname == "io" && sel == "SeekCurrent":
-		pname = "std::ios"
-		sel = "cur"
+// cSplitFuncType splits a function type (see FormatFuncType) in results and parameters
+func cSplitFuncType(ftype string) (results, params string, ok bool) {
+	if !strings.HasPrefix(ftype, "std::function<") || !strings.HasSuffix(ftype, ">") {
+		return "", "", false
+	}
 
-	case pname == "io" && sel == "SeekStart":
-		pname = "std::ios"
-		sel = "beg"
+	results, params = cSplitCall(ftype[len("std::function<") : len(ftype)-1])
+	return results, params, true
+}
 
-	case pname == "io" && sel == "SeekEnd":
-		pname = "std::ios"
-		sel = "end"
+// FormatFuncLit returns a lambda, that captures the variables by reference (as Go closures do)
+func (p *CPrinter) FormatFuncLit(ftype, body string) string {
+	if results, params, ok := cSplitFuncType(ftype); ok {
+		return fmt.Sprintf("[&](%s) -> %s %s", params, results, body)
+	}
 
-	case pname == "fmt" && sel == "Printf":
-		pname = "std"
-		sel = "printf"
+	return ftype + body
+}
 
-	case pname == "fmt" && sel == "Sprintf":
-		pname = "std"
-		sel = "sprintf"
+func (p *CPrinter) FormatSelector(pname, sel string, isObject bool) string {
+	if !isObject {
+		expr, pkg, ok := p.imports.selector("c", pname, sel)
+		if ok {
+			return expr
+		}
 
-	case pname == "strconv" && sel == "Itoa":
-		pname = "std"
-		sel = "to_string"
+		pname = pkg
 	}
 
 	if isObject {
@@ -674,13 +1385,63 @@ func (p *CPrinter) FormatSelector(pname, sel string, isObject bool) string {
 	}
 }
 
+func (p *CPrinter) HeapPointers() bool {
+	return p.SharedPointers
+}
+
+func (p *CPrinter) FormatPointerType(t string) string {
+	return fmt.Sprintf("Ptr<%s>", t)
+}
+
+// FormatHeapValue allocates a value with New (see go.h), or with new if the pointers are not reference counted
+func (p *CPrinter) FormatHeapValue(value, vtype string) string {
+	switch {
+	case !p.SharedPointers:
+		return "new " + value
+	case len(vtype) == 0:
+		return fmt.Sprintf("New(%s)", value)
+	default:
+		return fmt.Sprintf("New<%s>(%s)", vtype, value)
+	}
+}
+
+func (p *CPrinter) FormatHeapVar(name string) string {
+	return fmt.Sprintf("(*%s)", name)
+}
+
+// FormatAddress returns a Ptr that doesn't own the value, or that shares the ownership of owner
+func (p *CPrinter) FormatAddress(expr, owner string) string {
+	if len(owner) == 0 {
+		return fmt.Sprintf("Ref(&%s)", expr)
+	}
+	return fmt.Sprintf("Ref(%s, &%s)", owner, expr)
+}
+
+// FormatPointerSelector selects a field or a method through a pointer (see PointerSelector)
+func (p *CPrinter) FormatPointerSelector(pname, sel string) string {
+	return fmt.Sprintf("%s->%s", pname, sel)
+}
+
 func (p *CPrinter) FormatTypeAssert(orig, assert string) string {
-	if assert == "type" {
-		p.ctx.caseType = orig
-		return ""
+	return fmt.Sprintf("typeAssert<%v>(%v)", cPointerType(assert), orig)
+}
+
+func (p *CPrinter) FormatTypeAssertOk(orig, assert string) string {
+	return fmt.Sprintf("typeAssertOk<%v>(%v)", cPointerType(assert), orig)
+}
+
+// FormatImplements returns Implements<I, T...> (see go.h), since the dynamic type of a value
+// can't be checked against the methods of an interface
+func (p *CPrinter) FormatImplements(iface string, types []string) string {
+	if len(types) == 0 {
+		return iface
 	}
 
-	return fmt.Sprintf("typeAssert<%v>(%v)", assert, orig)
+	ctypes := make([]string, len(types))
+	for i, t := range types {
+		ctypes[i] = cPointerType(t)
+	}
+	return fmt.Sprintf("Implements<%s, %s>", iface, strings.Join(ctypes, ", "))
 }
 
 // Guess type and return type and new value
@@ -704,6 +1465,10 @@ func cGuessType(value string) (string, string) {
 		return "std::string", value
 
 	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '.':
+		if strings.Contains(value, " ") {
+			// an expression (2 * time.Second), with the type of the operands
+			return vtype, value
+		}
 		if strings.Contains(value, ".") || strings.Contains(value, "E") {
 			return "double", value
 		}
@@ -724,18 +1489,10 @@ func cGuessType(value string) (string, string) {
 		return "void*", value
 	}
 
-	//
-	// x = make(t) -> t x()
-	//
-	if strings.HasPrefix(value, "make(") {
-		i := strings.IndexAny(value, ",)")
-		return value[5:i], value
-	}
-
 	//
 	// a map
 	//
-	if strings.HasPrefix(value, "std::map<") {
+	if strings.HasPrefix(value, "Map<") {
 		// a map
 		if p, ok := FindMatch(value, '<', '>'); ok {
 			return value[:p+1], value
@@ -756,7 +1513,10 @@ func cGuessType(value string) (string, string) {
 			}
 
 		case '{':
-			// should be struct
+			// should be struct (or a pointer to it, new T{...})
+			if strings.HasPrefix(value, "new ") || value[0] == '&' {
+				return vtype, value
+			}
 			return value[:i], value
 
 		}
@@ -768,33 +1528,140 @@ func cGuessType(value string) (string, string) {
 	return vtype, value
 }
 
-func cFormatMake(args string) string {
-	if strings.HasPrefix(args, "std::map<") {
-		// make map
-		p := strings.LastIndex(args, ">")
-		return args[:p+1] + "()"
-	} else if strings.Contains(args, "[]") {
-		// make slice
-		// TODO: this could be make([]x, cap, max)
+// cSplitArgs splits a list of arguments, ignoring the commas in template arguments and nested expressions
+func cSplitArgs(args string) (list []string) {
+	depth, start := 0, 0
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case '<', '(', '[', '{':
+			depth++
+		case '>', ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				list = append(list, strings.TrimSpace(args[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return append(list, strings.TrimSpace(args[start:]))
+}
+
+func (p *CPrinter) SourcePath(path string) string {
+	return path
+}
+
+// ProjectFiles generates a CMakeLists.txt with a library for each package and an executable for each main package
+func (p *CPrinter) ProjectFiles(proj *Project) map[string]string {
+	var b strings.Builder
 
-		p := strings.LastIndex(args, "]")
-		slicedef, n := args[:p], args[p+1:]
-		if len(n) > 0 {
-			// should be ", N"
-			n = n[2:]
+	fmt.Fprintf(&b, "cmake_minimum_required(VERSION 3.10)\n")
+	fmt.Fprintf(&b, "project(%s CXX)\n\n", Identifier(proj.Name))
+	fmt.Fprintf(&b, "set(CMAKE_CXX_STANDARD 17)\n")
+	fmt.Fprintf(&b, "set(CMAKE_CXX_STANDARD_REQUIRED ON)\n\n")
+	fmt.Fprintf(&b, "set(WALKNGO_RUNTIME %q CACHE PATH \"path of the walkngo runtime/c folder\")\n", proj.Runtime)
+	fmt.Fprintf(&b, "if(NOT WALKNGO_RUNTIME)\n  message(FATAL_ERROR \"set WALKNGO_RUNTIME to the walkngo runtime/c folder\")\nendif()\n\n")
+	fmt.Fprintf(&b, "include_directories(${WALKNGO_RUNTIME} ${CMAKE_CURRENT_SOURCE_DIR})\n")
+	fmt.Fprintf(&b, "find_package(Threads REQUIRED)\n")
+
+	libs := []string{}
+
+	for _, pkg := range proj.Libraries() {
+		target := proj.Target(pkg)
+		libs = append(libs, target)
+
+		fmt.Fprintf(&b, "\nadd_library(%s\n", target)
+		for _, f := range pkg.Files {
+			fmt.Fprintf(&b, "  %s\n", pkg.Path(f))
 		}
+		fmt.Fprintf(&b, ")\n")
+		fmt.Fprintf(&b, "set_target_properties(%s PROPERTIES LINKER_LANGUAGE CXX)\n", target)
+		fmt.Fprintf(&b, "target_link_libraries(%s Threads::Threads)\n", target)
+	}
 
-		return fmt.Sprintf("%s%s]", slicedef, n)
-	} else {
-		// make chan
+	for _, pkg := range proj.Commands() {
+		target := proj.Target(pkg)
 
-		p := strings.LastIndex(args, ">")
-		chandef, n := args[:p+1], args[p+1:]
-		if len(n) > 0 {
-			// should be ", N"
-			n = n[2:]
+		fmt.Fprintf(&b, "\nadd_executable(%s\n", target)
+		for _, f := range pkg.Files {
+			fmt.Fprintf(&b, "  %s\n", pkg.Path(f))
 		}
+		fmt.Fprintf(&b, ")\n")
+		fmt.Fprintf(&b, "target_link_libraries(%s %s)\n", target, strings.Join(append(libs, "Threads::Threads"), " "))
+	}
+
+	return map[string]string{"CMakeLists.txt": b.String()}
+}
+
+// SetDeclMode switches between printing the package header (true) and the definitions (false)
+func (p *CPrinter) SetDeclMode(decl bool) {
+	p.decl = decl
+}
+
+func (p *CPrinter) PrintDeclStart(pkg string) {
+	guard := "_" + strings.ToUpper(Identifier(pkg)) + "_H"
 
-		return fmt.Sprintf("%s(%s)", chandef, n)
+	p.PrintLevel(NL, "//package", pkg)
+	p.PrintLevel(NL, "#ifndef", guard)
+	p.PrintLevel(NL, "#define", guard)
+	p.PrintLevel(NL, "#include <go.h>")
+}
+
+func (p *CPrinter) PrintDeclEnd(pkg string) {
+	p.PrintLevel(NL, "\n#endif")
+}
+
+// PrintScopeStart opens the namespace for the package (the main package stays in the global namespace)
+func (p *CPrinter) PrintScopeStart(pkg string) {
+	p.pkg = pkg
+
+	if pkg != "main" {
+		p.PrintLevel(NL, "\nnamespace", cNamespace(pkg), "{")
+	}
+}
+
+func (p *CPrinter) PrintScopeEnd(pkg string) {
+	if pkg != "main" {
+		p.PrintLevel(NL, "\n} // namespace", cNamespace(pkg))
+	}
+}
+
+func (p *CPrinter) PrintTypeDecl(name string) {
+	if p.Header && !p.decl {
+		return // in the header
 	}
+
+	p.PrintLevel(SEMI, "typedef", "struct _"+name, name)
+}
+
+func (p *CPrinter) PrintFuncDecl(name, params, results string) {
+	if p.Header && !p.decl {
+		return // in the header
+	}
+
+	p.PrintLevel(SEMI, fmt.Sprintf("%s %s(%s)", cResults(results), name, params))
+}
+
+func (p *CPrinter) FormatMethodDecl(name, params, results string) string {
+	return p.indent() + fmt.Sprintf("%s %s(%s)", cResults(results), name, params) + SEMI
+}
+
+// FormatNamedType defines a named type with methods as a struct wrapping a value of the underlying type,
+// that converts from and to it (so that i.e. Code(42) and int(code) work, and a Code with an Error() method is an error)
+func (p *CPrinter) FormatNamedType(name, typedef, methods string) string {
+	fields := p.indent() + typedef + " _value{};\n"
+	fields += p.indent() + fmt.Sprintf("_%s() {}\n", name)
+	fields += p.indent() + fmt.Sprintf("_%s(%s v) : _value(v) {}\n", name, typedef)
+	fields += p.indent() + fmt.Sprintf("operator %s&() { return _value; }\n", typedef)
+	fields += p.indent() + fmt.Sprintf("operator const %s&() const { return _value; }\n", typedef)
+	fields += methods
+	fields += p.indent() + fmt.Sprintf("static constexpr const char *_type = %q;\n", p.pkg+"."+cReserved.Unescape(name))
+	return fmt.Sprintf("struct _%s {\n%s}", name, fields)
+}
+
+// EscapeIdent renames the identifiers that are C++ reserved words
+func (p *CPrinter) EscapeIdent(id string) string {
+	return cReserved.Escape(id)
 }
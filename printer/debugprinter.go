@@ -3,9 +3,11 @@ package printer
 import (
 	"fmt"
 	"io"
+	"strings"
 )
 
-// DebugPrinter wraps a Printer with debug messages
+// DebugPrinter wraps a Printer with debug messages.
+// It implements all the optional interfaces: use As to check which ones the wrapped printer implements.
 type DebugPrinter struct {
 	P Printer
 }
@@ -261,3 +263,235 @@ func (d *DebugPrinter) FormatTypeAssert(orig, assert string) string {
 	fmt.Println("/* FormatTypeAssert", orig, assert, "*/")
 	return d.P.FormatTypeAssert(orig, assert)
 }
+
+func (d *DebugPrinter) SourcePath(path string) string {
+	if pp, ok := d.P.(ProjectPrinter); ok {
+		return pp.SourcePath(path)
+	}
+
+	return path
+}
+
+func (d *DebugPrinter) ProjectFiles(p *Project) map[string]string {
+	if pp, ok := d.P.(ProjectPrinter); ok {
+		return pp.ProjectFiles(p)
+	}
+
+	return nil
+}
+
+func (d *DebugPrinter) EscapeIdent(id string) string {
+	if e, ok := d.P.(Escaper); ok {
+		return e.EscapeIdent(id)
+	}
+
+	return id
+}
+
+func (d *DebugPrinter) SetDeclMode(decl bool) {
+	if dp, ok := d.P.(DeclPrinter); ok {
+		dp.SetDeclMode(decl)
+	}
+}
+
+func (d *DebugPrinter) PrintDeclStart(pkg string) {
+	fmt.Println("/* PrintDeclStart", pkg, "*/")
+	if dp, ok := d.P.(DeclPrinter); ok {
+		dp.PrintDeclStart(pkg)
+	}
+}
+
+func (d *DebugPrinter) PrintDeclEnd(pkg string) {
+	fmt.Println("/* PrintDeclEnd", pkg, "*/")
+	if dp, ok := d.P.(DeclPrinter); ok {
+		dp.PrintDeclEnd(pkg)
+	}
+}
+
+func (d *DebugPrinter) PrintScopeStart(pkg string) {
+	fmt.Println("/* PrintScopeStart", pkg, "*/")
+	if dp, ok := d.P.(DeclPrinter); ok {
+		dp.PrintScopeStart(pkg)
+	}
+}
+
+func (d *DebugPrinter) PrintScopeEnd(pkg string) {
+	fmt.Println("/* PrintScopeEnd", pkg, "*/")
+	if dp, ok := d.P.(DeclPrinter); ok {
+		dp.PrintScopeEnd(pkg)
+	}
+}
+
+func (d *DebugPrinter) PrintTypeDecl(name string) {
+	fmt.Println("/* PrintTypeDecl", name, "*/")
+	if dp, ok := d.P.(DeclPrinter); ok {
+		dp.PrintTypeDecl(name)
+	}
+}
+
+func (d *DebugPrinter) PrintFuncDecl(name, params, results string) {
+	fmt.Println("/* PrintFuncDecl", name, params, results, "*/")
+	if dp, ok := d.P.(DeclPrinter); ok {
+		dp.PrintFuncDecl(name, params, results)
+	}
+}
+
+func (d *DebugPrinter) FormatMethodDecl(name, params, results string) string {
+	fmt.Println("/* FormatMethodDecl", name, params, results, "*/")
+	if dp, ok := d.P.(DeclPrinter); ok {
+		return dp.FormatMethodDecl(name, params, results)
+	}
+
+	return ""
+}
+
+func (d *DebugPrinter) FormatNamedType(name, typedef, methods string) string {
+	fmt.Println("/* FormatNamedType", name, typedef, methods, "*/")
+	if dp, ok := d.P.(DeclPrinter); ok {
+		return dp.FormatNamedType(name, typedef, methods)
+	}
+
+	return typedef
+}
+
+func (d *DebugPrinter) PrintLocalImport(imp LocalImport) {
+	fmt.Println("/* PrintLocalImport", imp.Name, imp.Path, imp.Dir, "*/")
+	if li, ok := d.P.(LocalImporter); ok {
+		li.PrintLocalImport(imp)
+	} else {
+		d.P.PrintImport(imp.Name, imp.Path)
+	}
+}
+
+func (d *DebugPrinter) FormatMapStore(m, key string) string {
+	fmt.Println("/* FormatMapStore", m, key, "*/")
+	if ms, ok := d.P.(MapStorer); ok {
+		return ms.FormatMapStore(m, key)
+	}
+
+	return d.P.FormatMapIndex(m, key, "", false)
+}
+
+func (d *DebugPrinter) FormatPointerSelector(pname, sel string) string {
+	fmt.Println("/* FormatPointerSelector", pname, sel, "*/")
+	if ps, ok := d.P.(PointerSelector); ok {
+		return ps.FormatPointerSelector(pname, sel)
+	}
+
+	return d.P.FormatSelector(pname, sel, true)
+}
+
+func (d *DebugPrinter) FormatReceive(ch string, check bool) string {
+	fmt.Println("/* FormatReceive", ch, check, "*/")
+	if cr, ok := d.P.(ChanReceiver); ok {
+		return cr.FormatReceive(ch, check)
+	}
+
+	return d.P.FormatUnary("<-", ch)
+}
+
+func (d *DebugPrinter) PrintSelect(chans, values []string, hasDefault bool) []string {
+	fmt.Println("/* PrintSelect", chans, values, hasDefault, "*/")
+	return d.P.(SelectPrinter).PrintSelect(chans, values, hasDefault)
+}
+
+func (d *DebugPrinter) PrintSelectCase(index int) {
+	fmt.Println("/* PrintSelectCase", index, "*/")
+	d.P.(SelectPrinter).PrintSelectCase(index)
+}
+
+func (d *DebugPrinter) PrintEndSelectCase() {
+	fmt.Println("/* PrintEndSelectCase */")
+	d.P.(SelectPrinter).PrintEndSelectCase()
+}
+
+func (d *DebugPrinter) PrintRedeclare(lhs []string, isNew []bool, rhs string, rtuple bool) {
+	fmt.Println("/* PrintRedeclare", lhs, isNew, rhs, rtuple, "*/")
+	if r, ok := d.P.(Redeclarer); ok {
+		r.PrintRedeclare(lhs, isNew, rhs, rtuple)
+		return
+	}
+
+	d.P.PrintAssignment(strings.Join(lhs, ", "), ":=", rhs, len(lhs) > 1, rtuple)
+}
+
+func (d *DebugPrinter) SetDefer(hasDefer bool) {
+	fmt.Println("/* SetDefer", hasDefer, "*/")
+	if dp, ok := d.P.(DeferPrinter); ok {
+		dp.SetDefer(hasDefer)
+	}
+}
+
+func (d *DebugPrinter) PrintDeferScope() {
+	fmt.Println("/* PrintDeferScope */")
+	d.P.(DeferPrinter).PrintDeferScope()
+}
+
+func (d *DebugPrinter) FormatDivisor(expr string) string {
+	fmt.Println("/* FormatDivisor", expr, "*/")
+	return d.P.(IntegerDivider).FormatDivisor(expr)
+}
+
+func (d *DebugPrinter) FormatTypeAssertOk(orig, assert string) string {
+	fmt.Println("/* FormatTypeAssertOk", orig, assert, "*/")
+	return d.P.(TypeAsserter).FormatTypeAssertOk(orig, assert)
+}
+
+func (d *DebugPrinter) FormatImplements(iface string, types []string) string {
+	fmt.Println("/* FormatImplements", iface, types, "*/")
+	return d.P.(TypeAsserter).FormatImplements(iface, types)
+}
+
+func (d *DebugPrinter) PrintTypeSwitch(init, expr string, cases [][]string) {
+	fmt.Println("/* PrintTypeSwitch", init, expr, cases, "*/")
+	d.P.(TypeAsserter).PrintTypeSwitch(init, expr, cases)
+}
+
+func (d *DebugPrinter) PrintTypeCase(index int, types []string, name string) {
+	fmt.Println("/* PrintTypeCase", index, types, name, "*/")
+	d.P.(TypeAsserter).PrintTypeCase(index, types, name)
+}
+
+func (d *DebugPrinter) PrintEndTypeCase() {
+	fmt.Println("/* PrintEndTypeCase */")
+	d.P.(TypeAsserter).PrintEndTypeCase()
+}
+
+func (d *DebugPrinter) HeapPointers() bool {
+	return d.P.(HeapAllocator).HeapPointers()
+}
+
+func (d *DebugPrinter) FormatPointerType(t string) string {
+	fmt.Println("/* FormatPointerType", t, "*/")
+	return d.P.(HeapAllocator).FormatPointerType(t)
+}
+
+func (d *DebugPrinter) FormatHeapValue(value, vtype string) string {
+	fmt.Println("/* FormatHeapValue", value, vtype, "*/")
+	return d.P.(HeapAllocator).FormatHeapValue(value, vtype)
+}
+
+func (d *DebugPrinter) FormatHeapVar(name string) string {
+	fmt.Println("/* FormatHeapVar", name, "*/")
+	return d.P.(HeapAllocator).FormatHeapVar(name)
+}
+
+func (d *DebugPrinter) FormatAddress(expr, owner string) string {
+	fmt.Println("/* FormatAddress", expr, owner, "*/")
+	return d.P.(HeapAllocator).FormatAddress(expr, owner)
+}
+
+func (d *DebugPrinter) PrintStringRange(key, value, expr string) {
+	fmt.Println("/* PrintStringRange", key, value, expr, "*/")
+	d.P.(StringConverter).PrintStringRange(key, value, expr)
+}
+
+func (d *DebugPrinter) FormatRuneString(expr string) string {
+	fmt.Println("/* FormatRuneString", expr, "*/")
+	return d.P.(StringConverter).FormatRuneString(expr)
+}
+
+func (d *DebugPrinter) FormatStringByte(expr, index string) string {
+	fmt.Println("/* FormatStringByte", expr, index, "*/")
+	return d.P.(StringConverter).FormatStringByte(expr, index)
+}
@@ -13,11 +13,14 @@ type GoPrinter struct {
 	level    int
 	sameline bool
 	w        io.Writer
+
+	imports importMap // package name -> import path, for the library mappings
 }
 
 func (p *GoPrinter) Reset() {
 	p.level = 0
 	p.sameline = false
+	p.imports = nil
 }
 
 func (p *GoPrinter) PushContext(c ContextType) {
@@ -96,6 +99,13 @@ func (p *GoPrinter) PrintPackage(name string) {
 }
 
 func (p *GoPrinter) PrintImport(name, path string) {
+	if m := p.imports.add("go", name, path); m != nil && m.Imports != nil {
+		for _, line := range m.Imports {
+			p.PrintLevel(NL, line)
+		}
+		return
+	}
+
 	p.PrintLevel(NL, "import", name, path)
 }
 
@@ -335,6 +345,15 @@ func (p *GoPrinter) FormatFuncLit(ftype, body string) string {
 }
 
 func (p *GoPrinter) FormatSelector(pname, sel string, isObject bool) string {
+	if !isObject {
+		expr, pkg, ok := p.imports.selector("go", pname, sel)
+		if ok {
+			return expr
+		}
+
+		pname = pkg
+	}
+
 	return fmt.Sprintf("%s.%s", pname, sel)
 }
 
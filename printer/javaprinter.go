@@ -3,17 +3,30 @@ package printer
 import (
 	"fmt"
 	"io"
+	"path"
 	"strings"
 )
 
+// javaReserved are the Java reserved words (and names used by the generated code)
+var javaReserved = NewReservedWords(`
+	abstract assert boolean break byte case catch char class const continue default do double else
+	enum extends final finally float for goto if implements import instanceof int interface long native new
+	null package private protected public record return short static strictfp super switch synchronized
+	this throw throws transient true false try var void volatile while yield`)
+
 // JavaPrinter implement the Printer interface for Java programs
 type JavaPrinter struct {
 	Printer
 
+	// Group is the Java package prefix of the converted packages (i.e. com.example)
+	Group string
+
 	level    int
 	sameline bool
 	w        io.Writer
 
+	imports importMap // package name -> import path, for the library mappings
+
 	ctx *Jcontext
 }
 
@@ -118,6 +131,7 @@ func javatype(t string) string {
 func (p *JavaPrinter) Reset() {
 	p.level = 0
 	p.sameline = false
+	p.imports = nil
 	p.ctx = nil
 }
 
@@ -199,9 +213,25 @@ func (p *JavaPrinter) PrintPackage(name string) {
 }
 
 func (p *JavaPrinter) PrintImport(name, path string) {
+	if m := p.imports.add("java", name, path); m != nil && m.Imports != nil {
+		for _, line := range m.Imports {
+			p.PrintLevel(NL, line)
+		}
+		return
+	}
+
 	p.PrintLevel(NL, "import", name, path)
 }
 
+// PrintLocalImport imports the classes of a converted package
+func (p *JavaPrinter) PrintLocalImport(imp LocalImport) {
+	p.imports.add("java", imp.LocalName(), imp.Path)
+
+	if pkg := p.javaPackage(imp.Dir); len(pkg) > 0 {
+		p.PrintLevel(NL, "import "+pkg+".*;")
+	}
+}
+
 func (p *JavaPrinter) PrintType(name, typedef string) {
 	//p.PrintLevel(NL, "type", name, typedef)
 
@@ -539,6 +569,15 @@ func (p *JavaPrinter) FormatFuncLit(ftype, body string) string {
 }
 
 func (p *JavaPrinter) FormatSelector(pname, sel string, isObject bool) string {
+	if !isObject {
+		expr, pkg, ok := p.imports.selector("java", pname, sel)
+		if ok {
+			return expr
+		}
+
+		pname = pkg
+	}
+
 	if isObject {
 		return fmt.Sprintf("%s.%s", p.ctx.Selector(pname), sel)
 	} else {
@@ -549,3 +588,65 @@ func (p *JavaPrinter) FormatSelector(pname, sel string, isObject bool) string {
 func (p *JavaPrinter) FormatTypeAssert(orig, assert string) string {
 	return fmt.Sprintf("%s.(%s)", orig, assert)
 }
+
+// SourcePath returns the path of a converted file in the Maven layout
+func (p *JavaPrinter) SourcePath(file string) string {
+	return path.Join("src/main/java", strings.Replace(p.Group, ".", "/", -1), file)
+}
+
+// javaPackage returns the Java package name for a package folder
+func (p *JavaPrinter) javaPackage(dir string) string {
+	return strings.Trim(strings.Replace(path.Join(p.Group, dir), "/", ".", -1), ".")
+}
+
+// ProjectFiles generates a Maven pom.xml
+func (p *JavaPrinter) ProjectFiles(proj *Project) map[string]string {
+	var b strings.Builder
+
+	group := p.Group
+	if len(group) == 0 {
+		group = Identifier(proj.Name)
+	}
+
+	fmt.Fprintf(&b, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(&b, "<project xmlns=\"http://maven.apache.org/POM/4.0.0\"\n")
+	fmt.Fprintf(&b, "         xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\"\n")
+	fmt.Fprintf(&b, "         xsi:schemaLocation=\"http://maven.apache.org/POM/4.0.0 http://maven.apache.org/xsd/maven-4.0.0.xsd\">\n")
+	fmt.Fprintf(&b, "  <modelVersion>4.0.0</modelVersion>\n\n")
+	fmt.Fprintf(&b, "  <groupId>%s</groupId>\n", group)
+	fmt.Fprintf(&b, "  <artifactId>%s</artifactId>\n", proj.Name)
+	fmt.Fprintf(&b, "  <version>0.1.0</version>\n")
+	fmt.Fprintf(&b, "  <packaging>jar</packaging>\n\n")
+	fmt.Fprintf(&b, "  <properties>\n")
+	fmt.Fprintf(&b, "    <maven.compiler.source>11</maven.compiler.source>\n")
+	fmt.Fprintf(&b, "    <maven.compiler.target>11</maven.compiler.target>\n")
+	fmt.Fprintf(&b, "    <project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>\n")
+	fmt.Fprintf(&b, "  </properties>\n")
+
+	for _, pkg := range proj.Commands() {
+		if len(pkg.Main) == 0 {
+			continue
+		}
+
+		// only one main class per jar
+		mainClass := p.javaPackage(strings.TrimPrefix(pkg.Path(TrimExt(pkg.Main)), p.SourcePath("")+"/"))
+
+		fmt.Fprintf(&b, "\n  <build>\n    <plugins>\n      <plugin>\n")
+		fmt.Fprintf(&b, "        <groupId>org.apache.maven.plugins</groupId>\n")
+		fmt.Fprintf(&b, "        <artifactId>maven-jar-plugin</artifactId>\n")
+		fmt.Fprintf(&b, "        <configuration>\n          <archive>\n            <manifest>\n")
+		fmt.Fprintf(&b, "              <mainClass>%s</mainClass>\n", mainClass)
+		fmt.Fprintf(&b, "            </manifest>\n          </archive>\n        </configuration>\n")
+		fmt.Fprintf(&b, "      </plugin>\n    </plugins>\n  </build>\n")
+		break
+	}
+
+	fmt.Fprintf(&b, "</project>\n")
+
+	return map[string]string{"pom.xml": b.String()}
+}
+
+// EscapeIdent renames the identifiers that are Java reserved words
+func (p *JavaPrinter) EscapeIdent(id string) string {
+	return javaReserved.Escape(id)
+}
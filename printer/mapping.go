@@ -0,0 +1,157 @@
+package printer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+)
+
+// PackageMapping describes how a Go package is converted to a target language
+type PackageMapping struct {
+	// Imports are the lines printed for the import (i.e. "#include <fmt.h>" or "use std::fmt;").
+	// If not specified the printer default is used, an empty list removes the import.
+	Imports []string `json:"imports"`
+
+	// Package replaces the package name in selectors (i.e. "time" -> "go_time")
+	Package string `json:"package,omitempty"`
+
+	// Names maps package members to target expressions (i.e. "Printf" -> "std::printf")
+	Names map[string]string `json:"names,omitempty"`
+}
+
+// Mappings contains the package mappings per language ("c", "go", "java", "python", "rust", "swift", "zig")
+// and per Go import path
+type Mappings map[string]map[string]*PackageMapping
+
+//go:embed mappings.json
+var defaultMappings []byte
+
+// the mappings used by the printers
+var mappings = Mappings{}
+
+func init() {
+	if err := mappings.Load(defaultMappings); err != nil {
+		panic(err)
+	}
+}
+
+// LoadMappings reads a JSON mappings file and merges it with the current mappings
+func LoadMappings(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := mappings.Load(data); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	return nil
+}
+
+// Load merges the JSON mappings in data. Existing imports and package names are replaced,
+// names are added or replaced.
+func (m Mappings) Load(data []byte) error {
+	var mm Mappings
+
+	if err := json.Unmarshal(data, &mm); err != nil {
+		return err
+	}
+
+	for lang, pkgs := range mm {
+		if m[lang] == nil {
+			m[lang] = map[string]*PackageMapping{}
+		}
+
+		for ipath, pm := range pkgs {
+			cur, ok := m[lang][ipath]
+			if !ok {
+				m[lang][ipath] = pm
+				continue
+			}
+
+			if pm.Imports != nil {
+				cur.Imports = pm.Imports
+			}
+			if len(pm.Package) > 0 {
+				cur.Package = pm.Package
+			}
+			if cur.Names == nil {
+				cur.Names = map[string]string{}
+			}
+			for k, v := range pm.Names {
+				cur.Names[k] = v
+			}
+		}
+	}
+
+	return nil
+}
+
+// LookupMapping returns the mapping for a (quoted or unquoted) Go import path, or nil
+func LookupMapping(lang, ipath string) *PackageMapping {
+	if p, err := strconv.Unquote(ipath); err == nil {
+		ipath = p
+	}
+
+	return mappings[lang][ipath]
+}
+
+// isLocal returns true if name is in the list of the imported project packages
+func isLocal(local []string, name string) bool {
+	for _, l := range local {
+		if l == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importMap tracks the imports of the current file (package name -> import path)
+type importMap map[string]string
+
+// add registers an import and returns its mapping (or nil)
+func (im *importMap) add(lang, name, ipath string) *PackageMapping {
+	if p, err := strconv.Unquote(ipath); err == nil {
+		ipath = p
+	}
+
+	if *im == nil {
+		*im = importMap{}
+	}
+
+	if len(name) == 0 {
+		name = path.Base(ipath)
+	}
+
+	(*im)[name] = ipath
+	return mappings[lang][ipath]
+}
+
+// selector returns the mapped expression for pname.sel, if pname is an imported package with a mapping.
+// If the package is mapped but not the name, pname is replaced with the mapped package name (if any).
+func (im importMap) selector(lang, pname, sel string) (expr, pkg string, mapped bool) {
+	ipath, ok := im[pname]
+	if !ok {
+		return "", pname, false
+	}
+
+	pm := mappings[lang][ipath]
+	if pm == nil {
+		return "", pname, false
+	}
+
+	if expr, ok := pm.Names[sel]; ok {
+		return expr, pname, true
+	}
+
+	if len(pm.Package) > 0 {
+		pname = pm.Package
+	}
+
+	return "", pname, false
+}
@@ -175,6 +175,205 @@ type Printer interface {
 	FormatTypeAssert(orig, assert string) string
 }
 
+// As returns the printer p as a T (one of the optional interfaces below), if it implements T.
+// A DebugPrinter implements all of them, forwarding the calls to the wrapped printer,
+// so it's only returned as a T if the wrapped printer implements T.
+func As[T any](p Printer) (T, bool) {
+	if d, ok := p.(*DebugPrinter); ok {
+		if _, ok := d.P.(T); !ok {
+			var none T
+			return none, false
+		}
+	}
+
+	t, ok := p.(T)
+	return t, ok
+}
+
+// Escaper is implemented by the printers that need to rename the identifiers
+// that are reserved words in the target language
+type Escaper interface {
+	EscapeIdent(id string) string
+}
+
+// MapStorer is implemented by the printers that assign map elements with a different expression
+// than the one used to read them (i.e. to avoid inserting missing keys on reads)
+type MapStorer interface {
+	FormatMapStore(m, key string) string
+}
+
+// PointerSelector is implemented by the printers that select the fields and methods of a pointer
+// with a different expression than the one used for values (i.e. p->x in C++)
+type PointerSelector interface {
+	FormatPointerSelector(pname, sel string) string
+}
+
+// ChanReceiver is implemented by the printers that have a different expression for the
+// comma-ok form of a channel receive (v, ok := <-ch)
+type ChanReceiver interface {
+	FormatReceive(ch string, check bool) string
+}
+
+// SelectPrinter is implemented by the printers that support the select statement.
+// PrintSelect gets the channels of the cases (the default case excluded), with the values for the send cases
+// (empty for the receive cases), and returns the expressions that replace the channels of the receive cases
+// in the case statements (v, ok := <-ch), since the values are received when the case is chosen.
+type SelectPrinter interface {
+	// print a "select" opening statement
+	PrintSelect(chans, values []string, hasDefault bool) []string
+
+	// print a "case" opening statement of a select (index is the position of the case, -1 for default)
+	PrintSelectCase(index int)
+
+	// print a "case" closing statement of a select
+	PrintEndSelectCase()
+}
+
+// TypeAsserter is implemented by the printers that check the dynamic types at runtime (i.e. C++, that has
+// no switch on types). A type switch is printed as a switch on the index of the first case that matches:
+// PrintTypeSwitch gets the types of the cases (the default case excluded, "nil" for nil) and PrintTypeCase
+// declares the switch variable (name is empty if it's not used in the case), with the type of the case
+// if there is only one, or the type of x.
+type TypeAsserter interface {
+	// format the comma-ok form of a type assertion (v, ok := x.(T))
+	FormatTypeAssertOk(orig, assert string) string
+
+	// format the target of a type assertion (or a case of a type switch) to an interface with methods,
+	// that matches the values of types (the types of the package that implement it)
+	FormatImplements(iface string, types []string) string
+
+	// print a type switch opening statement
+	PrintTypeSwitch(init, expr string, cases [][]string)
+
+	// print a "case" opening statement of a type switch (index is the position of the case, -1 for default)
+	PrintTypeCase(index int, types []string, name string)
+
+	// print a "case" closing statement of a type switch
+	PrintEndTypeCase()
+}
+
+// HeapAllocator is implemented by the printers that allocate on the heap the values whose address is taken.
+// &T{...} is always FormatHeapValue. If HeapPointers is true the pointers are reference counted (i.e. C++ with
+// shared pointers): the local variables whose address is taken are allocated on the heap, with FormatHeapValue
+// as initial value and FormatHeapVar for their uses, and &x is the variable itself. The other addresses
+// are FormatAddress.
+type HeapAllocator interface {
+	// HeapPointers returns true if the pointers are reference counted
+	HeapPointers() bool
+
+	// format a pointer type (*T), if HeapPointers is true
+	FormatPointerType(t string) string
+
+	// format a value allocated on the heap (of type vtype, if not empty)
+	FormatHeapValue(value, vtype string) string
+
+	// format a use of a local variable allocated on the heap
+	FormatHeapVar(name string) string
+
+	// format the address of a value that is part of the value pointed to by owner (&p.x), or of a value
+	// that is not allocated on the heap (owner is empty)
+	FormatAddress(expr, owner string) string
+}
+
+// StringConverter is implemented by the printers whose strings are sequences of bytes that know nothing
+// about UTF-8 (i.e. C++). The walker uses the type checker to find the ranges over a string, that decode
+// the runes, the conversions of an integer to a string and the bytes of a string (s[i] is a byte, not a char).
+type StringConverter interface {
+	// print a range over the runes of a string (the key is the index of the first byte of the rune)
+	PrintStringRange(key, value, expr string)
+
+	// format the conversion of an integer to the UTF-8 encoding of the rune (string(r))
+	FormatRuneString(expr string) string
+
+	// format the byte at index of a string (s[i])
+	FormatStringByte(expr, index string) string
+}
+
+// Redeclarer is implemented by the printers that need to know which variables of a short variable
+// declaration are new, when some are already declared (v, err := f() after err := g())
+type Redeclarer interface {
+	PrintRedeclare(lhs []string, isNew []bool, rhs string, rtuple bool)
+}
+
+// DeferPrinter is implemented by the printers that need to know if a function has deferred calls
+// before printing its body (i.e. to run them when the function returns or panics)
+type DeferPrinter interface {
+	SetDefer(hasDefer bool)
+
+	// PrintDeferScope is called before a statement of the function body with deferred calls in its blocks
+	// (i.e. to keep alive the variables they refer to until they run)
+	PrintDeferScope()
+}
+
+// IntegerDivider is implemented by the printers whose integer division by zero doesn't panic (i.e. C++,
+// where it's undefined behavior). The walker uses the type checker to find the divisors of the integer
+// divisions and remainders (x / y, x % y, x /= y and x %= y) that are not constants.
+type IntegerDivider interface {
+	// format the divisor of an integer division, that panics if it's zero
+	FormatDivisor(expr string) string
+}
+
+// DeclPrinter is implemented by the printers for languages that need a declaration before use (i.e. C++).
+// The walker prints forward declarations and sorts the top level declarations, and it can generate
+// a declaration file per package (i.e. a C++ header), with the definitions in the converted files.
+type DeclPrinter interface {
+	// SetDeclMode switches between printing declarations (true) and definitions (false)
+	SetDeclMode(decl bool)
+
+	// print the start/end of the declaration file (i.e. include guards)
+	PrintDeclStart(pkg string)
+	PrintDeclEnd(pkg string)
+
+	// print the start/end of the package scope (i.e. a namespace), after the imports
+	PrintScopeStart(pkg string)
+	PrintScopeEnd(pkg string)
+
+	// print a forward declaration for a struct type
+	PrintTypeDecl(name string)
+
+	// print a function prototype
+	PrintFuncDecl(name, params, results string)
+
+	// format a method declaration, for the receiver type definition
+	FormatMethodDecl(name, params, results string) string
+
+	// format the definition of a named type with methods that is not a struct
+	// (i.e. a struct wrapping the value, since a C++ typedef can't have methods)
+	FormatNamedType(name, typedef, methods string) string
+}
+
+// ReservedWords is the set of reserved words of a target language
+type ReservedWords map[string]bool
+
+// NewReservedWords creates a set of reserved words from a space separated list
+func NewReservedWords(words string) ReservedWords {
+	rw := ReservedWords{}
+	for _, w := range strings.Fields(words) {
+		rw[w] = true
+	}
+	return rw
+}
+
+// Escape appends an underscore to reserved words (new -> new_).
+// Identifiers that already look like an escaped reserved word get an extra underscore (new_ -> new__),
+// so that the renaming never clashes with existing names.
+func (rw ReservedWords) Escape(id string) string {
+	if rw[strings.TrimRight(id, "_")] {
+		return id + "_"
+	}
+
+	return id
+}
+
+// Unescape returns the original name of an escaped identifier (new_ -> new, new__ -> new_)
+func (rw ReservedWords) Unescape(id string) string {
+	if strings.HasSuffix(id, "_") && rw[strings.TrimRight(id, "_")] {
+		return id[:len(id)-1]
+	}
+
+	return id
+}
+
 // Pair contains a pair of values (name/value, name/type, etc.)
 type Pair [2]string
 
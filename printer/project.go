@@ -0,0 +1,227 @@
+package printer
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Package describes a converted Go package, as written in the output folder
+type Package struct {
+	Name  string   // the Go package name
+	Dir   string   // the output folder, relative to the project root ("" for the root)
+	Files []string // the converted files, relative to Dir
+	Main  string   // the file containing "func main", for main packages
+}
+
+// Path returns the path of the package file, relative to the project root
+func (p *Package) Path(file string) string {
+	return path.Join(p.Dir, file)
+}
+
+// Project describes the set of converted packages
+type Project struct {
+	Name     string     // the project name
+	Runtime  string     // the path of the walkngo runtime for the target language
+	Packages []*Package // the converted packages, sorted by Dir
+}
+
+// ProjectPrinter is implemented by the printers that can generate the build files
+// for a converted project
+type ProjectPrinter interface {
+	// SourcePath returns the output path (relative to the project root) for a converted file
+	SourcePath(path string) string
+
+	// ProjectFiles returns the build files to generate, as a map of path (relative to the project root) to content
+	ProjectFiles(p *Project) map[string]string
+}
+
+// LocalImport describes the import of another converted package of the project
+type LocalImport struct {
+	Name    string // the import name, if renamed
+	Path    string // the (quoted) Go import path
+	Package string // the Go package name
+	Dir     string // the output folder of the imported package, relative to the project root ("" for the root)
+	From    string // the output folder of the importing file
+}
+
+// LocalName returns the name used to refer to the imported package
+func (imp LocalImport) LocalName() string {
+	if len(imp.Name) > 0 && imp.Name != "_" && imp.Name != "." {
+		return imp.Name
+	}
+
+	return imp.Package
+}
+
+// LocalImporter is implemented by the printers that can refer to the other converted packages
+// with the module system of the target language
+type LocalImporter interface {
+	PrintLocalImport(imp LocalImport)
+}
+
+// AddFile adds a converted file to the project (if not already there), creating the package if needed
+func (p *Project) AddFile(name, file string, isMain bool) {
+	dir, file := path.Split(file)
+	dir = strings.Trim(dir, "/")
+
+	i := sort.Search(len(p.Packages), func(i int) bool { return p.Packages[i].Dir >= dir })
+	if i == len(p.Packages) || p.Packages[i].Dir != dir {
+		p.Packages = append(p.Packages, nil)
+		copy(p.Packages[i+1:], p.Packages[i:])
+		p.Packages[i] = &Package{Name: name, Dir: dir}
+	}
+
+	pkg := p.Packages[i]
+	pkg.Name = name
+
+	j := sort.SearchStrings(pkg.Files, file)
+	if j == len(pkg.Files) || pkg.Files[j] != file {
+		pkg.Files = append(pkg.Files, "")
+		copy(pkg.Files[j+1:], pkg.Files[j:])
+		pkg.Files[j] = file
+	}
+
+	if isMain {
+		pkg.Main = file
+	} else if pkg.Main == file {
+		pkg.Main = ""
+	}
+}
+
+// RemoveFile removes a converted file from the project, and the package if it's empty
+func (p *Project) RemoveFile(file string) {
+	dir, file := path.Split(file)
+	dir = strings.Trim(dir, "/")
+
+	for i, pkg := range p.Packages {
+		if pkg.Dir != dir {
+			continue
+		}
+
+		for j, f := range pkg.Files {
+			if f == file {
+				pkg.Files = append(pkg.Files[:j], pkg.Files[j+1:]...)
+				break
+			}
+		}
+
+		if pkg.Main == file {
+			pkg.Main = ""
+		}
+
+		if len(pkg.Files) == 0 {
+			p.Packages = append(p.Packages[:i], p.Packages[i+1:]...)
+		}
+
+		return
+	}
+}
+
+// Libraries returns the non-main packages
+func (p *Project) Libraries() (libs []*Package) {
+	for _, pkg := range p.Packages {
+		if pkg.Name != "main" {
+			libs = append(libs, pkg)
+		}
+	}
+
+	return
+}
+
+// Commands returns the main packages
+func (p *Project) Commands() (cmds []*Package) {
+	for _, pkg := range p.Packages {
+		if pkg.Name == "main" {
+			cmds = append(cmds, pkg)
+		}
+	}
+
+	return
+}
+
+// hasLibrary returns true if dir or one of its subfolders contains a non-main package
+func (p *Project) hasLibrary(dir string) bool {
+	for _, pkg := range p.Libraries() {
+		if pkg.Dir == dir || strings.HasPrefix(pkg.Dir, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subdirs returns the packages folders that are direct children of dir
+func (p *Project) Subdirs(dir string) (subdirs []string) {
+	seen := map[string]bool{}
+
+	for _, pkg := range p.Packages {
+		rel := pkg.Dir
+		if dir != "" {
+			if !strings.HasPrefix(rel, dir+"/") {
+				continue
+			}
+			rel = rel[len(dir)+1:]
+		}
+
+		if rel == "" {
+			continue
+		}
+
+		sub := strings.SplitN(rel, "/", 2)[0]
+		if !seen[sub] {
+			seen[sub] = true
+			subdirs = append(subdirs, sub)
+		}
+	}
+
+	return
+}
+
+// Target returns a name for the executable/library built from the package
+func (p *Project) Target(pkg *Package) string {
+	if pkg.Dir == "" {
+		return Identifier(p.Name)
+	}
+
+	return Identifier(path.Base(pkg.Dir))
+}
+
+// Identifier converts a name into a valid identifier (letters, digits and underscores)
+func Identifier(name string) string {
+	id := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+
+	if id == "" || unicode.IsDigit(rune(id[0])) {
+		id = "_" + id
+	}
+
+	return id
+}
+
+// RelPath returns the slash separated path of target relative to the folder dir
+func RelPath(dir, target string) string {
+	var from, to []string
+	if len(dir) > 0 {
+		from = strings.Split(dir, "/")
+	}
+	if len(target) > 0 {
+		to = strings.Split(target, "/")
+	}
+
+	for len(from) > 0 && len(to) > 1 && from[0] == to[0] {
+		from, to = from[1:], to[1:]
+	}
+
+	return path.Join(strings.Repeat("../", len(from)), path.Join(to...))
+}
+
+// TrimExt removes the extension from a file name
+func TrimExt(file string) string {
+	return strings.TrimSuffix(file, path.Ext(file))
+}
@@ -3,9 +3,16 @@ package printer
 import (
 	"fmt"
 	"io"
+	"path"
+	"strconv"
 	"strings"
 )
 
+// pyReserved are the Python reserved words (and names used by the generated code)
+var pyReserved = NewReservedWords(`
+	False None True and as assert async await break class continue def del elif else except
+	finally for from global if import in is lambda nonlocal not or pass raise return self try while with yield`)
+
 // PythonPrinter implement the Printer interface for Python programs
 type PythonPrinter struct {
 	Printer
@@ -13,11 +20,14 @@ type PythonPrinter struct {
 	level    int
 	sameline bool
 	w        io.Writer
+
+	imports importMap // package name -> import path, for the library mappings
 }
 
 func (p *PythonPrinter) Reset() {
 	p.level = 0
 	p.sameline = false
+	p.imports = nil
 }
 
 func (p *PythonPrinter) PushContext(c ContextType) {
@@ -109,6 +119,13 @@ func (p *PythonPrinter) PrintPackage(name string) {
 }
 
 func (p *PythonPrinter) PrintImport(name, path string) {
+	if m := p.imports.add("python", name, path); m != nil && m.Imports != nil {
+		for _, line := range m.Imports {
+			p.PrintLevel(NL, line)
+		}
+		return
+	}
+
 	if len(name) > 0 {
 		p.PrintLevel(NL, "import", path, "as", name)
 	} else {
@@ -116,6 +133,36 @@ func (p *PythonPrinter) PrintImport(name, path string) {
 	}
 }
 
+// PrintLocalImport imports a converted package relative to the current package
+// (the project root is the top level package, see ProjectFiles)
+func (p *PythonPrinter) PrintLocalImport(imp LocalImport) {
+	p.imports.add("python", imp.LocalName(), imp.Path)
+
+	// from the current package up to the project root
+	dots := "."
+	if len(imp.From) > 0 {
+		dots += strings.Repeat(".", strings.Count(imp.From, "/")+1)
+	}
+
+	if len(imp.Dir) == 0 {
+		p.PrintLevel(NL, "import importlib")
+		p.PrintLevel(NL, imp.LocalName(), "=", fmt.Sprintf("importlib.import_module(%q, __package__)", dots))
+		return
+	}
+
+	parent, mod := path.Split(imp.Dir)
+	from := dots + strings.Replace(strings.Trim(parent, "/"), "/", ".", -1)
+
+	switch local := imp.LocalName(); {
+	case imp.Name == ".":
+		p.PrintLevel(NL, "from", strings.TrimSuffix(from, ".")+"."+mod, "import *")
+	case local != mod:
+		p.PrintLevel(NL, "from", from, "import", mod, "as", local)
+	default:
+		p.PrintLevel(NL, "from", from, "import", mod)
+	}
+}
+
 func (p *PythonPrinter) PrintType(name, typedef string) {
 	p.PrintLevel(NL, "type", name, typedef)
 }
@@ -390,6 +437,15 @@ func (p *PythonPrinter) FormatFuncLit(ftype, body string) string {
 }
 
 func (p *PythonPrinter) FormatSelector(pname, sel string, isObject bool) string {
+	if !isObject {
+		expr, pkg, ok := p.imports.selector("python", pname, sel)
+		if ok {
+			return expr
+		}
+
+		pname = pkg
+	}
+
 	return fmt.Sprintf("%s.%s", pname, sel)
 }
 
@@ -404,3 +460,79 @@ func Nil(v string) string {
 		return v
 	}
 }
+
+func (p *PythonPrinter) SourcePath(path string) string {
+	return path
+}
+
+// ProjectFiles generates a pyproject.toml and an __init__.py for each package folder
+// (the project root is the top level package)
+func (p *PythonPrinter) ProjectFiles(proj *Project) map[string]string {
+	files := map[string]string{}
+	top := Identifier(proj.Name)
+
+	pkgs := map[string]*Package{}
+	for _, pkg := range proj.Packages {
+		pkgs[pkg.Dir] = pkg
+	}
+
+	names := []string{}
+
+	var modules func(dir string)
+	modules = func(dir string) {
+		var b strings.Builder
+
+		if pkg, ok := pkgs[dir]; ok {
+			fmt.Fprintf(&b, "# package %s\n", pkg.Name)
+			if pkg.Name != "main" {
+				for _, f := range pkg.Files {
+					fmt.Fprintf(&b, "from .%s import *\n", Identifier(TrimExt(f)))
+				}
+			}
+		}
+
+		files[path.Join(dir, "__init__.py")] = b.String()
+		names = append(names, strings.Replace(path.Join(top, dir), "/", ".", -1))
+
+		for _, sub := range proj.Subdirs(dir) {
+			modules(path.Join(dir, sub))
+		}
+	}
+
+	modules("")
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "[build-system]\nrequires = [\"setuptools>=61.0\"]\nbuild-backend = \"setuptools.build_meta\"\n\n")
+	fmt.Fprintf(&b, "[project]\nname = %q\nversion = \"0.1.0\"\nrequires-python = \">=3.8\"\n\n", top)
+	fmt.Fprintf(&b, "[tool.setuptools]\npackages = [%s]\npackage-dir = { %q = \".\" }\n", pyQuoteList(names), top)
+
+	scripts := ""
+	for _, pkg := range proj.Commands() {
+		if len(pkg.Main) > 0 {
+			mod := strings.Replace(path.Join(top, pkg.Dir, Identifier(TrimExt(pkg.Main))), "/", ".", -1)
+			scripts += fmt.Sprintf("%s = %q\n", proj.Target(pkg), mod+":main")
+		}
+	}
+
+	if len(scripts) > 0 {
+		fmt.Fprintf(&b, "\n[project.scripts]\n%s", scripts)
+	}
+
+	files["pyproject.toml"] = b.String()
+	return files
+}
+
+func pyQuoteList(l []string) string {
+	q := make([]string, len(l))
+	for i, s := range l {
+		q[i] = strconv.Quote(s)
+	}
+
+	return strings.Join(q, ", ")
+}
+
+// EscapeIdent renames the identifiers that are Python reserved words
+func (p *PythonPrinter) EscapeIdent(id string) string {
+	return pyReserved.Escape(id)
+}
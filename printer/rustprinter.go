@@ -3,9 +3,16 @@ package printer
 import (
 	"fmt"
 	"io"
+	"path"
 	"strings"
 )
 
+// rustReserved are the Rust reserved words (and names used by the generated code)
+var rustReserved = NewReservedWords(`
+	as async await break const continue crate dyn else enum extern false fn for if impl in let loop
+	match mod move mut pub ref return self Self static struct super trait true type unsafe use where while
+	abstract become box do final macro override priv try typeof unsized virtual yield`)
+
 // RustPrinter implement the Printer interface for Rust programs
 type RustPrinter struct {
 	Printer
@@ -13,11 +20,16 @@ type RustPrinter struct {
 	level    int
 	sameline bool
 	w        io.Writer
+
+	imports importMap // package name -> import path, for the library mappings
+	local   []string  // the imported project packages
 }
 
 func (p *RustPrinter) Reset() {
 	p.level = 0
 	p.sameline = false
+	p.imports = nil
+	p.local = nil
 }
 
 func (p *RustPrinter) PushContext(c ContextType) {
@@ -100,9 +112,39 @@ func (p *RustPrinter) PrintPackage(name string) {
 }
 
 func (p *RustPrinter) PrintImport(name, path string) {
+	if m := p.imports.add("rust", name, path); m != nil && m.Imports != nil {
+		for _, line := range m.Imports {
+			p.PrintLevel(NL, line)
+		}
+		return
+	}
+
 	p.PrintLevel(NL, "import", name, path)
 }
 
+// PrintLocalImport uses a converted package from the crate root (see ProjectFiles)
+func (p *RustPrinter) PrintLocalImport(imp LocalImport) {
+	mods := []string{"crate"}
+	if len(imp.Dir) > 0 {
+		for _, d := range strings.Split(imp.Dir, "/") {
+			mods = append(mods, Identifier(d))
+		}
+	}
+
+	use := strings.Join(mods, "::")
+
+	if imp.Name == "." {
+		use += "::*"
+	} else if local := imp.LocalName(); local != mods[len(mods)-1] {
+		use += " as " + local
+	}
+
+	p.PrintLevel(NL, "use "+use+";")
+
+	p.imports.add("rust", imp.LocalName(), imp.Path)
+	p.local = append(p.local, imp.LocalName())
+}
+
 func (p *RustPrinter) PrintType(name, typedef string) {
 	if strings.Contains(typedef, "%") {
 		p.PrintfLevel(NL, typedef, name)
@@ -405,9 +447,117 @@ func (p *RustPrinter) FormatFuncLit(ftype, body string) string {
 }
 
 func (p *RustPrinter) FormatSelector(pname, sel string, isObject bool) string {
+	if !isObject {
+		expr, pkg, ok := p.imports.selector("rust", pname, sel)
+		if ok {
+			return expr
+		}
+
+		pname = pkg
+
+		if isLocal(p.local, pname) {
+			return fmt.Sprintf("%s::%s", pname, sel)
+		}
+	}
+
 	return fmt.Sprintf("%s.%s", pname, sel)
 }
 
 func (p *RustPrinter) FormatTypeAssert(orig, assert string) string {
 	return fmt.Sprintf("%s.(%s)", orig, assert)
 }
+
+func (p *RustPrinter) SourcePath(path string) string {
+	return path
+}
+
+// ProjectFiles generates a Cargo.toml, a lib.rs crate root for the library packages,
+// a mod.rs with the "mod" declarations for each folder and a crate root for each main package
+func (p *RustPrinter) ProjectFiles(proj *Project) map[string]string {
+	files := map[string]string{}
+
+	var cargo strings.Builder
+
+	fmt.Fprintf(&cargo, "[package]\nname = %q\nversion = \"0.1.0\"\nedition = \"2021\"\n", Identifier(proj.Name))
+
+	pkgs := map[string]*Package{}
+	for _, pkg := range proj.Libraries() {
+		pkgs[pkg.Dir] = pkg
+	}
+
+	if len(pkgs) > 0 {
+		fmt.Fprintf(&cargo, "\n[lib]\npath = \"lib.rs\"\n")
+
+		// walk the folders from the root, so that all the intermediate modules are declared
+		var modules func(dir, modfile string)
+		modules = func(dir, modfile string) {
+			var b strings.Builder
+
+			if pkg, ok := pkgs[dir]; ok {
+				for _, f := range pkg.Files {
+					rustMod(&b, f, false)
+				}
+
+				// the package members are referred as module::name
+				for _, f := range pkg.Files {
+					fmt.Fprintf(&b, "pub use %s::*;\n", Identifier(TrimExt(f)))
+				}
+			}
+
+			for _, sub := range proj.Subdirs(dir) {
+				subdir := path.Join(dir, sub)
+				if !proj.hasLibrary(subdir) {
+					continue
+				}
+
+				fmt.Fprintf(&b, "pub mod %s;\n", Identifier(sub))
+				modules(subdir, path.Join(subdir, "mod.rs"))
+			}
+
+			files[modfile] = b.String()
+		}
+
+		modules("", "lib.rs")
+	}
+
+	for _, pkg := range proj.Commands() {
+		var b strings.Builder
+
+		target := proj.Target(pkg)
+		main := pkg.Path("main_" + target + ".rs")
+
+		// the crate root is not a mod.rs file, so the module paths are always explicit
+		for _, f := range pkg.Files {
+			rustMod(&b, f, true)
+		}
+
+		if len(pkgs) > 0 {
+			fmt.Fprintf(&b, "\n#[allow(unused_imports)]\nuse %s::*;\n", Identifier(proj.Name))
+		}
+
+		if len(pkg.Main) > 0 {
+			fmt.Fprintf(&b, "\nfn main() {\n  %s::main();\n}\n", Identifier(TrimExt(pkg.Main)))
+		}
+
+		fmt.Fprintf(&cargo, "\n[[bin]]\nname = %q\npath = %q\n", target, main)
+		files[main] = b.String()
+	}
+
+	files["Cargo.toml"] = cargo.String()
+	return files
+}
+
+// rustMod writes the "mod" declaration for a source file
+func rustMod(b *strings.Builder, file string, withPath bool) {
+	name := Identifier(TrimExt(file))
+	if withPath || name+".rs" != file {
+		fmt.Fprintf(b, "#[path = %q]\n", file)
+	}
+
+	fmt.Fprintf(b, "pub mod %s;\n", name)
+}
+
+// EscapeIdent renames the identifiers that are Rust reserved words
+func (p *RustPrinter) EscapeIdent(id string) string {
+	return rustReserved.Escape(id)
+}
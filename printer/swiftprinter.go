@@ -3,9 +3,17 @@ package printer
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 )
 
+// swiftReserved are the Swift reserved words (and names used by the generated code)
+var swiftReserved = NewReservedWords(`
+	associatedtype class deinit enum extension fileprivate func import init inout internal let open
+	operator private precedencegroup protocol public rethrows static struct subscript typealias var break
+	case catch continue default defer do else fallthrough for guard if in repeat return throw switch where
+	while Any as await false is nil self Self super throws true try`)
+
 // SwiftPrinter implement the Printer interface for Swift programs
 type SwiftPrinter struct {
 	Printer
@@ -13,11 +21,14 @@ type SwiftPrinter struct {
 	level    int
 	sameline bool
 	w        io.Writer
+
+	imports importMap // package name -> import path, for the library mappings
 }
 
 func (p *SwiftPrinter) Reset() {
 	p.level = 0
 	p.sameline = false
+	p.imports = nil
 }
 
 func (p *SwiftPrinter) PushContext(c ContextType) {
@@ -96,6 +107,13 @@ func (p *SwiftPrinter) PrintPackage(name string) {
 }
 
 func (p *SwiftPrinter) PrintImport(name, path string) {
+	if m := p.imports.add("swift", name, path); m != nil && m.Imports != nil {
+		for _, line := range m.Imports {
+			p.PrintLevel(NL, line)
+		}
+		return
+	}
+
 	p.PrintLevel(NL, "import", name, path)
 }
 
@@ -391,9 +409,75 @@ func (p *SwiftPrinter) FormatFuncLit(ftype, body string) string {
 }
 
 func (p *SwiftPrinter) FormatSelector(pname, sel string, isObject bool) string {
+	if !isObject {
+		expr, pkg, ok := p.imports.selector("swift", pname, sel)
+		if ok {
+			return expr
+		}
+
+		pname = pkg
+	}
+
 	return fmt.Sprintf("%s.%s", pname, sel)
 }
 
 func (p *SwiftPrinter) FormatTypeAssert(orig, assert string) string {
 	return fmt.Sprintf("%s.(%s)", orig, assert)
 }
+
+func (p *SwiftPrinter) SourcePath(path string) string {
+	return path
+}
+
+// ProjectFiles generates a Package.swift with a target for each package
+func (p *SwiftPrinter) ProjectFiles(proj *Project) map[string]string {
+	var b strings.Builder
+
+	libs := []string{}
+	for _, pkg := range proj.Libraries() {
+		libs = append(libs, strconv.Quote(proj.Target(pkg)))
+	}
+
+	fmt.Fprintf(&b, "// swift-tools-version:5.5\n")
+	fmt.Fprintf(&b, "import PackageDescription\n\n")
+	fmt.Fprintf(&b, "let package = Package(\n")
+	fmt.Fprintf(&b, "    name: %q,\n", proj.Name)
+	fmt.Fprintf(&b, "    targets: [\n")
+
+	for _, pkg := range proj.Packages {
+		kind := ".target"
+		deps := ""
+
+		if pkg.Name == "main" {
+			kind = ".executableTarget"
+			deps = strings.Join(libs, ", ")
+		}
+
+		dir := pkg.Dir
+		if dir == "" {
+			dir = "."
+		}
+
+		sources := make([]string, len(pkg.Files))
+		for i, f := range pkg.Files {
+			sources[i] = strconv.Quote(f)
+		}
+
+		fmt.Fprintf(&b, "        %s(\n", kind)
+		fmt.Fprintf(&b, "            name: %q,\n", proj.Target(pkg))
+		fmt.Fprintf(&b, "            dependencies: [%s],\n", deps)
+		fmt.Fprintf(&b, "            path: %q,\n", dir)
+		fmt.Fprintf(&b, "            sources: [%s]\n", strings.Join(sources, ", "))
+		fmt.Fprintf(&b, "        ),\n")
+	}
+
+	fmt.Fprintf(&b, "    ]\n")
+	fmt.Fprintf(&b, ")\n")
+
+	return map[string]string{"Package.swift": b.String()}
+}
+
+// EscapeIdent renames the identifiers that are Swift reserved words
+func (p *SwiftPrinter) EscapeIdent(id string) string {
+	return swiftReserved.Escape(id)
+}
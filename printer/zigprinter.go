@@ -3,10 +3,19 @@ package printer
 import (
 	"fmt"
 	"io"
+	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
+// zigReserved are the Zig reserved words (and names used by the generated code)
+var zigReserved = NewReservedWords(`
+	addrspace align allowzero and anyframe anytype asm async await break callconv catch comptime const
+	continue defer else enum errdefer error export extern fn for if inline linksection noalias noinline
+	nosuspend opaque or orelse packed pub resume return struct suspend switch test threadlocal try union
+	unreachable usingnamespace var volatile while type void bool null undefined true false`)
+
 const (
 	NULL = "null"
 )
@@ -19,6 +28,9 @@ type ZigPrinter struct {
 	sameline bool
 	w        io.Writer
 
+	imports importMap // package name -> import path, for the library mappings
+	local   []string  // the imported project packages
+
 	ctx *ZigContext
 }
 
@@ -53,6 +65,8 @@ func (p *ZigPrinter) Reset() {
 	p.level = 0
 	p.sameline = false
 
+	p.imports = nil
+	p.local = nil
 	p.ctx = nil
 }
 
@@ -161,18 +175,30 @@ func (p *ZigPrinter) PrintPackage(name string) {
 func (p *ZigPrinter) PrintImport(name, path string) {
 	p.PrintLevel(NL, "//import", name, path)
 
-	switch path {
-	case `"fmt"`:
-		p.PrintLevel(NL, "#include <fmt.h>")
+	if m := p.imports.add("zig", name, path); m != nil {
+		for _, line := range m.Imports {
+			p.PrintLevel(NL, line)
+		}
+	}
+}
+
+// PrintLocalImport imports the package root file of a converted package (see ZigPackageRoot)
+func (p *ZigPrinter) PrintLocalImport(imp LocalImport) {
+	p.PrintLevel(NL, "//import", imp.Name, imp.Path)
 
-	case `"sync"`:
-		p.PrintLevel(NL, "#include <sync.h>")
+	p.imports.add("zig", imp.LocalName(), imp.Path)
+	p.local = append(p.local, imp.LocalName())
 
-	case `"errors"`:
-		p.PrintLevel(NL, "#include <errors.h>")
+	if len(imp.Dir) == 0 {
+		return // the project root is not a module
+	}
+
+	root := fmt.Sprintf("@import(%q)", RelPath(imp.From, imp.Dir+".zig"))
 
-	case `"time"`:
-		p.PrintLevel(NL, "#include <go_time.h>")
+	if imp.Name == "." {
+		p.PrintLevel(SEMI, "usingnamespace", root)
+	} else {
+		p.PrintLevel(SEMI, "const", imp.LocalName(), "=", root)
 	}
 }
 
@@ -622,11 +648,6 @@ func (p *ZigPrinter) FormatChan(chdir, mtype string) string {
 }
 
 func (p *ZigPrinter) FormatCall(fun, args string, isFuncLit bool) string {
-	if strings.HasPrefix(fun, "time::") {
-		// need to rename, to avoid conflicts with C/C++ "time" :(
-		fun = "go_" + fun
-	}
-
 	if isFuncLit {
 		return fmt.Sprintf("[](%s)->%s", args, fun)
 	} else if fun == "len" {
@@ -655,34 +676,17 @@ func (p *ZigPrinter) FormatFuncLit(ftype, body string) string {
 }
 
 func (p *ZigPrinter) FormatSelector(pname, sel string, isObject bool) string {
-	switch {
-	case pname == "io" && sel == "ReadSeeker":
-		pname = "std"
-		sel = "istream"
-
-	case pname == "io" && sel == "SeekCurrent":
-		pname = "std::ios"
-		sel = "cur"
-
-	case pname == "io" && sel == "SeekStart":
-		pname = "std::ios"
-		sel = "beg"
-
-	case pname == "io" && sel == "SeekEnd":
-		pname = "std::ios"
-		sel = "end"
-
-	case pname == "fmt" && sel == "Printf":
-		pname = "std"
-		sel = "printf"
+	if !isObject {
+		expr, pkg, ok := p.imports.selector("zig", pname, sel)
+		if ok {
+			return expr
+		}
 
-	case pname == "fmt" && sel == "Sprintf":
-		pname = "std"
-		sel = "sprintf"
+		pname = pkg
 
-	case pname == "strconv" && sel == "Itoa":
-		pname = "std"
-		sel = "to_string"
+		if isLocal(p.local, pname) {
+			return fmt.Sprintf("%s.%s", pname, sel)
+		}
 	}
 
 	if isObject {
@@ -826,3 +830,88 @@ func getIdentifier(s string) string {
 
 	return s
 }
+
+func (p *ZigPrinter) SourcePath(path string) string {
+	return path
+}
+
+// ZigPackageRoot returns the root source file of a (library) package,
+// that is "dir.zig" next to the package folder
+func ZigPackageRoot(proj *Project, pkg *Package) string {
+	if pkg.Dir == "" {
+		return Identifier(proj.Name) + ".zig"
+	}
+
+	return pkg.Dir + ".zig"
+}
+
+// ProjectFiles generates a build.zig with a module for each package and an executable for each main package
+func (p *ZigPrinter) ProjectFiles(proj *Project) map[string]string {
+	files := map[string]string{}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "const std = @import(\"std\");\n\n")
+	fmt.Fprintf(&b, "pub fn build(b: *std.Build) void {\n")
+	fmt.Fprintf(&b, "    const target = b.standardTargetOptions(.{});\n")
+	fmt.Fprintf(&b, "    const optimize = b.standardOptimizeOption(.{});\n")
+
+	libs := []string{}
+
+	for _, pkg := range proj.Libraries() {
+		var root strings.Builder
+
+		rpath := ZigPackageRoot(proj, pkg)
+		rdir := path.Dir(rpath)
+
+		fmt.Fprintf(&root, "// package %s\n\n", pkg.Name)
+		for _, f := range pkg.Files {
+			rel, _ := filepath.Rel(rdir, pkg.Path(f))
+			fmt.Fprintf(&root, "pub usingnamespace @import(%q);\n", filepath.ToSlash(rel))
+		}
+		files[rpath] = root.String()
+
+		target := proj.Target(pkg)
+		libs = append(libs, target)
+
+		fmt.Fprintf(&b, "\n    const lib_%s = b.addModule(%q, .{ .root_source_file = b.path(%q) });\n", target, target, rpath)
+	}
+
+	cmds := proj.Commands()
+	if len(cmds) == 0 {
+		// zig doesn't like unused constants
+		fmt.Fprintf(&b, "\n    _ = target;\n    _ = optimize;\n")
+		for _, lib := range libs {
+			fmt.Fprintf(&b, "    _ = lib_%s;\n", lib)
+		}
+	}
+
+	for _, pkg := range cmds {
+		target := proj.Target(pkg)
+		main := pkg.Main
+		if len(main) == 0 {
+			main = pkg.Files[0]
+		}
+
+		fmt.Fprintf(&b, "\n    const exe_%s = b.addExecutable(.{\n", target)
+		fmt.Fprintf(&b, "        .name = %q,\n", target)
+		fmt.Fprintf(&b, "        .root_source_file = b.path(%q),\n", pkg.Path(main))
+		fmt.Fprintf(&b, "        .target = target,\n")
+		fmt.Fprintf(&b, "        .optimize = optimize,\n")
+		fmt.Fprintf(&b, "    });\n")
+		for _, lib := range libs {
+			fmt.Fprintf(&b, "    exe_%s.root_module.addImport(%q, lib_%s);\n", target, lib, lib)
+		}
+		fmt.Fprintf(&b, "    b.installArtifact(exe_%s);\n", target)
+	}
+
+	fmt.Fprintf(&b, "}\n")
+
+	files["build.zig"] = b.String()
+	return files
+}
+
+// EscapeIdent renames the identifiers that are Zig reserved words
+func (p *ZigPrinter) EscapeIdent(id string) string {
+	return zigReserved.Escape(id)
+}
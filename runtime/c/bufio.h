@@ -0,0 +1,615 @@
+#ifndef _GO_RUNTIME_BUFIO_H
+#define _GO_RUNTIME_BUFIO_H 1
+
+#include <go.h>
+#include <io.h>
+#include <utf8.h>
+#include <unicode.h>
+
+//
+// bufio: buffered Reader and Writer, and Scanner (with the split functions ScanLines, ScanWords, ScanRunes
+// and ScanBytes), over any io.Reader or io.Writer. As in Go, the constructors return pointers.
+//
+
+namespace bufio {
+
+const int MaxScanTokenSize = 64 * 1024;
+
+inline const error ErrTooLong = error("bufio.Scanner: token too long");
+inline const error ErrNegativeAdvance = error("bufio.Scanner: SplitFunc returns negative advance count");
+inline const error ErrAdvanceTooFar = error("bufio.Scanner: SplitFunc returns advance count beyond input");
+inline const error ErrBadReadCount = error("bufio.Scanner: Read returned impossible count");
+inline const error ErrFinalToken = error("final token");
+inline const error ErrBufferFull = error("bufio: buffer full");
+inline const error ErrNegativeCount = error("bufio: negative count");
+inline const error ErrInvalidUnreadByte = error("bufio: invalid use of UnreadByte");
+
+namespace detail {
+    const int defaultBufSize = 4096;
+    const int maxConsecutiveEmptyReads = 100;
+
+    inline int indexByte(const Slice<byte>& b, byte c) {
+        for (int i = 0; i < b.len(); i++) {
+            if (b[i] == c) {
+                return i;
+            }
+        }
+        return -1;
+    }
+
+    inline Slice<byte> dropCR(const Slice<byte>& data) {
+        if (data.len() > 0 && data[data.len() - 1] == '\r') {
+            return data(0, data.len() - 1);
+        }
+        return data;
+    }
+}
+
+// SplitFunc splits the input of a Scanner in tokens: it returns the number of bytes to advance the input,
+// the next token (nil if there is none yet) and an error (ErrFinalToken stops the scan without an error)
+typedef std::function<std::tuple<int, Slice<byte>, error>(Slice<byte> data, bool atEOF)> SplitFunc;
+
+// ScanBytes returns each byte as a token
+inline std::tuple<int, Slice<byte>, error> ScanBytes(Slice<byte> data, bool atEOF) {
+    if (atEOF && data.len() == 0) {
+        return std::make_tuple(0, Slice<byte>(), error());
+    }
+    return std::make_tuple(1, data(0, 1), error());
+}
+
+// ScanRunes returns each UTF-8 encoded rune as a token (an invalid encoding is U+FFFD)
+inline std::tuple<int, Slice<byte>, error> ScanRunes(Slice<byte> data, bool atEOF) {
+    if (atEOF && data.len() == 0) {
+        return std::make_tuple(0, Slice<byte>(), error());
+    }
+
+    if (data[0] < utf8::RuneSelf) {
+        return std::make_tuple(1, data(0, 1), error());
+    }
+
+    auto [r, width] = utf8::DecodeRune(data);
+    if (width > 1) {
+        return std::make_tuple(width, data(0, width), error());
+    }
+
+    if (!atEOF && !utf8::FullRune(data)) {
+        return std::make_tuple(0, Slice<byte>(), error()); // incomplete
+    }
+
+    (void)r;
+    return std::make_tuple(1, Slice<byte>(std::string("\xef\xbf\xbd")), error());
+}
+
+// ScanLines returns each line (without the \n or \r\n) as a token, the last one can be empty
+inline std::tuple<int, Slice<byte>, error> ScanLines(Slice<byte> data, bool atEOF) {
+    if (atEOF && data.len() == 0) {
+        return std::make_tuple(0, Slice<byte>(), error());
+    }
+    if (int i = detail::indexByte(data, '\n'); i >= 0) {
+        return std::make_tuple(i + 1, detail::dropCR(data(0, i)), error());
+    }
+    if (atEOF) {
+        return std::make_tuple(data.len(), detail::dropCR(data), error());
+    }
+    return std::make_tuple(0, Slice<byte>(), error());
+}
+
+// ScanWords returns each space separated word as a token
+inline std::tuple<int, Slice<byte>, error> ScanWords(Slice<byte> data, bool atEOF) {
+    int start = 0;
+    while (start < data.len()) {
+        auto [r, width] = utf8::DecodeRune(data(start));
+        if (!unicode::IsSpace(r)) {
+            break;
+        }
+        start += width;
+    }
+
+    for (int i = start; i < data.len();) {
+        auto [r, width] = utf8::DecodeRune(data(i));
+        if (unicode::IsSpace(r)) {
+            return std::make_tuple(i + width, data(start, i), error());
+        }
+        i += width;
+    }
+
+    if (atEOF && data.len() > start) {
+        return std::make_tuple(data.len(), data(start), error());
+    }
+    return std::make_tuple(start, Slice<byte>(), error());
+}
+
+// Scanner reads the tokens of the input (by default the lines)
+class Scanner {
+private:
+    io::Reader r;
+    SplitFunc split = ScanLines;
+    int maxTokenSize = MaxScanTokenSize;
+    Slice<byte> token;
+    Slice<byte> buf;
+    int start = 0;
+    int end = 0;
+    error err;
+    int empties = 0;
+    bool scanCalled = false;
+    bool done = false;
+
+    void setErr(error e) {
+        if (err == nullptr || err == io::EOF_) {
+            err = e;
+        }
+    }
+
+    bool advance(int n) {
+        if (n < 0) {
+            setErr(ErrNegativeAdvance);
+            return false;
+        }
+        if (n > end - start) {
+            setErr(ErrAdvanceTooFar);
+            return false;
+        }
+        start += n;
+        return true;
+    }
+
+public:
+    Scanner(io::Reader r) : r(r) {
+    }
+
+    // Scan advances to the next token, and returns false at the end of the input or on an error
+    bool Scan() {
+        if (done) {
+            return false;
+        }
+        scanCalled = true;
+
+        for (;;) {
+            // the tokens in the buffer
+            if (end > start || err != nullptr) {
+                auto [n, tok, e] = split(buf(start, end), err != nullptr);
+                if (e != nullptr) {
+                    if (e == ErrFinalToken) {
+                        token = tok;
+                        done = true;
+                        return tok != nullptr;
+                    }
+                    setErr(e);
+                    return false;
+                }
+                if (!advance(n)) {
+                    return false;
+                }
+                token = tok;
+                if (tok != nullptr) {
+                    if (err == nullptr || n > 0) {
+                        empties = 0;
+                    } else if (++empties > detail::maxConsecutiveEmptyReads) {
+                        panic("bufio.Scan: too many empty tokens without progressing");
+                    }
+                    return true;
+                }
+            }
+
+            if (err != nullptr) {
+                start = end = 0;
+                return false;
+            }
+
+            // read more data, after moving the data to the start of the buffer or growing it
+            if (start > 0 && (end == buf.len() || start > buf.len() / 2)) {
+                copy(buf, buf(start, end));
+                end -= start;
+                start = 0;
+            }
+
+            if (end == buf.len()) {
+                if (buf.len() >= maxTokenSize) {
+                    setErr(ErrTooLong);
+                    return false;
+                }
+                int size = std::min(buf.len() == 0 ? detail::defaultBufSize : buf.len() * 2, maxTokenSize);
+                Slice<byte> b(size);
+                copy(b, buf(start, end));
+                end -= start;
+                start = 0;
+                buf = b;
+            }
+
+            for (int loop = 0;;) {
+                auto [n, e] = r.Read(buf(end));
+                if (n < 0 || buf.len() - end < n) {
+                    setErr(ErrBadReadCount);
+                    break;
+                }
+                end += n;
+                if (e != nullptr) {
+                    setErr(e);
+                    break;
+                }
+                if (n > 0) {
+                    empties = 0;
+                    break;
+                }
+                if (++loop > detail::maxConsecutiveEmptyReads) {
+                    setErr(io::ErrNoProgress);
+                    break;
+                }
+            }
+        }
+    }
+
+    // Bytes returns the current token (valid until the next Scan)
+    Slice<byte> Bytes() const {
+        return token;
+    }
+
+    std::string Text() const {
+        return std::string(token);
+    }
+
+    // Err returns the first error (nil at the end of the input)
+    error Err() const {
+        return err == io::EOF_ ? error() : err;
+    }
+
+    void Split(SplitFunc f) {
+        if (scanCalled) {
+            panic("Split called after Scan");
+        }
+        split = f;
+    }
+
+    // Buffer sets the initial buffer and the maximum token size
+    void Buffer(Slice<byte> b, int max) {
+        if (scanCalled) {
+            panic("Buffer called after Scan");
+        }
+        buf = b(0, b.cap());
+        maxTokenSize = max;
+    }
+};
+
+inline Scanner *NewScanner(io::Reader r) {
+    return new Scanner(r);
+}
+
+// Reader is a buffered io.Reader
+class Reader {
+private:
+    io::Reader rd;
+    Slice<byte> buf;
+    int r = 0;
+    int w = 0;
+    error err;
+    int lastByte = -1;
+
+    error readErr() {
+        error e = err;
+        err = nullptr;
+        return e;
+    }
+
+    // fill reads a new chunk into the buffer
+    void fill() {
+        if (r > 0) {
+            copy(buf, buf(r, w));
+            w -= r;
+            r = 0;
+        }
+
+        for (int i = detail::maxConsecutiveEmptyReads; i > 0; i--) {
+            auto [n, e] = rd.Read(buf(w));
+            if (n < 0) {
+                panic("bufio: reader returned negative count from Read");
+            }
+            w += n;
+            if (e != nullptr) {
+                err = e;
+                return;
+            }
+            if (n > 0) {
+                return;
+            }
+        }
+        err = io::ErrNoProgress;
+    }
+
+public:
+    Reader(io::Reader rd, int size = detail::defaultBufSize) : rd(rd), buf(std::max(size, 16)) {
+    }
+
+    int Size() const {
+        return buf.len();
+    }
+
+    // Buffered returns the number of bytes that can be read from the buffer
+    int Buffered() const {
+        return w - r;
+    }
+
+    std::tuple<int, error> Read(const Slice<byte>& p) {
+        if (p.len() == 0) {
+            return std::make_tuple(0, Buffered() > 0 ? error() : readErr());
+        }
+
+        if (r == w) {
+            if (err != nullptr) {
+                return std::make_tuple(0, readErr());
+            }
+            if (p.len() >= buf.len()) {
+                // read directly into p
+                auto [n, e] = rd.Read(p);
+                if (n > 0) {
+                    lastByte = p[n - 1];
+                }
+                return std::make_tuple(n, e);
+            }
+            r = w = 0;
+            fill();
+            if (r == w) {
+                return std::make_tuple(0, readErr());
+            }
+        }
+
+        int n = copy(p, buf(r, w));
+        r += n;
+        lastByte = buf[r - 1];
+        return std::make_tuple(n, error());
+    }
+
+    std::tuple<byte, error> ReadByte() {
+        while (r == w) {
+            if (err != nullptr) {
+                return std::make_tuple(byte(0), readErr());
+            }
+            fill();
+        }
+
+        byte c = buf[r++];
+        lastByte = c;
+        return std::make_tuple(c, error());
+    }
+
+    error UnreadByte() {
+        if (lastByte < 0 || (r == 0 && w > 0)) {
+            return ErrInvalidUnreadByte;
+        }
+
+        if (r > 0) {
+            r--;
+        } else {
+            w = 1;
+        }
+        buf[r] = byte(lastByte);
+        lastByte = -1;
+        return nullptr;
+    }
+
+    // ReadRune reads a UTF-8 encoded rune (U+FFFD with size 1 for an invalid encoding)
+    std::tuple<rune, int, error> ReadRune() {
+        while (r + utf8::UTFMax > w && !utf8::FullRune(buf(r, w)) && err == nullptr && w - r < buf.len()) {
+            fill();
+        }
+
+        if (r == w) {
+            return std::make_tuple(rune(0), 0, readErr());
+        }
+
+        auto [c, size] = utf8::DecodeRune(buf(r, w));
+        r += size;
+        lastByte = buf[r - 1];
+        return std::make_tuple(c, size, error());
+    }
+
+    // Peek returns the next n bytes without reading them
+    std::tuple<Slice<byte>, error> Peek(int n) {
+        if (n < 0) {
+            return std::make_tuple(Slice<byte>(), ErrNegativeCount);
+        }
+
+        while (w - r < n && w - r < buf.len() && err == nullptr) {
+            fill();
+        }
+
+        if (n > buf.len()) {
+            return std::make_tuple(buf(r, w), ErrBufferFull);
+        }
+
+        error e;
+        if (int avail = w - r; avail < n) {
+            n = avail;
+            e = readErr();
+            if (e == nullptr) {
+                e = ErrBufferFull;
+            }
+        }
+        return std::make_tuple(buf(r, r + n), e);
+    }
+
+    // ReadSlice reads until the first delim (included) and returns a slice of the buffer, valid until the next read
+    // (ErrBufferFull if the buffer is full without a delim)
+    std::tuple<Slice<byte>, error> ReadSlice(byte delim) {
+        Slice<byte> line;
+        error e;
+        for (int s = 0;;) {
+            if (int i = detail::indexByte(buf(r + s, w), delim); i >= 0) {
+                i += s;
+                line = buf(r, r + i + 1);
+                r += i + 1;
+                break;
+            }
+            if (err != nullptr) {
+                line = buf(r, w);
+                r = w;
+                e = readErr();
+                break;
+            }
+            if (Buffered() >= buf.len()) {
+                r = w;
+                line = buf;
+                e = ErrBufferFull;
+                break;
+            }
+            s = w - r;
+            fill();
+        }
+
+        if (line.len() > 0) {
+            lastByte = line[line.len() - 1];
+        }
+        return std::make_tuple(line, e);
+    }
+
+    // ReadBytes reads until the first delim (included), or returns the data read and the error
+    std::tuple<Slice<byte>, error> ReadBytes(byte delim) {
+        Slice<byte> line(0);
+        for (;;) {
+            auto [frag, e] = ReadSlice(delim);
+            line = appendSlice(line, frag);
+            if (e != ErrBufferFull) {
+                return std::make_tuple(line, e);
+            }
+        }
+    }
+
+    std::tuple<std::string, error> ReadString(byte delim) {
+        auto [line, err] = ReadBytes(delim);
+        return std::make_tuple(std::string(line), err);
+    }
+
+    // ReadLine returns a line without the end of line bytes (isPrefix is true if the line is longer than the buffer)
+    std::tuple<Slice<byte>, bool, error> ReadLine() {
+        auto [line, e] = ReadSlice('\n');
+        if (e == ErrBufferFull) {
+            if (line.len() > 0 && line[line.len() - 1] == '\r') {
+                r--;
+                line = line(0, line.len() - 1);
+            }
+            return std::make_tuple(line, true, error());
+        }
+
+        if (line.len() == 0) {
+            return std::make_tuple(e != nullptr ? Slice<byte>() : line, false, e);
+        }
+
+        if (line[line.len() - 1] == '\n') {
+            line = detail::dropCR(line(0, line.len() - 1));
+        }
+        return std::make_tuple(line, false, error());
+    }
+};
+
+inline Reader *NewReaderSize(io::Reader rd, int size) {
+    return new Reader(rd, size);
+}
+
+inline Reader *NewReader(io::Reader rd) {
+    return new Reader(rd);
+}
+
+// Writer is a buffered io.Writer: the data is written when the buffer is full or by Flush
+class Writer {
+private:
+    io::Writer wr;
+    Slice<byte> buf;
+    int n = 0;
+    error err;
+
+public:
+    Writer(io::Writer wr, int size = detail::defaultBufSize) : wr(wr), buf(size > 0 ? size : detail::defaultBufSize) {
+    }
+
+    int Size() const {
+        return buf.len();
+    }
+
+    int Available() const {
+        return buf.len() - n;
+    }
+
+    int Buffered() const {
+        return n;
+    }
+
+    // Flush writes the buffered data
+    error Flush() {
+        if (err != nullptr) {
+            return err;
+        }
+        if (n == 0) {
+            return nullptr;
+        }
+
+        auto [written, e] = wr.Write(buf(0, n));
+        if (written < n && e == nullptr) {
+            e = io::ErrShortWrite;
+        }
+        if (e != nullptr) {
+            if (written > 0 && written < n) {
+                copy(buf, buf(written, n));
+            }
+            n -= written;
+            err = e;
+            return e;
+        }
+        n = 0;
+        return nullptr;
+    }
+
+    std::tuple<int, error> Write(Slice<byte> p) {
+        int nn = 0;
+        while (p.len() > Available() && err == nullptr) {
+            int written;
+            if (Buffered() == 0) {
+                // a large write with an empty buffer: write directly from p
+                std::tie(written, err) = wr.Write(p);
+            } else {
+                written = copy(buf(n), p);
+                n += written;
+                Flush();
+            }
+            nn += written;
+            p = p(written);
+        }
+
+        if (err != nullptr) {
+            return std::make_tuple(nn, err);
+        }
+
+        int written = copy(buf(n), p);
+        n += written;
+        return std::make_tuple(nn + written, error());
+    }
+
+    std::tuple<int, error> WriteString(const std::string& s) {
+        return Write(Slice<byte>(s));
+    }
+
+    error WriteByte(byte c) {
+        if (err != nullptr) {
+            return err;
+        }
+        if (Available() <= 0 && Flush() != nullptr) {
+            return err;
+        }
+        buf[n++] = c;
+        return nullptr;
+    }
+
+    std::tuple<int, error> WriteRune(rune r) {
+        return WriteString(utf8::detail::encode(r));
+    }
+};
+
+inline Writer *NewWriterSize(io::Writer w, int size) {
+    return new Writer(w, size);
+}
+
+inline Writer *NewWriter(io::Writer w) {
+    return new Writer(w);
+}
+
+}
+
+#endif
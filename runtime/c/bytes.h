@@ -0,0 +1,435 @@
+#ifndef _GO_RUNTIME_BYTES_H
+#define _GO_RUNTIME_BYTES_H 1
+
+#include <go.h>
+#include <go_strings.h>
+
+//
+// bytes: the strings functions for byte slices.
+//
+// As in Go, the functions that cut a slice (Split, Fields, Trim, Cut, etc.) return slices of the
+// same array, while the others (Join, Replace, ToUpper, etc.) return a new slice.
+//
+
+namespace bytes {
+
+    const int MinRead = 512;
+
+    namespace detail {
+
+        inline std::string str(const Slice<byte>& b) {
+            return std::string(b.begin(), b.end());
+        }
+
+        inline Slice<byte> bytes(const std::string& s) {
+            return Slice<byte>(s);
+        }
+
+        // explode splits s into UTF-8 sequences (at most n, the last one is the rest of s)
+        inline Slice<Slice<byte>> explode(const Slice<byte>& s, int n) {
+            int l = utf8::RuneCount(s);
+            if (n <= 0 || n > l) {
+                n = l;
+            }
+
+            Slice<Slice<byte>> a(n);
+            int i = 0;
+            for (int k = 0; k < n - 1; k++) {
+                int size = std::get<1>(utf8::DecodeRune(s(i)));
+                a[k] = s(i, i + size, i + size);
+                i += size;
+            }
+            if (n > 0) {
+                a[n - 1] = s(i);
+            }
+            return a;
+        }
+
+        // genSplit splits s around sep (keeping sepSave bytes of sep) in at most n subslices
+        inline Slice<Slice<byte>> genSplit(const Slice<byte>& s, const Slice<byte>& sep, int sepSave, int n) {
+            if (n == 0) {
+                return Slice<Slice<byte>>();
+            }
+            if (sep.len() == 0) {
+                return explode(s, n);
+            }
+
+            std::string ss = str(s), ssep = str(sep);
+            if (n < 0) {
+                n = strings::Count(ss, ssep) + 1;
+            }
+            if (n > s.len() + 1) {
+                n = s.len() + 1;
+            }
+
+            Slice<Slice<byte>> a(n);
+            n--;
+
+            size_t start = 0;
+            int i = 0;
+            for (; i < n; i++) {
+                size_t m = ss.find(ssep, start);
+                if (m == std::string::npos) {
+                    break;
+                }
+                a[i] = s(start, m + sepSave, m + sepSave);
+                start = m + ssep.size();
+            }
+            a[i] = s(start);
+            return a(0, i + 1);
+        }
+    }
+
+    inline bool Equal(const Slice<byte>& a, const Slice<byte>& b) {
+        return a.len() == b.len() && std::equal(a.begin(), a.end(), b.begin());
+    }
+
+    inline int Compare(const Slice<byte>& a, const Slice<byte>& b) {
+        return strings::Compare(detail::str(a), detail::str(b));
+    }
+
+    inline int Index(const Slice<byte>& s, const Slice<byte>& sep) {
+        return strings::Index(detail::str(s), detail::str(sep));
+    }
+
+    inline int LastIndex(const Slice<byte>& s, const Slice<byte>& sep) {
+        return strings::LastIndex(detail::str(s), detail::str(sep));
+    }
+
+    inline int IndexByte(const Slice<byte>& b, byte c) {
+        auto i = std::find(b.begin(), b.end(), c);
+        return i == b.end() ? -1 : int(i - b.begin());
+    }
+
+    inline int LastIndexByte(const Slice<byte>& s, byte c) {
+        for (int i = s.len() - 1; i >= 0; i--) {
+            if (s[i] == c) {
+                return i;
+            }
+        }
+        return -1;
+    }
+
+    inline int IndexRune(const Slice<byte>& s, rune r) {
+        return strings::IndexRune(detail::str(s), r);
+    }
+
+    inline int IndexAny(const Slice<byte>& s, const std::string& chars) {
+        return strings::IndexAny(detail::str(s), chars);
+    }
+
+    inline int LastIndexAny(const Slice<byte>& s, const std::string& chars) {
+        return strings::LastIndexAny(detail::str(s), chars);
+    }
+
+    inline int IndexFunc(const Slice<byte>& s, const std::function<bool(rune)>& f) {
+        return strings::IndexFunc(detail::str(s), f);
+    }
+
+    inline int LastIndexFunc(const Slice<byte>& s, const std::function<bool(rune)>& f) {
+        return strings::LastIndexFunc(detail::str(s), f);
+    }
+
+    inline bool Contains(const Slice<byte>& b, const Slice<byte>& subslice) {
+        return Index(b, subslice) >= 0;
+    }
+
+    inline bool ContainsAny(const Slice<byte>& b, const std::string& chars) {
+        return IndexAny(b, chars) >= 0;
+    }
+
+    inline bool ContainsRune(const Slice<byte>& b, rune r) {
+        return IndexRune(b, r) >= 0;
+    }
+
+    inline bool ContainsFunc(const Slice<byte>& b, const std::function<bool(rune)>& f) {
+        return IndexFunc(b, f) >= 0;
+    }
+
+    inline int Count(const Slice<byte>& s, const Slice<byte>& sep) {
+        return strings::Count(detail::str(s), detail::str(sep));
+    }
+
+    inline bool HasPrefix(const Slice<byte>& s, const Slice<byte>& prefix) {
+        return s.len() >= prefix.len() && std::equal(prefix.begin(), prefix.end(), s.begin());
+    }
+
+    inline bool HasSuffix(const Slice<byte>& s, const Slice<byte>& suffix) {
+        return s.len() >= suffix.len() && std::equal(suffix.begin(), suffix.end(), s.end() - suffix.len());
+    }
+
+    inline bool EqualFold(const Slice<byte>& s, const Slice<byte>& t) {
+        return strings::EqualFold(detail::str(s), detail::str(t));
+    }
+
+    inline std::tuple<Slice<byte>, Slice<byte>, bool> Cut(const Slice<byte>& s, const Slice<byte>& sep) {
+        if (int i = Index(s, sep); i >= 0) {
+            return {s(0, i), s(i + sep.len()), true};
+        }
+        return {s, Slice<byte>(), false};
+    }
+
+    inline std::tuple<Slice<byte>, bool> CutPrefix(const Slice<byte>& s, const Slice<byte>& prefix) {
+        if (!HasPrefix(s, prefix)) {
+            return {s, false};
+        }
+        return {s(prefix.len()), true};
+    }
+
+    inline std::tuple<Slice<byte>, bool> CutSuffix(const Slice<byte>& s, const Slice<byte>& suffix) {
+        if (!HasSuffix(s, suffix)) {
+            return {s, false};
+        }
+        return {s(0, s.len() - suffix.len()), true};
+    }
+
+    inline Slice<Slice<byte>> Split(const Slice<byte>& s, const Slice<byte>& sep) {
+        return detail::genSplit(s, sep, 0, -1);
+    }
+
+    inline Slice<Slice<byte>> SplitN(const Slice<byte>& s, const Slice<byte>& sep, int n) {
+        return detail::genSplit(s, sep, 0, n);
+    }
+
+    inline Slice<Slice<byte>> SplitAfter(const Slice<byte>& s, const Slice<byte>& sep) {
+        return detail::genSplit(s, sep, sep.len(), -1);
+    }
+
+    inline Slice<Slice<byte>> SplitAfterN(const Slice<byte>& s, const Slice<byte>& sep, int n) {
+        return detail::genSplit(s, sep, sep.len(), n);
+    }
+
+    inline Slice<Slice<byte>> FieldsFunc(const Slice<byte>& s, const std::function<bool(rune)>& f) {
+        Slice<Slice<byte>> a(0);
+        int start = -1;
+
+        for (int i = 0; i < s.len();) {
+            auto [r, size] = utf8::DecodeRune(s(i));
+            if (f(r)) {
+                if (start >= 0) {
+                    a = append(a, s(start, i, i));
+                    start = -1;
+                }
+            } else if (start < 0) {
+                start = i;
+            }
+            i += size;
+        }
+
+        if (start >= 0) {
+            a = append(a, s(start, s.len(), s.len()));
+        }
+        return a;
+    }
+
+    inline Slice<Slice<byte>> Fields(const Slice<byte>& s) {
+        return FieldsFunc(s, unicode::IsSpace);
+    }
+
+    inline Slice<byte> Join(const Slice<Slice<byte>>& s, const Slice<byte>& sep) {
+        Slice<byte> b(0);
+        for (int i = 0; i < s.len(); i++) {
+            if (i > 0) {
+                b = appendSlice(b, sep);
+            }
+            b = appendSlice(b, s[i]);
+        }
+        return b;
+    }
+
+    inline Slice<byte> Repeat(const Slice<byte>& b, int count) {
+        if (count < 0) {
+            panic("bytes: negative Repeat count");
+        }
+        return detail::bytes(strings::Repeat(detail::str(b), count));
+    }
+
+    inline Slice<byte> Replace(const Slice<byte>& s, const Slice<byte>& old, const Slice<byte>& new_, int n) {
+        return detail::bytes(strings::Replace(detail::str(s), detail::str(old), detail::str(new_), n));
+    }
+
+    inline Slice<byte> ReplaceAll(const Slice<byte>& s, const Slice<byte>& old, const Slice<byte>& new_) {
+        return Replace(s, old, new_, -1);
+    }
+
+    inline Slice<byte> Map(const std::function<rune(rune)>& mapping, const Slice<byte>& s) {
+        return detail::bytes(strings::Map(mapping, detail::str(s)));
+    }
+
+    inline Slice<byte> ToUpper(const Slice<byte>& s) {
+        return Map(unicode::ToUpper, s);
+    }
+
+    inline Slice<byte> ToLower(const Slice<byte>& s) {
+        return Map(unicode::ToLower, s);
+    }
+
+    inline Slice<byte> ToTitle(const Slice<byte>& s) {
+        return Map(unicode::ToTitle, s);
+    }
+
+    inline Slice<byte> ToValidUTF8(const Slice<byte>& s, const Slice<byte>& replacement) {
+        return detail::bytes(strings::ToValidUTF8(detail::str(s), detail::str(replacement)));
+    }
+
+    inline Slice<byte> TrimLeftFunc(const Slice<byte>& s, const std::function<bool(rune)>& f) {
+        return s(s.len() - strings::TrimLeftFunc(detail::str(s), f).size());
+    }
+
+    inline Slice<byte> TrimRightFunc(const Slice<byte>& s, const std::function<bool(rune)>& f) {
+        return s(0, strings::TrimRightFunc(detail::str(s), f).size());
+    }
+
+    inline Slice<byte> TrimFunc(const Slice<byte>& s, const std::function<bool(rune)>& f) {
+        return TrimRightFunc(TrimLeftFunc(s, f), f);
+    }
+
+    inline Slice<byte> TrimLeft(const Slice<byte>& s, const std::string& cutset) {
+        return s(s.len() - strings::TrimLeft(detail::str(s), cutset).size());
+    }
+
+    inline Slice<byte> TrimRight(const Slice<byte>& s, const std::string& cutset) {
+        return s(0, strings::TrimRight(detail::str(s), cutset).size());
+    }
+
+    inline Slice<byte> Trim(const Slice<byte>& s, const std::string& cutset) {
+        return TrimRight(TrimLeft(s, cutset), cutset);
+    }
+
+    inline Slice<byte> TrimSpace(const Slice<byte>& s) {
+        return TrimFunc(s, unicode::IsSpace);
+    }
+
+    inline Slice<byte> TrimPrefix(const Slice<byte>& s, const Slice<byte>& prefix) {
+        return HasPrefix(s, prefix) ? s(prefix.len()) : s;
+    }
+
+    inline Slice<byte> TrimSuffix(const Slice<byte>& s, const Slice<byte>& suffix) {
+        return HasSuffix(s, suffix) ? s(0, s.len() - suffix.len()) : s;
+    }
+
+    // Runes returns the runes (Unicode code points) of s
+    inline Slice<rune> Runes(const Slice<byte>& s) {
+        Slice<rune> t(utf8::RuneCount(s));
+        for (int i = 0, k = 0; i < s.len(); k++) {
+            auto [r, size] = utf8::DecodeRune(s(i));
+            t[k] = r;
+            i += size;
+        }
+        return t;
+    }
+
+    inline Slice<byte> Clone(const Slice<byte>& b) {
+        if (b == nullptr) {
+            return Slice<byte>();
+        }
+        return appendSlice(Slice<byte>(0), b);
+    }
+
+    // Buffer is a byte buffer with the Write methods
+    class Buffer {
+    private:
+        Slice<byte> buf;
+        int off = 0; // the bytes before off have been consumed (see Next)
+
+    public:
+        Buffer() {
+        }
+
+        explicit Buffer(const Slice<byte>& buf) : buf(buf) {
+        }
+
+        Slice<byte> Bytes() const {
+            return buf(off);
+        }
+
+        std::string String() const {
+            return detail::str(buf(off));
+        }
+
+        int Len() const {
+            return buf.len() - off;
+        }
+
+        int Cap() const {
+            return buf.cap();
+        }
+
+        void Reset() {
+            buf = buf(0, 0);
+            off = 0;
+        }
+
+        void Truncate(int n) {
+            if (n == 0) {
+                Reset();
+                return;
+            }
+            if (n < 0 || n > Len()) {
+                panic("bytes.Buffer: truncation out of range");
+            }
+            buf = buf(0, off + n);
+        }
+
+        void Grow(int n) {
+            if (n < 0) {
+                panic("bytes.Buffer.Grow: negative count");
+            }
+            if (buf.cap() - buf.len() < n) {
+                Slice<byte> b(buf.len(), buf.len() + n);
+                copy(b, buf);
+                buf = b;
+            }
+        }
+
+        // Next returns the next n bytes (or all of them) and consumes them
+        Slice<byte> Next(int n) {
+            n = std::min(n, Len());
+            Slice<byte> data = buf(off, off + n);
+            off += n;
+            return data;
+        }
+
+        // Read reads the next len(p) bytes (io.EOF if the buffer is empty)
+        std::tuple<int, error> Read(const Slice<byte>& p) {
+            if (Len() == 0) {
+                Reset();
+                return {0, p.len() == 0 ? error() : io::EOF_};
+            }
+            int n = copy(p, buf(off));
+            off += n;
+            return {n, error()};
+        }
+
+        std::tuple<int, error> Write(const Slice<byte>& p) {
+            buf = appendSlice(buf, p);
+            return {p.len(), error()};
+        }
+
+        std::tuple<int, error> WriteString(const std::string& s) {
+            buf = appendSlice(buf, s);
+            return {s.size(), error()};
+        }
+
+        error WriteByte(byte c) {
+            buf = append(buf, c);
+            return error();
+        }
+
+        std::tuple<int, error> WriteRune(rune r) {
+            std::string s = utf8::detail::encode(r);
+            buf = appendSlice(buf, s);
+            return {s.size(), error()};
+        }
+    };
+
+    inline Buffer *NewBuffer(const Slice<byte>& buf) {
+        return new Buffer(buf);
+    }
+
+    inline Buffer *NewBufferString(const std::string& s) {
+        return new Buffer(Slice<byte>(s));
+    }
+}
+
+#endif
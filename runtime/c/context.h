@@ -0,0 +1,450 @@
+#ifndef _GO_RUNTIME_CONTEXT_H
+#define _GO_RUNTIME_CONTEXT_H 1
+
+#include <go.h>
+#include <go_time.h>
+#include <map>
+
+//
+// context: a Context is a reference to a node of a tree rooted in Background (or TODO).
+//
+// Canceling a context closes its Done channel, sets its Err and cancels its children. The cancel contexts
+// are registered with the nearest cancel context among their parents, and WithDeadline and WithTimeout
+// cancel the context with a timer (see go_time.h). Value looks up the keys from the context to the root.
+//
+
+namespace context {
+
+typedef std::function<void()> CancelFunc;
+typedef std::function<void(error)> CancelCauseFunc;
+
+namespace detail {
+    // the error of the contexts canceled by a deadline
+    struct deadlineExceededError {
+        static constexpr const char *_type = "context.deadlineExceededError";
+
+        std::string Error() const {
+            return "context deadline exceeded";
+        }
+
+        bool Timeout() const {
+            return true;
+        }
+
+        bool Temporary() const {
+            return true;
+        }
+    };
+}
+
+inline const error Canceled = error("context canceled");
+inline const error DeadlineExceeded = detail::deadlineExceededError();
+
+namespace detail {
+    struct cancelCtx;
+
+    // a node of the context tree
+    struct node {
+        virtual ~node() {}
+        virtual std::tuple<go_time::Time, bool> Deadline() = 0;
+        virtual Chan<EmptyStruct> Done() = 0;
+        virtual error Err() = 0;
+        virtual std::any Value(const std::any& key) = 0;
+        virtual std::string String() = 0;
+
+        // the nearest cancel context (this one, or a parent), nullptr if the context can't be canceled
+        virtual cancelCtx *canceler() = 0;
+    };
+}
+
+class Context {
+private:
+    std::shared_ptr<detail::node> c;
+
+public:
+    static constexpr const char *_type = "context.Context";
+
+    Context() {
+    }
+
+    Context(std::nullptr_t) {
+    }
+
+    Context(std::shared_ptr<detail::node> c) : c(c) {
+    }
+
+    bool operator==(std::nullptr_t) const {
+        return c == nullptr;
+    }
+
+    bool operator!=(std::nullptr_t) const {
+        return c != nullptr;
+    }
+
+    bool operator==(const Context& other) const {
+        return c == other.c;
+    }
+
+    bool operator!=(const Context& other) const {
+        return c != other.c;
+    }
+
+    // Deadline returns the time when the context will be canceled, and false if there is no deadline
+    std::tuple<go_time::Time, bool> Deadline() const {
+        return node()->Deadline();
+    }
+
+    // Done returns a channel closed when the context is canceled (nil if it can't be canceled)
+    Chan<EmptyStruct> Done() const {
+        return node()->Done();
+    }
+
+    // Err returns nil until the context is canceled, then Canceled or DeadlineExceeded
+    error Err() const {
+        return node()->Err();
+    }
+
+    // Value returns the value associated with key in the context or its parents (nil if there is none)
+    std::any Value(std::any key) const {
+        if (auto s = std::any_cast<const char *>(&key)) {
+            key = std::string(*s);
+        }
+        return node()->Value(key);
+    }
+
+    std::string String() const {
+        return node()->String();
+    }
+
+    detail::node *node() const {
+        if (c == nullptr) {
+            panic(error("invalid memory address or nil pointer dereference"));
+        }
+        return c.get();
+    }
+};
+
+namespace detail {
+    // Background and TODO
+    struct emptyCtx : node {
+        std::string name;
+
+        emptyCtx(std::string name) : name(name) {
+        }
+
+        std::tuple<go_time::Time, bool> Deadline() override {
+            return std::make_tuple(go_time::Time(), false);
+        }
+
+        Chan<EmptyStruct> Done() override {
+            return nullptr;
+        }
+
+        error Err() override {
+            return nullptr;
+        }
+
+        std::any Value(const std::any&) override {
+            return std::any();
+        }
+
+        std::string String() override {
+            return name;
+        }
+
+        cancelCtx *canceler() override {
+            return nullptr;
+        }
+    };
+
+    // a context that can be canceled (WithCancel, and WithDeadline with a timer)
+    struct cancelCtx : node, std::enable_shared_from_this<cancelCtx> {
+        Context parent;
+        Chan<EmptyStruct> done{0};
+
+        std::mutex m;
+        error err;
+        error cause;
+        std::map<cancelCtx *, std::weak_ptr<cancelCtx>> children;
+
+        cancelCtx(Context parent) : parent(parent) {
+        }
+
+        ~cancelCtx() {
+            if (auto p = parent.node()->canceler()) {
+                p->removeChild(this);
+            }
+        }
+
+        // propagate registers the context with its parent, or cancels it if the parent is already canceled
+        void propagate() {
+            if (auto p = parent.node()->canceler()) {
+                std::unique_lock<std::mutex> lk(p->m);
+                if (p->err == nullptr) {
+                    p->children[this] = shared_from_this();
+                    return;
+                }
+
+                error e = p->err, c = p->cause;
+                lk.unlock();
+                cancel(false, e, c);
+            }
+        }
+
+        void removeChild(cancelCtx *child) {
+            std::unique_lock<std::mutex> lk(m);
+            children.erase(child);
+        }
+
+        // cancel closes done, cancels the children and, if removeFromParent is true, removes the context
+        // from its parent (a nil cause is err)
+        void cancel(bool removeFromParent, error e, error c) {
+            if (c == nullptr) {
+                c = e;
+            }
+
+            std::map<cancelCtx *, std::weak_ptr<cancelCtx>> canceled;
+            {
+                std::unique_lock<std::mutex> lk(m);
+                if (err != nullptr) {
+                    return; // already canceled
+                }
+
+                err = e;
+                cause = c;
+                done.Close();
+                std::swap(canceled, children);
+            }
+
+            for (auto& child : canceled) {
+                if (auto ch = child.second.lock()) {
+                    ch->cancel(false, e, c);
+                }
+            }
+
+            if (removeFromParent) {
+                if (auto p = parent.node()->canceler()) {
+                    p->removeChild(this);
+                }
+            }
+
+            stop();
+        }
+
+        // stop is called when the context is canceled (to stop the deadline timer)
+        virtual void stop() {
+        }
+
+        std::tuple<go_time::Time, bool> Deadline() override {
+            return parent.Deadline();
+        }
+
+        Chan<EmptyStruct> Done() override {
+            return done;
+        }
+
+        error Err() override {
+            std::unique_lock<std::mutex> lk(m);
+            return err;
+        }
+
+        std::any Value(const std::any& key) override {
+            return parent.node()->Value(key);
+        }
+
+        std::string String() override {
+            return parent.String() + ".WithCancel";
+        }
+
+        cancelCtx *canceler() override {
+            return this;
+        }
+
+        // the cancel function for the context
+        CancelFunc cancelFunc() {
+            auto self = shared_from_this();
+            return [self]() { self->cancel(true, Canceled, nullptr); };
+        }
+    };
+
+    // a context canceled at its deadline
+    struct timerCtx : cancelCtx {
+        go_time::Time deadline;
+        std::shared_ptr<go_time::detail::timer> t = std::make_shared<go_time::detail::timer>();
+
+        timerCtx(Context parent, go_time::Time deadline) : cancelCtx(parent), deadline(deadline) {
+        }
+
+        // start starts the timer (called after propagate)
+        void start() {
+            go_time::Duration d = go_time::Until(deadline);
+            if (d <= 0) {
+                cancel(true, DeadlineExceeded, nullptr);
+                return;
+            }
+
+            std::weak_ptr<cancelCtx> self = shared_from_this();
+            t->f = [self]() {
+                if (auto c = self.lock()) {
+                    c->cancel(true, DeadlineExceeded, nullptr);
+                }
+            };
+
+            std::unique_lock<std::mutex> lk(m);
+            if (err == nullptr) {
+                go_time::detail::timers::Get().Start(t, go_time::detail::monotonic() + d);
+            }
+        }
+
+        void stop() override {
+            go_time::detail::timers::Get().Stop(t);
+        }
+
+        std::tuple<go_time::Time, bool> Deadline() override {
+            return std::make_tuple(deadline, true);
+        }
+
+        std::string String() override {
+            return parent.String() + ".WithDeadline(" + deadline.String() + " [" + go_time::Until(deadline).String() + "])";
+        }
+    };
+
+    // a context with a key, value pair
+    struct valueCtx : node {
+        Context parent;
+        std::any key, val;
+        std::function<bool(const std::any&)> matches; // true for a key equal to this one
+
+        std::tuple<go_time::Time, bool> Deadline() override {
+            return parent.Deadline();
+        }
+
+        Chan<EmptyStruct> Done() override {
+            return parent.Done();
+        }
+
+        error Err() override {
+            return parent.Err();
+        }
+
+        std::any Value(const std::any& k) override {
+            if (matches(k)) {
+                return val;
+            }
+            return parent.node()->Value(k);
+        }
+
+        static std::string stringify(const std::any& v) {
+            if (auto s = std::any_cast<std::string>(&v)) {
+                return *s;
+            } else if (!v.has_value()) {
+                return "<nil>";
+            }
+            return "<not Stringer>";
+        }
+
+        std::string String() override {
+            return parent.String() + ".WithValue(" + stringify(key) + ", " + stringify(val) + ")";
+        }
+
+        cancelCtx *canceler() override {
+            return parent.node()->canceler();
+        }
+    };
+
+    inline void checkParent(const Context& parent) {
+        if (parent == nullptr) {
+            panic(error("cannot create context from nil parent"));
+        }
+    }
+}
+
+// Background returns the root context, never canceled and without values or deadline
+inline Context Background() {
+    static Context background(std::make_shared<detail::emptyCtx>("context.Background"));
+    return background;
+}
+
+// TODO is the same as Background, for the code that doesn't have a context yet
+inline Context TODO() {
+    static Context todo(std::make_shared<detail::emptyCtx>("context.TODO"));
+    return todo;
+}
+
+// WithCancel returns a child of parent that is canceled when cancel is called or the parent is canceled
+inline std::tuple<Context, CancelFunc> WithCancel(Context parent) {
+    detail::checkParent(parent);
+
+    auto c = std::make_shared<detail::cancelCtx>(parent);
+    c->propagate();
+    return std::make_tuple(Context(c), c->cancelFunc());
+}
+
+// WithCancelCause is like WithCancel, but the cancel function sets the cause returned by Cause
+inline std::tuple<Context, CancelCauseFunc> WithCancelCause(Context parent) {
+    detail::checkParent(parent);
+
+    auto c = std::make_shared<detail::cancelCtx>(parent);
+    c->propagate();
+    return std::make_tuple(Context(c), [c](error cause) { c->cancel(true, Canceled, cause); });
+}
+
+// Cause returns the cause of the cancellation of c (its Err, if it wasn't canceled with a cause)
+inline error Cause(Context c) {
+    if (auto cc = c.node()->canceler()) {
+        std::unique_lock<std::mutex> lk(cc->m);
+        return cc->cause;
+    }
+    return c.Err();
+}
+
+// WithDeadline returns a child of parent that is also canceled at d (with DeadlineExceeded)
+inline std::tuple<Context, CancelFunc> WithDeadline(Context parent, go_time::Time d) {
+    detail::checkParent(parent);
+
+    if (auto [cur, ok] = parent.Deadline(); ok && cur.Before(d)) {
+        // the current deadline is already sooner than the new one
+        return WithCancel(parent);
+    }
+
+    auto c = std::make_shared<detail::timerCtx>(parent, d);
+    c->propagate();
+    c->start();
+    return std::make_tuple(Context(c), c->cancelFunc());
+}
+
+// WithTimeout returns WithDeadline(parent, time.Now().Add(timeout))
+inline std::tuple<Context, CancelFunc> WithTimeout(Context parent, go_time::Duration timeout) {
+    return WithDeadline(parent, go_time::Now().Add(timeout));
+}
+
+// WithValue returns a child of parent where Value(key) is val. The keys are equal if they have the same type
+// and equal values (all the values of a type without ==, as the empty structs, are equal)
+template<class K, class V> Context WithValue(Context parent, const K& k, const V& v) {
+    detail::checkParent(parent);
+
+    typedef typename std::conditional<std::is_array<K>::value || std::is_same<K, const char *>::value, std::string, K>::type Key;
+    Key key = k;
+
+    auto c = std::make_shared<detail::valueCtx>();
+    c->parent = parent;
+    c->key = key;
+    if constexpr (std::is_same<V, const char *>::value || std::is_array<V>::value) {
+        c->val = std::string(v);
+    } else {
+        c->val = v;
+    }
+    c->matches = [key](const std::any& other) {
+        auto o = std::any_cast<Key>(&other);
+        if constexpr (error_detail::is_comparable<Key>::value) {
+            return o != nullptr && *o == key;
+        } else {
+            return o != nullptr;
+        }
+    };
+    return Context(c);
+}
+
+}
+
+#endif
@@ -2,9 +2,156 @@
 #define _GO_RUNTIME_ERRORS_H
 
 namespace errors {
-    error New(std::string message) {
+    namespace detail {
+        // the value of Join
+        struct joinError {
+            static constexpr const char *_type = "*errors.joinError";
+            Slice<error> errs;
+
+            std::string Error() const {
+                std::string s;
+                for (int i = 0; i < len(errs); i++) {
+                    if (i > 0) {
+                        s += "\n";
+                    }
+                    s += errs[i].Error();
+                }
+                return s;
+            }
+
+            Slice<error> Unwrap() const {
+                return errs;
+            }
+        };
+    }
+
+    inline error New(std::string message) {
         return error(message);
     }
+
+    inline const error ErrUnsupported = New("unsupported operation");
+
+    // Unwrap returns the result of the Unwrap() error method of err, or nil
+    // (also for the errors with an Unwrap() []error method)
+    inline error Unwrap(error err) {
+        std::vector<error> errs;
+        if (err != nullptr && err._iface()->unwrap(errs) == 1) {
+            return errs[0];
+        }
+        return nullptr;
+    }
+
+    // Is reports whether an error in the tree of err (err, and the errors returned by Unwrap, depth first)
+    // is equal to target or has an Is(error) method that returns true for it
+    inline bool Is(error err, error target) {
+        if (err == nullptr || target == nullptr) {
+            return err == target;
+        }
+
+        while (err != nullptr) {
+            if (err == target || err._iface()->is(target)) {
+                return true;
+            }
+
+            std::vector<error> errs;
+            switch (err._iface()->unwrap(errs)) {
+            case 1:
+                err = errs[0];
+                break;
+
+            case 2:
+                for (const error& e : errs) {
+                    if (Is(e, target)) {
+                        return true;
+                    }
+                }
+                return false;
+
+            default:
+                return false;
+            }
+        }
+
+        return false;
+    }
+
+    // As finds the first error in the tree of err that has the type of *target (any error if target is an *error)
+    // and if found, sets *target to it and returns true
+    template<class T> bool As(error err, T *target) {
+        if (target == nullptr) {
+            panic("errors: target cannot be nil");
+        }
+
+        while (err != nullptr) {
+            if constexpr (std::is_same<T, error>::value) {
+                *target = err;
+                return true;
+            } else if (err._iface()->type() == typeid(T)) {
+                *target = *static_cast<T *>(err._iface()->value());
+                return true;
+            }
+
+            std::vector<error> errs;
+            switch (err._iface()->unwrap(errs)) {
+            case 1:
+                err = errs[0];
+                break;
+
+            case 2:
+                for (const error& e : errs) {
+                    if (As(e, target)) {
+                        return true;
+                    }
+                }
+                return false;
+
+            default:
+                return false;
+            }
+        }
+
+        return false;
+    }
+
+    // As with a reference counted target (see Ptr in go.h): a *T found shares the ownership of err
+    template<class T> bool As(error err, const Ptr<T>& target) {
+        if (target == nullptr) {
+            panic("errors: target cannot be nil");
+        }
+
+        if constexpr (is_ptr<T>::value) {
+            typedef typename T::element_type E;
+
+            E *found = nullptr;
+            if (!As(err, &found)) {
+                return false;
+            }
+            *target = T(std::shared_ptr<E>(std::make_shared<error>(err), found));
+            return true;
+        } else {
+            return As(err, target.get());
+        }
+    }
+
+    // Join returns an error that wraps the non nil errs (nil if there are none),
+    // with the messages of the errors separated by newlines
+    inline error Join(const Slice<error>& errs) {
+        detail::joinError j;
+        for (const error& e : errs) {
+            if (e != nullptr) {
+                j.errs = append(j.errs, e);
+            }
+        }
+
+        if (len(j.errs) == 0) {
+            return nullptr;
+        }
+        return j;
+    }
+
+    template<class... E> error Join(const E&... errs) {
+        return Join(Slice<error>{error(errs)...});
+    }
 }
 
 #endif
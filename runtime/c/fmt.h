@@ -1,19 +1,960 @@
 #ifndef _GO_RUNTIME_FMT_H
 #define _GO_RUNTIME_FMT_H 1
 
-#include <iostream>
+#include <go.h>
+#include <utf8.h>
+#include <strconv.h>
+#include <cctype>
+#include <cmath>
+#include <cstdint>
+#include <cstdio>
+#include <cstdlib>
+#include <sstream>
+#include <type_traits>
+
+//
+// Go formatting (Print, Println, Printf, Sprint, Sprintln, Sprintf, Fprint, Fprintln, Fprintf, Errorf).
+//
+// The values are formatted as in Go: the operands that have an Error() or String() method use it,
+// slices and maps print their elements, and the structs generated by walkngo print their fields
+// (via the _fields method), so that %v, %+v and %#v work.
+//
 
 namespace fmt {
 
-    template<typename... T> void Print(T... args) {
-        int dummy[sizeof...(T)] = { (std::cout << args, 0)... };
+    // the flags of a verb (%+-# 0, width and precision)
+    struct Flags {
+        bool plus = false;
+        bool minus = false;
+        bool sharp = false;
+        bool space = false;
+        bool zero = false;
+        int width = -1;
+        int prec = -1;
+
+        bool plusV = false;  // %+v
+        bool sharpV = false; // %#v
+    };
+
+    namespace detail {
+
+        template<class T> struct is_slice : std::false_type {};
+        template<class T> struct is_slice<Slice<T>> : std::true_type {};
+
+        template<class T> struct is_map : std::false_type {};
+        template<class K, class V> struct is_map<Map<K, V>> : std::true_type {};
+
+        template<class T> struct is_chan : std::false_type {};
+        template<class T> struct is_chan<Chan<T>> : std::true_type {};
+
+        template<class T> struct is_tuple : std::false_type {};
+        template<class... T> struct is_tuple<std::tuple<T...>> : std::true_type {};
+
+        template<class T, class = void> struct has_error : std::false_type {};
+        template<class T> struct has_error<T, std::void_t<decltype(std::string(std::declval<T&>().Error()))>> : std::true_type {};
+
+        template<class T, class = void> struct has_string : std::false_type {};
+        template<class T> struct has_string<T, std::void_t<decltype(std::string(std::declval<T&>().String()))>> : std::true_type {};
+
+        // the structs generated by walkngo have a _fields method, that calls f(name, value) for each field
+        struct anyField {
+            template<class V> void operator()(const char *, const V&) const {
+            }
+        };
+
+        template<class T, class = void> struct has_fields : std::false_type {};
+        template<class T> struct has_fields<T, std::void_t<decltype(std::declval<T&>()._fields(anyField()))>> : std::true_type {};
+
+        // the named types with methods that are not structs wrap the value (see CPrinter.FormatNamedType)
+        template<class T, class = void> struct has_value : std::false_type {};
+        template<class T> struct has_value<T, std::void_t<decltype(std::declval<T&>()._value)>> : std::true_type {};
+
+        template<class T, class = void> struct has_type : std::false_type {};
+        template<class T> struct has_type<T, std::void_t<decltype(T::_type)>> : std::true_type {};
+
+        template<class T> constexpr bool is_string = std::is_same<T, std::string>::value
+            || std::is_same<T, const char *>::value || std::is_same<T, char *>::value;
+
+        // the Go name of a type (for %T and %#v)
+        template<class T> std::string typeName();
+
+        template<class T> std::string typeName() {
+            if constexpr (std::is_same<T, bool>::value) {
+                return "bool";
+            } else if constexpr (is_string<T>) {
+                return "string";
+            } else if constexpr (std::is_same<T, char>::value || std::is_same<T, unsigned char>::value) {
+                return "uint8";
+            } else if constexpr (std::is_same<T, signed char>::value) {
+                return "int8";
+            } else if constexpr (std::is_integral<T>::value) {
+                std::string name = std::is_signed<T>::value ? "int" : "uint";
+                if (std::is_same<T, int>::value) {
+                    return name;
+                }
+                return name + std::to_string(sizeof(T) * 8);
+            } else if constexpr (std::is_floating_point<T>::value) {
+                return "float" + std::to_string(sizeof(T) * 8);
+            } else if constexpr (std::is_same<T, std::nullptr_t>::value) {
+                return "<nil>";
+            } else if constexpr (std::is_same<T, std::any>::value) {
+                return "interface {}";
+            } else if constexpr (std::is_same<T, error>::value) {
+                return "error";
+            } else if constexpr (std::is_pointer<T>::value) {
+                return "*" + typeName<typename std::remove_cv<typename std::remove_pointer<T>::type>::type>();
+            } else if constexpr (is_ptr<T>::value) {
+                return "*" + typeName<typename T::element_type>();
+            } else if constexpr (is_slice<T>::value) {
+                return "[]" + typeName<typename T::value_type>();
+            } else if constexpr (std::is_array<T>::value) {
+                return "[" + std::to_string(std::extent<T>::value) + "]" + typeName<typename std::remove_extent<T>::type>();
+            } else if constexpr (is_chan<T>::value) {
+                return "chan " + typeName<typename T::value_type>();
+            } else if constexpr (is_map<T>::value) {
+                return "map[" + typeName<typename T::key_type>() + "]" + typeName<typename T::mapped_type>();
+            } else if constexpr (has_type<T>::value) {
+                return T::_type;
+            } else {
+                return typeid(T).name();
+            }
+        }
+
+        // the Go name of the dynamic type of an interface{} value
+        inline std::string anyTypeName(const std::any& a);
+
+        inline void pad(std::string& out, const std::string& s, const Flags& f, bool zero = false) {
+            int n = f.width - utf8::RuneCountInString(s);
+            if (n <= 0) {
+                out += s;
+                return;
+            }
+
+            if (f.minus) {
+                out += s;
+                out.append(n, ' ');
+                return;
+            }
+
+            if (zero && f.zero) {
+                // the zeros go after the sign
+                size_t sign = (!s.empty() && (s[0] == '-' || s[0] == '+' || s[0] == ' ')) ? 1 : 0;
+                out += s.substr(0, sign);
+                out.append(n, '0');
+                out += s.substr(sign);
+                return;
+            }
+
+            out.append(n, ' ');
+            out += s;
+        }
+
+        inline void badVerb(std::string& out, char verb, const std::string& type, const std::string& value) {
+            out += std::string("%!") + verb + "(" + type + "=" + value + ")";
+        }
+
+        inline std::string hex(uint64 v, bool upper) {
+            char buf[32];
+            std::snprintf(buf, sizeof(buf), upper ? "%llX" : "%llx", (unsigned long long) v);
+            return buf;
+        }
+
+        inline void fmtString(std::string& out, std::string s, char verb, const Flags& f) {
+            if (f.prec >= 0 && (verb == 's' || verb == 'v' || verb == 'q' || verb == 'x' || verb == 'X')) {
+                // the precision is the number of runes
+                size_t i = 0;
+                for (int n = 0; n < f.prec && i < s.size(); n++) {
+                    i += std::get<1>(utf8::detail::decodeAt(s, i));
+                }
+                s = s.substr(0, i);
+            }
+
+            switch (verb) {
+            case 'v':
+                if (f.sharpV) {
+                    pad(out, strconv::Quote(s), f);
+                    return;
+                }
+                pad(out, s, f);
+                return;
+
+            case 's':
+                pad(out, s, f);
+                return;
+
+            case 'q':
+                if (f.sharp && strconv::CanBackquote(s)) {
+                    pad(out, "`" + s + "`", f);
+                } else {
+                    pad(out, f.plus ? strconv::QuoteToASCII(s) : strconv::Quote(s), f);
+                }
+                return;
+
+            case 'x':
+            case 'X': {
+                std::string h;
+                for (size_t i = 0; i < s.size(); i++) {
+                    if (i > 0 && f.space) {
+                        h += ' ';
+                    }
+                    if (f.sharp && (i == 0 || f.space)) {
+                        h += verb == 'x' ? "0x" : "0X";
+                    }
+                    char buf[4];
+                    std::snprintf(buf, sizeof(buf), verb == 'x' ? "%02x" : "%02X", (unsigned char) s[i]);
+                    h += buf;
+                }
+                pad(out, h, f);
+                return;
+            }
+            }
+
+            badVerb(out, verb, "string", s);
+        }
+
+        inline void fmtBool(std::string& out, bool b, char verb, const Flags& f) {
+            if (verb == 'v' || verb == 't') {
+                pad(out, b ? "true" : "false", f);
+            } else {
+                badVerb(out, verb, "bool", b ? "true" : "false");
+            }
+        }
+
+        inline void fmtInteger(std::string& out, uint64 u, bool neg, char verb, const Flags& f, const std::string& type) {
+            std::string digits;
+
+            switch (verb) {
+            case 'v':
+            case 'd':
+                digits = std::to_string(u);
+                break;
+
+            case 'b':
+                for (uint64 v = u; v > 0; v >>= 1) {
+                    digits.insert(digits.begin(), char('0' + (v & 1)));
+                }
+                if (digits.empty()) {
+                    digits = "0";
+                }
+                break;
+
+            case 'o':
+            case 'O': {
+                char buf[32];
+                std::snprintf(buf, sizeof(buf), "%llo", (unsigned long long) u);
+                digits = buf;
+                break;
+            }
+
+            case 'x':
+            case 'X':
+                digits = hex(u, verb == 'X');
+                break;
+
+            case 'c':
+                pad(out, utf8::detail::encode(neg || u > uint64(utf8::MaxRune) ? utf8::RuneError : rune(u)), f);
+                return;
+
+            case 'q': {
+                rune r = neg || u > uint64(utf8::MaxRune) ? utf8::RuneError : rune(u);
+                pad(out, f.plus ? strconv::QuoteRuneToASCII(r) : strconv::QuoteRune(r), f);
+                return;
+            }
+
+            case 'U': {
+                char buf[32];
+                std::snprintf(buf, sizeof(buf), "U+%04llX", (unsigned long long) u);
+                std::string s = buf;
+                if (f.sharp) {
+                    s += " '" + utf8::detail::encode(rune(u)) + "'";
+                }
+                pad(out, s, f);
+                return;
+            }
+
+            default:
+                badVerb(out, verb, type, (neg ? "-" : "") + std::to_string(u));
+                return;
+            }
+
+            if (f.prec >= 0) {
+                if (f.prec == 0 && u == 0) {
+                    digits = "";
+                }
+                while ((int) digits.size() < f.prec) {
+                    digits = "0" + digits;
+                }
+            }
+
+            if (f.sharp || f.sharpV) {
+                switch (verb) {
+                case 'b': digits = "0b" + digits; break;
+                case 'o': if (digits[0] != '0') digits = "0" + digits; break;
+                case 'x': digits = "0x" + digits; break;
+                case 'X': digits = "0X" + digits; break;
+                }
+            }
+            if (verb == 'O') {
+                digits = "0o" + digits;
+            }
+
+            if (neg) {
+                digits = "-" + digits;
+            } else if (f.plus) {
+                digits = "+" + digits;
+            } else if (f.space) {
+                digits = " " + digits;
+            }
+
+            pad(out, digits, f, f.prec < 0);
+        }
+
+        inline void fmtFloat(std::string& out, double v, bool is32, char verb, const Flags& f) {
+            int prec = f.prec;
+
+            switch (verb) {
+            case 'v':
+                verb = 'g';
+                break;
+
+            case 'b':
+            case 'g':
+            case 'G':
+            case 'x':
+            case 'X':
+                break;
+
+            case 'F':
+                verb = 'f';
+                [[fallthrough]];
+
+            case 'f':
+            case 'e':
+            case 'E':
+                if (prec < 0) {
+                    prec = 6;
+                }
+                break;
+
+            default:
+                badVerb(out, verb, is32 ? "float32" : "float64", strconv::FormatFloat(v, 'g', -1, is32 ? 32 : 64));
+                return;
+            }
+
+            // the number, always with a sign
+            std::string num = strconv::FormatFloat(v, verb, prec, is32 ? 32 : 64);
+            if (num[0] != '-' && num[0] != '+') {
+                num = "+" + num;
+            }
+            if (f.space && num[0] == '+' && !f.plus) {
+                num[0] = ' ';
+            }
+
+            if (num[1] == 'I' || num[1] == 'N') {
+                // infinities and NaN are not padded with zeros
+                if (num[1] == 'N' && !f.space && !f.plus) {
+                    num = num.substr(1);
+                }
+                pad(out, num, f);
+                return;
+            }
+
+            if (f.plus || num[0] != '+') {
+                pad(out, num, f, true);
+            } else {
+                pad(out, num.substr(1), f, true);
+            }
+        }
+
+        inline std::string pointer(const void *p) {
+            return p == nullptr ? "0x0" : "0x" + hex((uint64) (uintptr_t) p, false);
+        }
+
+        template<class T> void printValue(std::string& out, const T& v, char verb, const Flags& f, int depth);
+
+        // printAny formats an interface{} value, for the types that can be stored in it by the runtime
+        inline void printAny(std::string& out, const std::any& a, char verb, const Flags& f, int depth);
+
+        template<class T> void printStruct(std::string& out, const T& v, char verb, const Flags& f, int depth) {
+            if (f.sharpV) {
+                out += typeName<T>();
+            }
+
+            out += "{";
+
+            bool first = true;
+            const_cast<T&>(v)._fields([&](const char *name, const auto& field) {
+                if (!first) {
+                    out += f.sharpV ? ", " : " ";
+                }
+                first = false;
+
+                if (f.plusV || f.sharpV) {
+                    out += name;
+                    out += ":";
+                }
+                printValue(out, field, verb, f, depth + 1);
+            });
+
+            out += "}";
+        }
+
+        template<class T> void printValue(std::string& out, const T& v, char verb, const Flags& f, int depth) {
+            if constexpr (std::is_base_of<Interface, T>::value) {
+                // the dynamic value
+                if (v == nullptr) {
+                    // a nil interface
+                    if (verb == 'T' || verb == 'v') {
+                        pad(out, "<nil>", f);
+                    } else {
+                        out += std::string("%!") + verb + "(<nil>)";
+                    }
+                } else if (verb == 'T') {
+                    pad(out, v._value()->typeName(), f);
+                } else {
+                    v._value()->print(out, verb, f, depth);
+                }
+                return;
+            }
+
+            if constexpr (std::is_same<T, error>::value) {
+                if (v == nullptr) {
+                    // a nil interface
+                    if (verb == 'T' || verb == 'v') {
+                        pad(out, "<nil>", f);
+                    } else {
+                        out += std::string("%!") + verb + "(<nil>)";
+                    }
+                    return;
+                }
+
+                if (verb == 'T') {
+                    // the dynamic type
+                    pad(out, v._iface()->typeName(), f);
+                    return;
+                }
+            }
+
+            if (verb == 'T' && !std::is_same<T, std::any>::value) {
+                pad(out, typeName<T>(), f);
+                return;
+            }
+
+            if constexpr (std::is_same<T, bool>::value) {
+                fmtBool(out, v, verb, f);
+            } else if constexpr (std::is_same<T, char>::value) {
+                fmtInteger(out, (unsigned char) v, false, verb, f, "uint8");
+            } else if constexpr (std::is_integral<T>::value) {
+                if (verb == 'p') {
+                    badVerb(out, verb, typeName<T>(), std::to_string(v));
+                } else if (std::is_signed<T>::value && v < 0) {
+                    fmtInteger(out, uint64(0) - uint64(v), true, verb, f, typeName<T>());
+                } else {
+                    fmtInteger(out, uint64(v), false, verb, f, typeName<T>());
+                }
+            } else if constexpr (std::is_floating_point<T>::value) {
+                fmtFloat(out, v, sizeof(T) == 4, verb, f);
+            } else if constexpr (std::is_same<T, std::string>::value) {
+                fmtString(out, v, verb, f);
+            } else if constexpr (is_string<T>) {
+                fmtString(out, v == nullptr ? "" : std::string(v), verb, f);
+            } else if constexpr (std::is_same<T, std::nullptr_t>::value) {
+                pad(out, verb == 'p' ? "%!p(<nil>)" : "<nil>", f);
+            } else if constexpr (std::is_same<T, std::any>::value) {
+                printAny(out, v, verb, f, depth);
+            } else if constexpr (is_ptr<T>::value) {
+                printValue(out, v.get(), verb, f, depth);
+            } else if constexpr (std::is_pointer<T>::value) {
+                typedef typename std::remove_cv<typename std::remove_pointer<T>::type>::type E;
+
+                if (verb == 'p' || v == nullptr || (depth > 0 && verb == 'v')) {
+                    if (v == nullptr && verb != 'p') {
+                        pad(out, f.sharpV ? "(" + typeName<T>() + ")(nil)" : "<nil>", f);
+                    } else {
+                        pad(out, pointer(v), f);
+                    }
+                } else if constexpr (has_error<E>::value || has_string<E>::value || has_fields<E>::value) {
+                    if constexpr (!has_error<E>::value && !has_string<E>::value) {
+                        out += "&";
+                    }
+                    printValue(out, *v, verb, f, depth + 1);
+                } else {
+                    pad(out, pointer(v), f);
+                }
+            } else if constexpr (has_error<T>::value || has_string<T>::value) {
+                if (f.sharpV || !(verb == 'v' || verb == 's' || verb == 'q' || verb == 'x' || verb == 'X')) {
+                    if constexpr (has_fields<T>::value) {
+                        printStruct(out, v, verb, f, depth);
+                    } else if constexpr (has_value<T>::value) {
+                        printValue(out, v._value, verb, f, depth);
+                    } else {
+                        badVerb(out, verb, typeName<T>(), "?");
+                    }
+                    return;
+                }
+
+                T& c = const_cast<T&>(v);
+                if constexpr (has_error<T>::value) {
+                    fmtString(out, c.Error(), verb, f);
+                } else {
+                    fmtString(out, c.String(), verb, f);
+                }
+            } else if constexpr (is_slice<T>::value) {
+                typedef typename T::value_type E;
+
+                if (verb == 'p') {
+                    pad(out, pointer(v.data()), f);
+                    return;
+                }
+
+                if constexpr (std::is_same<E, byte>::value) {
+                    if (verb == 's' || verb == 'q' || verb == 'x' || verb == 'X') {
+                        fmtString(out, std::string(v.begin(), v.end()), verb, f);
+                        return;
+                    }
+                }
+
+                if (f.sharpV) {
+                    out += typeName<T>();
+                    if (v == nullptr) {
+                        out += "(nil)";
+                        return;
+                    }
+                    out += "{";
+                } else {
+                    out += "[";
+                }
+
+                for (int i = 0; i < v.len(); i++) {
+                    if (i > 0) {
+                        out += f.sharpV ? ", " : " ";
+                    }
+                    printValue(out, v.data()[i], verb, f, depth + 1);
+                }
+
+                out += f.sharpV ? "}" : "]";
+            } else if constexpr (std::is_array<T>::value) {
+                // [N]T
+                out += f.sharpV ? typeName<T>() + "{" : "[";
+                for (size_t i = 0; i < std::extent<T>::value; i++) {
+                    if (i > 0) {
+                        out += f.sharpV ? ", " : " ";
+                    }
+                    printValue(out, v[i], verb, f, depth + 1);
+                }
+                out += f.sharpV ? "}" : "]";
+            } else if constexpr (is_map<T>::value) {
+                if (f.sharpV) {
+                    out += typeName<T>();
+                    if (v == nullptr) {
+                        out += "(nil)";
+                        return;
+                    }
+                    out += "{";
+                } else {
+                    out += "map[";
+                }
+
+                bool first = true;
+                for (auto& kv : v.sorted()) {
+                    if (!first) {
+                        out += f.sharpV ? ", " : " ";
+                    }
+                    first = false;
+
+                    printValue(out, kv.first, verb, f, depth + 1);
+                    out += ":";
+                    printValue(out, kv.second, verb, f, depth + 1);
+                }
+
+                out += f.sharpV ? "}" : "]";
+            } else if constexpr (is_tuple<T>::value) {
+                // a multiple value, i.e. Println(f())
+                bool first = true;
+                std::apply([&](const auto&... e) {
+                    ((out += first ? "" : " ", first = false, printValue(out, e, verb, f, depth)), ...);
+                }, v);
+            } else if constexpr (is_chan<T>::value) {
+                pad(out, v == nullptr ? "<nil>" : pointer(&v), f);
+            } else if constexpr (has_fields<T>::value) {
+                printStruct(out, v, verb, f, depth);
+            } else if constexpr (has_value<T>::value) {
+                printValue(out, v._value, verb, f, depth);
+            } else {
+                badVerb(out, verb, typeName<T>(), "?");
+            }
+        }
+
+        inline std::string anyTypeName(const std::any& a) {
+            return iface_detail::typeName(a);
+        }
+
+        inline void printAny(std::string& out, const std::any& a, char verb, const Flags& f, int depth) {
+            if (verb == 'T') {
+                pad(out, anyTypeName(a), f);
+            } else if (a == nullptr) {
+                pad(out, "<nil>", f);
+            } else if (auto i = iface_detail::asInterface(a)) {
+                i->_value()->print(out, verb, f, depth);
+            } else if (auto v = std::any_cast<std::string>(&a)) {
+                printValue(out, *v, verb, f, depth);
+            } else if (auto v = std::any_cast<const char *>(&a)) {
+                printValue(out, *v, verb, f, depth);
+            } else if (auto v = std::any_cast<int>(&a)) {
+                printValue(out, *v, verb, f, depth);
+            } else if (auto v = std::any_cast<long>(&a)) {
+                printValue(out, *v, verb, f, depth);
+            } else if (auto v = std::any_cast<long long>(&a)) {
+                printValue(out, *v, verb, f, depth);
+            } else if (auto v = std::any_cast<double>(&a)) {
+                printValue(out, *v, verb, f, depth);
+            } else if (auto v = std::any_cast<float>(&a)) {
+                printValue(out, *v, verb, f, depth);
+            } else if (auto v = std::any_cast<bool>(&a)) {
+                printValue(out, *v, verb, f, depth);
+            } else if (auto v = std::any_cast<error>(&a)) {
+                printValue(out, *v, verb, f, depth);
+            } else {
+                out += "(" + anyTypeName(a) + ")";
+            }
+        }
+
+        // an operand of a print function
+        struct Arg {
+            std::function<void(std::string&, char, const Flags&)> print;
+            std::function<std::string()> type;
+            std::function<bool(int&)> toInt; // for the * width and precision
+            std::function<error()> toError;  // for %w, nil if the operand is not an error
+            bool isString;
+        };
+
+        template<class T> void collect(std::vector<Arg>& args, const T& v) {
+            if constexpr (is_tuple<T>::value) {
+                // multiple values are multiple operands
+                std::apply([&](const auto&... e) { (collect(args, e), ...); }, v);
+            } else if constexpr (std::is_array<T>::value && std::is_same<std::remove_cv_t<std::remove_extent_t<T>>, char>::value) {
+                // a string literal
+                std::string s(v);
+                args.push_back(Arg{
+                    [s](std::string& out, char verb, const Flags& f) { printValue(out, s, verb, f, 0); },
+                    []() { return std::string("string"); },
+                    [](int&) { return false; },
+                    []() { return error(); },
+                    true
+                });
+            } else {
+                const T *p = &v;
+                args.push_back(Arg{
+                    [p](std::string& out, char verb, const Flags& f) { printValue(out, *p, verb, f, 0); },
+                    [p]() {
+                        if constexpr (std::is_same<T, error>::value) {
+                            return *p == nullptr ? std::string("<nil>") : p->_iface()->typeName();
+                        } else if constexpr (std::is_same<T, std::any>::value) {
+                            return anyTypeName(*p);
+                        } else {
+                            return typeName<T>();
+                        }
+                    },
+                    [p](int& n) {
+                        if constexpr (std::is_integral<T>::value && !std::is_same<T, bool>::value) {
+                            n = int(*p);
+                            return true;
+                        } else {
+                            return false;
+                        }
+                    },
+                    [p]() {
+                        if constexpr (std::is_same<T, error>::value || is_error_type<T>::value) {
+                            return error(*p);
+                        } else {
+                            return error();
+                        }
+                    },
+                    is_string<T> || std::is_same<T, std::string>::value
+                });
+            }
+        }
+
+        template<class... T> std::vector<Arg> collectAll(const T&... values) {
+            std::vector<Arg> args;
+            (collect(args, values), ...);
+            return args;
+        }
+
+        // doPrint adds spaces between the operands when neither is a string
+        inline std::string doPrint(const std::vector<Arg>& args) {
+            std::string out;
+            for (size_t i = 0; i < args.size(); i++) {
+                if (i > 0 && !args[i].isString && !args[i - 1].isString) {
+                    out += " ";
+                }
+                args[i].print(out, 'v', Flags());
+            }
+            return out;
+        }
+
+        // doPrintln always adds spaces between the operands, and a newline
+        inline std::string doPrintln(const std::vector<Arg>& args) {
+            std::string out;
+            for (size_t i = 0; i < args.size(); i++) {
+                if (i > 0) {
+                    out += " ";
+                }
+                args[i].print(out, 'v', Flags());
+            }
+            return out + "\n";
+        }
+
+        // doPrintf formats the operands according to format. The operands of the %w verbs (only allowed by Errorf)
+        // are added to wrapped
+        inline std::string doPrintf(const std::string& format, const std::vector<Arg>& args, std::vector<error> *wrapped = nullptr) {
+            std::string out;
+            size_t argNum = 0;
+
+            for (size_t i = 0; i < format.size();) {
+                if (format[i] != '%') {
+                    out += format[i++];
+                    continue;
+                }
+
+                i++;
+                Flags f;
+
+                // flags
+                for (; i < format.size(); i++) {
+                    char c = format[i];
+                    if (c == '#') f.sharp = true;
+                    else if (c == '0') f.zero = !f.minus;
+                    else if (c == '+') f.plus = true;
+                    else if (c == '-') { f.minus = true; f.zero = false; }
+                    else if (c == ' ') f.space = true;
+                    else break;
+                }
+
+                // width
+                if (i < format.size() && format[i] == '*') {
+                    i++;
+                    if (argNum < args.size() && args[argNum].toInt(f.width)) {
+                        if (f.width < 0) {
+                            f.minus = true;
+                            f.width = -f.width;
+                        }
+                    } else {
+                        out += "%!(BADWIDTH)";
+                    }
+                    argNum++;
+                } else {
+                    for (; i < format.size() && isdigit(format[i]); i++) {
+                        f.width = (f.width < 0 ? 0 : f.width * 10) + (format[i] - '0');
+                    }
+                }
+
+                // precision
+                if (i < format.size() && format[i] == '.') {
+                    i++;
+                    f.prec = 0;
+                    if (i < format.size() && format[i] == '*') {
+                        i++;
+                        if (argNum >= args.size() || !args[argNum].toInt(f.prec) || f.prec < 0) {
+                            f.prec = -1;
+                            out += "%!(BADPREC)";
+                        }
+                        argNum++;
+                    } else {
+                        for (; i < format.size() && isdigit(format[i]); i++) {
+                            f.prec = f.prec * 10 + (format[i] - '0');
+                        }
+                    }
+                }
+
+                if (i >= format.size()) {
+                    out += "%!(NOVERB)";
+                    break;
+                }
+
+                auto [verb, size] = utf8::detail::decodeAt(format, i);
+                i += size;
+
+                if (verb == '%') {
+                    out += '%';
+                    continue;
+                }
+
+                if (argNum >= args.size()) {
+                    out += "%!" + utf8::detail::encode(verb) + "(MISSING)";
+                    continue;
+                }
+
+                if (verb == 'v') {
+                    if (f.sharp) {
+                        f.sharp = false;
+                        f.sharpV = true;
+                    }
+                    if (f.plus) {
+                        f.plus = false;
+                        f.plusV = true;
+                    }
+                }
+
+                if (verb == 'w') {
+                    error err = args[argNum].toError();
+                    if (wrapped != nullptr && err != nullptr) {
+                        wrapped->push_back(err);
+                        verb = 'v';
+                    } else if (args[argNum].type() == "<nil>") {
+                        out += "%!w(<nil>)";
+                        argNum++;
+                        continue;
+                    }
+                }
+
+                if (verb > 0x7F || verb == 'w') {
+                    std::string v;
+                    args[argNum].print(v, 'v', Flags());
+                    out += "%!" + utf8::detail::encode(verb) + "(" + args[argNum].type() + "=" + v + ")";
+                } else {
+                    args[argNum].print(out, char(verb), f);
+                }
+                argNum++;
+            }
+
+            if (argNum < args.size()) {
+                out += "%!(EXTRA ";
+                for (size_t i = argNum; i < args.size(); i++) {
+                    if (i > argNum) {
+                        out += ", ";
+                    }
+                    out += args[i].type() + "=";
+                    args[i].print(out, 'v', Flags());
+                }
+                out += ")";
+            }
+
+            return out;
+        }
+
+        // write sends the output to a std::ostream or to a Go io.Writer (with a Write([]byte) method)
+        template<class W> void write(W& w, const std::string& s) {
+            typedef typename std::remove_pointer<W>::type WT;
+
+            if constexpr (std::is_base_of<std::ostream, WT>::value) {
+                if constexpr (std::is_pointer<W>::value) {
+                    *w << s;
+                } else {
+                    w << s;
+                }
+            } else if constexpr (std::is_pointer<W>::value || is_ptr<W>::value) {
+                w->Write(appendSlice(Slice<byte>(), s));
+            } else {
+                w.Write(appendSlice(Slice<byte>(), s));
+            }
+        }
+    }
+
+    template<class... T> std::string Sprint(const T&... args) {
+        return detail::doPrint(detail::collectAll(args...));
+    }
+
+    template<class... T> std::string Sprintln(const T&... args) {
+        return detail::doPrintln(detail::collectAll(args...));
+    }
+
+    template<class... T> std::string Sprintf(const std::string& format, const T&... args) {
+        return detail::doPrintf(format, detail::collectAll(args...));
+    }
+
+    template<class... T> void Print(const T&... args) {
+        std::cout << Sprint(args...);
+    }
+
+    template<class... T> void Println(const T&... args) {
+        std::cout << Sprintln(args...);
+    }
+
+    template<class... T> void Printf(const std::string& format, const T&... args) {
+        std::cout << Sprintf(format, args...);
+    }
+
+    template<class W, class... T> void Fprint(W&& w, const T&... args) {
+        detail::write(w, Sprint(args...));
+    }
+
+    template<class W, class... T> void Fprintln(W&& w, const T&... args) {
+        detail::write(w, Sprintln(args...));
+    }
+
+    template<class W, class... T> void Fprintf(W&& w, const std::string& format, const T&... args) {
+        detail::write(w, Sprintf(format, args...));
+    }
+
+    namespace detail {
+        // the value of Errorf with a %w verb
+        struct wrapError {
+            static constexpr const char *_type = "*fmt.wrapError";
+            std::string msg;
+            error err;
+
+            std::string Error() const {
+                return msg;
+            }
+
+            error Unwrap() const {
+                return err;
+            }
+        };
+
+        // the value of Errorf with more than one %w verb
+        struct wrapErrors {
+            static constexpr const char *_type = "*fmt.wrapErrors";
+            std::string msg;
+            Slice<error> errs;
+
+            std::string Error() const {
+                return msg;
+            }
+
+            Slice<error> Unwrap() const {
+                return errs;
+            }
+        };
     }
 
-    template<typename... T> void Println(T... args) {
-        int dummy[sizeof...(T)] = { (std::cout << args << " ", 0)... };
-        std::cout << std::endl;
+    // Errorf formats the error message like Sprintf. If format has a %w verb with an error operand,
+    // the error wraps it (Unwrap returns it), with more than one the error wraps all of them
+    template<class... T> error Errorf(const std::string& format, const T&... args) {
+        std::vector<error> wrapped;
+        std::string msg = detail::doPrintf(format, detail::collectAll(args...), &wrapped);
+
+        switch (wrapped.size()) {
+        case 0:
+            return error(msg);
+        case 1:
+            return detail::wrapError{msg, wrapped[0]};
+        default:
+            Slice<error> errs;
+            for (const error& e : wrapped) {
+                errs = append(errs, e);
+            }
+            return detail::wrapErrors{msg, errs};
+        }
     }
 
+    // Stringer is implemented by any value with a String method (see Interface in go.h)
+    struct Stringer : Interface {
+        static constexpr const char *_type = "fmt.Stringer";
+        static inline const bool _registered = Interface::_register<Stringer>();
+        Method<std::string()> String;
+
+        Stringer() {
+        }
+
+        Stringer(std::nullptr_t) {
+        }
+
+        template<class T, iface_detail::if_value<T, Stringer> = 0> Stringer(const T& v) : Interface(v) {
+            String = _bind(v, [](auto& _v) { return std::string(_v.String()); });
+        }
+    };
 }
 
 #endif
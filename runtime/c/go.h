@@ -9,6 +9,18 @@
 #include <thread>
 #include <mutex>
 #include <condition_variable>
+#include <functional>
+#include <memory>
+#include <vector>
+#include <algorithm>
+#include <random>
+#include <any>
+#include <typeindex>
+#include <exception>
+#include <sstream>
+#include <deque>
+#include <chrono>
+#include <cstdlib>
 
 typedef unsigned char      uint8;
 typedef unsigned short int uint16;
@@ -25,114 +37,2527 @@ typedef double float64;
 
 typedef uint8 byte;
 typedef int32 rune;
+typedef uint64 uintptr;
 
+// the literals of the strings that contain '\0' are "..."s
+using namespace std::string_literals;
+
+// struct{} (an anonymous struct can't be a template argument, as in chan struct{} or map[string]struct{})
+struct EmptyStruct {
+    static constexpr const char *_type = "struct {}";
+
+    template<class F> void _fields(F) {
+    }
+
+    bool operator==(const EmptyStruct&) const {
+        return true;
+    }
+
+    bool operator!=(const EmptyStruct&) const {
+        return false;
+    }
+
+    bool operator<(const EmptyStruct&) const {
+        return false;
+    }
+};
+
+template<class T> class Ptr;
+
+// is_error_type is true for the types with an Error() string method (and the pointers to them)
+template<class T, class = void> struct is_error_type : std::false_type {};
+template<class T> struct is_error_type<T, std::void_t<decltype(std::string(std::declval<T&>().Error()))>> : std::true_type {};
+template<class T> struct is_error_type<T*, std::void_t<decltype(std::string(std::declval<T*>()->Error()))>> : std::true_type {};
+
+// error is a Go error interface value. It holds a copy of any value with an Error() method (a user type or a pointer to it)
+// and the zero value (or nullptr) is a nil error.
+// Two errors are equal when they hold the same type and equal values: the values without an == operator
+// (as the errors created by errors.New) are only equal to themselves.
 class error {
+public:
+    // the dynamic value of an error
+    struct iface {
+        virtual ~iface() {}
+        virtual std::string Error() = 0;
+        virtual std::string typeName() const = 0;      // the Go type name (for %T)
+        virtual const std::type_info& type() const = 0;
+        virtual void *value() = 0;                     // a pointer to the value (of type type())
+        virtual bool equals(const iface& o) const = 0;
+        virtual bool is(const error& target) = 0;      // the Is(error) bool method, if any
+        virtual int unwrap(std::vector<error>& errs) = 0; // 1 for Unwrap() error, 2 for Unwrap() []error, 0 if there is none
+    };
+
+    template<class T> struct impl;
+
+    // the value of errors.New
+    struct errorString {
+        static constexpr const char *_type = "*errors.errorString";
+        std::string s;
+
+        std::string Error() const {
+            return s;
+        }
+    };
+
+private:
+    std::shared_ptr<iface> p;
+
+public:
+    error() {
+    }
+
+    error(std::nullptr_t) {
+    }
+
+    error(std::string message);
+
+    template<class T, typename std::enable_if<is_error_type<T>::value && !std::is_same<T, error>::value, int>::type = 0>
+    error(T v);
+
+    // a *T error that owns the T value
+    template<class T, typename std::enable_if<is_error_type<T*>::value, int>::type = 0>
+    error(std::shared_ptr<T> v);
+
+    template<class T, typename std::enable_if<is_error_type<T*>::value, int>::type = 0>
+    error(const Ptr<T>& v);
+
+    std::string Error() const;
+
+    // the dynamic value (nullptr for a nil error)
+    iface *_iface() const {
+        return p.get();
+    }
+
+    bool operator==(std::nullptr_t) const {
+        return p == nullptr;
+    }
+
+    bool operator!=(std::nullptr_t) const {
+        return p != nullptr;
+    }
+
+    bool operator==(const error& e) const {
+        return p == e.p || (p != nullptr && e.p != nullptr && p->equals(*e.p));
+    }
+
+    bool operator!=(const error& e) const {
+        return !(*this == e);
+    }
+};
+
+//
+// Panics are C++ exceptions carrying the Go value.
+//
+// The functions with deferred calls catch them: the deferred calls run (in LIFO order) while the stack
+// unwinds and recover() stops the panic, so that the function returns normally.
+// A panic that is not recovered terminates the program, as in Go.
+//
+
+class Panic : public std::exception {
+public:
+    std::any value;
+    std::string message;
+
+    Panic(std::any value, std::string message) : value(value), message(message) {
+    }
+
+    const char *what() const noexcept override {
+        return message.c_str();
+    }
+};
+
+// the state of the current panic, per goroutine (thread)
+struct PanicState {
+    std::any value;
+    bool panicking = false;
+    bool recovered = false;
+    int deferring = 0; // > 0 while the deferred calls run during a panic
+};
+
+inline PanicState& panicState() {
+    static thread_local PanicState state;
+    return state;
+}
+
+// the goroutine running on the current thread (main is 1)
+inline int& goroutineId() {
+    static thread_local int id = 1;
+    return id;
+}
+
+// panicMessage returns the text printed for a panic value
+template<class T> auto panicMessage(const T& v, int) -> decltype(std::declval<T&>().Error(), std::string()) {
+    T e = v;
+    return e.Error();
+}
+
+template<class T> auto panicMessage(const T& v, long) -> decltype(std::declval<std::ostream&>() << v, std::string()) {
+    std::ostringstream out;
+    out << v;
+    return out.str();
+}
+
+template<class T> std::string panicMessage(const T&, ...) {
+    return "(unprintable value)";
+}
+
+template<class T> [[noreturn]] void panic(const T& v) {
+    auto& state = panicState();
+    state.value = v;
+    state.panicking = true;
+    state.recovered = false;
+    throw Panic(v, panicMessage(v, 0));
+}
+
+[[noreturn]] inline void panic(const char *s) {
+    panic(std::string(s));
+}
+
+// checkDivisor returns the divisor of an integer division or remainder (x / y is x / checkDivisor(y)),
+// that panics if it's zero (a division by zero is undefined behavior in C++)
+template<class T> const T& checkDivisor(const T& y) {
+    if (y == 0) {
+        panic("runtime error: integer divide by zero");
+    }
+    return y;
+}
+
+//
+// Ptr is a Go pointer with a reference count (the --shared-pointers option): the values allocated by New
+// (new(T), &T{...} and the local variables whose address is taken) are deleted when they are no longer referenced.
+// A Ptr to a value that it doesn't own (Ref, or a raw pointer as the ones returned by os.Open) is valid
+// only while the value is, and a Ptr to a field or an element (&p.x) keeps alive the value that contains it.
+//
+
+template<class T> class Ptr {
+private:
+    template<class U> friend class Ptr;
+
+    std::shared_ptr<T> p;
+
+    T *checked() const {
+        if (p == nullptr) {
+            panic("runtime error: invalid memory address or nil pointer dereference");
+        }
+        return p.get();
+    }
+
+public:
+    typedef T element_type;
+
+    Ptr() {
+    }
+
+    Ptr(std::nullptr_t) {
+    }
+
+    // a pointer that doesn't own the value
+    Ptr(T *v) : p(std::shared_ptr<T>(), v) {
+    }
+
+    Ptr(std::shared_ptr<T> v) : p(v) {
+    }
+
+    // a pointer to v, that is part of the value of owner
+    template<class O> Ptr(const Ptr<O>& owner, T *v) : p(owner.p, v) {
+    }
+
+    T& operator*() const {
+        return *checked();
+    }
+
+    T *operator->() const {
+        return checked();
+    }
+
+    T *get() const {
+        return p.get();
+    }
+
+    // the shared_ptr of the value (with no owner, use_count() == 0, if it's not owned)
+    const std::shared_ptr<T>& shared() const {
+        return p;
+    }
+
+    // for the functions of the runtime that take a raw pointer (i.e. atomic.AddInt64(&n, 1))
+    operator T *() const {
+        return p.get();
+    }
+
+    bool operator==(std::nullptr_t) const {
+        return p == nullptr;
+    }
+
+    bool operator!=(std::nullptr_t) const {
+        return p != nullptr;
+    }
+
+    bool operator==(const Ptr& o) const {
+        return p.get() == o.p.get();
+    }
+
+    bool operator!=(const Ptr& o) const {
+        return p.get() != o.p.get();
+    }
+
+    bool operator==(T *o) const {
+        return p.get() == o;
+    }
+
+    bool operator!=(T *o) const {
+        return p.get() != o;
+    }
+
+    friend bool operator==(T *a, const Ptr& b) {
+        return a == b.p.get();
+    }
+
+    friend bool operator!=(T *a, const Ptr& b) {
+        return a != b.p.get();
+    }
+
+    bool operator<(const Ptr& o) const {
+        return std::less<T *>()(p.get(), o.p.get());
+    }
+};
+
+// New allocates a zero value (new(T)) or a copy of v (&T{...})
+template<class T> Ptr<T> New() {
+    return Ptr<T>(std::make_shared<T>());
+}
+
+template<class T> Ptr<T> New(const T& v) {
+    return Ptr<T>(std::make_shared<T>(v));
+}
+
+inline Ptr<std::string> New(const char *s) {
+    return New(std::string(s));
+}
+
+// Ref returns a pointer to v, that is not owned (i.e. a package variable) or is part of the value of owner
+template<class T> Ptr<T> Ref(T *v) {
+    return Ptr<T>(v);
+}
+
+template<class O, class T> Ptr<T> Ref(const Ptr<O>& owner, T *v) {
+    return Ptr<T>(owner, v);
+}
+
+template<class T> struct is_ptr : std::false_type {};
+template<class T> struct is_ptr<Ptr<T>> : std::true_type {};
+
+namespace error_detail {
+    template<class T, class = void> struct has_type : std::false_type {};
+    template<class T> struct has_type<T, std::void_t<decltype(T::_type)>> : std::true_type {};
+
+    template<class T> std::string typeName() {
+        if constexpr (std::is_pointer<T>::value) {
+            return "*" + typeName<typename std::remove_cv<typename std::remove_pointer<T>::type>::type>();
+        } else if constexpr (is_ptr<T>::value) {
+            return "*" + typeName<typename T::element_type>();
+        } else if constexpr (has_type<T>::value) {
+            return T::_type;
+        } else {
+            return typeid(T).name();
+        }
+    }
+
+    template<class T, class = void> struct is_comparable : std::false_type {};
+    template<class T> struct is_comparable<T, std::void_t<decltype(bool(std::declval<const T&>() == std::declval<const T&>()))>> : std::true_type {};
+
+    template<class T, class = void> struct has_is : std::false_type {};
+    template<class T> struct has_is<T, std::void_t<decltype(bool(std::declval<T&>().Is(std::declval<error>())))>> : std::true_type {};
+
+    template<class T, class = void> struct has_unwrap : std::false_type {};
+    template<class T> struct has_unwrap<T, std::void_t<decltype(std::declval<T&>().Unwrap())>> : std::true_type {};
+
+    template<class T> auto& deref(T& v) {
+        if constexpr (std::is_pointer<T>::value) {
+            return *v;
+        } else {
+            return v;
+        }
+    }
+}
+
+template<class T> struct error::impl : error::iface {
+    mutable T v;
+    std::shared_ptr<void> owner; // keeps the value pointed to by v alive
+
+    impl(const T& v, std::shared_ptr<void> owner = nullptr) : v(v), owner(owner) {
+    }
+
+    std::string Error() override {
+        if constexpr (std::is_pointer<T>::value) {
+            if (v == nullptr) {
+                panic("runtime error: invalid memory address or nil pointer dereference");
+            }
+        }
+        return error_detail::deref(v).Error();
+    }
+
+    std::string typeName() const override {
+        return error_detail::typeName<T>();
+    }
+
+    const std::type_info& type() const override {
+        return typeid(T);
+    }
+
+    void *value() override {
+        return &v;
+    }
+
+    bool equals(const iface& o) const override {
+        if constexpr (error_detail::is_comparable<T>::value) {
+            if (auto e = dynamic_cast<const impl<T> *>(&o)) {
+                return v == e->v;
+            }
+        }
+        return this == &o;
+    }
+
+    bool is(const error& target) override {
+        typedef typename std::remove_pointer<T>::type E;
+
+        if constexpr (error_detail::has_is<E>::value) {
+            if constexpr (std::is_pointer<T>::value) {
+                if (v == nullptr) {
+                    return false;
+                }
+            }
+            return error_detail::deref(v).Is(target);
+        }
+        return false;
+    }
+
+    int unwrap(std::vector<error>& errs) override {
+        typedef typename std::remove_pointer<T>::type E;
+
+        if constexpr (error_detail::has_unwrap<E>::value) {
+            if constexpr (std::is_pointer<T>::value) {
+                if (v == nullptr) {
+                    return 0;
+                }
+            }
+
+            auto u = error_detail::deref(v).Unwrap();
+            if constexpr (std::is_convertible<decltype(u), error>::value) {
+                errs.push_back(u);
+                return 1;
+            } else {
+                for (const error& e : u) {
+                    errs.push_back(e);
+                }
+                return 2;
+            }
+        }
+        return 0;
+    }
+};
+
+inline error::error(std::string message) : p(std::make_shared<impl<errorString>>(errorString{message})) {
+}
+
+template<class T, typename std::enable_if<is_error_type<T>::value && !std::is_same<T, error>::value, int>::type>
+error::error(T v) : p(std::make_shared<impl<T>>(v)) {
+}
+
+template<class T, typename std::enable_if<is_error_type<T*>::value, int>::type>
+error::error(std::shared_ptr<T> v) : p(std::make_shared<impl<T*>>(v.get(), v)) {
+}
+
+template<class T, typename std::enable_if<is_error_type<T*>::value, int>::type>
+error::error(const Ptr<T>& v) : p(std::make_shared<impl<T*>>(v.get(), v.shared())) {
+}
+
+inline std::string error::Error() const {
+    if (p == nullptr) {
+        panic("runtime error: invalid memory address or nil pointer dereference");
+    }
+    return p->Error();
+}
+
+// recover stops the current panic (if called by a deferred call) and returns its value
+inline std::any recover() {
+    auto& state = panicState();
+    if (!state.panicking || state.recovered || state.deferring == 0) {
+        return std::any();
+    }
+
+    state.recovered = true;
+    return state.value;
+}
+
+// fatalPanic prints the current exception and exits
+[[noreturn]] inline void fatalPanic() {
+    std::string message;
+
+    try {
+        throw;
+    } catch (const Panic& p) {
+        message = p.message;
+    } catch (const std::exception& e) {
+        message = e.what();
+    } catch (...) {
+        message = "unknown exception";
+    }
+
+    std::cout.flush();
+    std::cerr << "panic: " << message << std::endl << std::endl
+              << "goroutine " << goroutineId() << " [running]:" << std::endl;
+    std::_Exit(2);
+}
+
+// a panic that is not recovered in main terminates the program
+inline const std::terminate_handler goTerminate = std::set_terminate([]() {
+    if (std::current_exception()) {
+        fatalPanic();
+    }
+    std::abort();
+});
+
+namespace iface_detail {
+    inline bool isNil(const std::any& a);
+}
+
+// interface{} values (i.e. the value returned by recover) are compared to nil
+// (that is also a std::any holding nullptr, as for f(nil), or a nil interface value, see Interface)
+inline bool operator==(const std::any& a, std::nullptr_t) {
+    return !a.has_value() || a.type() == typeid(std::nullptr_t) || iface_detail::isNil(a);
+}
+
+inline bool operator!=(const std::any& a, std::nullptr_t) {
+    return !(a == nullptr);
+}
+
+// (a template, so that it's not used for the types that convert to std::any)
+template<class T, typename std::enable_if<std::is_same<T, std::any>::value, int>::type = 0>
+std::ostream& operator<<(std::ostream& out, const T& a) {
+    if (a == nullptr) {
+        return out << "<nil>";
+    }
+    if (auto v = std::any_cast<std::string>(&a)) {
+        return out << *v;
+    }
+    if (auto v = std::any_cast<const char *>(&a)) {
+        return out << *v;
+    }
+    if (auto v = std::any_cast<int>(&a)) {
+        return out << *v;
+    }
+    if (auto v = std::any_cast<long>(&a)) {
+        return out << *v;
+    }
+    if (auto v = std::any_cast<long long>(&a)) {
+        return out << *v;
+    }
+    if (auto v = std::any_cast<double>(&a)) {
+        return out << *v;
+    }
+    if (auto v = std::any_cast<bool>(&a)) {
+        return out << (*v ? "true" : "false");
+    }
+    if (auto v = std::any_cast<error>(&a)) {
+        error e = *v;
+        return out << e.Error();
+    }
+
+    return out << "(" << a.type().name() << ")";
+}
+
+//
+// Interfaces: an interface with methods declared in Go is a class derived from Interface (see CPrinter.FormatInterface),
+// like error and the io interfaces it holds a copy of any value with the methods of the interface (a T or a *T),
+// and each method is a Method member bound to the dynamic value. The zero value (or nullptr) is a nil interface,
+// and calling a method of a nil interface panics. An interface converts to another one with the same dynamic value.
+//
+// i.e. type Shape interface { Area() int } is
+//
+//   struct _Shape : Interface {
+//     static constexpr const char *_type = "main.Shape";
+//     static inline const bool _registered = Interface::_register<_Shape>();
+//     Method<int()> Area;
+//
+//     _Shape() {}
+//     _Shape(std::nullptr_t) {}
+//     template<class T, iface_detail::if_value<T, _Shape> = 0> _Shape(const T& v) : Interface(v) {
+//       Area = _bind(v, [](auto& _v, auto... _a) { return _v.Area(_a...); });
+//     }
+//   };
+//
+// An interface value converted to interface{} is a std::any holding the Interface: the interface types are registered
+// (with Interface::_register) so that the type assertions, the comparison to nil and fmt use its dynamic value.
+// The interface values print their dynamic value, with fmt.h (included at the end of go.h).
+//
+
+namespace fmt {
+    struct Flags;
+
+    namespace detail {
+        template<class T> void printValue(std::string& out, const T& v, char verb, const Flags& f, int depth);
+    }
+}
+
+namespace cmp_detail {
+    template<class T> bool equal(const T& a, const T& b);
+}
+
+class Interface;
+
+namespace iface_detail {
+    // the dynamic value of an Interface
+    struct value {
+        virtual ~value() {}
+        virtual const std::type_info& type() const = 0;
+        virtual void *ptr() = 0;                      // a pointer to the value (of type type())
+        virtual std::string typeName() const = 0;     // the Go type name (for %T)
+        virtual bool equals(const value& o) const = 0;
+        virtual void print(std::string& out, char verb, const fmt::Flags& f, int depth) const = 0;
+    };
+
+    template<class T> struct holder : value {
+        T v;
+
+        holder(const T& v) : v(v) {
+        }
+
+        // the receiver of the methods
+        auto& receiver() {
+            if constexpr (std::is_pointer<T>::value || is_ptr<T>::value) {
+                if (v == nullptr) {
+                    panic("runtime error: invalid memory address or nil pointer dereference");
+                }
+                return *v;
+            } else {
+                return v;
+            }
+        }
+
+        const std::type_info& type() const override {
+            return typeid(T);
+        }
+
+        void *ptr() override {
+            return &v;
+        }
+
+        std::string typeName() const override {
+            return error_detail::typeName<T>();
+        }
+
+        // the same type and an equal value (it panics if the type is not comparable)
+        bool equals(const value& o) const override {
+            auto h = dynamic_cast<const holder<T> *>(&o);
+            return h != nullptr && cmp_detail::equal(v, h->v);
+        }
+
+        void print(std::string& out, char verb, const fmt::Flags& f, int depth) const override {
+            fmt::detail::printValue(out, v, verb, f, depth);
+        }
+    };
+
+    // the interface types, that can be held by an interface{} (see Interface::_register)
+    inline std::map<std::type_index, const Interface *(*)(const std::any&)>& interfaces() {
+        static std::map<std::type_index, const Interface *(*)(const std::any&)> types;
+        return types;
+    }
+
+    // enables the conversion of a T to the interface I (not for I itself, nil or an interface{})
+    template<class T, class I> using if_value = typename std::enable_if<!std::is_same<T, I>::value &&
+        !std::is_same<T, std::nullptr_t>::value && !std::is_same<T, std::any>::value, int>::type;
+}
+
+class Interface {
+private:
+    std::shared_ptr<iface_detail::value> p;
+
+protected:
+    Interface() {
+    }
+
+    template<class T> Interface(const T& v) {
+        if constexpr (std::is_base_of<Interface, T>::value) {
+            p = static_cast<const Interface&>(v).p;
+        } else {
+            p = std::make_shared<iface_detail::holder<T>>(v);
+        }
+    }
+
+    // binds a method, f(receiver, args...), to the dynamic value v (that is already held by the interface)
+    template<class T, class F> auto _bind(const T& v, F f) const {
+        if constexpr (std::is_base_of<Interface, T>::value) {
+            return [v, f](auto... a) { return f(const_cast<T&>(v), a...); };
+        } else {
+            auto h = std::static_pointer_cast<iface_detail::holder<T>>(p);
+            return [h, f](auto... a) { return f(h->receiver(), a...); };
+        }
+    }
+
+public:
+    // registers the interface type I (at initialization)
+    template<class I> static bool _register() {
+        iface_detail::interfaces()[typeid(I)] = [](const std::any& a) -> const Interface * {
+            return std::any_cast<I>(&a);
+        };
+        return true;
+    }
+
+    // the dynamic value (nullptr for a nil interface)
+    iface_detail::value *_value() const {
+        return p.get();
+    }
+
+    // the dynamic value, if it's a T (as for a type assertion)
+    template<class T> T *As() const {
+        if (p == nullptr || p->type() != typeid(T)) {
+            return nullptr;
+        }
+        return static_cast<T *>(p->ptr());
+    }
+
+    bool operator==(std::nullptr_t) const {
+        return p == nullptr;
+    }
+
+    bool operator!=(std::nullptr_t) const {
+        return p != nullptr;
+    }
+
+    bool operator==(const Interface& i) const {
+        return p == i.p || (p != nullptr && i.p != nullptr && p->equals(*i.p));
+    }
+
+    bool operator!=(const Interface& i) const {
+        return !(*this == i);
+    }
+};
+
+namespace iface_detail {
+    // the interface value held by an interface{}, if any
+    inline const Interface *asInterface(const std::any& a) {
+        auto& types = interfaces();
+        if (!a.has_value() || types.empty()) {
+            return nullptr;
+        }
+
+        auto i = types.find(a.type());
+        return i == types.end() ? nullptr : i->second(a);
+    }
+
+    inline bool isNil(const std::any& a) {
+        auto i = asInterface(a);
+        return i != nullptr && *i == nullptr;
+    }
+}
+
+// a method of an interface value
+template<class F> class Method;
+
+template<class R, class... A> class Method<R(A...)> {
+private:
+    std::function<R(A...)> f;
+
+public:
+    Method() {
+    }
+
+    template<class F> Method(F f) : f(f) {
+    }
+
+    R operator()(A... a) const {
+        if (!f) {
+            panic("runtime error: invalid memory address or nil pointer dereference");
+        }
+        return f(a...);
+    }
+};
+
+// the target of an assertion to the interface I from a value that can hold one of the types T...
+// (the types of the converted package that implement I, see TypeAsserter.FormatImplements)
+template<class I, class... T> struct Implements {};
+
+//
+// Type assertions: x.(T) is typeAssert<T>(x), v, ok := x.(T) is typeAssertOk<T>(x) and a type switch
+// is a switch on typeCase (the index of the first case that matches the dynamic type of x).
+//
+// x is an interface{} (std::any), an error or an interface value with an As<T>() method (as io.Reader).
+// The dynamic type is the exact type of the value stored in the interface, except that a string literal
+// (a const char *) is a string. T can also be an interface: interface{} and the type of x match any non-nil
+// value, error matches an interface{} holding an error and nullptr_t (case nil) matches a nil interface.
+// Implements<I, T...> matches an I or one of the types T... (that implement I) and the value is an I.
+//
+
+namespace iface_detail {
+    // the Go name of T, for the panic messages
+    template<class T> std::string typeName() {
+        if constexpr (std::is_same<T, bool>::value) {
+            return "bool";
+        } else if constexpr (std::is_same<T, std::string>::value || std::is_same<T, const char *>::value) {
+            return "string";
+        } else if constexpr (std::is_same<T, byte>::value) {
+            return "uint8";
+        } else if constexpr (std::is_same<T, int8>::value) {
+            return "int8";
+        } else if constexpr (std::is_integral<T>::value) {
+            std::string name = std::is_signed<T>::value ? "int" : "uint";
+            if (std::is_same<T, int>::value) {
+                return name;
+            }
+            return name + std::to_string(sizeof(T) * 8);
+        } else if constexpr (std::is_floating_point<T>::value) {
+            return "float" + std::to_string(sizeof(T) * 8);
+        } else if constexpr (std::is_same<T, std::any>::value) {
+            return "interface {}";
+        } else if constexpr (std::is_same<T, error>::value) {
+            return "error";
+        } else {
+            return error_detail::typeName<T>();
+        }
+    }
+
+    // the Go name of the dynamic type of an interface{} (the types that can't be named are the C++ ones)
+    template<class I> std::string typeName(const I& x);
+
+    inline std::string typeName(const std::any& a) {
+        if (a == nullptr) return "<nil>";
+        if (auto i = asInterface(a)) return typeName(*i);
+        if (a.type() == typeid(std::string)) return "string";
+        if (a.type() == typeid(const char *)) return "string";
+        if (a.type() == typeid(int)) return "int";
+        if (a.type() == typeid(long)) return "int64";
+        if (a.type() == typeid(long long)) return "int64";
+        if (a.type() == typeid(double)) return "float64";
+        if (a.type() == typeid(float)) return "float32";
+        if (a.type() == typeid(bool)) return "bool";
+        if (auto e = std::any_cast<error>(&a)) return *e == nullptr ? "<nil>" : e->_iface()->typeName();
+        return a.type().name();
+    }
+
+    inline std::string typeName(const error& e) {
+        return e == nullptr ? "<nil>" : e._iface()->typeName();
+    }
+
+    template<class I> std::string typeName(const I& x) {
+        if constexpr (std::is_base_of<Interface, I>::value) {
+            if (x != nullptr) {
+                return x._value()->typeName();
+            }
+        }
+        return x == nullptr ? "<nil>" : "?";
+    }
+
+    // the dynamic value of x, if it's a T
+    template<class T> const T *dynamic(const std::any& x) {
+        if (auto i = asInterface(x)) {
+            return i->template As<T>();
+        }
+        return std::any_cast<T>(&x);
+    }
+
+    template<class T> const T *dynamic(const error& x) {
+        if (auto i = x._iface(); i != nullptr && i->type() == typeid(T)) {
+            return static_cast<const T *>(i->value());
+        }
+        return nullptr;
+    }
+
+    template<class T, class I> auto dynamic(const I& x) -> decltype(x.template As<T>()) {
+        return x.template As<T>();
+    }
+
+    // the result of an assertion to T (I for Implements<I, ...>)
+    template<class T> struct implements : std::false_type {
+        typedef T type;
+    };
+
+    template<class I, class... T> struct implements<Implements<I, T...>> : std::true_type {
+        typedef I type;
+    };
+
+    template<class T, class I> bool is(const I& x);
+    template<class T, class I> T get(const I& x);
+
+    template<class I, class... T, class X> bool isImplements(const X& x, Implements<I, T...> *) {
+        return is<I>(x) || (is<T>(x) || ...);
+    }
+
+    template<class I, class... T, class X> I getImplements(const X& x, Implements<I, T...> *) {
+        if (is<I>(x)) {
+            return get<I>(x);
+        }
+
+        I v;
+        ((v == nullptr && is<T>(x) ? (v = I(get<T>(x)), true) : false), ...);
+        return v;
+    }
+
+    template<class T, class I> bool is(const I& x) {
+        if constexpr (implements<T>::value) {
+            return isImplements(x, static_cast<T *>(nullptr));
+        } else if constexpr (std::is_same<T, std::nullptr_t>::value) {
+            return x == nullptr;
+        } else if constexpr (std::is_same<T, std::any>::value || std::is_same<T, I>::value) {
+            return x != nullptr;
+        } else if constexpr (std::is_same<T, std::string>::value && std::is_same<I, std::any>::value) {
+            return dynamic<T>(x) != nullptr || dynamic<const char *>(x) != nullptr;
+        } else if constexpr (is_ptr<T>::value) {
+            // a Ptr, or the raw pointer held by an error or an io interface
+            return dynamic<T>(x) != nullptr || dynamic<typename T::element_type *>(x) != nullptr;
+        } else {
+            return dynamic<T>(x) != nullptr;
+        }
+    }
+
+    // the value of x as a T (is<T>(x) is true)
+    template<class T, class I> T get(const I& x) {
+        if constexpr (std::is_same<T, std::any>::value || std::is_same<T, I>::value) {
+            return T(x);
+        } else if constexpr (std::is_same<T, std::string>::value && std::is_same<I, std::any>::value) {
+            if (auto s = dynamic<const char *>(x)) {
+                return *s;
+            }
+            return *dynamic<T>(x);
+        } else if constexpr (is_ptr<T>::value) {
+            typedef typename T::element_type E;
+            if (auto p = dynamic<E *>(x)) {
+                // the value is kept alive by (a copy of) the interface
+                return T(std::shared_ptr<E>(std::make_shared<I>(x), *p));
+            }
+            return *dynamic<T>(x);
+        } else {
+            return *dynamic<T>(x);
+        }
+    }
+
+    // the types of a case of a type switch
+    template<class I, class... T> bool matches(const I& x, std::tuple<T...> *) {
+        return (is<T>(x) || ...);
+    }
+}
+
+// typeAssert returns the value of x as a T, or panics if the dynamic type of x is not T
+template<class T, class I> typename iface_detail::implements<T>::type typeAssert(const I& x) {
+    typedef typename iface_detail::implements<T>::type R;
+
+    if (!iface_detail::is<T>(x)) {
+        if (iface_detail::implements<T>::value && x != nullptr) {
+            panic("interface conversion: " + iface_detail::typeName(x) + " is not " + iface_detail::typeName<R>());
+        }
+        panic("interface conversion: " + iface_detail::typeName<I>() + " is " + iface_detail::typeName(x) +
+            ", not " + iface_detail::typeName<R>());
+    }
+
+    if constexpr (iface_detail::implements<T>::value) {
+        return iface_detail::getImplements(x, static_cast<T *>(nullptr));
+    } else {
+        return iface_detail::get<T>(x);
+    }
+}
+
+// typeAssertOk returns the value of x as a T and true, or the zero value and false
+template<class T, class I> std::tuple<typename iface_detail::implements<T>::type, bool> typeAssertOk(const I& x) {
+    typedef typename iface_detail::implements<T>::type R;
+
+    if (!iface_detail::is<T>(x)) {
+        return std::make_tuple(R{}, false);
+    }
+
+    if constexpr (iface_detail::implements<T>::value) {
+        return std::make_tuple(iface_detail::getImplements(x, static_cast<T *>(nullptr)), true);
+    } else {
+        return std::make_tuple(iface_detail::get<T>(x), true);
+    }
+}
+
+// typeCase returns the index of the first case (a std::tuple of the types) that matches x, or -1 (default)
+template<class... C, class I> int typeCase(const I& x) {
+    int i = 0, index = -1;
+    ((index < 0 && iface_detail::matches(x, static_cast<C *>(nullptr)) ? index = i : 0, i++), ...);
+    return index;
+}
+
+//
+// Goroutines: a pool of worker threads runs the goroutines to completion.
+//
+// A goroutine that blocks (i.e. on a channel) keeps its thread, so when all the workers are blocked
+// a new one is started, to run the queued goroutines. The number of running workers is bounded by
+// GOMAXPROCS (the number of CPUs by default), and the number of threads by GOMAXTHREADS (1000 by default):
+// at the limit the queued goroutines wait for a blocked one to finish.
+//
+// When all the goroutines (main included) are blocked, and there are no pending timers,
+// the program exits with a deadlock error, or with a thread exhaustion error if the blocked goroutines
+// are waiting for the queued ones.
+//
+
+class Scheduler {
+private:
+    std::mutex m;
+    std::condition_variable work;
+    std::deque<std::function<void()>> queue;
+    int maxprocs;
+    int maxthreads;
+    int workers = 0;
+    int idle = 0;            // workers waiting for a goroutine
+    int blockedWorkers = 0;  // workers running a blocked goroutine
+    int live = 1;            // goroutines, queued or running (main included)
+    int blocked = 0;         // blocked goroutines (main included)
+    int timers = 0;          // pending timers (and sleeping goroutines), that can wake up a blocked goroutine
+    long epoch = 0;          // changes every time a goroutine blocks or unblocks
+    bool watching = false;
+    int lastId = 1;          // main is goroutine 1
+
+    static bool& isWorker() {
+        static thread_local bool worker = false;
+        return worker;
+    }
+
+    Scheduler() {
+        maxprocs = std::max(1, (int) std::thread::hardware_concurrency());
+        if (const char *env = std::getenv("GOMAXPROCS")) {
+            maxprocs = std::max(1, std::atoi(env));
+        }
+        maxthreads = 1000;
+        if (const char *env = std::getenv("GOMAXTHREADS")) {
+            maxthreads = std::max(1, std::atoi(env));
+        }
+    }
+
+    // start a new worker if there are queued goroutines and no available workers (called with m locked)
+    void spawn() {
+        if (queue.empty() || idle > 0 || workers - blockedWorkers >= maxprocs || workers >= maxthreads) {
+            return;
+        }
+
+        workers++;
+        std::thread([this]() { run(); }).detach();
+    }
+
+    void run() {
+        isWorker() = true;
+
+        std::unique_lock<std::mutex> lk(m);
+
+        for (;;) {
+            idle++;
+            while (queue.empty()) {
+                work.wait(lk);
+            }
+            idle--;
+
+            auto fun = std::move(queue.front());
+            queue.pop_front();
+
+            lk.unlock();
+            try {
+                fun();
+            } catch (...) {
+                fatalPanic();
+            }
+            lk.lock();
+
+            live--;
+            epoch++;
+            fun = nullptr;
+        }
+    }
+
+    // the watchdog reports a deadlock if all the goroutines are blocked (and stay blocked for a while,
+    // since a goroutine that has been woken up is still counted as blocked until it runs)
+    void watch() {
+        std::unique_lock<std::mutex> lk(m);
+
+        long last = -1;
+
+        for (;;) {
+            // the queued goroutines can't run if all the threads are blocked
+            bool stuck = queue.empty() || (workers >= maxthreads && blockedWorkers == workers);
+
+            if (blocked == live - (int) queue.size() && stuck && timers == 0) {
+                if (epoch == last) {
+                    std::cout.flush();
+                    if (queue.empty()) {
+                        std::cerr << "fatal error: all goroutines are asleep - deadlock!" << std::endl;
+                    } else {
+                        std::cerr << "runtime: program exceeds " << maxthreads << "-thread limit" << std::endl;
+                        std::cerr << "fatal error: thread exhaustion" << std::endl;
+                    }
+                    std::_Exit(2);
+                }
+
+                last = epoch;
+            } else {
+                last = -1;
+            }
+
+            lk.unlock();
+            std::this_thread::sleep_for(std::chrono::milliseconds(100));
+            lk.lock();
+        }
+    }
+
+public:
+    // the scheduler is never destroyed, since the workers can still be running when main returns
+    static Scheduler& Get() {
+        static Scheduler *s = new Scheduler();
+        return *s;
+    }
+
+    // go fun()
+    void Go(std::function<void()> fun) {
+        std::unique_lock<std::mutex> lk(m);
+        int id = ++lastId;
+        queue.push_back([id, fun]() {
+            goroutineId() = id;
+            fun();
+        });
+        live++;
+        epoch++;
+        spawn();
+        work.notify_one();
+    }
+
+    // Block is called before a goroutine waits for another goroutine
+    void Block() {
+        std::unique_lock<std::mutex> lk(m);
+        blocked++;
+        epoch++;
+
+        if (isWorker()) {
+            blockedWorkers++;
+            spawn();
+        }
+
+        if (!watching) {
+            watching = true;
+            std::thread([this]() { watch(); }).detach();
+        }
+    }
+
+    // Unblock is called when the goroutine stops waiting
+    void Unblock() {
+        std::unique_lock<std::mutex> lk(m);
+        blocked--;
+        epoch++;
+
+        if (isWorker()) {
+            blockedWorkers--;
+        }
+    }
+
+    // Threads returns the number of worker threads
+    int Threads() {
+        std::unique_lock<std::mutex> lk(m);
+        return workers;
+    }
+
+    // SetMaxThreads sets the limit of worker threads and returns the previous one (as debug.SetMaxThreads)
+    int SetMaxThreads(int n) {
+        std::unique_lock<std::mutex> lk(m);
+        int prev = maxthreads;
+        maxthreads = std::max(1, n);
+        return prev;
+    }
+
+    // AddTimers is called when a timer starts (1) and when it fires or it's stopped (-1)
+    void AddTimers(int n) {
+        std::unique_lock<std::mutex> lk(m);
+        timers += n;
+        epoch++;
+    }
+};
+
+// Blocking marks the current goroutine as blocked, for the lifetime of the object
+class Blocking {
+public:
+    Blocking() {
+        Scheduler::Get().Block();
+    }
+
+    ~Blocking() {
+        Scheduler::Get().Unblock();
+    }
+};
+
+inline void Goroutine(std::function<void()> fun) {
+    Scheduler::Get().Go(std::move(fun));
+}
+
+// go f(args), with the arguments evaluated now
+template<class F, class... A> void Goroutine(F f, A... args) {
+    Scheduler::Get().Go([f, args...]() mutable { f(args...); });
+}
+
+//
+// Deferred calls: the calls are pushed on the Defers of the function, and run in LIFO order
+// when the function returns or panics.
+//
+// A defer at the function level is a Deferred object, that runs its call (and the ones deferred after it)
+// when it goes out of scope, while the variables it refers to are still alive.
+// Calls deferred in a nested block (i.e. in a loop) capture the receiver by address and the arguments by value,
+// and they are run by a Deferred without a call, declared before the statement that contains the block.
+//
+
+class Defers {
+private:
+    std::vector<std::function<void()>> calls;
+    std::exception_ptr replaced; // a panic in a deferred call, while panicking
+
+public:
+    // Push adds a call with the arguments evaluated now, and returns its position
+    template<class F, class... A> size_t Push(F f, A... args) {
+        calls.push_back([f, args...]() mutable { f(args...); });
+        return calls.size() - 1;
+    }
+
+    // defer f(args) in a nested block
+    template<class F, class... A> void Defer(F f, A... args) {
+        Push(f, args...);
+    }
+
+    // Size returns the number of pending calls
+    size_t Size() const {
+        return calls.size();
+    }
+
+    // RunFrom runs the calls deferred after position 'from', in reverse order
+    void RunFrom(size_t from, bool unwinding) {
+        while (calls.size() > from) {
+            auto call = std::move(calls.back());
+            calls.pop_back();
+
+            if (!unwinding) {
+                call();
+                continue;
+            }
+
+            auto& state = panicState();
+            state.deferring++;
+            try {
+                call();
+            } catch (...) {
+                replaced = std::current_exception();
+            }
+            state.deferring--;
+        }
+    }
+
+    // Run runs the remaining calls, when the function returns
+    void Run() {
+        RunFrom(0, false);
+    }
+
+    // Recover is called when the function panics (in the catch block): it runs the remaining calls
+    // and rethrows the panic, unless it was recovered
+    void Recover() {
+        RunFrom(0, true);
+
+        auto& state = panicState();
+        if (state.panicking && state.recovered) {
+            state = PanicState();
+            return;
+        }
+
+        if (replaced) {
+            auto e = replaced;
+            replaced = nullptr;
+            std::rethrow_exception(e);
+        }
+
+        throw;
+    }
+};
+
+class Deferred {
+private:
+    Defers& defers;
+    size_t index;
+    int uncaught;
+
+public:
+    template<class F, class... A> Deferred(Defers& d, F f, A... args)
+        : defers(d), index(d.Push(f, args...)), uncaught(std::uncaught_exceptions()) {
+    }
+
+    // the scope of the calls deferred in the nested blocks
+    explicit Deferred(Defers& d) : defers(d), index(d.Size()), uncaught(std::uncaught_exceptions()) {
+    }
+
+    ~Deferred() noexcept(false) {
+        defers.RunFrom(index, std::uncaught_exceptions() > uncaught);
+    }
+};
+
+//
+// Channels: a reference to a queue shared by senders and receivers
+//
+
+namespace select_detail {
+    // a goroutine waiting in a select, woken up when the state of one of the channels changes
+    struct waiter {
+        std::mutex m;
+        std::condition_variable cond;
+        bool woken = false;
+
+        void wake() {
+            std::unique_lock<std::mutex> lk(m);
+            woken = true;
+            cond.notify_one();
+        }
+    };
+}
+
+template<class T> class Chan {
+private:
+    struct state {
+        std::queue<T> buffer;
+        int size;           // 0 for an unbuffered channel
+        bool closed = false;
+        long sent = 0;      // number of values sent (used for the unbuffered rendezvous)
+        long received = 0;  // number of values received
+        int receivers = 0;  // waiting receivers (an unbuffered send in a select is ready if there is one)
+        std::mutex m;
+        std::condition_variable send_cond;
+        std::condition_variable recv_cond;
+        std::vector<std::pair<select_detail::waiter *, bool>> selects; // the selects waiting to send (false) or receive
+
+        state(int n) : size(n) {
+        }
+
+        // wake up the selects, when the state of the channel changes
+        void notify() {
+            for (auto& s : selects) {
+                s.first->wake();
+            }
+        }
+    };
+
+    std::shared_ptr<state> c; // nullptr for a nil channel
+
+    // a nil channel blocks forever
+    static void block() {
+        Blocking b;
+        std::mutex m;
+        std::condition_variable cond;
+        std::unique_lock<std::mutex> lk(m);
+        for (;;) {
+            cond.wait(lk);
+        }
+    }
+
+    // put adds value to the buffer (with room for it) and, for an unbuffered channel,
+    // waits for a receiver to take it (called with the lock held)
+    void put(std::unique_lock<std::mutex>& lk, const T& value) const {
+        if (c->closed) {
+            lk.unlock();
+            panic("send on closed channel");
+        }
+
+        c->buffer.push(value);
+        long id = ++c->sent;
+        c->recv_cond.notify_one();
+        c->notify();
+
+        if (c->size == 0) {
+            // unbuffered: wait for a receiver to take the value
+            if (c->received < id) {
+                Blocking b;
+                while (c->received < id) {
+                    c->send_cond.wait(lk);
+                }
+            }
+        }
+    }
+
+    // take removes a value from the buffer, or returns the zero value if the channel is closed and empty
+    // (called with the lock held)
+    std::tuple<T, bool> take() const {
+        if (c->buffer.empty()) {
+            return std::make_tuple(T(), false);
+        }
+
+        T ret = c->buffer.front();
+        c->buffer.pop();
+        c->received++;
+        c->send_cond.notify_all();
+        c->notify();
+        return std::make_tuple(ret, true);
+    }
+
+public:
+    typedef T value_type;
+
+    // a nil channel
+    Chan() {
+    }
+
+    Chan(std::nullptr_t) {
+    }
+
+    // make(chan T, n)
+    explicit Chan(int n) : c(std::make_shared<state>(n)) {
+    }
+
+    bool operator==(std::nullptr_t) const {
+        return c == nullptr;
+    }
+
+    bool operator!=(std::nullptr_t) const {
+        return c != nullptr;
+    }
+
+    bool operator==(const Chan& other) const {
+        return c == other.c;
+    }
+
+    int len() const {
+        if (c == nullptr) {
+            return 0;
+        }
+
+        std::unique_lock<std::mutex> lk(c->m);
+        return c->size > 0 ? c->buffer.size() : 0;
+    }
+
+    int cap() const {
+        return c == nullptr ? 0 : c->size;
+    }
+
+    // ch <- value
+    void Send(T value) const {
+        if (c == nullptr) {
+            block();
+        }
+
+        std::unique_lock<std::mutex> lk(c->m);
+
+        if (!c->closed && int(c->buffer.size()) >= std::max(c->size, 1)) {
+            Blocking b;
+            while (!c->closed && int(c->buffer.size()) >= std::max(c->size, 1)) {
+                c->send_cond.wait(lk);
+            }
+        }
+
+        put(lk, value);
+    }
+
+    // v, ok := <-ch (the zero value and false when the channel is closed and empty)
+    std::tuple<T, bool> Lookup() const {
+        if (c == nullptr) {
+            block();
+        }
+
+        std::unique_lock<std::mutex> lk(c->m);
+
+        if (c->buffer.empty() && !c->closed) {
+            Blocking b;
+            c->receivers++;
+            c->notify();
+            while (c->buffer.empty() && !c->closed) {
+                c->recv_cond.wait(lk);
+            }
+            c->receivers--;
+        }
+
+        return take();
+    }
+
+    // <-ch
+    T Receive() const {
+        return std::get<0>(Lookup());
+    }
+
+    // TrySend sends value if it doesn't block (there is room in the buffer or, for an unbuffered channel,
+    // a receiver is waiting) and returns true (used by select)
+    bool TrySend(T value) const {
+        if (c == nullptr) {
+            return false;
+        }
+
+        std::unique_lock<std::mutex> lk(c->m);
+
+        if (!c->closed) {
+            if (c->size > 0 ? int(c->buffer.size()) >= c->size : (c->receivers == 0 || !c->buffer.empty())) {
+                return false;
+            }
+        }
+
+        put(lk, value);
+        return true;
+    }
+
+    // TryLookup receives a value if it doesn't block and returns true (used by select)
+    bool TryLookup(T& value, bool& ok) const {
+        if (c == nullptr) {
+            return false;
+        }
+
+        std::unique_lock<std::mutex> lk(c->m);
+
+        if (c->buffer.empty() && !c->closed) {
+            return false;
+        }
+
+        std::tie(value, ok) = take();
+        return true;
+    }
+
+    // Watch registers (or unregisters) a select waiting to send or receive on the channel
+    void Watch(select_detail::waiter *w, bool recv, bool on) const {
+        if (c == nullptr) {
+            return;
+        }
+
+        std::unique_lock<std::mutex> lk(c->m);
+
+        if (on) {
+            c->selects.emplace_back(w, recv);
+            if (recv) {
+                c->receivers++;
+                c->notify();
+            }
+        } else {
+            c->selects.erase(std::find(c->selects.begin(), c->selects.end(), std::make_pair(w, recv)));
+            if (recv) {
+                c->receivers--;
+            }
+        }
+    }
+
+    // close(ch)
+    void Close() const {
+        if (c == nullptr) {
+            panic("close of nil channel");
+        }
+
+        std::unique_lock<std::mutex> lk(c->m);
+
+        if (c->closed) {
+            lk.unlock();
+            panic("close of closed channel");
+        }
+
+        c->closed = true;
+        c->recv_cond.notify_all();
+        c->send_cond.notify_all();
+        c->notify();
+    }
+
+    // for v := range ch: receives until the channel is closed
+    class iterator {
+        const Chan *ch;
+        T value;
+        bool ok;
+
+    public:
+        iterator(const Chan *ch) : ch(ch), ok(ch != nullptr) {
+            ++*this;
+        }
+
+        const T& operator*() const {
+            return value;
+        }
+
+        iterator& operator++() {
+            if (ok) {
+                std::tie(value, ok) = ch->Lookup();
+            }
+            return *this;
+        }
+
+        bool operator!=(const iterator& other) const {
+            return ok != other.ok;
+        }
+    };
+
+    iterator begin() const {
+        return iterator(this);
+    }
+
+    iterator end() const {
+        return iterator(nullptr);
+    }
+};
+
+// the directional channels are the same type in C++
+template<class T> using SendChan = Chan<T>;
+template<class T> using ReceiveChan = Chan<T>;
+
+template<class T> int len(const Chan<T>& ch) {
+    return ch.len();
+}
+
+template<class T> int cap(const Chan<T>& ch) {
+    return ch.cap();
+}
+
+//
+// select: the cases are evaluated (in order) when the Select is created, and Wait runs one
+// that is ready (chosen at random if more than one is), or the default case (-1) if none is ready.
+// The received values are kept in the case, i.e.
+//
+//   switch (auto _select = Select{SelectRecv(ch), SelectSend(out, v)}; _select.Wait(false)) {
+//   case 0: { auto x = std::get<0>(_select).Receive(); ... }
+//
+
+namespace select_detail {
+    struct selectCase {
+        virtual bool Try() = 0;
+        virtual void Watch(waiter *w, bool on) = 0;
+    };
+
+    inline int wait(selectCase **cases, int n, bool hasDefault) {
+        static thread_local std::minstd_rand rnd(std::random_device{}());
+
+        std::vector<int> order(n);
+        for (int i = 0; i < n; i++) {
+            order[i] = i;
+        }
+
+        waiter w;
+        bool watching = false;
+
+        auto unwatch = [&]() {
+            if (watching) {
+                watching = false;
+                for (int i = 0; i < n; i++) {
+                    cases[i]->Watch(&w, false);
+                }
+            }
+        };
+
+        try {
+            for (;;) {
+                {
+                    std::unique_lock<std::mutex> lk(w.m);
+                    w.woken = false;
+                }
+
+                std::shuffle(order.begin(), order.end(), rnd);
+                for (int i : order) {
+                    if (cases[i]->Try()) {
+                        unwatch();
+                        return i;
+                    }
+                }
+
+                if (hasDefault) {
+                    return -1;
+                }
+
+                if (!watching) {
+                    // check again after registering, not to miss a change
+                    watching = true;
+                    for (int i = 0; i < n; i++) {
+                        cases[i]->Watch(&w, true);
+                    }
+                    continue;
+                }
+
+                Blocking b;
+                std::unique_lock<std::mutex> lk(w.m);
+                while (!w.woken) {
+                    w.cond.wait(lk);
+                }
+            }
+        } catch (...) {
+            unwatch();
+            throw;
+        }
+    }
+}
+
+template<class T> class SelectRecv : public select_detail::selectCase {
+private:
+    Chan<T> ch;
+    T value{};
+    bool ok = false;
+
+public:
+    SelectRecv(const Chan<T>& ch) : ch(ch) {
+    }
+
+    bool Try() override {
+        return ch.TryLookup(value, ok);
+    }
+
+    void Watch(select_detail::waiter *w, bool on) override {
+        ch.Watch(w, true, on);
+    }
+
+    // the received value
+    T Receive() const {
+        return value;
+    }
+
+    std::tuple<T, bool> Lookup() const {
+        return std::make_tuple(value, ok);
+    }
+};
+
+template<class T> class SelectSend : public select_detail::selectCase {
+private:
+    Chan<T> ch;
+    T value;
+
+public:
+    SelectSend(const Chan<T>& ch, T value) : ch(ch), value(value) {
+    }
+
+    bool Try() override {
+        return ch.TrySend(value);
+    }
+
+    void Watch(select_detail::waiter *w, bool on) override {
+        ch.Watch(w, false, on);
+    }
+};
+
+template<class T, class V> SelectSend(const Chan<T>&, const V&) -> SelectSend<T>;
+
+template<class... C> class Select : public std::tuple<C...> {
+public:
+    Select(C... cases) : std::tuple<C...>(cases...) {
+    }
+
+    // Wait returns the index of the case that was run, or -1 for the default case
+    int Wait(bool hasDefault) {
+        return std::apply([&](C&... c) {
+            select_detail::selectCase *cases[] = {&c..., nullptr};
+            return select_detail::wait(cases, sizeof...(C), hasDefault);
+        }, static_cast<std::tuple<C...>&>(*this));
+    }
+};
+
+//
+// UTF-8: the strings are sequences of bytes, and the runes are decoded when needed
+// (range, []rune(s), string(r)). The rest of unicode/utf8 is in utf8.h.
+//
+
+namespace utf8 {
+
+    const rune RuneError = 0xFFFD;
+    const rune RuneSelf = 0x80;
+    const rune MaxRune = 0x10FFFF;
+    const int UTFMax = 4;
+
+    namespace detail {
+
+        // decode returns the rune at p[0:n] and its size
+        inline std::tuple<rune, int> decode(const byte *p, size_t n) {
+            if (n == 0) {
+                return {RuneError, 0};
+            }
+
+            byte c = p[0];
+            if (c < 0x80) {
+                return {c, 1};
+            }
+
+            int size = c >= 0xF0 ? 4 : c >= 0xE0 ? 3 : c >= 0xC2 ? 2 : 0;
+            if (size == 0 || c > 0xF4 || size > (int) n) {
+                return {RuneError, 1};
+            }
+
+            rune r = c & (0x7F >> size);
+            for (int i = 1; i < size; i++) {
+                if ((p[i] & 0xC0) != 0x80) {
+                    return {RuneError, 1};
+                }
+                r = (r << 6) | (p[i] & 0x3F);
+            }
+
+            static const rune min[] = {0, 0, 0x80, 0x800, 0x10000};
+            if (r < min[size] || r > MaxRune || (r >= 0xD800 && r <= 0xDFFF)) {
+                return {RuneError, 1};
+            }
+
+            return {r, size};
+        }
+
+        // decodeAt returns the rune at s[i] and its size
+        inline std::tuple<rune, int> decodeAt(const std::string& s, size_t i) {
+            return decode((const byte *) s.data() + i, s.size() - i);
+        }
+
+        // encode returns the UTF-8 encoding of r (RuneError for an invalid rune)
+        inline std::string encode(rune r) {
+            std::string s;
+            uint32 c = r;
+
+            if (r < 0 || r > MaxRune || (r >= 0xD800 && r <= 0xDFFF)) {
+                c = RuneError;
+            }
+
+            if (c < 0x80) {
+                s += char(c);
+            } else if (c < 0x800) {
+                s += char(0xC0 | (c >> 6));
+                s += char(0x80 | (c & 0x3F));
+            } else if (c < 0x10000) {
+                s += char(0xE0 | (c >> 12));
+                s += char(0x80 | ((c >> 6) & 0x3F));
+                s += char(0x80 | (c & 0x3F));
+            } else {
+                s += char(0xF0 | (c >> 18));
+                s += char(0x80 | ((c >> 12) & 0x3F));
+                s += char(0x80 | ((c >> 6) & 0x3F));
+                s += char(0x80 | (c & 0x3F));
+            }
+
+            return s;
+        }
+    }
+}
+
+//
+// Slices: a view (offset, len, cap) on a shared backing array
+//
+
+template<class T> class Slice {
 private:
-    std::string s;
+    std::shared_ptr<T> _a; // the backing array (nullptr for a nil slice)
+    int _off;
+    int _len;
+    int _cap;
+
+    Slice(std::shared_ptr<T> a, int off, int len, int cap) : _a(a), _off(off), _len(len), _cap(cap) {
+    }
+
+    static std::shared_ptr<T> alloc(int cap) {
+        return std::shared_ptr<T>(new T[cap](), std::default_delete<T[]>());
+    }
+
+    // the length of []T(s): the bytes, or the runes for []rune(s)
+    static int stringLen(const std::string& s) {
+        if constexpr (std::is_same<T, rune>::value) {
+            int n = 0;
+            for (size_t i = 0; i < s.size(); n++) {
+                i += std::get<1>(utf8::detail::decodeAt(s, i));
+            }
+            return n;
+        } else {
+            return int(s.size());
+        }
+    }
+
 public:
-    error(std::string message) {
-        s = message;
+    typedef T value_type;
+
+    // a nil slice
+    Slice() : _off(0), _len(0), _cap(0) {
+    }
+
+    Slice(std::nullptr_t) : Slice() {
+    }
+
+    // make([]T, len, cap)
+    explicit Slice(int len, int cap = -1) : _off(0), _len(len), _cap(cap < 0 ? len : cap) {
+        if (_len < 0 || _len > _cap) {
+            panic(std::string("runtime error: makeslice: len out of range"));
+        }
+
+        _a = alloc(_cap);
+    }
+
+    // []T{...}
+    Slice(std::initializer_list<T> values) : Slice(values.size()) {
+        std::copy(values.begin(), values.end(), data());
+    }
+
+    // []byte(s), or []rune(s) (the runes decoded from UTF-8)
+    explicit Slice(const std::string& s) : Slice(stringLen(s)) {
+        if constexpr (std::is_same<T, rune>::value) {
+            T *p = data();
+            for (size_t i = 0; i < s.size(); p++) {
+                auto [r, size] = utf8::detail::decodeAt(s, i);
+                *p = r;
+                i += size;
+            }
+        } else {
+            std::copy(s.begin(), s.end(), data());
+        }
+    }
+
+    // string(b), or string(r) (the runes encoded as UTF-8)
+    explicit operator std::string() const {
+        if constexpr (std::is_same<T, rune>::value) {
+            std::string s;
+            for (auto r : *this) {
+                s += utf8::detail::encode(r);
+            }
+            return s;
+        } else {
+            return std::string(begin(), end());
+        }
+    }
+
+    // a slice of an array (that must outlive the slice)
+    template<size_t N> static Slice Of(T (&a)[N], int lo, int hi, int max) {
+        return Slice(std::shared_ptr<T>(a, [](T*){}), 0, N, N)(lo, hi, max);
     }
 
-    std::string Error() {
+    int len() const {
+        return _len;
+    }
+
+    int cap() const {
+        return _cap;
+    }
+
+    T *data() const {
+        return _a.get() + _off;
+    }
+
+    T *begin() const {
+        return data();
+    }
+
+    T *end() const {
+        return data() + _len;
+    }
+
+    bool operator==(std::nullptr_t) const {
+        return _a == nullptr;
+    }
+
+    bool operator!=(std::nullptr_t) const {
+        return _a != nullptr;
+    }
+
+    T& operator[](int i) const {
+        if (i < 0 || i >= _len) {
+            panic("runtime error: index out of range [" + std::to_string(i) + "] with length " + std::to_string(_len));
+        }
+
+        return data()[i];
+    }
+
+    // s[lo:hi:max]
+    Slice operator()(int lo, int hi, int max) const {
+        if (lo < 0 || hi < lo || max < hi || max > _cap) {
+            panic("runtime error: slice bounds out of range [" + std::to_string(lo) + ":" + std::to_string(hi) + "] with capacity " + std::to_string(_cap));
+        }
+
+        if (_a == nullptr) {
+            return Slice();
+        }
+
+        return Slice(_a, _off + lo, hi - lo, max - lo);
+    }
+
+    // s[lo:hi]
+    Slice operator()(int lo, int hi) const {
+        return (*this)(lo, hi, _cap);
+    }
+
+    // s[lo:]
+    Slice operator()(int lo) const {
+        return (*this)(lo, _len, _cap);
+    }
+
+    // append grows the slice as Go does: in place if there is enough capacity, otherwise in a new backing array
+    Slice append(const T *values, int n) const {
+        int newlen = _len + n;
+
+        if (newlen <= _cap) {
+            Slice s(_a, _off, newlen, _cap);
+            std::copy(values, values + n, s.data() + _len);
+            return s;
+        }
+
+        int newcap = _cap;
+        if (newlen > 2 * _cap) {
+            newcap = newlen;
+        } else if (_cap < 256) {
+            newcap = 2 * _cap;
+        } else {
+            while (newcap < newlen) {
+                newcap += (newcap + 3 * 256) / 4;
+            }
+        }
+
+        Slice s(alloc(newcap), 0, newlen, newcap);
+        std::copy(begin(), end(), s.data());
+        std::copy(values, values + n, s.data() + _len);
         return s;
     }
 };
 
-inline void panic(std::string &arg) {
-    std::cerr << "panic: " << arg << std::endl;
-    char *paniker = 0;
-    *paniker = 0;
+template<class T> int len(const Slice<T>& s) {
+    return s.len();
 }
 
-inline void Goroutine(std::function<void()> const& fun) {
-    std::thread t(fun);
-    t.detach();
+template<class T> int cap(const Slice<T>& s) {
+    return s.cap();
 }
 
-class Deferred {
+inline int len(const std::string& s) {
+    return s.size();
+}
+
+template<class T, size_t N> int len(const T (&)[N]) {
+    return N;
+}
+
+template<class T, size_t N> int cap(const T (&)[N]) {
+    return N;
+}
+
+// append(s, v1, v2...)
+template<class T, class... Args> Slice<T> append(const Slice<T>& s, Args... values) {
+    T v[] = { T(values)... };
+    return s.append(v, sizeof...(values));
+}
+
+template<class T> Slice<T> append(const Slice<T>& s) {
+    return s;
+}
+
+// append(s, t...)
+template<class T> Slice<T> appendSlice(const Slice<T>& s, const Slice<T>& t) {
+    return s.append(t.data(), t.len());
+}
+
+// append(b, str...)
+inline Slice<byte> appendSlice(const Slice<byte>& s, const std::string& t) {
+    return s.append(reinterpret_cast<const byte*>(t.data()), t.size());
+}
+
+// copy(dst, src): the slices can overlap
+template<class T> int copy(const Slice<T>& dst, const Slice<T>& src) {
+    int n = std::min(dst.len(), src.len());
+    if (std::less<T*>()(src.data(), dst.data())) {
+        std::copy_backward(src.data(), src.data() + n, dst.data() + n);
+    } else {
+        std::copy(src.data(), src.data() + n, dst.data());
+    }
+    return n;
+}
+
+inline int copy(const Slice<byte>& dst, const std::string& src) {
+    int n = std::min(dst.len(), (int)src.size());
+    std::copy(src.data(), src.data() + n, dst.data());
+    return n;
+}
+
+// slice expressions: x[lo:hi:max]
+template<class T> Slice<T> SliceExpr(const Slice<T>& s, int lo) {
+    return s(lo);
+}
+
+template<class T> Slice<T> SliceExpr(const Slice<T>& s, int lo, int hi) {
+    return s(lo, hi);
+}
+
+template<class T> Slice<T> SliceExpr(const Slice<T>& s, int lo, int hi, int max) {
+    return s(lo, hi, max);
+}
+
+template<class T, size_t N> Slice<T> SliceExpr(T (&a)[N], int lo, int hi = N, int max = N) {
+    return Slice<T>::Of(a, lo, hi, max);
+}
+
+inline std::string SliceExpr(const std::string& s, int lo, int hi = -1) {
+    if (hi < 0) {
+        hi = s.size();
+    }
+
+    if (lo < 0 || hi < lo || hi > (int)s.size()) {
+        panic("runtime error: slice bounds out of range [" + std::to_string(lo) + ":" + std::to_string(hi) + "] with length " + std::to_string(s.size()));
+    }
+
+    return s.substr(lo, hi - lo);
+}
+
+// a string literal is a string, not an array
+inline std::string SliceExpr(const char *s, int lo, int hi = -1) {
+    return SliceExpr(std::string(s), lo, hi);
+}
+
+template<class T> std::ostream& operator<<(std::ostream& os, const Slice<T>& s) {
+    os << "[";
+    for (int i = 0; i < s.len(); i++) {
+        os << (i > 0 ? " " : "") << s.data()[i];
+    }
+    return os << "]";
+}
+
+//
+// Comparisons: the structs generated by walkngo have a _tie method, that returns a tuple of references
+// to the fields, and compare their fields with equal (==, !=) and less (<, the order of the map keys).
+// The types that are not comparable in Go (slices, maps and functions) panic at runtime, as the interface
+// values that hold them do (Go rejects the other cases at compile time).
+//
+
+namespace cmp_detail {
+    template<class T, class = void> struct has_equal : std::false_type {};
+    template<class T> struct has_equal<T, std::void_t<decltype(bool(std::declval<const T&>() == std::declval<const T&>()))>> : std::true_type {};
+
+    template<class T, class = void> struct has_less : std::false_type {};
+    template<class T> struct has_less<T, std::void_t<decltype(bool(std::declval<const T&>() < std::declval<const T&>()))>> : std::true_type {};
+
+    template<class T> struct is_tuple : std::false_type {};
+    template<class... T> struct is_tuple<std::tuple<T...>> : std::true_type {};
+
+    template<class T> [[noreturn]] void uncomparable() {
+        panic("runtime error: comparing uncomparable type " + iface_detail::typeName<T>());
+    }
+
+    template<class T> bool equal(const T& a, const T& b);
+    template<class T> bool less(const T& a, const T& b);
+
+    template<size_t I = 0, class... T> bool equalTuple(const std::tuple<T...>& a, const std::tuple<T...>& b) {
+        if constexpr (I == sizeof...(T)) {
+            return true;
+        } else {
+            return equal(std::get<I>(a), std::get<I>(b)) && equalTuple<I + 1>(a, b);
+        }
+    }
+
+    template<size_t I = 0, class... T> bool lessTuple(const std::tuple<T...>& a, const std::tuple<T...>& b) {
+        if constexpr (I == sizeof...(T)) {
+            return false;
+        } else {
+            if (less(std::get<I>(a), std::get<I>(b))) {
+                return true;
+            }
+            if (less(std::get<I>(b), std::get<I>(a))) {
+                return false;
+            }
+            return lessTuple<I + 1>(a, b);
+        }
+    }
+
+    // the basic types held by an interface{} that can be compared
+    typedef std::tuple<bool, int8, byte, int16, uint16, int32, uint32, long, unsigned long, long long, unsigned long long,
+        float, double, std::string, const char *, error> anyTypes;
+
+    template<class T> auto normalize(const T& v) {
+        if constexpr (std::is_same<T, const char *>::value) {
+            return std::string(v);
+        } else {
+            return v;
+        }
+    }
+
+    // a string literal in an interface{} is a string
+    inline const std::type_info& anyType(const std::any& a) {
+        return a.type() == typeid(const char *) ? typeid(std::string) : a.type();
+    }
+
+    inline std::string anyString(const std::any& a) {
+        auto s = std::any_cast<const char *>(&a);
+        return s != nullptr ? std::string(*s) : *std::any_cast<std::string>(&a);
+    }
+
+    // f(x, y) with the values of a and b, that hold the same type
+    template<class F, class... T> bool applyAny(const std::any& a, const std::any& b, F f, std::tuple<T...> *) {
+        bool found = false, result = false;
+        ((!found && a.type() == typeid(T) ?
+            (found = true, result = f(normalize(*std::any_cast<T>(&a)), normalize(*std::any_cast<T>(&b)))) : false), ...);
+        if (!found) {
+            panic("runtime error: comparing uncomparable type " + iface_detail::typeName(a));
+        }
+        return result;
+    }
+
+    template<class T> bool equal(const T& a, const T& b) {
+        if constexpr (is_tuple<T>::value) {
+            return equalTuple(a, b);
+        } else if constexpr (std::is_same<T, std::any>::value) {
+            if (a == nullptr || b == nullptr) {
+                return a == nullptr && b == nullptr;
+            }
+            if (anyType(a) != anyType(b)) {
+                return false;
+            }
+            if (a.type() != b.type()) {
+                return anyString(a) == anyString(b);
+            }
+            return applyAny(a, b, [](const auto& x, const auto& y) { return equal(x, y); }, (anyTypes *) nullptr);
+        } else if constexpr (has_equal<T>::value) {
+            return a == b;
+        } else {
+            uncomparable<T>();
+        }
+    }
+
+    template<class T> bool less(const T& a, const T& b) {
+        if constexpr (is_tuple<T>::value) {
+            return lessTuple(a, b);
+        } else if constexpr (std::is_same<T, std::any>::value) {
+            // nil first, then by type
+            if (a == nullptr || b == nullptr) {
+                return a == nullptr && b != nullptr;
+            }
+            if (anyType(a) != anyType(b)) {
+                return anyType(a).before(anyType(b));
+            }
+            if (a.type() != b.type()) {
+                return anyString(a) < anyString(b);
+            }
+            return applyAny(a, b, [](const auto& x, const auto& y) { return less(x, y); }, (anyTypes *) nullptr);
+        } else if constexpr (std::is_same<T, error>::value) {
+            // errors are only compared for equality: order by the address of the value
+            return std::less<const void *>()(a._iface(), b._iface());
+        } else if constexpr (has_less<T>::value) {
+            return a < b;
+        } else {
+            uncomparable<T>();
+        }
+    }
+
+    // the order of the keys of a map
+    template<class K> struct Less {
+        bool operator()(const K& a, const K& b) const {
+            return less(a, b);
+        }
+    };
+}
+
+//
+// Maps: a reference to a shared std::map (nullptr for a nil map).
+// Reading a missing key returns the zero value without inserting it, writing to a nil map panics.
+// The keys are ordered by cmp_detail::less, so any comparable Go type (i.e. a struct) can be a key.
+//
+
+template<class K, class V> class Map {
 private:
-    std::function<void()> const& deferred_call;
+    typedef std::map<K, V, cmp_detail::Less<K>> map_type;
+
+    std::shared_ptr<map_type> _m;
 
 public:
-    Deferred(std::function<void()> const& fun) : deferred_call(fun) {
+    typedef K key_type;
+    typedef V mapped_type;
+
+    // a nil map
+    Map() {
+    }
+
+    Map(std::nullptr_t) {
+    }
+
+    // make(map[K]V, hint)
+    explicit Map(int hint) : _m(std::make_shared<map_type>()) {
+    }
+
+    // map[K]V{...}
+    Map(std::initializer_list<std::pair<const K, V>> values) : _m(std::make_shared<map_type>(values)) {
+    }
+
+    bool operator==(std::nullptr_t) const {
+        return _m == nullptr;
+    }
+
+    bool operator!=(std::nullptr_t) const {
+        return _m != nullptr;
+    }
+
+    int len() const {
+        return _m ? _m->size() : 0;
+    }
+
+    // v := m[k]
+    V Get(const K& k) const {
+        if (_m) {
+            auto it = _m->find(k);
+            if (it != _m->end()) {
+                return it->second;
+            }
+        }
+
+        return V();
+    }
+
+    // v, ok := m[k]
+    std::tuple<V, bool> Lookup(const K& k) const {
+        if (_m) {
+            auto it = _m->find(k);
+            if (it != _m->end()) {
+                return std::make_tuple(it->second, true);
+            }
+        }
+
+        return std::make_tuple(V(), false);
+    }
+
+    // m[k] = v (or m[k] += v, m[k]++, etc.)
+    V& operator[](const K& k) const {
+        if (!_m) {
+            panic("assignment to entry in nil map");
+        }
+
+        return (*_m)[k];
+    }
+
+    // delete(m, k)
+    void Delete(const K& k) const {
+        if (_m) {
+            _m->erase(k);
+        }
+    }
+
+    bool Contains(const K& k) const {
+        return _m && _m->count(k) > 0;
+    }
+
+    // the keys, in unspecified order (as in Go, it changes every time)
+    std::vector<K> Keys() const {
+        std::vector<K> keys;
+        if (_m) {
+            for (auto& kv : *_m) {
+                keys.push_back(kv.first);
+            }
+
+            static thread_local std::minstd_rand rnd(std::random_device{}());
+            std::shuffle(keys.begin(), keys.end(), rnd);
+        }
+        return keys;
     }
 
-    ~Deferred() {
-        deferred_call();
+    const map_type& sorted() const {
+        static const map_type empty;
+        return _m ? *_m : empty;
     }
 };
 
-template<typename Base, typename T>
-inline std::tuple<const Base*, bool> typeAssert(const T *ptr) {
-    const Base* t = dynamic_cast<const Base*>(ptr);
-    return std::make_tuple(t, t != nullptr);
+template<class K, class V> int len(const Map<K, V>& m) {
+    return m.len();
 }
 
-template<class T> class Chan {
+template<class K, class V> std::ostream& operator<<(std::ostream& os, const Map<K, V>& m) {
+    // as fmt does, print the keys in order
+    os << "map[";
+    bool first = true;
+    for (auto& kv : m.sorted()) {
+        os << (first ? "" : " ") << kv.first << ":" << kv.second;
+        first = false;
+    }
+    return os << "]";
+}
+
+// MapRange iterates over the map entries in random order, skipping the entries deleted during the iteration
+template<class K, class V> class MapRange {
 private:
-    std::queue<T> buffer;
-    int size;
-    std::mutex m;
-    std::condition_variable send_cond;
-    std::condition_variable recv_cond;
+    Map<K, V> m;
+    std::vector<K> keys;
 
 public:
-    Chan(int n=1) : size(n) {
-    }
+    class iterator {
+        const MapRange *r;
+        size_t i;
 
-    void Send(T value) {
-        std::unique_lock<std::mutex> lk(m);
+        void skip() {
+            while (i < r->keys.size() && !r->m.Contains(r->keys[i])) {
+                i++;
+            }
+        }
+
+    public:
+        iterator(const MapRange *r, size_t i) : r(r), i(i) {
+            skip();
+        }
+
+        std::pair<K, V> operator*() const {
+            return std::make_pair(r->keys[i], r->m.Get(r->keys[i]));
+        }
+
+        iterator& operator++() {
+            i++;
+            skip();
+            return *this;
+        }
 
-        while (buffer.size() >= size) {
-            send_cond.wait(lk);
+        bool operator!=(const iterator& other) const {
+            return i != other.i;
         }
+    };
 
-        buffer.push(value);
-        recv_cond.notify_one();
+    MapRange(const Map<K, V>& m) : m(m), keys(m.Keys()) {
     }
 
-    T Receive() {
-        std::unique_lock<std::mutex> lk(m);
+    iterator begin() const {
+        return iterator(this, 0);
+    }
+
+    iterator end() const {
+        return iterator(this, keys.size());
+    }
+};
+
+//
+// range: for (auto [k, v] : Range(x))
+//
+
+template<class T> class SliceRange {
+private:
+    Slice<T> s;
+
+public:
+    class iterator {
+        const SliceRange *r;
+        int i;
+
+    public:
+        iterator(const SliceRange *r, int i) : r(r), i(i) {
+        }
+
+        std::pair<int, T> operator*() const {
+            return std::make_pair(i, r->s.data()[i]);
+        }
+
+        iterator& operator++() {
+            i++;
+            return *this;
+        }
 
-        while (buffer.empty()) {
-            recv_cond.wait(lk);
+        bool operator!=(const iterator& other) const {
+            return i != other.i;
         }
+    };
+
+    SliceRange(const Slice<T>& s) : s(s) {
+    }
+
+    // the length is evaluated once, as in Go
+    iterator begin() const {
+        return iterator(this, 0);
+    }
 
-        T ret = buffer.front();
-        buffer.pop();
-        send_cond.notify_one();
-        return ret;
+    iterator end() const {
+        return iterator(this, s.len());
     }
 };
 
-template<class T> class Slice {
+template<class T> SliceRange<T> Range(const Slice<T>& s) {
+    return SliceRange<T>(s);
+}
+
+template<class T, size_t N> SliceRange<T> Range(T (&a)[N]) {
+    return SliceRange<T>(Slice<T>::Of(a, 0, N, N));
+}
+
+// range over a channel returns (value, index) pairs, for the key only form of the range statement
+template<class T> class ChanRange {
 private:
-    T *_p;
-    int _len;
-    int _cap;
+    Chan<T> ch;
 
 public:
-    Slice(T a[], int len) : _p(a), _len(len), _cap(len) {
+    class iterator {
+        typename Chan<T>::iterator it;
+        int i;
+
+    public:
+        iterator(typename Chan<T>::iterator it) : it(it), i(0) {
+        }
+
+        std::pair<T, int> operator*() const {
+            return std::make_pair(*it, i);
+        }
+
+        iterator& operator++() {
+            ++it;
+            i++;
+            return *this;
+        }
+
+        bool operator!=(const iterator& other) const {
+            return it != other.it;
+        }
+    };
+
+    ChanRange(const Chan<T>& ch) : ch(ch) {
     }
 
-    Slice(T a[], int len, int cap) : _p(a), _len(len), _cap(cap) {
+    iterator begin() const {
+        return iterator(ch.begin());
     }
 
-    int len() {
-        return _len;
+    iterator end() const {
+        return iterator(ch.end());
     }
+};
 
-    int cap() {
-        return _cap;
+template<class T> ChanRange<T> Range(const Chan<T>& ch) {
+    return ChanRange<T>(ch);
+}
+
+template<class K, class V> MapRange<K, V> Range(const Map<K, V>& m) {
+    return MapRange<K, V>(m);
+}
+
+// range over a string returns (index, rune) pairs, where the index is the position of the first byte of the rune
+// and an invalid encoding is RuneError (the type checker tells a string from a []byte or a [N]char literal)
+class StringRange {
+private:
+    std::string s;
+
+public:
+    class iterator {
+        const std::string *s;
+        size_t i;
+        std::tuple<rune, int> r;
+
+    public:
+        iterator(const std::string *s, size_t i) : s(s), i(i), r(utf8::detail::decodeAt(*s, i)) {
+        }
+
+        std::pair<int, rune> operator*() const {
+            return std::make_pair(int(i), std::get<0>(r));
+        }
+
+        iterator& operator++() {
+            i += std::get<1>(r);
+            r = utf8::detail::decodeAt(*s, i);
+            return *this;
+        }
+
+        bool operator!=(const iterator& other) const {
+            return i != other.i;
+        }
+    };
+
+    // a copy, since the string is evaluated once
+    StringRange(const std::string& s) : s(s) {
     }
 
-    Slice operator()(int first) {
-        return Slice(_p+first, _len - first);
+    iterator begin() const {
+        return iterator(&s, 0);
     }
 
-    Slice operator()(int first, int last) {
-        return Slice(_p + first, last-first);
+    iterator end() const {
+        return iterator(&s, s.size());
     }
 };
+
+inline StringRange RangeRunes(const std::string& s) {
+    return StringRange(s);
+}
+
+// string(r): the UTF-8 encoding of an integer (RuneError if it's not a valid rune)
+template<class T, typename std::enable_if<std::is_integral<T>::value, int>::type = 0> std::string runeString(T r) {
+    long long v = (long long) r;
+    return utf8::detail::encode(v < 0 || v > utf8::MaxRune ? utf8::RuneError : rune(v));
+}
+
+// the command line arguments (os.Args, see os.h), set at the start of main
+namespace os {
+    inline Slice<std::string> Args;
+
+    namespace detail {
+        inline Slice<std::string> args(int argc, char **argv) {
+            Slice<std::string> a(argc);
+            for (int i = 0; i < argc; i++) {
+                a[i] = argv[i];
+            }
+            return a;
+        }
+    }
+}
+
+// the interface values print their dynamic value with fmt (see Interface)
+#include <fmt.h>
+
 #endif
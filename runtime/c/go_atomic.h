@@ -0,0 +1,253 @@
+#ifndef _GO_RUNTIME_ATOMIC_H
+#define _GO_RUNTIME_ATOMIC_H 1
+
+#include <go.h>
+#include <atomic>
+#include <shared_mutex>
+
+//
+// sync/atomic (the header is not atomic.h, that could clash with a system header).
+//
+// The functions operate on plain variables (int32, long long for int64, ...) with the GCC/Clang atomic builtins,
+// the types (Int32, Int64, Uint32, Uint64, Uintptr, Bool and Pointer<T>) are based on std::atomic
+// and Value on a std::shared_mutex.
+// All the operations are sequentially consistent, as in Go.
+//
+
+namespace atomic {
+
+    namespace detail {
+        template<class T> T add(T *addr, T delta) {
+            return __atomic_add_fetch(addr, delta, __ATOMIC_SEQ_CST);
+        }
+
+        template<class T> T load(T *addr) {
+            return __atomic_load_n(addr, __ATOMIC_SEQ_CST);
+        }
+
+        template<class T> void store(T *addr, T v) {
+            __atomic_store_n(addr, v, __ATOMIC_SEQ_CST);
+        }
+
+        template<class T> T swap(T *addr, T v) {
+            return __atomic_exchange_n(addr, v, __ATOMIC_SEQ_CST);
+        }
+
+        template<class T> bool cas(T *addr, T old, T v) {
+            return __atomic_compare_exchange_n(addr, &old, v, false, __ATOMIC_SEQ_CST, __ATOMIC_SEQ_CST);
+        }
+
+        // And and Or return the old value
+        template<class T> T and_(T *addr, T mask) {
+            return __atomic_fetch_and(addr, mask, __ATOMIC_SEQ_CST);
+        }
+
+        template<class T> T or_(T *addr, T mask) {
+            return __atomic_fetch_or(addr, mask, __ATOMIC_SEQ_CST);
+        }
+
+        // an integer type
+        template<class T> class Integer {
+        private:
+            std::atomic<T> v{0};
+
+        public:
+            T Load() const {
+                return v.load();
+            }
+
+            void Store(T n) {
+                v.store(n);
+            }
+
+            // Add returns the new value
+            T Add(T delta) {
+                return v.fetch_add(delta) + delta;
+            }
+
+            T Swap(T n) {
+                return v.exchange(n);
+            }
+
+            bool CompareAndSwap(T old, T n) {
+                return v.compare_exchange_strong(old, n);
+            }
+
+            T And(T mask) {
+                return v.fetch_and(mask);
+            }
+
+            T Or(T mask) {
+                return v.fetch_or(mask);
+            }
+        };
+    }
+
+    inline int AddInt32(int *addr, int delta) { return detail::add(addr, delta); }
+    inline long long AddInt64(long long *addr, long long delta) { return detail::add(addr, delta); }
+    inline uint32 AddUint32(uint32 *addr, uint32 delta) { return detail::add(addr, delta); }
+    inline uint64 AddUint64(uint64 *addr, uint64 delta) { return detail::add(addr, delta); }
+    inline uintptr AddUintptr(uintptr *addr, uintptr delta) { return detail::add(addr, delta); }
+
+    inline int LoadInt32(int *addr) { return detail::load(addr); }
+    inline long long LoadInt64(long long *addr) { return detail::load(addr); }
+    inline uint32 LoadUint32(uint32 *addr) { return detail::load(addr); }
+    inline uint64 LoadUint64(uint64 *addr) { return detail::load(addr); }
+    inline uintptr LoadUintptr(uintptr *addr) { return detail::load(addr); }
+    template<class T> T *LoadPointer(T **addr) { return detail::load(addr); }
+
+    inline void StoreInt32(int *addr, int v) { detail::store(addr, v); }
+    inline void StoreInt64(long long *addr, long long v) { detail::store(addr, v); }
+    inline void StoreUint32(uint32 *addr, uint32 v) { detail::store(addr, v); }
+    inline void StoreUint64(uint64 *addr, uint64 v) { detail::store(addr, v); }
+    inline void StoreUintptr(uintptr *addr, uintptr v) { detail::store(addr, v); }
+    template<class T> void StorePointer(T **addr, T *v) { detail::store(addr, v); }
+
+    inline int SwapInt32(int *addr, int v) { return detail::swap(addr, v); }
+    inline long long SwapInt64(long long *addr, long long v) { return detail::swap(addr, v); }
+    inline uint32 SwapUint32(uint32 *addr, uint32 v) { return detail::swap(addr, v); }
+    inline uint64 SwapUint64(uint64 *addr, uint64 v) { return detail::swap(addr, v); }
+    inline uintptr SwapUintptr(uintptr *addr, uintptr v) { return detail::swap(addr, v); }
+    template<class T> T *SwapPointer(T **addr, T *v) { return detail::swap(addr, v); }
+
+    inline bool CompareAndSwapInt32(int *addr, int old, int v) { return detail::cas(addr, old, v); }
+    inline bool CompareAndSwapInt64(long long *addr, long long old, long long v) { return detail::cas(addr, old, v); }
+    inline bool CompareAndSwapUint32(uint32 *addr, uint32 old, uint32 v) { return detail::cas(addr, old, v); }
+    inline bool CompareAndSwapUint64(uint64 *addr, uint64 old, uint64 v) { return detail::cas(addr, old, v); }
+    inline bool CompareAndSwapUintptr(uintptr *addr, uintptr old, uintptr v) { return detail::cas(addr, old, v); }
+    template<class T> bool CompareAndSwapPointer(T **addr, T *old, T *v) { return detail::cas(addr, old, v); }
+
+    inline int AndInt32(int *addr, int mask) { return detail::and_(addr, mask); }
+    inline long long AndInt64(long long *addr, long long mask) { return detail::and_(addr, mask); }
+    inline uint32 AndUint32(uint32 *addr, uint32 mask) { return detail::and_(addr, mask); }
+    inline uint64 AndUint64(uint64 *addr, uint64 mask) { return detail::and_(addr, mask); }
+    inline uintptr AndUintptr(uintptr *addr, uintptr mask) { return detail::and_(addr, mask); }
+
+    inline int OrInt32(int *addr, int mask) { return detail::or_(addr, mask); }
+    inline long long OrInt64(long long *addr, long long mask) { return detail::or_(addr, mask); }
+    inline uint32 OrUint32(uint32 *addr, uint32 mask) { return detail::or_(addr, mask); }
+    inline uint64 OrUint64(uint64 *addr, uint64 mask) { return detail::or_(addr, mask); }
+    inline uintptr OrUintptr(uintptr *addr, uintptr mask) { return detail::or_(addr, mask); }
+
+    typedef detail::Integer<int> Int32;
+    typedef detail::Integer<long long> Int64;
+    typedef detail::Integer<uint32> Uint32;
+    typedef detail::Integer<uint64> Uint64;
+    typedef detail::Integer<uintptr> Uintptr;
+
+    class Bool {
+    private:
+        std::atomic<bool> v{false};
+
+    public:
+        bool Load() const {
+            return v.load();
+        }
+
+        void Store(bool b) {
+            v.store(b);
+        }
+
+        bool Swap(bool b) {
+            return v.exchange(b);
+        }
+
+        bool CompareAndSwap(bool old, bool b) {
+            return v.compare_exchange_strong(old, b);
+        }
+    };
+
+    template<class T> class Pointer {
+    private:
+        std::atomic<T *> v{nullptr};
+
+    public:
+        T *Load() const {
+            return v.load();
+        }
+
+        void Store(T *p) {
+            v.store(p);
+        }
+
+        T *Swap(T *p) {
+            return v.exchange(p);
+        }
+
+        bool CompareAndSwap(T *old, T *p) {
+            return v.compare_exchange_strong(old, p);
+        }
+    };
+
+    //
+    // Value holds a value of any type: all the values stored in a Value must have the same type
+    // and nil can't be stored
+    //
+    class Value {
+    private:
+        mutable std::shared_mutex m;
+        std::any v;
+
+        static std::any normalize(std::any x) {
+            if (auto s = std::any_cast<const char *>(&x)) {
+                return std::string(*s);
+            }
+            return x;
+        }
+
+        // check panics if x can't be stored in the Value (called with m locked)
+        void check(const std::any& x) {
+            if (!x.has_value()) {
+                panic("sync/atomic: store of nil value into Value");
+            }
+            if (v.has_value() && v.type() != x.type()) {
+                panic("sync/atomic: store of inconsistently typed value into Value");
+            }
+        }
+
+    public:
+        // Load returns the value set by the most recent Store (nil if there has been no Store)
+        std::any Load() const {
+            std::shared_lock<std::shared_mutex> lk(m);
+            return v;
+        }
+
+        void Store(std::any x) {
+            x = normalize(x);
+            std::unique_lock<std::shared_mutex> lk(m);
+            check(x);
+            v = x;
+        }
+
+        std::any Swap(std::any x) {
+            x = normalize(x);
+            std::unique_lock<std::shared_mutex> lk(m);
+            check(x);
+            std::swap(v, x);
+            return x;
+        }
+
+        // CompareAndSwap stores x if the current value is old (a number, a bool or a string)
+        template<class T> bool CompareAndSwap(const T& old, std::any x) {
+            x = normalize(x);
+            std::unique_lock<std::shared_mutex> lk(m);
+            check(x);
+
+            bool equal;
+            if constexpr (std::is_array<T>::value || std::is_same<T, const char *>::value) {
+                auto s = std::any_cast<std::string>(&v);
+                equal = s != nullptr && *s == old;
+            } else {
+                auto s = std::any_cast<T>(&v);
+                equal = s != nullptr && *s == old;
+            }
+
+            if (equal) {
+                v = x;
+            }
+            return equal;
+        }
+    };
+}
+
+#endif
@@ -0,0 +1,623 @@
+#ifndef _GO_RUNTIME_STRINGS_H
+#define _GO_RUNTIME_STRINGS_H 1
+
+#include <go.h>
+#include <io.h>
+#include <utf8.h>
+#include <unicode.h>
+#include <functional>
+
+//
+// strings: the Go string functions, on UTF-8 encoded std::string.
+//
+// (the header is not strings.h, that is a system header)
+//
+
+namespace strings {
+
+    inline int Compare(const std::string& a, const std::string& b) {
+        int c = a.compare(b);
+        return c < 0 ? -1 : c > 0 ? 1 : 0;
+    }
+
+    inline int Index(const std::string& s, const std::string& substr) {
+        size_t i = s.find(substr);
+        return i == std::string::npos ? -1 : int(i);
+    }
+
+    inline int LastIndex(const std::string& s, const std::string& substr) {
+        size_t i = s.rfind(substr);
+        return i == std::string::npos ? -1 : int(i);
+    }
+
+    inline int IndexByte(const std::string& s, byte c) {
+        size_t i = s.find(char(c));
+        return i == std::string::npos ? -1 : int(i);
+    }
+
+    inline int LastIndexByte(const std::string& s, byte c) {
+        size_t i = s.rfind(char(c));
+        return i == std::string::npos ? -1 : int(i);
+    }
+
+    // IndexRune returns the index of the first r in s (for RuneError, the first invalid UTF-8 sequence or U+FFFD)
+    inline int IndexRune(const std::string& s, rune r) {
+        if (r >= 0 && r < utf8::RuneSelf) {
+            return IndexByte(s, byte(r));
+        } else if (r == utf8::RuneError) {
+            for (size_t i = 0; i < s.size();) {
+                auto [c, size] = utf8::detail::decodeAt(s, i);
+                if (c == utf8::RuneError) {
+                    return i;
+                }
+                i += size;
+            }
+            return -1;
+        } else if (!utf8::ValidRune(r)) {
+            return -1;
+        }
+        return Index(s, utf8::detail::encode(r));
+    }
+
+    inline int IndexFunc(const std::string& s, const std::function<bool(rune)>& f) {
+        for (size_t i = 0; i < s.size();) {
+            auto [r, size] = utf8::detail::decodeAt(s, i);
+            if (f(r)) {
+                return i;
+            }
+            i += size;
+        }
+        return -1;
+    }
+
+    inline int LastIndexFunc(const std::string& s, const std::function<bool(rune)>& f) {
+        for (size_t i = s.size(); i > 0;) {
+            auto [r, size] = utf8::detail::decodeLast((const byte *) s.data(), i);
+            i -= size;
+            if (f(r)) {
+                return i;
+            }
+        }
+        return -1;
+    }
+
+    inline int IndexAny(const std::string& s, const std::string& chars) {
+        if (chars.empty()) {
+            return -1;
+        }
+        return IndexFunc(s, [&](rune r) { return IndexRune(chars, r) >= 0; });
+    }
+
+    inline int LastIndexAny(const std::string& s, const std::string& chars) {
+        if (chars.empty()) {
+            return -1;
+        }
+        return LastIndexFunc(s, [&](rune r) { return IndexRune(chars, r) >= 0; });
+    }
+
+    inline bool Contains(const std::string& s, const std::string& substr) {
+        return Index(s, substr) >= 0;
+    }
+
+    inline bool ContainsAny(const std::string& s, const std::string& chars) {
+        return IndexAny(s, chars) >= 0;
+    }
+
+    inline bool ContainsRune(const std::string& s, rune r) {
+        return IndexRune(s, r) >= 0;
+    }
+
+    inline bool ContainsFunc(const std::string& s, const std::function<bool(rune)>& f) {
+        return IndexFunc(s, f) >= 0;
+    }
+
+    inline bool HasPrefix(const std::string& s, const std::string& prefix) {
+        return s.size() >= prefix.size() && s.compare(0, prefix.size(), prefix) == 0;
+    }
+
+    inline bool HasSuffix(const std::string& s, const std::string& suffix) {
+        return s.size() >= suffix.size() && s.compare(s.size() - suffix.size(), suffix.size(), suffix) == 0;
+    }
+
+    // Count returns the number of non-overlapping instances of substr in s (the number of runes plus one for "")
+    inline int Count(const std::string& s, const std::string& substr) {
+        if (substr.empty()) {
+            return utf8::RuneCountInString(s) + 1;
+        }
+
+        int n = 0;
+        for (size_t i = s.find(substr); i != std::string::npos; i = s.find(substr, i + substr.size())) {
+            n++;
+        }
+        return n;
+    }
+
+    inline std::tuple<std::string, std::string, bool> Cut(const std::string& s, const std::string& sep) {
+        if (int i = Index(s, sep); i >= 0) {
+            return {s.substr(0, i), s.substr(i + sep.size()), true};
+        }
+        return {s, "", false};
+    }
+
+    inline std::tuple<std::string, bool> CutPrefix(const std::string& s, const std::string& prefix) {
+        if (!HasPrefix(s, prefix)) {
+            return {s, false};
+        }
+        return {s.substr(prefix.size()), true};
+    }
+
+    inline std::tuple<std::string, bool> CutSuffix(const std::string& s, const std::string& suffix) {
+        if (!HasSuffix(s, suffix)) {
+            return {s, false};
+        }
+        return {s.substr(0, s.size() - suffix.size()), true};
+    }
+
+    // EqualFold reports whether s and t are equal under simple Unicode case folding
+    inline bool EqualFold(const std::string& s, const std::string& t) {
+        size_t i = 0, j = 0;
+
+        while (i < s.size() && j < t.size()) {
+            auto [sr, ssize] = utf8::detail::decodeAt(s, i);
+            auto [tr, tsize] = utf8::detail::decodeAt(t, j);
+            i += ssize;
+            j += tsize;
+
+            if (tr == sr) {
+                continue;
+            }
+            if (tr < sr) {
+                std::swap(tr, sr);
+            }
+
+            if (tr < utf8::RuneSelf) {
+                if (sr >= 'A' && sr <= 'Z' && tr == sr + 'a' - 'A') {
+                    continue;
+                }
+                return false;
+            }
+
+            // the runes equivalent to sr, in increasing order
+            rune r = unicode::SimpleFold(sr);
+            while (r != sr && r < tr) {
+                r = unicode::SimpleFold(r);
+            }
+            if (r == tr) {
+                continue;
+            }
+            return false;
+        }
+
+        return i == s.size() && j == t.size();
+    }
+
+    namespace detail {
+
+        // explode splits s into UTF-8 sequences (at most n, the last one is the rest of s)
+        inline Slice<std::string> explode(const std::string& s, int n) {
+            int l = utf8::RuneCountInString(s);
+            if (n < 0 || n > l) {
+                n = l;
+            }
+
+            Slice<std::string> a(n);
+            size_t i = 0;
+            for (int k = 0; k < n - 1; k++) {
+                int size = std::get<1>(utf8::detail::decodeAt(s, i));
+                a[k] = s.substr(i, size);
+                i += size;
+            }
+            if (n > 0) {
+                a[n - 1] = s.substr(i);
+            }
+            return a;
+        }
+
+        // genSplit splits s around sep (keeping sepSave bytes of sep) in at most n substrings
+        inline Slice<std::string> genSplit(const std::string& s, const std::string& sep, int sepSave, int n) {
+            if (n == 0) {
+                return Slice<std::string>();
+            }
+            if (sep.empty()) {
+                return explode(s, n);
+            }
+            if (n < 0) {
+                n = Count(s, sep) + 1;
+            }
+            if (n > int(s.size()) + 1) {
+                n = s.size() + 1;
+            }
+
+            Slice<std::string> a(n);
+            n--;
+
+            size_t start = 0;
+            int i = 0;
+            for (; i < n; i++) {
+                size_t m = s.find(sep, start);
+                if (m == std::string::npos) {
+                    break;
+                }
+                a[i] = s.substr(start, m - start + sepSave);
+                start = m + sep.size();
+            }
+            a[i] = s.substr(start);
+            return a(0, i + 1);
+        }
+
+        inline std::string trimLeft(const std::string& s, const std::function<bool(rune)>& f) {
+            size_t i = 0;
+            while (i < s.size()) {
+                auto [r, size] = utf8::detail::decodeAt(s, i);
+                if (!f(r)) {
+                    break;
+                }
+                i += size;
+            }
+            return s.substr(i);
+        }
+
+        inline std::string trimRight(const std::string& s, const std::function<bool(rune)>& f) {
+            size_t i = s.size();
+            while (i > 0) {
+                auto [r, size] = utf8::detail::decodeLast((const byte *) s.data(), i);
+                if (!f(r)) {
+                    break;
+                }
+                i -= size;
+            }
+            return s.substr(0, i);
+        }
+    }
+
+    inline Slice<std::string> Split(const std::string& s, const std::string& sep) {
+        return detail::genSplit(s, sep, 0, -1);
+    }
+
+    inline Slice<std::string> SplitN(const std::string& s, const std::string& sep, int n) {
+        return detail::genSplit(s, sep, 0, n);
+    }
+
+    inline Slice<std::string> SplitAfter(const std::string& s, const std::string& sep) {
+        return detail::genSplit(s, sep, sep.size(), -1);
+    }
+
+    inline Slice<std::string> SplitAfterN(const std::string& s, const std::string& sep, int n) {
+        return detail::genSplit(s, sep, sep.size(), n);
+    }
+
+    // FieldsFunc splits s around the runs of the runes that satisfy f
+    inline Slice<std::string> FieldsFunc(const std::string& s, const std::function<bool(rune)>& f) {
+        Slice<std::string> a(0);
+        int start = -1;
+
+        for (size_t i = 0; i < s.size();) {
+            auto [r, size] = utf8::detail::decodeAt(s, i);
+            if (f(r)) {
+                if (start >= 0) {
+                    a = append(a, s.substr(start, i - start));
+                    start = -1;
+                }
+            } else if (start < 0) {
+                start = i;
+            }
+            i += size;
+        }
+
+        if (start >= 0) {
+            a = append(a, s.substr(start));
+        }
+        return a;
+    }
+
+    // Fields splits s around the runs of white space
+    inline Slice<std::string> Fields(const std::string& s) {
+        return FieldsFunc(s, unicode::IsSpace);
+    }
+
+    inline std::string Join(const Slice<std::string>& elems, const std::string& sep) {
+        std::string s;
+        for (int i = 0; i < elems.len(); i++) {
+            if (i > 0) {
+                s += sep;
+            }
+            s += elems[i];
+        }
+        return s;
+    }
+
+    inline std::string Repeat(const std::string& s, int count) {
+        if (count < 0) {
+            panic("strings: negative Repeat count");
+        }
+
+        std::string r;
+        r.reserve(s.size() * count);
+        for (int i = 0; i < count; i++) {
+            r += s;
+        }
+        return r;
+    }
+
+    // Replace replaces the first n (all if n < 0) instances of old with new (after each rune for an empty old)
+    inline std::string Replace(const std::string& s, const std::string& old, const std::string& new_, int n) {
+        if (old == new_ || n == 0) {
+            return s;
+        }
+
+        if (int m = Count(s, old); m == 0) {
+            return s;
+        } else if (n < 0 || m < n) {
+            n = m;
+        }
+
+        std::string b;
+        size_t start = 0;
+        for (int i = 0; i < n; i++) {
+            size_t j = start;
+            if (old.empty()) {
+                if (i > 0) {
+                    j += std::get<1>(utf8::detail::decodeAt(s, start));
+                }
+            } else {
+                j = s.find(old, start);
+            }
+            b += s.substr(start, j - start);
+            b += new_;
+            start = j + old.size();
+        }
+        b += s.substr(start);
+        return b;
+    }
+
+    inline std::string ReplaceAll(const std::string& s, const std::string& old, const std::string& new_) {
+        return Replace(s, old, new_, -1);
+    }
+
+    // Map returns s with all its runes modified by mapping (the negative runes are dropped)
+    inline std::string Map(const std::function<rune(rune)>& mapping, const std::string& s) {
+        std::string b;
+        for (size_t i = 0; i < s.size();) {
+            auto [r, size] = utf8::detail::decodeAt(s, i);
+            if (rune m = mapping(r); m >= 0) {
+                b += utf8::detail::encode(m);
+            }
+            i += size;
+        }
+        return b;
+    }
+
+    inline std::string ToUpper(const std::string& s) {
+        return Map(unicode::ToUpper, s);
+    }
+
+    inline std::string ToLower(const std::string& s) {
+        return Map(unicode::ToLower, s);
+    }
+
+    inline std::string ToTitle(const std::string& s) {
+        return Map(unicode::ToTitle, s);
+    }
+
+    // ToValidUTF8 replaces each run of invalid UTF-8 bytes with replacement
+    inline std::string ToValidUTF8(const std::string& s, const std::string& replacement) {
+        std::string b;
+        bool invalid = false;
+
+        for (size_t i = 0; i < s.size();) {
+            auto [r, size] = utf8::detail::decodeAt(s, i);
+            if (r == utf8::RuneError && size == 1) {
+                if (!invalid) {
+                    b += replacement;
+                    invalid = true;
+                }
+            } else {
+                b += s.substr(i, size);
+                invalid = false;
+            }
+            i += size;
+        }
+        return b;
+    }
+
+    inline std::string TrimLeftFunc(const std::string& s, const std::function<bool(rune)>& f) {
+        return detail::trimLeft(s, f);
+    }
+
+    inline std::string TrimRightFunc(const std::string& s, const std::function<bool(rune)>& f) {
+        return detail::trimRight(s, f);
+    }
+
+    inline std::string TrimFunc(const std::string& s, const std::function<bool(rune)>& f) {
+        return detail::trimRight(detail::trimLeft(s, f), f);
+    }
+
+    // TrimLeft removes the leading runes contained in cutset
+    inline std::string TrimLeft(const std::string& s, const std::string& cutset) {
+        if (s.empty() || cutset.empty()) {
+            return s;
+        }
+        return detail::trimLeft(s, [&](rune r) { return ContainsRune(cutset, r); });
+    }
+
+    // TrimRight removes the trailing runes contained in cutset
+    inline std::string TrimRight(const std::string& s, const std::string& cutset) {
+        if (s.empty() || cutset.empty()) {
+            return s;
+        }
+        return detail::trimRight(s, [&](rune r) { return ContainsRune(cutset, r); });
+    }
+
+    inline std::string Trim(const std::string& s, const std::string& cutset) {
+        return TrimRight(TrimLeft(s, cutset), cutset);
+    }
+
+    inline std::string TrimSpace(const std::string& s) {
+        return TrimFunc(s, unicode::IsSpace);
+    }
+
+    inline std::string TrimPrefix(const std::string& s, const std::string& prefix) {
+        return HasPrefix(s, prefix) ? s.substr(prefix.size()) : s;
+    }
+
+    inline std::string TrimSuffix(const std::string& s, const std::string& suffix) {
+        return HasSuffix(s, suffix) ? s.substr(0, s.size() - suffix.size()) : s;
+    }
+
+    inline std::string Clone(const std::string& s) {
+        return s;
+    }
+
+    // Builder builds a string with the Write methods
+    class Builder {
+    private:
+        std::string buf;
+
+    public:
+        std::string String() const {
+            return buf;
+        }
+
+        int Len() const {
+            return buf.size();
+        }
+
+        int Cap() const {
+            return buf.capacity();
+        }
+
+        void Reset() {
+            buf = std::string();
+        }
+
+        void Grow(int n) {
+            if (n < 0) {
+                panic("strings.Builder.Grow: negative count");
+            }
+            buf.reserve(buf.size() + n);
+        }
+
+        std::tuple<int, error> Write(const Slice<byte>& p) {
+            buf.append(p.begin(), p.end());
+            return {p.len(), error()};
+        }
+
+        error WriteByte(byte c) {
+            buf += char(c);
+            return error();
+        }
+
+        std::tuple<int, error> WriteRune(rune r) {
+            std::string s = utf8::detail::encode(r);
+            buf += s;
+            return {s.size(), error()};
+        }
+
+        std::tuple<int, error> WriteString(const std::string& s) {
+            buf += s;
+            return {s.size(), error()};
+        }
+    };
+
+    // Replacer replaces a list of strings with replacements (the first old string that matches wins)
+    class Replacer {
+    private:
+        std::vector<std::pair<std::string, std::string>> oldnew;
+
+    public:
+        Replacer(const std::vector<std::string>& args) {
+            if (args.size() % 2 == 1) {
+                panic("strings.NewReplacer: odd argument count");
+            }
+            for (size_t i = 0; i < args.size(); i += 2) {
+                oldnew.push_back({args[i], args[i + 1]});
+            }
+        }
+
+        std::string Replace(const std::string& s) const {
+            std::string b;
+            size_t last = 0;
+            bool prevMatchEmpty = false;
+
+            for (size_t i = 0; i <= s.size();) {
+                const std::pair<std::string, std::string> *match = nullptr;
+                for (auto& p : oldnew) {
+                    // the empty string doesn't match twice at the same position
+                    if (p.first.empty() && prevMatchEmpty) {
+                        continue;
+                    }
+                    if (s.compare(i, p.first.size(), p.first) == 0 && i + p.first.size() <= s.size()) {
+                        match = &p;
+                        break;
+                    }
+                }
+
+                prevMatchEmpty = match != nullptr && match->first.empty();
+                if (match != nullptr) {
+                    b += s.substr(last, i - last);
+                    b += match->second;
+                    i += match->first.size();
+                    last = i;
+                    continue;
+                }
+                i++;
+            }
+
+            if (last < s.size()) {
+                b += s.substr(last);
+            }
+            return b;
+        }
+    };
+
+    template<class... S> Replacer *NewReplacer(const S&... oldnew) {
+        return new Replacer({std::string(oldnew)...});
+    }
+
+    // Reader reads a string (an io.Reader)
+    class Reader {
+    private:
+        std::string s;
+        size_t i = 0;
+
+    public:
+        explicit Reader(const std::string& s) : s(s) {
+        }
+
+        // Len returns the number of unread bytes
+        int Len() const {
+            return i < s.size() ? s.size() - i : 0;
+        }
+
+        long long Size() const {
+            return s.size();
+        }
+
+        std::tuple<int, error> Read(const Slice<byte>& b) {
+            if (i >= s.size()) {
+                return {0, io::EOF_};
+            }
+            int n = copy(b, s.substr(i, b.len()));
+            i += n;
+            return {n, error()};
+        }
+
+        std::tuple<byte, error> ReadByte() {
+            if (i >= s.size()) {
+                return {0, io::EOF_};
+            }
+            return {byte(s[i++]), error()};
+        }
+
+        void Reset(const std::string& str) {
+            s = str;
+            i = 0;
+        }
+    };
+
+    inline Reader *NewReader(const std::string& s) {
+        return new Reader(s);
+    }
+}
+
+#endif
@@ -1,22 +1,1389 @@
 #ifndef _GO_RUNTIME_TIME_H
 #define _GO_RUNTIME_TIME_H 1
 
+#include <go.h>
 #include <thread>
 #include <chrono>
+#include <ctime>
+#include <cstring>
+
+//
+// time (the header is not time.h, that would clash with the system header).
+//
+// Duration is an int64 count of nanoseconds, Time a wall clock time (seconds and nanoseconds since the Unix epoch)
+// with a location and, for the times returned by Now, a monotonic clock reading used by Sub, Since and Until.
+// The timers (NewTimer, After, AfterFunc, NewTicker, Tick) are run by a timer thread and send the current time
+// on a Chan<Time>, so they can be used in a select.
+//
 
 namespace go_time {
 
-const std::chrono::duration<double> Nanosecond = std::chrono::nanoseconds(1);
-const std::chrono::duration<double> Microsecond = std::chrono::microseconds(1);
-const std::chrono::duration<double, std::milli> Millisecond = std::chrono::milliseconds(1);
-const std::chrono::duration<double> Second = std::chrono::seconds(1);
-const std::chrono::duration<double> Minute = std::chrono::minutes(1);
-const std::chrono::duration<double> Hour = std::chrono::hours(1);
+struct Duration {
+    static constexpr const char *_type = "time.Duration";
+
+    long long _value{};
+
+    constexpr Duration() {
+    }
+
+    constexpr Duration(long long v) : _value(v) {
+    }
+
+    operator long long&() {
+        return _value;
+    }
+
+    constexpr operator const long long&() const {
+        return _value;
+    }
+
+    std::string String() const;
+
+    long long Nanoseconds() const {
+        return _value;
+    }
+
+    long long Microseconds() const {
+        return _value / 1000;
+    }
+
+    long long Milliseconds() const {
+        return _value / 1000000;
+    }
+
+    double Seconds() const;
+    double Minutes() const;
+    double Hours() const;
+
+    Duration Truncate(Duration m) const;
+    Duration Round(Duration m) const;
+    Duration Abs() const;
+};
+
+// the arithmetic operators keep the Duration type (2 * time.Second is a Duration)
+constexpr Duration operator-(Duration d) { return -d._value; }
+constexpr Duration operator+(Duration a, Duration b) { return a._value + b._value; }
+constexpr Duration operator-(Duration a, Duration b) { return a._value - b._value; }
+constexpr Duration operator*(Duration a, Duration b) { return a._value * b._value; }
+constexpr Duration operator/(Duration a, Duration b) { return a._value / b._value; }
+constexpr Duration operator%(Duration a, Duration b) { return a._value % b._value; }
+
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator*(Duration d, N n) { return d._value * n; }
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator*(N n, Duration d) { return n * d._value; }
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator/(Duration d, N n) { return d._value / n; }
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator/(N n, Duration d) { return n / d._value; }
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator%(Duration d, N n) { return d._value % n; }
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator%(N n, Duration d) { return n % d._value; }
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator+(Duration d, N n) { return d._value + n; }
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator+(N n, Duration d) { return n + d._value; }
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator-(Duration d, N n) { return d._value - n; }
+template<class N, class = std::enable_if_t<std::is_integral<N>::value>> constexpr Duration operator-(N n, Duration d) { return n - d._value; }
+
+inline constexpr Duration Nanosecond = 1;
+inline constexpr Duration Microsecond = 1000 * Nanosecond;
+inline constexpr Duration Millisecond = 1000 * Microsecond;
+inline constexpr Duration Second = 1000 * Millisecond;
+inline constexpr Duration Minute = 60 * Second;
+inline constexpr Duration Hour = 60 * Minute;
+
+namespace detail {
+    constexpr long long minDuration = -9223372036854775807LL - 1;
+    constexpr long long maxDuration = 9223372036854775807LL;
+
+    // fmtFrac formats the fraction of v / 10**prec (i.e. ".12345") into the tail of buf, omitting the trailing zeros
+    // (and the dot if the fraction is 0). It returns the index where the output begins and v / 10**prec.
+    inline int fmtFrac(char *buf, int w, unsigned long long& v, int prec) {
+        bool print = false;
+        for (int i = 0; i < prec; i++) {
+            int digit = v % 10;
+            print = print || digit != 0;
+            if (print) {
+                buf[--w] = '0' + digit;
+            }
+            v /= 10;
+        }
+        if (print) {
+            buf[--w] = '.';
+        }
+        return w;
+    }
+
+    // fmtInt formats v into the tail of buf and returns the index where the output begins
+    inline int fmtInt(char *buf, int w, unsigned long long v) {
+        if (v == 0) {
+            buf[--w] = '0';
+        } else {
+            while (v > 0) {
+                buf[--w] = '0' + v % 10;
+                v /= 10;
+            }
+        }
+        return w;
+    }
+
+    inline bool lessThanHalf(long long x, long long y) {
+        return (unsigned long long) x + (unsigned long long) x < (unsigned long long) y;
+    }
+}
+
+// String returns the duration in the form "72h3m0.5s" (as in Go, the durations less than one second
+// use a smaller unit, i.e. "1.5ms", and the zero duration is "0s")
+inline std::string Duration::String() const {
+    char buf[32];
+    int w = sizeof(buf);
+
+    unsigned long long u = _value;
+    bool neg = _value < 0;
+    if (neg) {
+        u = -u;
+    }
+
+    if (u < (unsigned long long) Second._value) {
+        // less than one second: use a smaller unit, such as "1.2ms"
+        int prec = 0;
+        buf[--w] = 's';
+        w--;
+
+        if (u == 0) {
+            buf[w] = '0';
+            return std::string(buf + w, sizeof(buf) - w);
+        } else if (u < (unsigned long long) Microsecond._value) {
+            buf[w] = 'n';
+        } else if (u < (unsigned long long) Millisecond._value) {
+            // U+00B5 'µ' micro sign == 0xC2 0xB5
+            prec = 3;
+            buf[w] = '\xB5';
+            buf[--w] = '\xC2';
+        } else {
+            prec = 6;
+            buf[w] = 'm';
+        }
+
+        w = detail::fmtFrac(buf, w, u, prec);
+        w = detail::fmtInt(buf, w, u);
+    } else {
+        buf[--w] = 's';
+        w = detail::fmtFrac(buf, w, u, 9);
+
+        // u is now integer seconds
+        w = detail::fmtInt(buf, w, u % 60);
+        u /= 60;
+
+        if (u > 0) {
+            buf[--w] = 'm';
+            w = detail::fmtInt(buf, w, u % 60);
+            u /= 60;
+
+            if (u > 0) {
+                buf[--w] = 'h';
+                w = detail::fmtInt(buf, w, u);
+            }
+        }
+    }
+
+    if (neg) {
+        buf[--w] = '-';
+    }
+
+    return std::string(buf + w, sizeof(buf) - w);
+}
+
+inline double Duration::Seconds() const {
+    long long sec = _value / Second;
+    long long nsec = _value % Second;
+    return double(sec) + double(nsec) / 1e9;
+}
+
+inline double Duration::Minutes() const {
+    long long min = _value / Minute;
+    long long nsec = _value % Minute;
+    return double(min) + double(nsec) / (60 * 1e9);
+}
+
+inline double Duration::Hours() const {
+    long long hour = _value / Hour;
+    long long nsec = _value % Hour;
+    return double(hour) + double(nsec) / (60 * 60 * 1e9);
+}
+
+// Truncate rounds toward zero to a multiple of m
+inline Duration Duration::Truncate(Duration m) const {
+    if (m <= 0) {
+        return *this;
+    }
+    return _value - _value % m._value;
+}
+
+// Round rounds to the nearest multiple of m (halfway values away from zero)
+inline Duration Duration::Round(Duration m) const {
+    if (m <= 0) {
+        return *this;
+    }
+
+    long long d = _value;
+    long long r = d % m._value;
+
+    if (d < 0) {
+        r = -r;
+        if (detail::lessThanHalf(r, m._value)) {
+            return d + r;
+        }
+        if (long long d1 = d - m._value + r; d1 < d) {
+            return d1;
+        }
+        return detail::minDuration;
+    }
+
+    if (detail::lessThanHalf(r, m._value)) {
+        return d - r;
+    }
+    if (long long d1 = d + m._value - r; d1 > d) {
+        return d1;
+    }
+    return detail::maxDuration;
+}
+
+inline Duration Duration::Abs() const {
+    if (_value >= 0) {
+        return *this;
+    } else if (_value == detail::minDuration) {
+        return detail::maxDuration;
+    }
+    return -_value;
+}
+
+struct Month {
+    static constexpr const char *_type = "time.Month";
+
+    int _value{};
+
+    constexpr Month() {
+    }
+
+    constexpr Month(int v) : _value(v) {
+    }
+
+    operator int&() {
+        return _value;
+    }
+
+    constexpr operator const int&() const {
+        return _value;
+    }
+
+    std::string String() const {
+        static const char *names[] = {"January", "February", "March", "April", "May", "June",
+            "July", "August", "September", "October", "November", "December"};
+
+        if (_value >= 1 && _value <= 12) {
+            return names[_value - 1];
+        }
+        return "%!Month(" + std::to_string(_value) + ")";
+    }
+};
+
+inline constexpr Month January = 1;
+inline constexpr Month February = 2;
+inline constexpr Month March = 3;
+inline constexpr Month April = 4;
+inline constexpr Month May = 5;
+inline constexpr Month June = 6;
+inline constexpr Month July = 7;
+inline constexpr Month August = 8;
+inline constexpr Month September = 9;
+inline constexpr Month October = 10;
+inline constexpr Month November = 11;
+inline constexpr Month December = 12;
+
+struct Weekday {
+    static constexpr const char *_type = "time.Weekday";
+
+    int _value{};
+
+    constexpr Weekday() {
+    }
+
+    constexpr Weekday(int v) : _value(v) {
+    }
+
+    operator int&() {
+        return _value;
+    }
+
+    constexpr operator const int&() const {
+        return _value;
+    }
+
+    std::string String() const {
+        static const char *names[] = {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"};
+
+        if (_value >= 0 && _value <= 6) {
+            return names[_value];
+        }
+        return "%!Weekday(" + std::to_string(_value) + ")";
+    }
+};
+
+inline constexpr Weekday Sunday = 0;
+inline constexpr Weekday Monday = 1;
+inline constexpr Weekday Tuesday = 2;
+inline constexpr Weekday Wednesday = 3;
+inline constexpr Weekday Thursday = 4;
+inline constexpr Weekday Friday = 5;
+inline constexpr Weekday Saturday = 6;
+
+// a time zone: UTC, Local (the system time zone) or a FixedZone
+struct Location {
+    static constexpr const char *_type = "time.Location";
+
+    std::string name;
+    int offset = 0;     // seconds east of UTC, for a fixed zone
+    bool local = false;
+
+    std::string String() const {
+        return name;
+    }
+
+    // lookup returns the zone name and offset at the unix time sec
+    int lookup(long long sec, std::string& zone) const {
+        if (!local) {
+            zone = name;
+            return offset;
+        }
+
+        std::time_t t = sec;
+        std::tm tm{};
+        if (localtime_r(&t, &tm) == nullptr) {
+            zone = "UTC";
+            return 0;
+        }
+
+        zone = tm.tm_zone != nullptr ? tm.tm_zone : "";
+        return tm.tm_gmtoff;
+    }
+};
+
+inline Location *const UTC = new Location{"UTC", 0, false};
+inline Location *const Local = new Location{"Local", 0, true};
+
+inline Location *FixedZone(std::string name, int offset) {
+    return new Location{name, offset, false};
+}
+
+inline const std::string Layout      = "01/02 03:04:05PM '06 -0700";
+inline const std::string ANSIC       = "Mon Jan _2 15:04:05 2006";
+inline const std::string UnixDate    = "Mon Jan _2 15:04:05 MST 2006";
+inline const std::string RubyDate    = "Mon Jan 02 15:04:05 -0700 2006";
+inline const std::string RFC822      = "02 Jan 06 15:04 MST";
+inline const std::string RFC822Z     = "02 Jan 06 15:04 -0700";
+inline const std::string RFC850      = "Monday, 02-Jan-06 15:04:05 MST";
+inline const std::string RFC1123     = "Mon, 02 Jan 2006 15:04:05 MST";
+inline const std::string RFC1123Z    = "Mon, 02 Jan 2006 15:04:05 -0700";
+inline const std::string RFC3339     = "2006-01-02T15:04:05Z07:00";
+inline const std::string RFC3339Nano = "2006-01-02T15:04:05.999999999Z07:00";
+inline const std::string Kitchen     = "3:04PM";
+inline const std::string Stamp       = "Jan _2 15:04:05";
+inline const std::string StampMilli  = "Jan _2 15:04:05.000";
+inline const std::string StampMicro  = "Jan _2 15:04:05.000000";
+inline const std::string StampNano   = "Jan _2 15:04:05.000000000";
+inline const std::string DateTime    = "2006-01-02 15:04:05";
+inline const std::string DateOnly    = "2006-01-02";
+inline const std::string TimeOnly    = "15:04:05";
+
+namespace detail {
+    // seconds from January 1, year 1 (the zero Time) to the Unix epoch
+    constexpr long long unixToInternal = 62135596800LL;
+
+    // the monotonic clock, in nanoseconds since the start of the program
+    inline const std::chrono::steady_clock::time_point start = std::chrono::steady_clock::now();
+
+    inline long long monotonic() {
+        return std::chrono::duration_cast<std::chrono::nanoseconds>(std::chrono::steady_clock::now() - start).count();
+    }
+
+    // civil returns year, month, day for the days since 1970-01-01 (and daysFromCivil the reverse),
+    // in the proleptic Gregorian calendar
+    inline void civil(long long z, long long& y, int& m, int& d) {
+        z += 719468;
+        long long era = (z >= 0 ? z : z - 146096) / 146097;
+        long long doe = z - era * 146097;
+        long long yoe = (doe - doe / 1460 + doe / 36524 - doe / 146096) / 365;
+        long long doy = doe - (365 * yoe + yoe / 4 - yoe / 100);
+        long long mp = (5 * doy + 2) / 153;
+        d = doy - (153 * mp + 2) / 5 + 1;
+        m = mp < 10 ? mp + 3 : mp - 9;
+        y = yoe + era * 400 + (m <= 2);
+    }
+
+    inline long long daysFromCivil(long long y, int m, int d) {
+        y -= m <= 2;
+        long long era = (y >= 0 ? y : y - 399) / 400;
+        long long yoe = y - era * 400;
+        long long doy = (153 * (m > 2 ? m - 3 : m + 9) + 2) / 5 + d - 1;
+        long long doe = yoe * 365 + yoe / 4 - yoe / 100 + doy;
+        return era * 146097 + doe - 719468;
+    }
+
+    // floorDiv returns a / b rounded toward negative infinity (and sets r to the non negative remainder)
+    inline long long floorDiv(long long a, long long b, long long& r) {
+        long long q = a / b;
+        r = a % b;
+        if (r < 0) {
+            q--;
+            r += b;
+        }
+        return q;
+    }
+
+    // appendInt appends x, zero padded to width digits
+    inline void appendInt(std::string& b, long long x, int width) {
+        unsigned long long u = x;
+        if (x < 0) {
+            b += '-';
+            u = -u;
+        }
+
+        std::string digits = std::to_string(u);
+        for (int i = digits.size(); i < width; i++) {
+            b += '0';
+        }
+        b += digits;
+    }
+
+    // the elements of a layout
+    enum {
+        stdNone,
+        stdLongMonth, stdMonth, stdNumMonth, stdZeroMonth,
+        stdLongWeekDay, stdWeekDay,
+        stdDay, stdUnderDay, stdZeroDay, stdUnderYearDay, stdZeroYearDay,
+        stdHour, stdHour12, stdZeroHour12,
+        stdMinute, stdZeroMinute,
+        stdSecond, stdZeroSecond,
+        stdLongYear, stdYear,
+        stdPM, stdpm,
+        stdTZ,
+        stdISO8601TZ, stdISO8601SecondsTZ, stdISO8601ShortTZ, stdISO8601ColonTZ, stdISO8601ColonSecondsTZ,
+        stdNumTZ, stdNumSecondsTz, stdNumShortTZ, stdNumColonTZ, stdNumColonSecondsTZ,
+        stdFracSecond0, stdFracSecond9,
+    };
+
+    inline bool startsWithLowerCase(const std::string& s, size_t i) {
+        return i < s.size() && s[i] >= 'a' && s[i] <= 'z';
+    }
+
+    // nextStdChunk finds the first layout element in layout[i:], returning its position,
+    // the element and the position after it (and the digits and separator of a fractional second)
+    inline int nextStdChunk(const std::string& layout, size_t& i, size_t& end, int& digits, char& sep) {
+        auto has = [&](size_t i, const char *s) {
+            return layout.compare(i, std::strlen(s), s) == 0;
+        };
+
+        for (; i < layout.size(); i++) {
+            switch (layout[i]) {
+            case 'J': // January, Jan
+                if (has(i, "Jan")) {
+                    if (has(i, "January")) {
+                        end = i + 7;
+                        return stdLongMonth;
+                    }
+                    if (!startsWithLowerCase(layout, i + 3)) {
+                        end = i + 3;
+                        return stdMonth;
+                    }
+                }
+                break;
+
+            case 'M': // Monday, Mon, MST
+                if (has(i, "Mon")) {
+                    if (has(i, "Monday")) {
+                        end = i + 6;
+                        return stdLongWeekDay;
+                    }
+                    if (!startsWithLowerCase(layout, i + 3)) {
+                        end = i + 3;
+                        return stdWeekDay;
+                    }
+                }
+                if (has(i, "MST")) {
+                    end = i + 3;
+                    return stdTZ;
+                }
+                break;
+
+            case '0': // 01, 02, 03, 04, 05, 06, 002
+                if (i + 1 < layout.size() && layout[i + 1] >= '1' && layout[i + 1] <= '6') {
+                    static const int std0x[] = {stdZeroMonth, stdZeroDay, stdZeroHour12, stdZeroMinute, stdZeroSecond, stdYear};
+                    end = i + 2;
+                    return std0x[layout[i + 1] - '1'];
+                }
+                if (has(i, "002")) {
+                    end = i + 3;
+                    return stdZeroYearDay;
+                }
+                break;
+
+            case '1': // 15, 1
+                if (has(i, "15")) {
+                    end = i + 2;
+                    return stdHour;
+                }
+                end = i + 1;
+                return stdNumMonth;
+
+            case '2': // 2006, 2
+                if (has(i, "2006")) {
+                    end = i + 4;
+                    return stdLongYear;
+                }
+                end = i + 1;
+                return stdDay;
+
+            case '_': // _2, _2006, __2
+                if (has(i, "_2")) {
+                    // _2006 is really a literal _, followed by stdLongYear
+                    if (has(i, "_2006")) {
+                        i++;
+                        end = i + 4;
+                        return stdLongYear;
+                    }
+                    end = i + 2;
+                    return stdUnderDay;
+                }
+                if (has(i, "__2")) {
+                    end = i + 3;
+                    return stdUnderYearDay;
+                }
+                break;
+
+            case '3':
+                end = i + 1;
+                return stdHour12;
+
+            case '4':
+                end = i + 1;
+                return stdMinute;
+
+            case '5':
+                end = i + 1;
+                return stdSecond;
+
+            case 'P': // PM
+                if (has(i, "PM")) {
+                    end = i + 2;
+                    return stdPM;
+                }
+                break;
+
+            case 'p': // pm
+                if (has(i, "pm")) {
+                    end = i + 2;
+                    return stdpm;
+                }
+                break;
+
+            case '-': // -070000, -07:00:00, -0700, -07:00, -07
+                if (has(i, "-070000")) {
+                    end = i + 7;
+                    return stdNumSecondsTz;
+                }
+                if (has(i, "-07:00:00")) {
+                    end = i + 9;
+                    return stdNumColonSecondsTZ;
+                }
+                if (has(i, "-0700")) {
+                    end = i + 5;
+                    return stdNumTZ;
+                }
+                if (has(i, "-07:00")) {
+                    end = i + 6;
+                    return stdNumColonTZ;
+                }
+                if (has(i, "-07")) {
+                    end = i + 3;
+                    return stdNumShortTZ;
+                }
+                break;
+
+            case 'Z': // Z070000, Z07:00:00, Z0700, Z07:00, Z07
+                if (has(i, "Z070000")) {
+                    end = i + 7;
+                    return stdISO8601SecondsTZ;
+                }
+                if (has(i, "Z07:00:00")) {
+                    end = i + 9;
+                    return stdISO8601ColonSecondsTZ;
+                }
+                if (has(i, "Z0700")) {
+                    end = i + 5;
+                    return stdISO8601TZ;
+                }
+                if (has(i, "Z07:00")) {
+                    end = i + 6;
+                    return stdISO8601ColonTZ;
+                }
+                if (has(i, "Z07")) {
+                    end = i + 3;
+                    return stdISO8601ShortTZ;
+                }
+                break;
+
+            case '.': // .000 or .999, ,000 or ,999 (repeated digits for the fractional seconds)
+            case ',':
+                if (i + 1 < layout.size() && (layout[i + 1] == '0' || layout[i + 1] == '9')) {
+                    char ch = layout[i + 1];
+                    size_t j = i + 1;
+                    while (j < layout.size() && layout[j] == ch) {
+                        j++;
+                    }
+
+                    // the string of digits must end here, to be a fractional second
+                    if (j >= layout.size() || layout[j] < '0' || layout[j] > '9') {
+                        digits = j - (i + 1);
+                        sep = layout[i];
+                        end = j;
+                        return ch == '0' ? stdFracSecond0 : stdFracSecond9;
+                    }
+                }
+                break;
+            }
+        }
+
+        end = i;
+        return stdNone;
+    }
+
+    // the timers, run by a thread in the order of their expiration
+    struct timer {
+        std::function<void()> f;
+        long long when = 0;    // monotonic time
+        long long period = 0;  // for a ticker
+        bool active = false;
+        std::multimap<long long, std::shared_ptr<timer>>::iterator pos;
+    };
+
+    class timers {
+    private:
+        std::mutex m;
+        std::condition_variable cond;
+        std::multimap<long long, std::shared_ptr<timer>> queue;
+        bool running = false;
+
+        void run() {
+            std::unique_lock<std::mutex> lk(m);
+
+            for (;;) {
+                if (queue.empty()) {
+                    cond.wait(lk);
+                    continue;
+                }
+
+                long long now = monotonic();
+                auto first = queue.begin();
+                if (first->first > now) {
+                    cond.wait_for(lk, std::chrono::nanoseconds(first->first - now));
+                    continue;
+                }
+
+                auto t = first->second;
+                queue.erase(first);
+
+                if (t->period > 0) {
+                    // a ticker drops the ticks it's late for
+                    t->when += t->period * (1 + (now - t->when) / t->period);
+                    t->pos = queue.emplace(t->when, t);
+                } else {
+                    t->active = false;
+                    Scheduler::Get().AddTimers(-1);
+                }
+
+                auto f = t->f;
+                lk.unlock();
+                f();
+                lk.lock();
+            }
+        }
+
+    public:
+        // the timer thread is never stopped
+        static timers& Get() {
+            static timers *t = new timers();
+            return *t;
+        }
+
+        // Start starts (or restarts) the timer, to expire at when, and returns true if it was active
+        bool Start(const std::shared_ptr<timer>& t, long long when) {
+            std::unique_lock<std::mutex> lk(m);
+
+            bool active = t->active;
+            if (active) {
+                queue.erase(t->pos);
+            } else {
+                t->active = true;
+                Scheduler::Get().AddTimers(1);
+            }
+
+            t->when = when;
+            t->pos = queue.emplace(when, t);
+
+            if (!running) {
+                running = true;
+                std::thread([this]() { run(); }).detach();
+            }
+
+            cond.notify_one();
+            return active;
+        }
+
+        // Stop stops the timer and returns true if it was active
+        bool Stop(const std::shared_ptr<timer>& t) {
+            std::unique_lock<std::mutex> lk(m);
+
+            if (!t->active) {
+                return false;
+            }
+
+            queue.erase(t->pos);
+            t->active = false;
+            Scheduler::Get().AddTimers(-1);
+            return true;
+        }
+    };
+}
+
+class Time {
+private:
+    long long sec = -detail::unixToInternal; // seconds since the Unix epoch (the zero Time is January 1, year 1 UTC)
+    int nsec = 0;
+    long long mono = 0;                      // monotonic clock reading + 1 (0 if there is none)
+    go_time::Location *loc = nullptr;                 // nullptr for UTC
+
+    go_time::Location *location() const {
+        return loc != nullptr ? loc : go_time::UTC;
+    }
+
+    // abs returns the seconds since the Unix epoch in the time zone, with its name and offset
+    long long abs(std::string& zone, int& offset) const {
+        offset = location()->lookup(sec, zone);
+        return sec + offset;
+    }
+
+    long long abs() const {
+        std::string zone;
+        int offset;
+        return abs(zone, offset);
+    }
 
-typedef std::chrono::duration<double> Duration;
+    // the days since the Unix epoch, and the seconds in the day, in the time zone
+    long long days(long long& secs) const {
+        return detail::floorDiv(abs(), 86400, secs);
+    }
 
+public:
+    static constexpr const char *_type = "time.Time";
+
+    Time() {
+    }
+
+    Time(long long sec, int nsec, long long mono, go_time::Location *loc) : sec(sec), nsec(nsec), mono(mono), loc(loc == go_time::UTC ? nullptr : loc) {
+    }
+
+    // the wall clock time, in seconds since the Unix epoch and nanoseconds
+    long long Unix() const {
+        return sec;
+    }
+
+    long long UnixNano() const {
+        return sec * 1000000000LL + nsec;
+    }
+
+    long long UnixMicro() const {
+        return sec * 1000000LL + nsec / 1000;
+    }
+
+    long long UnixMilli() const {
+        return sec * 1000LL + nsec / 1000000;
+    }
+
+    bool IsZero() const {
+        return sec == -detail::unixToInternal && nsec == 0;
+    }
+
+    std::tuple<long long, go_time::Month, int> Date() const {
+        long long secs, y;
+        int m, d;
+        detail::civil(days(secs), y, m, d);
+        return std::make_tuple(y, go_time::Month(m), d);
+    }
+
+    long long Year() const {
+        return std::get<0>(Date());
+    }
+
+    go_time::Month Month() const {
+        return std::get<1>(Date());
+    }
+
+    int Day() const {
+        return std::get<2>(Date());
+    }
+
+    go_time::Weekday Weekday() const {
+        long long secs, wd;
+        detail::floorDiv(days(secs) + 4, 7, wd); // January 1, 1970 was a Thursday
+        return go_time::Weekday(wd);
+    }
+
+    int YearDay() const {
+        long long secs;
+        long long d = days(secs);
+        return d - detail::daysFromCivil(std::get<0>(Date()), 1, 1) + 1;
+    }
+
+    std::tuple<int, int, int> Clock() const {
+        long long secs;
+        days(secs);
+        return std::make_tuple(secs / 3600, secs / 60 % 60, secs % 60);
+    }
+
+    int Hour() const {
+        return std::get<0>(Clock());
+    }
+
+    int Minute() const {
+        return std::get<1>(Clock());
+    }
+
+    int Second() const {
+        return std::get<2>(Clock());
+    }
+
+    int Nanosecond() const {
+        return nsec;
+    }
+
+    go_time::Location *Location() const {
+        return location();
+    }
+
+    std::tuple<std::string, int> Zone() const {
+        std::string zone;
+        int offset = location()->lookup(sec, zone);
+        return std::make_tuple(zone, offset);
+    }
+
+    Time In(go_time::Location *l) const {
+        if (l == nullptr) {
+            panic("time: missing Location in call to Time.In");
+        }
+        return Time(sec, nsec, mono, l);
+    }
+
+    Time UTC() const {
+        return Time(sec, nsec, mono, nullptr);
+    }
+
+    Time Local() const {
+        return Time(sec, nsec, mono, go_time::Local);
+    }
+
+    Time Add(Duration d) const {
+        long long ds = d / go_time::Second;
+        long long ns = nsec + d % go_time::Second;
+        if (ns >= 1000000000) {
+            ds++;
+            ns -= 1000000000;
+        } else if (ns < 0) {
+            ds--;
+            ns += 1000000000;
+        }
+        return Time(sec + ds, ns, mono != 0 ? mono + d._value : 0, loc);
+    }
+
+    // Sub returns t - u, using the monotonic clock if both times have it
+    Duration Sub(const Time& u) const {
+        if (mono != 0 && u.mono != 0) {
+            return mono - u.mono;
+        }
+        return (sec - u.sec) * go_time::Second + (nsec - u.nsec);
+    }
+
+    Time AddDate(int years, int months, int days) const;
+
+    bool After(const Time& u) const {
+        if (mono != 0 && u.mono != 0) {
+            return mono > u.mono;
+        }
+        return sec > u.sec || (sec == u.sec && nsec > u.nsec);
+    }
+
+    bool Before(const Time& u) const {
+        if (mono != 0 && u.mono != 0) {
+            return mono < u.mono;
+        }
+        return sec < u.sec || (sec == u.sec && nsec < u.nsec);
+    }
+
+    bool Equal(const Time& u) const {
+        if (mono != 0 && u.mono != 0) {
+            return mono == u.mono;
+        }
+        return sec == u.sec && nsec == u.nsec;
+    }
+
+    int Compare(const Time& u) const {
+        return Before(u) ? -1 : After(u) ? 1 : 0;
+    }
+
+    // Truncate and Round work on the time since the zero Time, and strip the monotonic clock reading
+    Time Truncate(Duration d) const {
+        if (d <= 0) {
+            return Time(sec, nsec, 0, loc);
+        }
+        return Time(sec, nsec, 0, loc).Add(-rem(d));
+    }
+
+    Time Round(Duration d) const {
+        if (d <= 0) {
+            return Time(sec, nsec, 0, loc);
+        }
+
+        Duration r = rem(d);
+        if (detail::lessThanHalf(r, d)) {
+            return Time(sec, nsec, 0, loc).Add(-r);
+        }
+        return Time(sec, nsec, 0, loc).Add(d - r);
+    }
+
+    // rem returns the time since the zero Time modulo d
+    Duration rem(Duration d) const {
+        __int128 t = (__int128) (sec + detail::unixToInternal) * 1000000000 + nsec;
+        return (long long) (t % d._value);
+    }
+
+    std::string Format(const std::string& layout) const;
+
+    // String returns the time formatted with "2006-01-02 15:04:05.999999999 -0700 MST",
+    // and the monotonic clock reading (m=±seconds) if there is one
+    std::string String() const {
+        std::string s = Format("2006-01-02 15:04:05.999999999 -0700 MST");
+
+        if (mono != 0) {
+            long long m = mono - 1;
+            s += m < 0 ? " m=-" : " m=+";
+            if (m < 0) {
+                m = -m;
+            }
+            detail::appendInt(s, m / 1000000000, 1);
+            s += ".";
+            detail::appendInt(s, m % 1000000000, 9);
+        }
+
+        return s;
+    }
+};
+
+inline Time Now() {
+    auto now = std::chrono::duration_cast<std::chrono::nanoseconds>(std::chrono::system_clock::now().time_since_epoch()).count();
+    long long rem;
+    long long sec = detail::floorDiv(now, 1000000000, rem);
+    return Time(sec, rem, detail::monotonic() + 1, Local);
+}
+
+inline Time Unix(long long sec, long long nsec) {
+    if (nsec < 0 || nsec >= 1000000000) {
+        long long n;
+        sec += detail::floorDiv(nsec, 1000000000, n);
+        nsec = n;
+    }
+    return Time(sec, nsec, 0, Local);
+}
+
+inline Time UnixMilli(long long msec) {
+    return Unix(msec / 1000, (msec % 1000) * 1000000);
+}
+
+inline Time UnixMicro(long long usec) {
+    return Unix(usec / 1000000, (usec % 1000000) * 1000);
+}
+
+// Date returns the time for yyyy-mm-dd hh:mm:ss + nsec in loc, normalizing the values out of range
+// (October 32 is November 1)
+inline Time Date(long long year, Month month, long long day, long long hour, long long min, long long sec, long long nsec, Location *loc) {
+    if (loc == nullptr) {
+        panic("time: missing Location in call to Date");
+    }
+
+    long long m = month - 1, r;
+    year += detail::floorDiv(m, 12, r);
+    m = r;
+
+    sec += detail::floorDiv(nsec, 1000000000, nsec);
+
+    long long unix = (detail::daysFromCivil(year, m + 1, 1) + day - 1) * 86400 + hour * 3600 + min * 60 + sec;
+
+    // the offset of the zone at the local time (checked again at the resulting time, for the zone transitions)
+    std::string zone;
+    int offset = loc->lookup(unix, zone);
+    if (int o = loc->lookup(unix - offset, zone); o != offset) {
+        offset = o;
+    }
+
+    return Time(unix - offset, nsec, 0, loc);
+}
+
+inline Time Time::AddDate(int years, int months, int days) const {
+    auto [year, month, day] = Date();
+    auto [hour, min, sec] = Clock();
+    return go_time::Date(year + years, month + months, day + days, hour, min, sec, nsec, location());
+}
+
+inline Duration Since(const Time& t) {
+    return Now().Sub(t);
+}
+
+inline Duration Until(const Time& t) {
+    return t.Sub(Now());
+}
+
+// Format returns the time formatted with a Go layout, the representation of
+// Mon Jan 2 15:04:05 MST 2006 (i.e. "2006-01-02T15:04:05Z07:00")
+inline std::string Time::Format(const std::string& layout) const {
+    std::string zone;
+    int offset;
+    long long abs = this->abs(zone, offset);
+
+    long long secs, year;
+    int month, day;
+    long long days = detail::floorDiv(abs, 86400, secs);
+    detail::civil(days, year, month, day);
+    int yday = days - detail::daysFromCivil(year, 1, 1) + 1;
+    int hour = secs / 3600, min = secs / 60 % 60, sec = secs % 60;
+
+    std::string b;
+
+    for (size_t i = 0; i < layout.size();) {
+        size_t start = i, end;
+        int digits = 0;
+        char sep = '.';
+
+        int std = detail::nextStdChunk(layout, start, end, digits, sep);
+        b.append(layout, i, start - i);
+        i = end;
+
+        switch (std) {
+        case detail::stdNone:
+            break;
+
+        case detail::stdYear:
+            detail::appendInt(b, (year < 0 ? -year : year) % 100, 2);
+            break;
+
+        case detail::stdLongYear:
+            detail::appendInt(b, year, 4);
+            break;
+
+        case detail::stdMonth:
+            b += go_time::Month(month).String().substr(0, 3);
+            break;
+
+        case detail::stdLongMonth:
+            b += go_time::Month(month).String();
+            break;
+
+        case detail::stdNumMonth:
+            detail::appendInt(b, month, 0);
+            break;
+
+        case detail::stdZeroMonth:
+            detail::appendInt(b, month, 2);
+            break;
+
+        case detail::stdWeekDay:
+            b += Weekday().String().substr(0, 3);
+            break;
+
+        case detail::stdLongWeekDay:
+            b += Weekday().String();
+            break;
+
+        case detail::stdDay:
+            detail::appendInt(b, day, 0);
+            break;
+
+        case detail::stdUnderDay:
+            if (day < 10) {
+                b += ' ';
+            }
+            detail::appendInt(b, day, 0);
+            break;
+
+        case detail::stdZeroDay:
+            detail::appendInt(b, day, 2);
+            break;
+
+        case detail::stdUnderYearDay:
+            if (yday < 100) {
+                b += ' ';
+                if (yday < 10) {
+                    b += ' ';
+                }
+            }
+            detail::appendInt(b, yday, 0);
+            break;
+
+        case detail::stdZeroYearDay:
+            detail::appendInt(b, yday, 3);
+            break;
+
+        case detail::stdHour:
+            detail::appendInt(b, hour, 2);
+            break;
+
+        case detail::stdHour12:
+        case detail::stdZeroHour12:
+            detail::appendInt(b, hour % 12 == 0 ? 12 : hour % 12, std == detail::stdZeroHour12 ? 2 : 0);
+            break;
+
+        case detail::stdMinute:
+            detail::appendInt(b, min, 0);
+            break;
+
+        case detail::stdZeroMinute:
+            detail::appendInt(b, min, 2);
+            break;
+
+        case detail::stdSecond:
+            detail::appendInt(b, sec, 0);
+            break;
+
+        case detail::stdZeroSecond:
+            detail::appendInt(b, sec, 2);
+            break;
+
+        case detail::stdPM:
+            b += hour >= 12 ? "PM" : "AM";
+            break;
+
+        case detail::stdpm:
+            b += hour >= 12 ? "pm" : "am";
+            break;
+
+        case detail::stdISO8601TZ:
+        case detail::stdISO8601ColonTZ:
+        case detail::stdISO8601SecondsTZ:
+        case detail::stdISO8601ShortTZ:
+        case detail::stdISO8601ColonSecondsTZ:
+        case detail::stdNumTZ:
+        case detail::stdNumColonTZ:
+        case detail::stdNumSecondsTz:
+        case detail::stdNumShortTZ:
+        case detail::stdNumColonSecondsTZ: {
+            bool iso = std >= detail::stdISO8601TZ && std <= detail::stdISO8601ColonSecondsTZ;
+            if (iso && offset == 0) {
+                b += 'Z';
+                break;
+            }
+
+            int zone = offset / 60; // in minutes
+            int absoffset = offset;
+            if (zone < 0) {
+                b += '-';
+                zone = -zone;
+                absoffset = -absoffset;
+            } else {
+                b += '+';
+            }
+
+            detail::appendInt(b, zone / 60, 2);
+            if (std == detail::stdISO8601ColonTZ || std == detail::stdNumColonTZ ||
+                    std == detail::stdISO8601ColonSecondsTZ || std == detail::stdNumColonSecondsTZ) {
+                b += ':';
+            }
+            if (std != detail::stdNumShortTZ && std != detail::stdISO8601ShortTZ) {
+                detail::appendInt(b, zone % 60, 2);
+            }
+
+            if (std == detail::stdISO8601SecondsTZ || std == detail::stdNumSecondsTz ||
+                    std == detail::stdNumColonSecondsTZ || std == detail::stdISO8601ColonSecondsTZ) {
+                if (std == detail::stdNumColonSecondsTZ || std == detail::stdISO8601ColonSecondsTZ) {
+                    b += ':';
+                }
+                detail::appendInt(b, absoffset % 60, 2);
+            }
+            break;
+        }
+
+        case detail::stdTZ:
+            if (!zone.empty()) {
+                b += zone;
+                break;
+            } else {
+                // no zone name: use the -0700 format
+                int zone = offset / 60;
+                if (zone < 0) {
+                    b += '-';
+                    zone = -zone;
+                } else {
+                    b += '+';
+                }
+                detail::appendInt(b, zone / 60, 2);
+                detail::appendInt(b, zone % 60, 2);
+            }
+            break;
+
+        case detail::stdFracSecond0:
+        case detail::stdFracSecond9: {
+            bool trim = std == detail::stdFracSecond9;
+            if (trim && (digits == 0 || nsec == 0)) {
+                break;
+            }
+
+            std::string frac;
+            detail::appendInt(frac, nsec, 9);
+            if (digits < 9) {
+                frac.resize(digits);
+            }
+            if (trim) {
+                frac.erase(frac.find_last_not_of('0') + 1);
+            }
+            if (!frac.empty() || !trim) {
+                b += sep;
+                b += frac;
+            }
+            break;
+        }
+        }
+    }
+
+    return b;
+}
+
+// Sleep pauses the current goroutine for at least the duration d
 inline void Sleep(Duration d) {
-    std::this_thread::sleep_for(d);
+    if (d <= 0) {
+        return;
+    }
+
+    Scheduler::Get().AddTimers(1);
+    {
+        Blocking b;
+        std::this_thread::sleep_for(std::chrono::nanoseconds(d._value));
+    }
+    Scheduler::Get().AddTimers(-1);
+}
+
+namespace detail {
+    // sendTime sends the current time on c, unless a value is already pending
+    inline std::function<void()> sendTime(Chan<Time> c) {
+        return [c]() { c.TrySend(Now()); };
+    }
+
+    // drain removes a pending value from c, so that a stopped or reset timer doesn't deliver a stale time
+    inline void drain(const Chan<Time>& c) {
+        Time t;
+        bool ok;
+        c.TryLookup(t, ok);
+    }
+}
+
+// a Timer sends the current time on C (or calls a function, for AfterFunc) when it expires
+class Timer {
+private:
+    std::shared_ptr<detail::timer> t = std::make_shared<detail::timer>();
+
+public:
+    Chan<Time> C;
+
+    Timer(Duration d, Chan<Time> c, std::function<void()> f) : C(c) {
+        t->f = f;
+        detail::timers::Get().Start(t, detail::monotonic() + std::max(d, Duration(0)));
+    }
+
+    // Stop prevents the Timer from firing, and returns false if it already expired or has been stopped
+    bool Stop() {
+        bool active = detail::timers::Get().Stop(t);
+        if (C != nullptr) {
+            detail::drain(C);
+        }
+        return active;
+    }
+
+    // Reset changes the timer to expire after d, and returns true if it was active
+    bool Reset(Duration d) {
+        bool active = detail::timers::Get().Stop(t);
+        if (C != nullptr) {
+            detail::drain(C);
+        }
+        detail::timers::Get().Start(t, detail::monotonic() + std::max(d, Duration(0)));
+        return active;
+    }
+};
+
+inline Timer *NewTimer(Duration d) {
+    Chan<Time> c(1);
+    return new Timer(d, c, detail::sendTime(c));
+}
+
+// AfterFunc calls f in its own goroutine after d
+inline Timer *AfterFunc(Duration d, std::function<void()> f) {
+    return new Timer(d, nullptr, [f]() { Goroutine(f); });
+}
+
+// After returns a channel that receives the current time after d
+inline Chan<Time> After(Duration d) {
+    Chan<Time> c(1);
+    auto t = std::make_shared<detail::timer>();
+    t->f = detail::sendTime(c);
+    detail::timers::Get().Start(t, detail::monotonic() + std::max(d, Duration(0)));
+    return c;
+}
+
+// a Ticker sends the current time on C every period (dropping the ticks for the slow receivers)
+class Ticker {
+private:
+    std::shared_ptr<detail::timer> t = std::make_shared<detail::timer>();
+
+public:
+    Chan<Time> C{1};
+
+    Ticker(Duration d) {
+        if (d <= 0) {
+            panic(error("non-positive interval for NewTicker"));
+        }
+
+        t->f = detail::sendTime(C);
+        t->period = d;
+        detail::timers::Get().Start(t, detail::monotonic() + d);
+    }
+
+    void Stop() {
+        detail::timers::Get().Stop(t);
+        detail::drain(C);
+    }
+
+    // Reset stops the ticker and resets its period to d
+    void Reset(Duration d) {
+        if (d <= 0) {
+            panic(error("non-positive interval for Ticker.Reset"));
+        }
+
+        detail::timers::Get().Stop(t);
+        detail::drain(C);
+        t->period = d;
+        detail::timers::Get().Start(t, detail::monotonic() + d);
+    }
+};
+
+inline Ticker *NewTicker(Duration d) {
+    return new Ticker(d);
+}
+
+// Tick returns the channel of a Ticker that is never stopped (nil if d <= 0)
+inline Chan<Time> Tick(Duration d) {
+    if (d <= 0) {
+        return nullptr;
+    }
+    return NewTicker(d)->C;
 }
 
 }
@@ -0,0 +1,342 @@
+#ifndef _GO_RUNTIME_IO_H
+#define _GO_RUNTIME_IO_H 1
+
+#include <go.h>
+
+//
+// io: Reader, Writer, Closer, Seeker and their combinations are interface values, like error: they hold a copy
+// of any value with the methods of the interface (usually a pointer, as *os.File or *bufio.Writer),
+// and the zero value (or nullptr) is a nil interface. An interface converts to the ones with fewer methods.
+//
+// io.EOF is io::EOF_, since EOF is a macro of the C library (see mappings.json).
+//
+
+namespace io {
+
+inline const error EOF_ = error("EOF");
+inline const error ErrUnexpectedEOF = error("unexpected EOF");
+inline const error ErrShortWrite = error("short write");
+inline const error ErrShortBuffer = error("short buffer");
+inline const error ErrNoProgress = error("multiple Read calls return no data or error");
+
+const int SeekStart = 0;
+const int SeekCurrent = 1;
+const int SeekEnd = 2;
+
+namespace detail {
+    enum {
+        READ = 1,
+        WRITE = 2,
+        CLOSE = 4,
+        SEEK = 8,
+    };
+
+    template<class T, class = void> struct has_read : std::false_type {};
+    template<class T> struct has_read<T, std::void_t<decltype(std::declval<T&>().Read(std::declval<Slice<byte>>()))>> : std::true_type {};
+
+    template<class T, class = void> struct has_write : std::false_type {};
+    template<class T> struct has_write<T, std::void_t<decltype(std::declval<T&>().Write(std::declval<Slice<byte>>()))>> : std::true_type {};
+
+    template<class T, class = void> struct has_close : std::false_type {};
+    template<class T> struct has_close<T, std::void_t<decltype(std::declval<T&>().Close())>> : std::true_type {};
+
+    template<class T, class = void> struct has_seek : std::false_type {};
+    template<class T> struct has_seek<T, std::void_t<decltype(std::declval<T&>().Seek(0LL, 0))>> : std::true_type {};
+
+    // the interface methods of T (or *T)
+    template<class T> constexpr int methods() {
+        typedef typename std::remove_pointer<T>::type V;
+        return (has_read<V>::value ? READ : 0) | (has_write<V>::value ? WRITE : 0) |
+            (has_close<V>::value ? CLOSE : 0) | (has_seek<V>::value ? SEEK : 0);
+    }
+
+    // the dynamic value of an interface
+    struct value {
+        virtual ~value() {}
+        virtual std::tuple<int, error> Read(const Slice<byte>& p) = 0;
+        virtual std::tuple<int, error> Write(const Slice<byte>& p) = 0;
+        virtual error Close() = 0;
+        virtual std::tuple<long long, error> Seek(long long offset, int whence) = 0;
+        virtual const std::type_info& type() const = 0;
+        virtual void *ptr() = 0; // a pointer to the value (of type type())
+    };
+
+    template<class T> struct impl : value {
+        typedef typename std::remove_pointer<T>::type V;
+        T v;
+        std::shared_ptr<void> owner; // keeps the value pointed to by v alive
+
+        impl(const T& v, std::shared_ptr<void> owner = nullptr) : v(v), owner(owner) {
+        }
+
+        V& get() {
+            if constexpr (std::is_pointer<T>::value) {
+                if (v == nullptr) {
+                    panic("runtime error: invalid memory address or nil pointer dereference");
+                }
+            }
+            return error_detail::deref(v);
+        }
+
+        std::tuple<int, error> Read(const Slice<byte>& p) override {
+            if constexpr (has_read<V>::value) {
+                auto [n, err] = get().Read(p);
+                return std::make_tuple(int(n), err);
+            } else {
+                return std::make_tuple(0, error("Read not implemented"));
+            }
+        }
+
+        std::tuple<int, error> Write(const Slice<byte>& p) override {
+            if constexpr (has_write<V>::value) {
+                auto [n, err] = get().Write(p);
+                return std::make_tuple(int(n), err);
+            } else {
+                return std::make_tuple(0, error("Write not implemented"));
+            }
+        }
+
+        error Close() override {
+            if constexpr (has_close<V>::value) {
+                return get().Close();
+            } else {
+                return error("Close not implemented");
+            }
+        }
+
+        std::tuple<long long, error> Seek(long long offset, int whence) override {
+            if constexpr (has_seek<V>::value) {
+                auto [n, err] = get().Seek(offset, whence);
+                return std::make_tuple((long long)n, err);
+            } else {
+                return std::make_tuple(0LL, error("Seek not implemented"));
+            }
+        }
+
+        const std::type_info& type() const override {
+            return typeid(T);
+        }
+
+        void *ptr() override {
+            return &v;
+        }
+    };
+
+    template<int M> class iface;
+
+    template<class T> struct is_iface : std::false_type {};
+    template<int M> struct is_iface<iface<M>> : std::true_type {};
+
+    // an interface with the methods M
+    template<int M> class iface {
+    private:
+        template<int N> friend class iface;
+
+        std::shared_ptr<value> p;
+
+        value *get() const {
+            if (p == nullptr) {
+                panic("runtime error: invalid memory address or nil pointer dereference");
+            }
+            return p.get();
+        }
+
+    public:
+        iface() {
+        }
+
+        iface(std::nullptr_t) {
+        }
+
+        template<class T, typename std::enable_if<!is_iface<T>::value && (methods<T>() & M) == M, int>::type = 0>
+        iface(T v) : p(std::make_shared<impl<T>>(v)) {
+        }
+
+        // a *T (see Ptr in go.h)
+        template<class T, typename std::enable_if<(methods<T *>() & M) == M, int>::type = 0>
+        iface(const Ptr<T>& v) : p(std::make_shared<impl<T *>>(v.get(), v.shared())) {
+        }
+
+        template<int N, typename std::enable_if<N != M && (N & M) == M, int>::type = 0>
+        iface(const iface<N>& i) : p(i.p) {
+        }
+
+        bool operator==(std::nullptr_t) const {
+            return p == nullptr;
+        }
+
+        bool operator!=(std::nullptr_t) const {
+            return p != nullptr;
+        }
+
+        template<int N> bool operator==(const iface<N>& i) const {
+            return p == i.p;
+        }
+
+        template<int N> bool operator!=(const iface<N>& i) const {
+            return p != i.p;
+        }
+
+        // the dynamic value, if it's a T (as for a type assertion)
+        template<class T> T *As() const {
+            if (p == nullptr || p->type() != typeid(T)) {
+                return nullptr;
+            }
+            return static_cast<T *>(p->ptr());
+        }
+
+        template<int N = M, typename std::enable_if<(N & READ) != 0, int>::type = 0>
+        std::tuple<int, error> Read(const Slice<byte>& b) const {
+            return get()->Read(b);
+        }
+
+        template<int N = M, typename std::enable_if<(N & WRITE) != 0, int>::type = 0>
+        std::tuple<int, error> Write(const Slice<byte>& b) const {
+            return get()->Write(b);
+        }
+
+        template<int N = M, typename std::enable_if<(N & CLOSE) != 0, int>::type = 0>
+        error Close() const {
+            return get()->Close();
+        }
+
+        template<int N = M, typename std::enable_if<(N & SEEK) != 0, int>::type = 0>
+        std::tuple<long long, error> Seek(long long offset, int whence) const {
+            return get()->Seek(offset, whence);
+        }
+    };
+}
+
+typedef detail::iface<detail::READ> Reader;
+typedef detail::iface<detail::WRITE> Writer;
+typedef detail::iface<detail::CLOSE> Closer;
+typedef detail::iface<detail::SEEK> Seeker;
+typedef detail::iface<detail::READ | detail::WRITE> ReadWriter;
+typedef detail::iface<detail::READ | detail::CLOSE> ReadCloser;
+typedef detail::iface<detail::WRITE | detail::CLOSE> WriteCloser;
+typedef detail::iface<detail::READ | detail::WRITE | detail::CLOSE> ReadWriteCloser;
+typedef detail::iface<detail::READ | detail::SEEK> ReadSeeker;
+typedef detail::iface<detail::WRITE | detail::SEEK> WriteSeeker;
+typedef detail::iface<detail::READ | detail::WRITE | detail::SEEK> ReadWriteSeeker;
+
+// ReadAtLeast reads into buf until it has read at least min bytes
+inline std::tuple<int, error> ReadAtLeast(Reader r, Slice<byte> buf, int min) {
+    if (buf.len() < min) {
+        return std::make_tuple(0, ErrShortBuffer);
+    }
+
+    int n = 0;
+    error err;
+    while (n < min && err == nullptr) {
+        int nn;
+        std::tie(nn, err) = r.Read(buf(n));
+        n += nn;
+    }
+
+    if (n >= min) {
+        err = nullptr;
+    } else if (n > 0 && err == EOF_) {
+        err = ErrUnexpectedEOF;
+    }
+    return std::make_tuple(n, err);
+}
+
+// ReadFull reads exactly len(buf) bytes (EOF if none were read, ErrUnexpectedEOF if only some were)
+inline std::tuple<int, error> ReadFull(Reader r, Slice<byte> buf) {
+    return ReadAtLeast(r, buf, buf.len());
+}
+
+// ReadAll reads until EOF (that is not an error)
+inline std::tuple<Slice<byte>, error> ReadAll(Reader r) {
+    Slice<byte> b(0, 512);
+    for (;;) {
+        if (b.len() == b.cap()) {
+            b = append(b, byte(0))(0, b.len()); // grow
+        }
+
+        auto [n, err] = r.Read(b(b.len(), b.cap()));
+        b = b(0, b.len() + n);
+        if (err != nullptr) {
+            if (err == EOF_) {
+                err = nullptr;
+            }
+            return std::make_tuple(b, err);
+        }
+    }
+}
+
+// WriteString writes s to w
+inline std::tuple<int, error> WriteString(Writer w, const std::string& s) {
+    return w.Write(Slice<byte>(s));
+}
+
+// CopyN copies n bytes (or until EOF, if n < 0) from src to dst and returns the number of bytes copied
+inline std::tuple<long long, error> CopyN(Writer dst, Reader src, long long n) {
+    Slice<byte> buf(32 * 1024);
+    long long written = 0;
+
+    while (n < 0 || written < n) {
+        Slice<byte> b = buf;
+        if (n >= 0 && n - written < buf.len()) {
+            b = buf(0, int(n - written));
+        }
+
+        auto [nr, rerr] = src.Read(b);
+        if (nr > 0) {
+            auto [nw, werr] = dst.Write(b(0, nr));
+            written += nw;
+            if (werr != nullptr) {
+                return std::make_tuple(written, werr);
+            }
+            if (nw != nr) {
+                return std::make_tuple(written, ErrShortWrite);
+            }
+        }
+
+        if (rerr != nullptr) {
+            if (rerr == EOF_ && n >= 0) {
+                return std::make_tuple(written, EOF_);
+            }
+            return std::make_tuple(written, rerr == EOF_ ? error() : rerr);
+        }
+    }
+
+    return std::make_tuple(written, error());
+}
+
+// Copy copies from src to dst until EOF (that is not an error)
+inline std::tuple<long long, error> Copy(Writer dst, Reader src) {
+    return CopyN(dst, src, -1);
+}
+
+namespace detail {
+    struct discard {
+        std::tuple<int, error> Write(const Slice<byte>& p) {
+            return std::make_tuple(p.len(), error());
+        }
+    };
+
+    struct nopCloser {
+        Reader r;
+
+        std::tuple<int, error> Read(const Slice<byte>& p) {
+            return r.Read(p);
+        }
+
+        error Close() {
+            return nullptr;
+        }
+    };
+}
+
+// Discard is a Writer on which all the writes succeed
+inline const Writer Discard = detail::discard();
+
+// NopCloser returns a ReadCloser with a Close method that does nothing
+inline ReadCloser NopCloser(Reader r) {
+    return detail::nopCloser{r};
+}
+
+}
+
+#endif
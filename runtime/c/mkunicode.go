@@ -0,0 +1,99 @@
+//go:build ignore
+
+// mkunicode generates unicode_tables.h, the Unicode tables of unicode.h, from the tables of the
+// Go unicode package (so that the C++ runtime classifies and maps the runes as Go does).
+//
+//	go run mkunicode.go > unicode_tables.h
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+var tables = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Letter", unicode.Letter},
+	{"Upper", unicode.Upper},
+	{"Lower", unicode.Lower},
+	{"Title", unicode.Title},
+	{"Mark", unicode.Mark},
+	{"Digit", unicode.Digit},
+	{"Number", unicode.Number},
+	{"Punct", unicode.Punct},
+	{"Symbol", unicode.Symbol},
+	{"Space", unicode.Zs},
+	{"WhiteSpace", unicode.White_Space},
+	{"Control", unicode.Cc},
+}
+
+func main() {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by mkunicode.go from the Go unicode tables (version %s). DO NOT EDIT.\n\n", unicode.Version)
+	fmt.Fprintln(&b, "#ifndef _GO_RUNTIME_UNICODE_TABLES_H")
+	fmt.Fprintln(&b, "#define _GO_RUNTIME_UNICODE_TABLES_H 1")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "namespace unicode {")
+	fmt.Fprintln(&b, "    namespace detail {")
+
+	for _, t := range tables {
+		var ranges []string
+		for _, r := range t.table.R16 {
+			ranges = append(ranges, fmt.Sprintf("{0x%04X, 0x%04X, %d}", r.Lo, r.Hi, r.Stride))
+		}
+		for _, r := range t.table.R32 {
+			ranges = append(ranges, fmt.Sprintf("{0x%04X, 0x%04X, %d}", r.Lo, r.Hi, r.Stride))
+		}
+
+		fmt.Fprintf(&b, "\n        inline const Range %s[] = {\n", t.name)
+		printLines(&b, ranges, 4)
+		fmt.Fprintln(&b, "        };")
+	}
+
+	var cases []string
+	for _, c := range unicode.CaseRanges {
+		cases = append(cases, fmt.Sprintf("{0x%04X, 0x%04X, {%d, %d, %d}}", c.Lo, c.Hi, c.Delta[0], c.Delta[1], c.Delta[2]))
+	}
+
+	fmt.Fprintln(&b, "\n        inline const CaseRange CaseRanges[] = {")
+	printLines(&b, cases, 3)
+	fmt.Fprintln(&b, "        };")
+
+	var orbit []string
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		// the runes that SimpleFold does not map to their lower (or upper) case
+		if f := unicode.SimpleFold(r); f != fold(r) {
+			orbit = append(orbit, fmt.Sprintf("{0x%04X, 0x%04X}", r, f))
+		}
+	}
+
+	fmt.Fprintln(&b, "\n        inline const FoldPair FoldOrbit[] = {")
+	printLines(&b, orbit, 6)
+	fmt.Fprintln(&b, "        };")
+
+	fmt.Fprintln(&b, "    }")
+	fmt.Fprintln(&b, "}")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "#endif")
+
+	os.Stdout.WriteString(b.String())
+}
+
+func fold(r rune) rune {
+	if l := unicode.ToLower(r); l != r {
+		return l
+	}
+	return unicode.ToUpper(r)
+}
+
+func printLines(b *strings.Builder, items []string, perLine int) {
+	for i := 0; i < len(items); i += perLine {
+		end := min(i+perLine, len(items))
+		fmt.Fprintf(b, "            %s,\n", strings.Join(items[i:end], ", "))
+	}
+}
@@ -0,0 +1,411 @@
+#ifndef _GO_RUNTIME_OS_H
+#define _GO_RUNTIME_OS_H 1
+
+#include <go.h>
+#include <io.h>
+#include <cerrno>
+#include <cstring>
+#include <fcntl.h>
+#include <unistd.h>
+#include <sys/stat.h>
+
+//
+// os: File is a POSIX file descriptor, with the io Read, Write, Seek and Close methods.
+//
+// The errors are Go errors: *fs.PathError (open foo: no such file or directory) wrapping a syscall.Errno,
+// that matches ErrNotExist, ErrExist and ErrPermission with errors.Is.
+// The flags of OpenFile are the POSIX ones (os.O_CREATE is O_CREAT, see mappings.json).
+// Args (in go.h) is set at the start of main.
+//
+
+namespace os {
+
+typedef uint32 FileMode;
+
+const FileMode ModePerm = 0777;
+
+inline const error ErrInvalid = error("invalid argument");
+inline const error ErrPermission = error("permission denied");
+inline const error ErrExist = error("file already exists");
+inline const error ErrNotExist = error("file does not exist");
+inline const error ErrClosed = error("file already closed");
+
+// Errno is a system call error number (syscall.Errno)
+struct Errno {
+    static constexpr const char *_type = "syscall.Errno";
+    int e = 0;
+
+    std::string Error() const {
+        std::string s = std::strerror(e);
+        if (!s.empty()) {
+            s[0] = std::tolower(s[0]); // as the Go messages
+        }
+        return s;
+    }
+
+    bool Is(const error& target) const {
+        if (target == ErrPermission) {
+            return e == EACCES || e == EPERM;
+        } else if (target == ErrExist) {
+            return e == EEXIST || e == ENOTEMPTY;
+        } else if (target == ErrNotExist) {
+            return e == ENOENT;
+        }
+        return false;
+    }
+
+    bool operator==(const Errno& other) const {
+        return e == other.e;
+    }
+};
+
+// PathError records an error and the operation and file path that caused it (*fs.PathError)
+struct PathError {
+    static constexpr const char *_type = "fs.PathError";
+    std::string Op;
+    std::string Path;
+    error Err;
+
+    std::string Error() const {
+        return Op + " " + Path + ": " + Err.Error();
+    }
+
+    error Unwrap() const {
+        return Err;
+    }
+};
+
+// LinkError records an error during a link or rename (*os.LinkError)
+struct LinkError {
+    static constexpr const char *_type = "os.LinkError";
+    std::string Op;
+    std::string Old;
+    std::string New;
+    error Err;
+
+    std::string Error() const {
+        return Op + " " + Old + " " + New + ": " + Err.Error();
+    }
+
+    error Unwrap() const {
+        return Err;
+    }
+};
+
+namespace detail {
+    inline error pathError(std::string op, std::string path, error err) {
+        return error(std::make_shared<PathError>(PathError{op, path, err}));
+    }
+
+    // the error of the last system call
+    inline error errnoError(std::string op, std::string path) {
+        return pathError(op, path, Errno{errno});
+    }
+
+    // the dynamic value of err, if it's a T
+    template<class T> T *as(const error& err) {
+        if (auto i = err._iface(); i != nullptr && i->type() == typeid(T)) {
+            return static_cast<T *>(i->value());
+        }
+        return nullptr;
+    }
+
+    // the error wrapped by a *PathError or a *LinkError
+    inline error underlying(error err) {
+        if (auto pe = as<PathError *>(err)) {
+            return (*pe)->Err;
+        } else if (auto le = as<LinkError *>(err)) {
+            return (*le)->Err;
+        }
+        return err;
+    }
+
+    inline bool matches(error err, error target) {
+        err = underlying(err);
+        if (auto e = as<Errno>(err)) {
+            return e->Is(target);
+        }
+        return err == target;
+    }
+}
+
+// File is an open file descriptor (*os.File)
+class File {
+private:
+    int fd;
+    std::string name;
+
+public:
+    File(int fd, std::string name) : fd(fd), name(name) {
+    }
+
+    std::string Name() const {
+        return name;
+    }
+
+    uintptr Fd() const {
+        return uintptr(fd);
+    }
+
+    std::tuple<int, error> Read(const Slice<byte>& b) {
+        if (fd < 0) {
+            return std::make_tuple(0, detail::pathError("read", name, ErrClosed));
+        } else if (b.len() == 0) {
+            return std::make_tuple(0, error());
+        }
+
+        for (;;) {
+            ssize_t n = ::read(fd, b.data(), b.len());
+            if (n > 0) {
+                return std::make_tuple(int(n), error());
+            } else if (n == 0) {
+                return std::make_tuple(0, io::EOF_);
+            } else if (errno != EINTR) {
+                return std::make_tuple(0, detail::errnoError("read", name));
+            }
+        }
+    }
+
+    std::tuple<int, error> Write(const Slice<byte>& b) {
+        if (fd < 0) {
+            return std::make_tuple(0, detail::pathError("write", name, ErrClosed));
+        }
+
+        if (fd == 1 || fd == 2) {
+            std::cout.flush(); // keep the order of the output of fmt
+        }
+
+        int written = 0;
+        while (written < b.len()) {
+            ssize_t n = ::write(fd, b.data() + written, b.len() - written);
+            if (n < 0) {
+                if (errno == EINTR) {
+                    continue;
+                }
+                return std::make_tuple(written, detail::errnoError("write", name));
+            }
+            written += n;
+        }
+        return std::make_tuple(written, error());
+    }
+
+    std::tuple<int, error> WriteString(const std::string& s) {
+        return Write(Slice<byte>(s));
+    }
+
+    std::tuple<long long, error> Seek(long long offset, int whence) {
+        if (fd < 0) {
+            return std::make_tuple(0LL, detail::pathError("seek", name, ErrClosed));
+        }
+
+        off_t pos = ::lseek(fd, offset, whence);
+        if (pos < 0) {
+            return std::make_tuple(0LL, detail::errnoError("seek", name));
+        }
+        return std::make_tuple((long long)pos, error());
+    }
+
+    error Sync() {
+        if (fd < 0) {
+            return detail::pathError("sync", name, ErrClosed);
+        } else if (::fsync(fd) < 0) {
+            return detail::errnoError("sync", name);
+        }
+        return nullptr;
+    }
+
+    error Close() {
+        if (fd < 0) {
+            return detail::pathError("close", name, ErrClosed);
+        }
+
+        int err = ::close(fd);
+        fd = -1;
+        if (err < 0) {
+            return detail::errnoError("close", name);
+        }
+        return nullptr;
+    }
+};
+
+inline File *Stdin = new File(0, "/dev/stdin");
+inline File *Stdout = new File(1, "/dev/stdout");
+inline File *Stderr = new File(2, "/dev/stderr");
+
+// OpenFile opens a file with the flags (O_RDONLY, O_CREAT, ...) and the permissions for a new file
+inline std::tuple<File *, error> OpenFile(const std::string& name, int flag, FileMode perm) {
+    int fd;
+    do {
+        fd = ::open(name.c_str(), flag | O_CLOEXEC, perm);
+    } while (fd < 0 && errno == EINTR);
+
+    if (fd < 0) {
+        return std::make_tuple(nullptr, detail::errnoError("open", name));
+    }
+    return std::make_tuple(new File(fd, name), error());
+}
+
+// Open opens a file for reading
+inline std::tuple<File *, error> Open(const std::string& name) {
+    return OpenFile(name, O_RDONLY, 0);
+}
+
+// Create creates (or truncates) a file for reading and writing
+inline std::tuple<File *, error> Create(const std::string& name) {
+    return OpenFile(name, O_RDWR | O_CREAT | O_TRUNC, 0666);
+}
+
+// ReadFile returns the content of a file
+inline std::tuple<Slice<byte>, error> ReadFile(const std::string& name) {
+    auto [f, err] = Open(name);
+    if (err != nullptr) {
+        return std::make_tuple(Slice<byte>(), err);
+    }
+
+    auto [data, rerr] = io::ReadAll(f);
+    f->Close();
+    delete f;
+    return std::make_tuple(data, rerr);
+}
+
+// WriteFile writes data to a file, created with perm if it doesn't exist
+inline error WriteFile(const std::string& name, const Slice<byte>& data, FileMode perm) {
+    auto [f, err] = OpenFile(name, O_WRONLY | O_CREAT | O_TRUNC, perm);
+    if (err != nullptr) {
+        return err;
+    }
+
+    std::tie(std::ignore, err) = f->Write(data);
+    if (auto cerr = f->Close(); err == nullptr) {
+        err = cerr;
+    }
+    delete f;
+    return err;
+}
+
+// Remove removes a file or an empty directory
+inline error Remove(const std::string& name) {
+    if (::unlink(name.c_str()) == 0) {
+        return nullptr;
+    }
+
+    int e = errno;
+    if (::rmdir(name.c_str()) == 0) {
+        return nullptr;
+    }
+    if (errno != ENOTDIR) {
+        e = errno;
+    }
+    return detail::pathError("remove", name, Errno{e});
+}
+
+// Rename renames (moves) a file
+inline error Rename(const std::string& oldpath, const std::string& newpath) {
+    if (::rename(oldpath.c_str(), newpath.c_str()) < 0) {
+        return error(std::make_shared<LinkError>(LinkError{"rename", oldpath, newpath, Errno{errno}}));
+    }
+    return nullptr;
+}
+
+// Mkdir creates a directory
+inline error Mkdir(const std::string& name, FileMode perm) {
+    if (::mkdir(name.c_str(), perm) < 0) {
+        return detail::errnoError("mkdir", name);
+    }
+    return nullptr;
+}
+
+// MkdirAll creates a directory and its missing parents
+inline error MkdirAll(const std::string& path, FileMode perm) {
+    struct stat st;
+    if (::stat(path.c_str(), &st) == 0) {
+        if (S_ISDIR(st.st_mode)) {
+            return nullptr;
+        }
+        return detail::pathError("mkdir", path, Errno{ENOTDIR});
+    }
+
+    auto i = path.find_last_not_of('/');
+    if (i != std::string::npos) {
+        auto j = path.find_last_of('/', i);
+        if (j != std::string::npos && j > 0) {
+            if (auto err = MkdirAll(path.substr(0, j), perm); err != nullptr) {
+                return err;
+            }
+        }
+    }
+
+    if (auto err = Mkdir(path, perm); err != nullptr) {
+        // the directory may have been created in the meantime
+        if (::stat(path.c_str(), &st) == 0 && S_ISDIR(st.st_mode)) {
+            return nullptr;
+        }
+        return err;
+    }
+    return nullptr;
+}
+
+// Getwd returns the current directory
+inline std::tuple<std::string, error> Getwd() {
+    char buf[4096];
+    if (::getcwd(buf, sizeof(buf)) == nullptr) {
+        return std::make_tuple(std::string(), detail::pathError("getwd", ".", Errno{errno}));
+    }
+    return std::make_tuple(std::string(buf), error());
+}
+
+// TempDir returns $TMPDIR, or /tmp
+inline std::string TempDir() {
+    const char *dir = std::getenv("TMPDIR");
+    return dir != nullptr && *dir != '\0' ? dir : "/tmp";
+}
+
+inline std::string Getenv(const std::string& key) {
+    const char *v = std::getenv(key.c_str());
+    return v != nullptr ? v : "";
+}
+
+inline std::tuple<std::string, bool> LookupEnv(const std::string& key) {
+    const char *v = std::getenv(key.c_str());
+    return std::make_tuple(std::string(v != nullptr ? v : ""), v != nullptr);
+}
+
+inline error Setenv(const std::string& key, const std::string& value) {
+    if (::setenv(key.c_str(), value.c_str(), 1) < 0) {
+        return error(std::make_shared<PathError>(PathError{"setenv", key, Errno{errno}}));
+    }
+    return nullptr;
+}
+
+inline error Unsetenv(const std::string& key) {
+    ::unsetenv(key.c_str());
+    return nullptr;
+}
+
+inline int Getpid() {
+    return ::getpid();
+}
+
+// Exit exits the program with the status code, without running the deferred calls
+[[noreturn]] inline void Exit(int code) {
+    std::cout.flush();
+    std::cerr.flush();
+    std::_Exit(code);
+}
+
+// IsNotExist, IsExist and IsPermission check the errors returned by the functions of os
+inline bool IsNotExist(error err) {
+    return detail::matches(err, ErrNotExist);
+}
+
+inline bool IsExist(error err) {
+    return detail::matches(err, ErrExist);
+}
+
+inline bool IsPermission(error err) {
+    return detail::matches(err, ErrPermission);
+}
+
+}
+
+#endif
@@ -0,0 +1,991 @@
+#ifndef _GO_RUNTIME_STRCONV_H
+#define _GO_RUNTIME_STRCONV_H 1
+
+#include <go.h>
+#include <utf8.h>
+#include <unicode.h>
+#include <cmath>
+#include <cstdio>
+#include <cstdlib>
+#include <cstring>
+#include <optional>
+
+//
+// strconv: conversions to and from the string representation of the basic types.
+//
+// The parse functions accept the Go syntax (with the base prefixes and the underscores of ParseInt
+// with base 0, the hexadecimal floats, "inf" and "nan") and fail with the Go errors, i.e.
+// strconv.Atoi: parsing "x": invalid syntax.
+//
+
+namespace strconv {
+
+    const int IntSize = sizeof(int) * 8;
+
+    inline const error ErrRange = error("value out of range");
+    inline const error ErrSyntax = error("invalid syntax");
+
+    // NumError records a failed conversion (the parse functions return a *NumError)
+    struct NumError {
+        static constexpr const char *_type = "strconv.NumError";
+        std::string Func; // the failing function (ParseBool, ParseInt, ParseUint, ParseFloat, Atoi)
+        std::string Num;  // the input
+        error Err;        // the reason the conversion failed (ErrRange, ErrSyntax, etc.)
+
+        std::string Error() const;
+
+        error Unwrap() const {
+            return Err;
+        }
+    };
+
+    inline bool IsPrint(rune r) {
+        return unicode::IsPrint(r);
+    }
+
+    inline bool IsGraphic(rune r) {
+        return unicode::IsGraphic(r);
+    }
+
+    namespace detail {
+
+        inline const char *lowerhex = "0123456789abcdef";
+        inline const char *upperhex = "0123456789ABCDEF";
+
+        inline char lower(char c) {
+            return c | ('x' - 'X');
+        }
+
+        inline void appendEscapedRune(std::string& buf, rune r, char quote, bool ascii, bool graphic) {
+            if (r == quote || r == '\\') {
+                buf += '\\';
+                buf += char(r);
+                return;
+            }
+
+            if (ascii) {
+                if (r < utf8::RuneSelf && IsPrint(r)) {
+                    buf += char(r);
+                    return;
+                }
+            } else if (IsPrint(r) || (graphic && IsGraphic(r))) {
+                buf += utf8::detail::encode(r);
+                return;
+            }
+
+            switch (r) {
+            case '\a': buf += "\\a"; return;
+            case '\b': buf += "\\b"; return;
+            case '\f': buf += "\\f"; return;
+            case '\n': buf += "\\n"; return;
+            case '\r': buf += "\\r"; return;
+            case '\t': buf += "\\t"; return;
+            case '\v': buf += "\\v"; return;
+            }
+
+            if (r < ' ' || r == 0x7F) {
+                buf += "\\x";
+                buf += lowerhex[byte(r) >> 4];
+                buf += lowerhex[byte(r) & 0xF];
+                return;
+            }
+
+            if (!utf8::ValidRune(r)) {
+                r = utf8::RuneError;
+            }
+
+            int n = r < 0x10000 ? 4 : 8;
+            buf += r < 0x10000 ? "\\u" : "\\U";
+            for (int s = (n - 1) * 4; s >= 0; s -= 4) {
+                buf += lowerhex[(r >> s) & 0xF];
+            }
+        }
+
+        inline std::string quoteWith(const std::string& s, char quote, bool ascii, bool graphic) {
+            std::string buf(1, quote);
+            for (size_t i = 0; i < s.size();) {
+                auto [r, width] = utf8::detail::decodeAt(s, i);
+                if (width == 1 && r == utf8::RuneError) {
+                    byte b = s[i];
+                    buf += "\\x";
+                    buf += lowerhex[b >> 4];
+                    buf += lowerhex[b & 0xF];
+                } else {
+                    appendEscapedRune(buf, r, quote, ascii, graphic);
+                }
+                i += width;
+            }
+            return buf + quote;
+        }
+
+        inline std::string quoteRuneWith(rune r, bool ascii, bool graphic) {
+            if (!utf8::ValidRune(r)) {
+                r = utf8::RuneError;
+            }
+            std::string buf = "'";
+            appendEscapedRune(buf, r, '\'', ascii, graphic);
+            return buf + "'";
+        }
+
+        // underscoreOK reports whether the underscores in s only separate digits (or a base prefix and a digit)
+        inline bool underscoreOK(std::string s) {
+            char saw = '^';
+            size_t i = 0;
+
+            if (s.size() >= 1 && (s[0] == '-' || s[0] == '+')) {
+                s = s.substr(1);
+            }
+
+            bool hex = false;
+            if (s.size() >= 2 && s[0] == '0' && (lower(s[1]) == 'b' || lower(s[1]) == 'o' || lower(s[1]) == 'x')) {
+                i = 2;
+                saw = '0';
+                hex = lower(s[1]) == 'x';
+            }
+
+            for (; i < s.size(); i++) {
+                if ((s[i] >= '0' && s[i] <= '9') || (hex && lower(s[i]) >= 'a' && lower(s[i]) <= 'f')) {
+                    saw = '0';
+                    continue;
+                }
+                if (s[i] == '_') {
+                    if (saw != '0') {
+                        return false;
+                    }
+                    saw = '_';
+                    continue;
+                }
+                if (saw == '_') {
+                    return false;
+                }
+                saw = '!';
+            }
+            return saw != '_';
+        }
+
+        inline NumError syntaxError(const std::string& fn, const std::string& s) {
+            return NumError{fn, s, ErrSyntax};
+        }
+
+        inline NumError rangeError(const std::string& fn, const std::string& s) {
+            return NumError{fn, s, ErrRange};
+        }
+
+        inline error toError(const std::optional<NumError>& err) {
+            return err ? error(std::make_shared<NumError>(*err)) : error();
+        }
+
+        inline std::optional<NumError> parseUint(const std::string& s0, int base, int bitSize, uint64& n) {
+            const std::string fn = "ParseUint";
+            n = 0;
+
+            if (s0.empty()) {
+                return syntaxError(fn, s0);
+            }
+
+            bool base0 = base == 0;
+            std::string s = s0;
+
+            if (base == 0) {
+                base = 10;
+                if (s[0] == '0') {
+                    if (s.size() >= 3 && lower(s[1]) == 'b') {
+                        base = 2;
+                        s = s.substr(2);
+                    } else if (s.size() >= 3 && lower(s[1]) == 'o') {
+                        base = 8;
+                        s = s.substr(2);
+                    } else if (s.size() >= 3 && lower(s[1]) == 'x') {
+                        base = 16;
+                        s = s.substr(2);
+                    } else {
+                        base = 8;
+                        s = s.substr(1);
+                    }
+                }
+            } else if (base < 2 || base > 36) {
+                return NumError{fn, s0, error("invalid base " + std::to_string(base))};
+            }
+
+            if (bitSize == 0) {
+                bitSize = IntSize;
+            } else if (bitSize < 0 || bitSize > 64) {
+                return NumError{fn, s0, error("invalid bit size " + std::to_string(bitSize))};
+            }
+
+            uint64 cutoff = UINT64_MAX / base + 1;
+            uint64 maxVal = bitSize == 64 ? UINT64_MAX : (uint64(1) << bitSize) - 1;
+            bool underscores = false;
+
+            for (char c : s) {
+                int d;
+                if (c == '_' && base0) {
+                    underscores = true;
+                    continue;
+                } else if (c >= '0' && c <= '9') {
+                    d = c - '0';
+                } else if (lower(c) >= 'a' && lower(c) <= 'z') {
+                    d = lower(c) - 'a' + 10;
+                } else {
+                    return syntaxError(fn, s0);
+                }
+
+                if (d >= base) {
+                    n = 0;
+                    return syntaxError(fn, s0);
+                }
+
+                if (n >= cutoff) {
+                    n = maxVal;
+                    return rangeError(fn, s0);
+                }
+                n *= base;
+
+                uint64 n1 = n + d;
+                if (n1 < n || n1 > maxVal) {
+                    n = maxVal;
+                    return rangeError(fn, s0);
+                }
+                n = n1;
+            }
+
+            if (underscores && !underscoreOK(s0)) {
+                n = 0;
+                return syntaxError(fn, s0);
+            }
+
+            return std::nullopt;
+        }
+
+        inline std::optional<NumError> parseInt(const std::string& fn, const std::string& s0, int base, int bitSize, int64& n) {
+            n = 0;
+
+            if (s0.empty()) {
+                return syntaxError(fn, s0);
+            }
+
+            std::string s = s0;
+            bool neg = false;
+            if (s[0] == '+') {
+                s = s.substr(1);
+            } else if (s[0] == '-') {
+                neg = true;
+                s = s.substr(1);
+            }
+
+            uint64 un;
+            auto err = parseUint(s, base, bitSize, un);
+            if (err && !(err->Err == ErrRange)) {
+                err->Func = fn;
+                err->Num = s0;
+                return err;
+            }
+
+            if (bitSize == 0) {
+                bitSize = IntSize;
+            }
+
+            uint64 cutoff = uint64(1) << (bitSize - 1);
+            if (!neg && un >= cutoff) {
+                n = int64(cutoff - 1);
+                return rangeError(fn, s0);
+            }
+            if (neg && un > cutoff) {
+                n = -int64(cutoff);
+                return rangeError(fn, s0);
+            }
+
+            n = neg ? int64(0 - un) : int64(un);
+            return std::nullopt;
+        }
+
+        inline size_t prefixIgnoreCase(const std::string& s, size_t i, const char *prefix) {
+            size_t n = 0;
+            while (prefix[n] && i + n < s.size() && lower(s[i + n]) == prefix[n]) {
+                n++;
+            }
+            return n;
+        }
+
+        // special parses inf, infinity and nan (case insensitive), returning the number of bytes consumed
+        inline size_t special(const std::string& s, double& f) {
+            if (s.empty()) {
+                return 0;
+            }
+
+            size_t nsign = 0;
+            double sign = 1;
+            if (s[0] == '+' || s[0] == '-') {
+                sign = s[0] == '-' ? -1 : 1;
+                nsign = 1;
+            }
+
+            if (nsign < s.size() && lower(s[nsign]) == 'i') {
+                size_t n = prefixIgnoreCase(s, nsign, "infinity");
+                if (n > 3 && n < 8) {
+                    n = 3;
+                }
+                if (n == 3 || n == 8) {
+                    f = sign * INFINITY;
+                    return nsign + n;
+                }
+            } else if (nsign == 0 && lower(s[0]) == 'n' && prefixIgnoreCase(s, 0, "nan") == 3) {
+                f = NAN;
+                return 3;
+            }
+
+            return 0;
+        }
+
+        // validFloat reports whether s is a decimal or hexadecimal floating-point literal
+        inline bool validFloat(const std::string& s) {
+            size_t i = 0;
+            if (i < s.size() && (s[i] == '+' || s[i] == '-')) {
+                i++;
+            }
+
+            bool hex = false;
+            if (i + 2 <= s.size() && s[i] == '0' && lower(s[i + 1]) == 'x') {
+                hex = true;
+                i += 2;
+            }
+
+            bool sawdot = false, sawdigits = false, underscores = false;
+            for (; i < s.size(); i++) {
+                char c = s[i];
+                if (c == '_') {
+                    underscores = true;
+                } else if (c == '.') {
+                    if (sawdot) {
+                        break;
+                    }
+                    sawdot = true;
+                } else if ((c >= '0' && c <= '9') || (hex && lower(c) >= 'a' && lower(c) <= 'f')) {
+                    sawdigits = true;
+                } else {
+                    break;
+                }
+            }
+
+            if (!sawdigits) {
+                return false;
+            }
+
+            if (i < s.size() && lower(s[i]) == (hex ? 'p' : 'e')) {
+                i++;
+                if (i < s.size() && (s[i] == '+' || s[i] == '-')) {
+                    i++;
+                }
+                bool exp = false;
+                for (; i < s.size() && ((s[i] >= '0' && s[i] <= '9') || s[i] == '_'); i++) {
+                    if (s[i] == '_') {
+                        underscores = true;
+                    } else {
+                        exp = true;
+                    }
+                }
+                if (!exp) {
+                    return false;
+                }
+            } else if (hex) {
+                return false; // the exponent is required
+            }
+
+            return i == s.size() && (!underscores || underscoreOK(s));
+        }
+
+        // decimal returns the digits (without the trailing zeros) and the decimal point position of a %e number
+        inline std::pair<std::string, int> decimal(const std::string& s) {
+            size_t e = s.find('e');
+            int exp = std::atoi(s.c_str() + e + 1);
+
+            std::string digits;
+            for (size_t i = 0; i < e; i++) {
+                if (s[i] >= '0' && s[i] <= '9') {
+                    digits += s[i];
+                }
+            }
+            while (digits.size() > 1 && digits.back() == '0') {
+                digits.pop_back();
+            }
+
+            return {digits, digits == "0" ? 1 : exp + 1};
+        }
+
+        // the shortest decimal digits that represent v (as a float64 or a float32), and the decimal point position
+        inline std::pair<std::string, int> shortest(double v, bool is32) {
+            char buf[64];
+            for (int prec = 0; prec < 17; prec++) {
+                std::snprintf(buf, sizeof(buf), "%.*e", prec, v);
+                if (is32 ? std::strtof(buf, nullptr) == float(v) : std::strtod(buf, nullptr) == v) {
+                    break;
+                }
+            }
+            return decimal(buf);
+        }
+
+        // the digits rounded to prec significant digits
+        inline std::pair<std::string, int> rounded(double v, int prec) {
+            char buf[512];
+            std::snprintf(buf, sizeof(buf), "%.*e", prec - 1, v);
+            return decimal(buf);
+        }
+
+        // %e formatting of the digits, with prec digits after the decimal point
+        inline std::string fmtE(const std::string& digits, int dp, int prec, char fmt) {
+            std::string s(1, digits[0]);
+            if (prec > 0) {
+                s += '.';
+                for (int i = 1; i <= prec; i++) {
+                    s += i < (int) digits.size() ? digits[i] : '0';
+                }
+            }
+
+            int exp = digits == "0" ? 0 : dp - 1;
+            char buf[16];
+            std::snprintf(buf, sizeof(buf), "%c%c%02d", fmt, exp < 0 ? '-' : '+', std::abs(exp));
+            return s + buf;
+        }
+
+        // %f formatting of the digits, with prec digits after the decimal point
+        inline std::string fmtF(const std::string& digits, int dp, int prec) {
+            int nd = digits.size();
+            std::string s;
+
+            if (dp > 0) {
+                for (int i = 0; i < dp; i++) {
+                    s += i < nd ? digits[i] : '0';
+                }
+            } else {
+                s = "0";
+            }
+
+            if (prec > 0) {
+                s += '.';
+                for (int i = 0; i < prec; i++) {
+                    int j = dp + i;
+                    s += j >= 0 && j < nd ? digits[j] : '0';
+                }
+            }
+
+            return s;
+        }
+
+        // %g formatting of the digits: %e for the large and small exponents, %f otherwise
+        inline std::string fmtG(const std::string& digits, int dp, int prec, bool shortest, char fmt) {
+            int nd = digits.size();
+            int eprec = prec;
+            if (eprec > nd && nd >= dp) {
+                eprec = nd;
+            }
+            if (shortest) {
+                eprec = 6;
+            }
+
+            int exp = dp - 1;
+            if (exp < -4 || exp >= eprec) {
+                if (prec > nd) {
+                    prec = nd;
+                }
+                return fmtE(digits, dp, prec - 1, fmt + 'e' - 'g');
+            }
+
+            if (prec > dp) {
+                prec = nd;
+            }
+            return fmtF(digits, dp, std::max(prec - dp, 0));
+        }
+
+        // %b formatting: the mantissa and the binary exponent
+        inline std::string fmtB(uint64 mant, int exp, int mantbits) {
+            exp -= mantbits;
+            return std::to_string(mant) + "p" + (exp >= 0 ? "+" : "") + std::to_string(exp);
+        }
+
+        // %x formatting: the hexadecimal mantissa (-1.xxx) and the binary exponent
+        inline std::string fmtX(uint64 mant, int exp, int prec, char fmt, int mantbits) {
+            if (mant == 0) {
+                exp = 0;
+            }
+
+            // shift the digits so that the leading 1 (if any) is at bit 60
+            mant <<= 60 - mantbits;
+            while (mant != 0 && (mant & (uint64(1) << 60)) == 0) {
+                mant <<= 1;
+                exp--;
+            }
+
+            if (prec >= 0 && prec < 15) {
+                int shift = prec * 4;
+                uint64 extra = (mant << shift) & ((uint64(1) << 60) - 1);
+                mant >>= 60 - shift;
+                if ((extra | (mant & 1)) > (uint64(1) << 59)) {
+                    mant++;
+                }
+                mant <<= 60 - shift;
+                if (mant & (uint64(1) << 61)) {
+                    // wrapped around
+                    mant >>= 1;
+                    exp++;
+                }
+            }
+
+            const char *hex = fmt == 'X' ? upperhex : lowerhex;
+
+            std::string s = "0";
+            s += fmt;
+            s += char('0' + ((mant >> 60) & 1));
+
+            mant <<= 4; // remove the leading 0 or 1
+            if (prec < 0 && mant != 0) {
+                s += '.';
+                for (; mant != 0; mant <<= 4) {
+                    s += hex[(mant >> 60) & 15];
+                }
+            } else if (prec > 0) {
+                s += '.';
+                for (int i = 0; i < prec; i++, mant <<= 4) {
+                    s += hex[(mant >> 60) & 15];
+                }
+            }
+
+            char buf[16];
+            std::snprintf(buf, sizeof(buf), "%c%c%02d", fmt == 'X' ? 'P' : 'p', exp < 0 ? '-' : '+', std::abs(exp));
+            return s + buf;
+        }
+    }
+
+    inline std::string NumError::Error() const {
+        return "strconv." + Func + ": parsing " + detail::quoteWith(Num, '"', false, false) + ": " + Err.Error();
+    }
+
+    inline std::string Quote(const std::string& s) {
+        return detail::quoteWith(s, '"', false, false);
+    }
+
+    inline std::string QuoteToASCII(const std::string& s) {
+        return detail::quoteWith(s, '"', true, false);
+    }
+
+    inline std::string QuoteToGraphic(const std::string& s) {
+        return detail::quoteWith(s, '"', false, true);
+    }
+
+    inline std::string QuoteRune(rune r) {
+        return detail::quoteRuneWith(r, false, false);
+    }
+
+    inline std::string QuoteRuneToASCII(rune r) {
+        return detail::quoteRuneWith(r, true, false);
+    }
+
+    inline std::string QuoteRuneToGraphic(rune r) {
+        return detail::quoteRuneWith(r, false, true);
+    }
+
+    // CanBackquote reports whether s can be a raw string literal
+    inline bool CanBackquote(const std::string& s) {
+        for (size_t i = 0; i < s.size();) {
+            auto [r, width] = utf8::detail::decodeAt(s, i);
+            i += width;
+
+            if (width > 1) {
+                if (r == 0xFEFF) {
+                    return false;
+                }
+                continue;
+            }
+            if (r == utf8::RuneError) {
+                return false;
+            }
+            if ((r < ' ' && r != '\t') || r == '`' || r == 0x7F) {
+                return false;
+            }
+        }
+        return true;
+    }
+
+    // UnquoteChar decodes the first character (or escape sequence) of s, in a literal quoted by quote.
+    // It returns the character, whether it is a multibyte UTF-8 character, the rest of s and an error.
+    inline std::tuple<rune, bool, std::string, error> UnquoteChar(const std::string& s, byte quote) {
+        auto fail = std::make_tuple(rune(0), false, std::string(), ErrSyntax);
+
+        if (s.empty()) {
+            return fail;
+        }
+
+        byte c = s[0];
+        if (c == quote && (quote == '\'' || quote == '"')) {
+            return fail;
+        } else if (c >= utf8::RuneSelf) {
+            auto [r, size] = utf8::DecodeRuneInString(s);
+            return {r, true, s.substr(size), error()};
+        } else if (c != '\\') {
+            return {c, false, s.substr(1), error()};
+        }
+
+        // an escape sequence
+        if (s.size() <= 1) {
+            return fail;
+        }
+
+        c = s[1];
+        std::string tail = s.substr(2);
+        rune value = 0;
+        bool multibyte = false;
+
+        switch (c) {
+        case 'a': value = '\a'; break;
+        case 'b': value = '\b'; break;
+        case 'f': value = '\f'; break;
+        case 'n': value = '\n'; break;
+        case 'r': value = '\r'; break;
+        case 't': value = '\t'; break;
+        case 'v': value = '\v'; break;
+
+        case 'x':
+        case 'u':
+        case 'U': {
+            size_t n = c == 'x' ? 2 : c == 'u' ? 4 : 8;
+            if (tail.size() < n) {
+                return fail;
+            }
+            for (size_t j = 0; j < n; j++) {
+                char h = detail::lower(tail[j]);
+                if (h >= '0' && h <= '9') {
+                    value = (value << 4) | (h - '0');
+                } else if (h >= 'a' && h <= 'f') {
+                    value = (value << 4) | (h - 'a' + 10);
+                } else {
+                    return fail;
+                }
+            }
+            tail = tail.substr(n);
+            if (c != 'x') {
+                // \x is a single byte, possibly not UTF-8
+                if (!utf8::ValidRune(value)) {
+                    return fail;
+                }
+                multibyte = true;
+            }
+            break;
+        }
+
+        case '0': case '1': case '2': case '3': case '4': case '5': case '6': case '7':
+            value = c - '0';
+            if (tail.size() < 2) {
+                return fail;
+            }
+            for (int j = 0; j < 2; j++) {
+                int x = tail[j] - '0';
+                if (x < 0 || x > 7) {
+                    return fail;
+                }
+                value = (value << 3) | x;
+            }
+            tail = tail.substr(2);
+            if (value > 255) {
+                return fail;
+            }
+            break;
+
+        case '\\':
+            value = '\\';
+            break;
+
+        case '\'':
+        case '"':
+            if (c != quote) {
+                return fail;
+            }
+            value = c;
+            break;
+
+        default:
+            return fail;
+        }
+
+        return {value, multibyte, tail, error()};
+    }
+
+    // Unquote interprets s as a single-quoted, double-quoted or backquoted Go literal, returning its value
+    inline std::tuple<std::string, error> Unquote(const std::string& s) {
+        auto fail = std::make_tuple(std::string(), ErrSyntax);
+
+        if (s.size() < 2) {
+            return fail;
+        }
+
+        char quote = s[0];
+        size_t end = s.find(quote, 1);
+        if (end == std::string::npos) {
+            return fail;
+        }
+        end++;
+
+        std::string body = s.substr(1, end - 2);
+
+        if (quote == '`') {
+            if (end != s.size()) {
+                return fail;
+            }
+
+            std::string out;
+            for (char c : body) {
+                if (c != '\r') {
+                    out += c;
+                }
+            }
+            return {out, error()};
+        }
+
+        if (quote != '"' && quote != '\'') {
+            return fail;
+        }
+
+        if (body.find('\\') == std::string::npos && body.find('\n') == std::string::npos) {
+            // no escape sequences
+            bool valid;
+            if (quote == '"') {
+                valid = utf8::ValidString(body);
+            } else {
+                auto [r, n] = utf8::DecodeRuneInString(body);
+                valid = size_t(n) == body.size() && n > 0 && (r != utf8::RuneError || n != 1);
+            }
+            if (valid) {
+                return end == s.size() ? std::make_tuple(body, error()) : fail;
+            }
+        }
+
+        std::string in = s.substr(1);
+        std::string buf;
+
+        while (!in.empty() && in[0] != quote) {
+            if (in[0] == '\n') {
+                return fail;
+            }
+
+            auto [r, multibyte, rem, err] = UnquoteChar(in, quote);
+            if (err != nullptr) {
+                return fail;
+            }
+            in = rem;
+
+            if (r < utf8::RuneSelf || !multibyte) {
+                buf += char(r);
+            } else {
+                buf += utf8::detail::encode(r);
+            }
+
+            if (quote == '\'') {
+                break; // a single character
+            }
+        }
+
+        if (in.size() != 1 || in[0] != quote) {
+            return fail;
+        }
+
+        return {buf, error()};
+    }
+
+    inline std::tuple<bool, error> ParseBool(const std::string& str) {
+        if (str == "1" || str == "t" || str == "T" || str == "true" || str == "TRUE" || str == "True") {
+            return {true, error()};
+        }
+        if (str == "0" || str == "f" || str == "F" || str == "false" || str == "FALSE" || str == "False") {
+            return {false, error()};
+        }
+        return {false, detail::toError(detail::syntaxError("ParseBool", str))};
+    }
+
+    inline std::string FormatBool(bool b) {
+        return b ? "true" : "false";
+    }
+
+    inline std::tuple<uint64, error> ParseUint(const std::string& s, int base, int bitSize) {
+        uint64 n;
+        auto err = detail::parseUint(s, base, bitSize, n);
+        return {n, detail::toError(err)};
+    }
+
+    inline std::tuple<int64, error> ParseInt(const std::string& s, int base, int bitSize) {
+        int64 n;
+        auto err = detail::parseInt("ParseInt", s, base, bitSize, n);
+        return {n, detail::toError(err)};
+    }
+
+    inline std::tuple<int, error> Atoi(const std::string& s) {
+        int64 n;
+        auto err = detail::parseInt("Atoi", s, 10, 0, n);
+        return {int(n), detail::toError(err)};
+    }
+
+    // ParseFloat converts s to a float64 (or to the float32 nearest to it when bitSize is 32)
+    inline std::tuple<float64, error> ParseFloat(const std::string& s, int bitSize) {
+        const std::string fn = "ParseFloat";
+
+        double f;
+        if (size_t n = detail::special(s, f); n > 0 && n == s.size()) {
+            return {f, error()};
+        }
+
+        if (!detail::validFloat(s)) {
+            return {0, detail::toError(detail::syntaxError(fn, s))};
+        }
+
+        std::string digits;
+        for (char c : s) {
+            if (c != '_') {
+                digits += c;
+            }
+        }
+
+        if (bitSize == 32) {
+            f = std::strtof(digits.c_str(), nullptr);
+        } else {
+            f = std::strtod(digits.c_str(), nullptr);
+        }
+
+        if (std::isinf(f)) {
+            return {f, detail::toError(detail::rangeError(fn, s))};
+        }
+        return {f, error()};
+    }
+
+    inline std::string FormatUint(uint64 i, int base) {
+        if (base < 2 || base > 36) {
+            panic("strconv: illegal AppendInt/FormatInt base");
+        }
+
+        static const char *digits = "0123456789abcdefghijklmnopqrstuvwxyz";
+
+        std::string s;
+        do {
+            s.insert(s.begin(), digits[i % base]);
+            i /= base;
+        } while (i > 0);
+        return s;
+    }
+
+    inline std::string FormatInt(int64 i, int base) {
+        if (i < 0) {
+            return "-" + FormatUint(uint64(0) - uint64(i), base);
+        }
+        return FormatUint(uint64(i), base);
+    }
+
+    inline std::string Itoa(int i) {
+        return FormatInt(i, 10);
+    }
+
+    // FormatFloat formats f with the format fmt ('b', 'e', 'E', 'f', 'g', 'G', 'x' or 'X') and precision prec
+    // (-1 for the smallest number of digits that represent f exactly), for a float64 or a float32 (bitSize 32)
+    inline std::string FormatFloat(float64 f, byte fmt, int prec, int bitSize) {
+        bool is32 = bitSize == 32;
+        if (is32) {
+            f = float32(f);
+        }
+
+        if (std::isnan(f)) {
+            return "NaN";
+        }
+        if (std::isinf(f)) {
+            return f < 0 ? "-Inf" : "+Inf";
+        }
+
+        bool neg = std::signbit(f);
+        double a = std::fabs(f);
+        std::string s;
+
+        switch (fmt) {
+        case 'b':
+        case 'x':
+        case 'X': {
+            int mantbits = is32 ? 23 : 52;
+            int exp;
+            uint64 mant;
+
+            if (is32) {
+                uint32 bits;
+                float32 v = a;
+                std::memcpy(&bits, &v, sizeof(bits));
+                exp = (bits >> 23) & 0xFF;
+                mant = bits & ((1 << 23) - 1);
+            } else {
+                uint64 bits;
+                std::memcpy(&bits, &a, sizeof(bits));
+                exp = (bits >> 52) & 0x7FF;
+                mant = bits & ((uint64(1) << 52) - 1);
+            }
+
+            if (exp == 0) {
+                exp++; // denormalized
+            } else {
+                mant |= uint64(1) << mantbits;
+            }
+            exp += is32 ? -127 : -1023;
+
+            s = fmt == 'b' ? detail::fmtB(mant, exp, mantbits) : detail::fmtX(mant, exp, prec, fmt, mantbits);
+            break;
+        }
+
+        case 'e':
+        case 'E':
+        case 'f':
+        case 'g':
+        case 'G': {
+            std::pair<std::string, int> digs;
+            bool shortest = prec < 0;
+
+            if (shortest) {
+                digs = detail::shortest(a, is32);
+                switch (fmt) {
+                case 'e': case 'E': prec = std::max((int) digs.first.size() - 1, 0); break;
+                case 'f': prec = std::max((int) digs.first.size() - digs.second, 0); break;
+                default: prec = digs.first.size(); break;
+                }
+            } else if (fmt == 'g' || fmt == 'G') {
+                digs = detail::rounded(a, prec == 0 ? 1 : prec);
+                if (prec == 0) {
+                    prec = 1;
+                }
+            } else {
+                // the digits up to the precision
+                char buf[512];
+                std::snprintf(buf, sizeof(buf), fmt == 'f' ? "%.*f" : "%.*e", std::min(prec, 400), a);
+                s = buf;
+                if (fmt == 'E') {
+                    s[s.find('e')] = 'E';
+                }
+                break;
+            }
+
+            auto& [digits, dp] = digs;
+            if (fmt == 'e' || fmt == 'E') {
+                s = detail::fmtE(digits, dp, prec, fmt);
+            } else if (fmt == 'f') {
+                s = detail::fmtF(digits, dp, prec);
+            } else {
+                s = detail::fmtG(digits, dp, prec, shortest, fmt);
+            }
+            break;
+        }
+
+        default:
+            return std::string("%") + char(fmt);
+        }
+
+        return neg ? "-" + s : s;
+    }
+
+    inline Slice<byte> AppendInt(const Slice<byte>& dst, int64 i, int base) {
+        return appendSlice(dst, FormatInt(i, base));
+    }
+
+    inline Slice<byte> AppendQuote(const Slice<byte>& dst, const std::string& s) {
+        return appendSlice(dst, Quote(s));
+    }
+}
+
+#endif
@@ -1,86 +1,261 @@
 #ifndef _GO_RUNTIME_SYNC_H
 #define _GO_RUNTIME_SYNC_H
 
+#include <go.h>
 #include <mutex>
+#include <shared_mutex>
 #include <condition_variable>
+#include <atomic>
+#include <unordered_map>
+#include <typeindex>
+
+//
+// sync: the goroutines that wait for a lock (or a WaitGroup, or a Cond) are blocked for the scheduler,
+// so that another worker can run the queued goroutines and a deadlock is detected.
+//
+// The misuses that are fatal errors in Go (i.e. unlocking an unlocked Mutex) terminate the program.
+//
 
 namespace sync {
 
-    //
-    // Mutex
-    //
-    class Mutex : private std::mutex {
-        friend class Cond;
-    public:
-        void Lock() {
-            std::mutex::lock();
+    namespace detail {
+        [[noreturn]] inline void fatal(const char *message) {
+            std::cout.flush();
+            std::cerr << "fatal error: " << message << std::endl;
+            std::_Exit(2);
         }
 
-        void Unlock() {
-            std::mutex::unlock();
+        // lock takes the lock of m, blocking the goroutine if it has to wait
+        template<class M> void lock(M& m) {
+            if (!m.try_lock()) {
+                Blocking b;
+                m.lock();
+            }
+        }
+
+        template<class M> void lock_shared(M& m) {
+            if (!m.try_lock_shared()) {
+                Blocking b;
+                m.lock_shared();
+            }
+        }
+
+        // repanic panics again with the value of a panic that has been caught
+        [[noreturn]] inline void repanic(const std::exception_ptr& e) {
+            try {
+                std::rethrow_exception(e);
+            } catch (const Panic& p) {
+                auto& state = panicState();
+                state.value = p.value;
+                state.panicking = true;
+                state.recovered = false;
+                throw;
+            }
         }
+    }
+
+    //
+    // Locker is the interface of the objects that can be locked and unlocked (the Mutex and the RWMutex)
+    //
+    class Locker {
+    public:
+        virtual ~Locker() {}
+        virtual void Lock() = 0;
+        virtual void Unlock() = 0;
     };
 
     //
-    // Condition
+    // Mutex is a mutual exclusion lock. As in Go it's not owned by a goroutine:
+    // it can be unlocked by a goroutine that didn't lock it
     //
-    class Cond {
+    class Mutex : public Locker {
     private:
-        std::condition_variable_any cv;
-    public:
-        Mutex *L;
-        Cond(Mutex &m) : L(&m) {}
+        std::mutex m;
+        std::condition_variable cv;
+        bool locked = false;
 
-        void Wait() {
-            cv.wait(static_cast<std::mutex&>(*L));
+    public:
+        void Lock() override {
+            std::unique_lock<std::mutex> lk(m);
+            if (locked) {
+                Blocking b;
+                while (locked) {
+                    cv.wait(lk);
+                }
+            }
+            locked = true;
         }
 
-        void Signal() {
-            cv.notify_one();
+        bool TryLock() {
+            std::unique_lock<std::mutex> lk(m);
+            if (locked) {
+                return false;
+            }
+            locked = true;
+            return true;
         }
 
-        void Broadcast() {
-            cv.notify_all();
+        void Unlock() override {
+            std::unique_lock<std::mutex> lk(m);
+            if (!locked) {
+                detail::fatal("sync: unlock of unlocked mutex");
+            }
+            locked = false;
+            cv.notify_one();
         }
     };
 
     //
-    // RWMutex is temporary implemented as a regular mutex
+    // RWMutex is a reader/writer mutual exclusion lock: any number of readers or a single writer.
+    // A blocked Lock call excludes the new readers, so that the writer eventually acquires the lock
     //
-    class RWMutex : private std::mutex {
+    class RWMutex : public Locker {
+    private:
+        std::shared_mutex rw;
+        std::mutex writer;           // held by the writer that is waiting for (or holding) the lock
+        std::atomic<int> readers{0};
+        std::atomic<bool> locked{false};
+
+        class rlocker : public Locker {
+            RWMutex *rw;
+        public:
+            rlocker(RWMutex *rw) : rw(rw) {}
+            void Lock() override { rw->RLock(); }
+            void Unlock() override { rw->RUnlock(); }
+        } r{this};
+
     public:
-        void Lock() {
-            std::mutex::lock();
+        void Lock() override {
+            detail::lock(writer);
+            detail::lock(rw);
+            locked = true;
+        }
+
+        bool TryLock() {
+            if (!writer.try_lock()) {
+                return false;
+            }
+            if (!rw.try_lock()) {
+                writer.unlock();
+                return false;
+            }
+            locked = true;
+            return true;
         }
 
-        void Unlock() {
-            std::mutex::unlock();
+        void Unlock() override {
+            if (!locked.exchange(false)) {
+                detail::fatal("sync: Unlock of unlocked RWMutex");
+            }
+            rw.unlock();
+            writer.unlock();
         }
 
         void RLock() {
-            std::mutex::lock();
+            // wait for the pending writer, if any
+            detail::lock(writer);
+            writer.unlock();
+
+            detail::lock_shared(rw);
+            readers++;
+        }
+
+        bool TryRLock() {
+            if (!writer.try_lock()) {
+                return false;
+            }
+            writer.unlock();
+
+            if (!rw.try_lock_shared()) {
+                return false;
+            }
+            readers++;
+            return true;
         }
 
         void RUnlock() {
-            std::mutex::unlock();
+            if (readers.fetch_sub(1) <= 0) {
+                readers++;
+                detail::fatal("sync: RUnlock of unlocked RWMutex");
+            }
+            rw.unlock_shared();
+        }
+
+        // RLocker returns a Locker that calls RLock and RUnlock
+        Locker *RLocker() {
+            return &r;
+        }
+    };
+
+    //
+    // Cond is a condition variable associated with a Locker (L), that must be held when calling Wait.
+    // Signal wakes the goroutine that has been waiting the longest
+    //
+    struct Cond {
+        Locker *L = nullptr;
+
+        std::mutex _m;
+        std::condition_variable _cv;
+        uint64 _waiters = 0;  // the ticket of the next waiter
+        uint64 _notified = 0; // the waiters with a lower ticket have been notified
+
+        void Wait() {
+            std::unique_lock<std::mutex> lk(_m);
+            uint64 ticket = _waiters++;
+            lk.unlock();
+
+            L->Unlock();
+
+            lk.lock();
+            if (_notified <= ticket) {
+                Blocking b;
+                while (_notified <= ticket) {
+                    _cv.wait(lk);
+                }
+            }
+            lk.unlock();
+
+            L->Lock();
+        }
+
+        void Signal() {
+            std::unique_lock<std::mutex> lk(_m);
+            if (_notified < _waiters) {
+                _notified++;
+                _cv.notify_all();
+            }
+        }
+
+        void Broadcast() {
+            std::unique_lock<std::mutex> lk(_m);
+            _notified = _waiters;
+            _cv.notify_all();
         }
     };
 
+    inline Cond *NewCond(Locker *l) {
+        return new Cond{l};
+    }
+
     //
-    // WaitGroup
+    // WaitGroup waits for a collection of goroutines to finish
     //
     class WaitGroup {
     private:
         std::mutex m;
         std::condition_variable cv;
-        int waiters;
+        int counter = 0;
+
     public:
         void Add(int delta) {
             std::unique_lock<std::mutex> lk(m);
-            waiters += delta;
-            if (waiters <= 0) {
-                // this should panic if waiters < 0
-                // but let's start with this
+            counter += delta;
+            if (counter < 0) {
+                counter -= delta;
+                lk.unlock();
+                panic("sync: negative WaitGroup counter");
+            }
+            if (counter == 0) {
                 cv.notify_all();
             }
         }
@@ -91,19 +266,339 @@ namespace sync {
 
         void Wait() {
             std::unique_lock<std::mutex> lk(m);
-            while (waiters > 0) cv.wait(lk);
+            if (counter > 0) {
+                Blocking b;
+                while (counter > 0) {
+                    cv.wait(lk);
+                }
+            }
+        }
+
+        // Go calls f in a new goroutine and adds that goroutine to the WaitGroup
+        void Go(std::function<void()> f) {
+            Add(1);
+            Goroutine([this, f]() {
+                struct done {
+                    WaitGroup *wg;
+                    ~done() { wg->Done(); }
+                } d{this};
+
+                f();
+            });
         }
     };
 
     //
-    // Once
+    // Once performs exactly one action: if the function passed to Do panics, Do considers it returned
+    // and the next calls don't call it again
     //
     class Once {
     private:
-        std::once_flag flag;
+        std::mutex m;
+        std::atomic<bool> done{false};
+
     public:
         void Do(std::function<void()> const& fun) {
-            std::call_once(flag, fun);
+            if (done) {
+                return;
+            }
+
+            detail::lock(m);
+            std::unique_lock<std::mutex> lk(m, std::adopt_lock);
+            if (!done) {
+                struct setDone {
+                    std::atomic<bool>& done;
+                    ~setDone() { done = true; }
+                } set{done};
+
+                fun();
+            }
+        }
+    };
+
+    // OnceFunc returns a function that calls f only once. If f panics, the returned function
+    // panics with the same value every time it's called
+    inline std::function<void()> OnceFunc(std::function<void()> f) {
+        struct state {
+            Once once;
+            std::exception_ptr p;
+        };
+
+        auto s = std::make_shared<state>();
+        return [s, f]() {
+            s->once.Do([&]() {
+                try {
+                    f();
+                } catch (...) {
+                    s->p = std::current_exception();
+                }
+            });
+
+            if (s->p) {
+                detail::repanic(s->p);
+            }
+        };
+    }
+
+    // OnceValue returns a function that calls f only once and returns its value
+    template<class F> auto OnceValue(F f) -> std::function<decltype(f())()> {
+        typedef decltype(f()) T;
+
+        struct state {
+            Once once;
+            T value{};
+            std::exception_ptr p;
+        };
+
+        auto s = std::make_shared<state>();
+        return [s, f]() -> T {
+            s->once.Do([&]() {
+                try {
+                    s->value = f();
+                } catch (...) {
+                    s->p = std::current_exception();
+                }
+            });
+
+            if (s->p) {
+                detail::repanic(s->p);
+            }
+            return s->value;
+        };
+    }
+
+    // OnceValues is OnceValue for a function that returns two values (a tuple)
+    template<class F> auto OnceValues(F f) -> std::function<decltype(f())()> {
+        return OnceValue(f);
+    }
+
+    //
+    // Pool is a set of temporary objects that may be reused. Get returns an object from the pool,
+    // or the result of New (nil if New is not set)
+    //
+    struct Pool {
+        std::function<std::any()> New;
+
+        std::mutex _m;
+        std::vector<std::any> _items;
+
+        std::any Get() {
+            {
+                std::unique_lock<std::mutex> lk(_m);
+                if (!_items.empty()) {
+                    std::any x = std::move(_items.back());
+                    _items.pop_back();
+                    return x;
+                }
+            }
+
+            if (New) {
+                return New();
+            }
+            return std::any();
+        }
+
+        void Put(std::any x) {
+            if (!x.has_value()) {
+                return;
+            }
+
+            std::unique_lock<std::mutex> lk(_m);
+            _items.push_back(std::move(x));
+        }
+    };
+
+    //
+    // Map is a map safe for concurrent use, with keys and values of any type (the keys of different types
+    // are different, as for a Go interface{})
+    //
+    class Map {
+    private:
+        // a key of any comparable type, with its hash
+        struct key {
+            std::any v;
+            size_t hash;
+            bool (*equal)(const std::any& a, const std::any& b);
+
+            bool operator==(const key& k) const {
+                return v.type() == k.v.type() && equal(v, k.v);
+            }
+        };
+
+        struct keyHash {
+            size_t operator()(const key& k) const {
+                return k.hash;
+            }
+        };
+
+        // the values are stored as in Go, i.e. a string literal is a std::string
+        template<class T> static auto normalize(const T& v) {
+            if constexpr (std::is_array<T>::value || std::is_same<T, const char *>::value) {
+                return std::string(v);
+            } else {
+                return v;
+            }
+        }
+
+        template<class T, class = void> struct hashable : std::false_type {};
+        template<class T> struct hashable<T, std::void_t<decltype(std::hash<T>()(std::declval<const T&>()))>> : std::true_type {};
+
+        template<class T> static size_t hashOf(const std::any& a) {
+            size_t h = typeid(T).hash_code();
+            if constexpr (hashable<T>::value) {
+                h ^= std::hash<T>()(*std::any_cast<T>(&a)) + 0x9e3779b9 + (h << 6) + (h >> 2);
+            }
+            return h;
+        }
+
+        template<class T> static bool equalOf(const std::any& a, const std::any& b) {
+            return *std::any_cast<T>(&a) == *std::any_cast<T>(&b);
+        }
+
+        // the functions of the key types, for the keys passed as std::any (i.e. by Range)
+        struct keyType {
+            size_t (*hash)(const std::any& a);
+            bool (*equal)(const std::any& a, const std::any& b);
+        };
+
+        static std::mutex& keyTypesLock() {
+            static std::mutex m;
+            return m;
+        }
+
+        static std::unordered_map<std::type_index, keyType>& keyTypes() {
+            static std::unordered_map<std::type_index, keyType> types;
+            return types;
+        }
+
+        template<class K> static key makeKey(const K& k) {
+            if constexpr (std::is_same<K, std::any>::value) {
+                std::unique_lock<std::mutex> lk(keyTypesLock());
+                auto it = keyTypes().find(k.type());
+                if (it == keyTypes().end()) {
+                    // a type that has never been stored
+                    return key{k, 0, [](const std::any&, const std::any&) { return false; }};
+                }
+                return key{k, it->second.hash(k), it->second.equal};
+            } else {
+                typedef decltype(normalize(k)) T;
+
+                static bool registered = [] {
+                    std::unique_lock<std::mutex> lk(keyTypesLock());
+                    keyTypes()[typeid(T)] = keyType{hashOf<T>, equalOf<T>};
+                    return true;
+                }();
+                (void) registered;
+
+                std::any v = normalize(k);
+                return key{v, hashOf<T>(v), equalOf<T>};
+            }
+        }
+
+        template<class V> static bool equalValue(const std::any& a, const V& b) {
+            auto v = normalize(b);
+            auto p = std::any_cast<decltype(v)>(&a);
+            return p != nullptr && *p == v;
+        }
+
+        std::shared_mutex m;
+        std::unordered_map<key, std::any, keyHash> items;
+
+    public:
+        template<class K> std::tuple<std::any, bool> Load(const K& k) {
+            std::shared_lock<std::shared_mutex> lk(m);
+            auto it = items.find(makeKey(k));
+            if (it == items.end()) {
+                return {std::any(), false};
+            }
+            return {it->second, true};
+        }
+
+        template<class K, class V> void Store(const K& k, const V& v) {
+            std::unique_lock<std::shared_mutex> lk(m);
+            items[makeKey(k)] = normalize(v);
+        }
+
+        // LoadOrStore returns the value for the key if present (and true), otherwise it stores v and returns it
+        template<class K, class V> std::tuple<std::any, bool> LoadOrStore(const K& k, const V& v) {
+            std::unique_lock<std::shared_mutex> lk(m);
+            auto [it, stored] = items.emplace(makeKey(k), normalize(v));
+            return {it->second, !stored};
+        }
+
+        template<class K> std::tuple<std::any, bool> LoadAndDelete(const K& k) {
+            std::unique_lock<std::shared_mutex> lk(m);
+            auto it = items.find(makeKey(k));
+            if (it == items.end()) {
+                return {std::any(), false};
+            }
+
+            std::any v = std::move(it->second);
+            items.erase(it);
+            return {v, true};
+        }
+
+        template<class K> void Delete(const K& k) {
+            std::unique_lock<std::shared_mutex> lk(m);
+            items.erase(makeKey(k));
+        }
+
+        // Swap stores v and returns the previous value (and true if there was one)
+        template<class K, class V> std::tuple<std::any, bool> Swap(const K& k, const V& v) {
+            std::unique_lock<std::shared_mutex> lk(m);
+            auto [it, stored] = items.emplace(makeKey(k), normalize(v));
+            if (stored) {
+                return {std::any(), false};
+            }
+
+            std::any previous = std::move(it->second);
+            it->second = normalize(v);
+            return {previous, true};
+        }
+
+        template<class K, class V, class W> bool CompareAndSwap(const K& k, const V& old, const W& v) {
+            std::unique_lock<std::shared_mutex> lk(m);
+            auto it = items.find(makeKey(k));
+            if (it == items.end() || !equalValue(it->second, old)) {
+                return false;
+            }
+
+            it->second = normalize(v);
+            return true;
+        }
+
+        template<class K, class V> bool CompareAndDelete(const K& k, const V& old) {
+            std::unique_lock<std::shared_mutex> lk(m);
+            auto it = items.find(makeKey(k));
+            if (it == items.end() || !equalValue(it->second, old)) {
+                return false;
+            }
+
+            items.erase(it);
+            return true;
+        }
+
+        // Range calls f for each key and value (on a snapshot of the map, so that f can modify it)
+        // until f returns false
+        void Range(std::function<bool(std::any, std::any)> f) {
+            std::vector<std::pair<std::any, std::any>> snapshot;
+            {
+                std::shared_lock<std::shared_mutex> lk(m);
+                for (auto& kv : items) {
+                    snapshot.emplace_back(kv.first.v, kv.second);
+                }
+            }
+
+            for (auto& kv : snapshot) {
+                if (!f(kv.first, kv.second)) {
+                    break;
+                }
+            }
+        }
+
+        void Clear() {
+            std::unique_lock<std::shared_mutex> lk(m);
+            items.clear();
         }
     };
 }
@@ -0,0 +1,156 @@
+//
+// Tests for the channels and select in go.h (make runtime-test)
+//
+
+#include "test.h"
+#include <go_atomic.h>
+
+static void sleepMs(int ms) {
+    std::this_thread::sleep_for(std::chrono::milliseconds(ms));
+}
+
+static void testBuffered() {
+    Chan<int> ch(2);
+    ch.Send(1);
+    ch.Send(2);
+    CHECK(len(ch) == 2 && cap(ch) == 2);
+    CHECK(ch.Receive() == 1 && ch.Receive() == 2 && len(ch) == 0);
+
+    // the buffered values are received after close
+    ch.Send(3);
+    ch.Close();
+    auto [v, ok] = ch.Lookup();
+    CHECK(v == 3 && ok);
+    std::tie(v, ok) = ch.Lookup();
+    CHECK(v == 0 && !ok);
+    CHECK(ch.Receive() == 0);
+
+    CHECK(panics([&]() { ch.Send(4); }) == "send on closed channel");
+    CHECK(panics([&]() { ch.Close(); }) == "close of closed channel");
+
+    Chan<int> nil;
+    CHECK(nil == nullptr && len(nil) == 0 && cap(nil) == 0);
+    CHECK(panics([&]() { nil.Close(); }) == "close of nil channel");
+}
+
+static void testUnbuffered() {
+    Chan<int> ch(0);
+    atomic::Bool sent;
+
+    // a send waits for the receiver
+    Goroutine([ch, &sent]() {
+        ch.Send(1);
+        sent.Store(true);
+    });
+    sleepMs(50);
+    CHECK(!sent.Load());
+    CHECK(ch.Receive() == 1);
+
+    Goroutine([ch]() {
+        for (int i = 0; i < 3; i++) {
+            ch.Send(i);
+        }
+        ch.Close();
+    });
+
+    // range receives (value, index) pairs until the channel is closed
+    int n = 0, sum = 0;
+    for (auto [v, i] : Range(ch)) {
+        CHECK(i == n);
+        n++;
+        sum += v;
+    }
+    CHECK(n == 3 && sum == 3);
+}
+
+static void testManySenders() {
+    const int N = 8, M = 1000;
+    Chan<int> ch(4);
+    atomic::Int32 left;
+    left.Store(N);
+
+    for (int i = 0; i < N; i++) {
+        Goroutine([ch, &left]() {
+            for (int j = 1; j <= M; j++) {
+                ch.Send(j);
+            }
+            if (left.Add(-1) == 0) {
+                ch.Close();
+            }
+        });
+    }
+
+    long sum = 0;
+    for (auto [v, _] : Range(ch)) {
+        sum += v;
+    }
+    CHECK(sum == long(N) * M * (M + 1) / 2);
+}
+
+static void testSelect() {
+    Chan<int> a(1), b(1);
+
+    // the default case when nothing is ready
+    {
+        auto s = Select{SelectRecv(a), SelectRecv(b)};
+        CHECK(s.Wait(true) == -1);
+    }
+
+    b.Send(5);
+    {
+        auto s = Select{SelectRecv(a), SelectRecv(b)};
+        CHECK(s.Wait(false) == 1 && std::get<1>(s).Receive() == 5);
+    }
+
+    // a send case
+    {
+        auto s = Select{SelectSend(a, 7), SelectRecv(b)};
+        CHECK(s.Wait(false) == 0 && a.Receive() == 7);
+    }
+
+    // a closed channel is always ready
+    a.Close();
+    {
+        auto s = Select{SelectRecv(a), SelectRecv(b)};
+        CHECK(s.Wait(false) == 0);
+        auto [v, ok] = std::get<0>(s).Lookup();
+        CHECK(v == 0 && !ok);
+    }
+
+    // waiting for another goroutine
+    Chan<std::string> c(0);
+    Goroutine([c]() {
+        sleepMs(20);
+        c.Send("x");
+    });
+    {
+        auto s = Select{SelectRecv(c), SelectRecv(Chan<int>())};
+        CHECK(s.Wait(false) == 0 && std::get<0>(s).Receive() == "x");
+    }
+}
+
+static void testSelectRandom() {
+    Chan<int> a(1), b(1);
+    int counts[2] = {0, 0};
+
+    // the ready cases are chosen at random
+    for (int i = 0; i < 200; i++) {
+        a.Send(1);
+        b.Send(2);
+        auto s = Select{SelectRecv(a), SelectRecv(b)};
+        int n = s.Wait(false);
+        counts[n]++;
+        (n == 0 ? b : a).Receive();
+    }
+    CHECK(counts[0] > 20 && counts[1] > 20);
+}
+
+int main() {
+    return runTests({
+        {"Buffered", testBuffered},
+        {"Unbuffered", testUnbuffered},
+        {"ManySenders", testManySenders},
+        {"Select", testSelect},
+        {"SelectRandom", testSelectRandom},
+    });
+}
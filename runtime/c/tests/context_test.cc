@@ -0,0 +1,170 @@
+//
+// Tests for context.h (make runtime-test)
+//
+
+#include "test.h"
+#include <context.h>
+#include <sync.h>
+#include <go_atomic.h>
+
+static const int N = 16; // goroutines
+
+using go_time::Duration;
+using go_time::Millisecond;
+using go_time::Second;
+
+// closed returns true if the channel is closed (without blocking)
+static bool closed(Chan<EmptyStruct> c) {
+    return Select{SelectRecv(c)}.Wait(true) == 0;
+}
+
+static void testBackground() {
+    auto ctx = context::Background();
+    CHECK(ctx.Done() == nullptr && ctx.Err() == nullptr && ctx.Value("key").has_value() == false);
+    CHECK(std::get<1>(ctx.Deadline()) == false);
+    CHECK(ctx.String() == "context.Background" && context::TODO().String() == "context.TODO");
+    CHECK(ctx == context::Background());
+
+    CHECK(panics([]() { context::WithCancel(nullptr); }) == "cannot create context from nil parent");
+}
+
+static void testCancel() {
+    auto [parent, cancelParent] = context::WithCancel(context::Background());
+    auto [child, cancelChild] = context::WithCancel(parent);
+    auto [other, cancelOther] = context::WithCancel(parent);
+    CHECK(child.String() == "context.Background.WithCancel.WithCancel");
+    CHECK(!closed(parent.Done()) && !closed(child.Done()) && child.Err() == nullptr);
+
+    // canceling a child doesn't cancel the parent
+    cancelChild();
+    cancelChild();
+    CHECK(closed(child.Done()) && child.Err() == context::Canceled);
+    CHECK(!closed(parent.Done()) && parent.Err() == nullptr);
+
+    // canceling the parent cancels the other children, and the children of a canceled context
+    auto value = context::WithValue(other, std::string("key"), 1);
+    auto [grandchild, cancelGrandchild] = context::WithCancel(value);
+    cancelParent();
+    CHECK(parent.Err() == context::Canceled && other.Err() == context::Canceled && value.Err() == context::Canceled);
+    CHECK(closed(other.Done()) && closed(grandchild.Done()));
+
+    auto [late, cancelLate] = context::WithCancel(parent);
+    CHECK(closed(late.Done()) && late.Err() == context::Canceled);
+    cancelOther();
+    cancelGrandchild();
+    cancelLate();
+
+    // the cause
+    auto [withCause, cancelCause] = context::WithCancelCause(context::Background());
+    auto [under, cancelUnder] = context::WithCancel(withCause);
+    auto reason = error("reason");
+    cancelCause(reason);
+    CHECK(withCause.Err() == context::Canceled && context::Cause(withCause) == reason && context::Cause(under) == reason);
+    CHECK(context::Cause(context::Background()) == nullptr);
+    cancelUnder();
+}
+
+static void testDeadline() {
+    auto start = go_time::Now();
+    auto [ctx, cancel] = context::WithTimeout(context::Background(), 20 * Millisecond);
+    auto [deadline, ok] = ctx.Deadline();
+    CHECK(ok && deadline.Sub(start) >= 20 * Millisecond);
+    CHECK(ctx.String().find("context.Background.WithDeadline(") == 0);
+
+    ctx.Done().Receive();
+    CHECK(go_time::Since(start) >= 20 * Millisecond);
+    CHECK(ctx.Err() == context::DeadlineExceeded && ctx.Err().Error() == "context deadline exceeded");
+    CHECK(ctx.Err()._iface()->typeName() == "context.deadlineExceededError");
+    cancel();
+    CHECK(ctx.Err() == context::DeadlineExceeded);
+
+    // canceled before the deadline
+    auto [early, cancelEarly] = context::WithTimeout(context::Background(), Second);
+    cancelEarly();
+    CHECK(early.Err() == context::Canceled);
+
+    // a deadline in the past, and a parent with a sooner deadline
+    auto [past, cancelPast] = context::WithDeadline(context::Background(), start);
+    CHECK(closed(past.Done()) && past.Err() == context::DeadlineExceeded);
+    cancelPast();
+
+    auto [soon, cancelSoon] = context::WithTimeout(context::Background(), 10 * Millisecond);
+    auto [later, cancelLater] = context::WithTimeout(soon, Second);
+    CHECK(std::get<0>(later.Deadline()).Equal(std::get<0>(soon.Deadline())));
+    later.Done().Receive();
+    CHECK(later.Err() == context::DeadlineExceeded);
+    cancelSoon();
+    cancelLater();
+}
+
+struct userKey {
+};
+
+static void testValue() {
+    auto ctx = context::WithValue(context::Background(), "user", "gopher");
+    ctx = context::WithValue(ctx, 1, 2.5);
+    ctx = context::WithValue(ctx, userKey(), 42);
+    ctx = context::WithValue(ctx, "user", "shadow");
+
+    CHECK(std::any_cast<std::string>(ctx.Value("user")) == "shadow");
+    CHECK(std::any_cast<double>(ctx.Value(1)) == 2.5);
+    CHECK(std::any_cast<int>(ctx.Value(userKey())) == 42);
+    CHECK(!ctx.Value(2).has_value() && !ctx.Value(1L).has_value());
+    CHECK(ctx.String() == "context.Background.WithValue(user, gopher).WithValue(<not Stringer>, <not Stringer>)"
+        ".WithValue(<not Stringer>, <not Stringer>).WithValue(user, shadow)");
+}
+
+static void testConcurrent() {
+    // the workers stop when the context is canceled
+    auto [ctx, cancel] = context::WithCancel(context::Background());
+    sync::WaitGroup wg;
+    atomic::Int32 stopped;
+    for (int i = 0; i < N; i++) {
+        wg.Add(1);
+        auto [c, cancelChild] = context::WithCancel(ctx);
+        Goroutine([&wg, &stopped, c = c, cancelChild = cancelChild]() {
+            Chan<int> work(0);
+            for (;;) {
+                switch (auto _select = Select{SelectRecv(c.Done()), SelectSend(work, 1)}; _select.Wait(true)) {
+                case 0:
+                    stopped.Add(1);
+                    cancelChild();
+                    wg.Done();
+                    return;
+                }
+                go_time::Sleep(Millisecond);
+            }
+        });
+    }
+
+    go_time::Sleep(10 * Millisecond);
+    CHECK(stopped.Load() == 0);
+    cancel();
+    wg.Wait();
+    CHECK(stopped.Load() == N);
+
+    // the contexts canceled concurrently with their parents
+    for (int i = 0; i < 100; i++) {
+        auto [parent, cancelParent] = context::WithTimeout(context::Background(), Duration(i % 3) * Millisecond);
+        Chan<bool> done(0);
+        Goroutine([=, parent = parent]() {
+            auto [child, cancelChild] = context::WithCancel(parent);
+            cancelChild();
+            child.Done().Receive();
+            done.Close();
+        });
+        cancelParent();
+        done.Receive();
+        CHECK(parent.Err() != nullptr);
+    }
+}
+
+int main() {
+    return runTests({
+        {"Background", testBackground},
+        {"Cancel", testCancel},
+        {"Deadline", testDeadline},
+        {"Value", testValue},
+        {"Concurrent", testConcurrent},
+    });
+}
@@ -0,0 +1,141 @@
+//
+// Tests for defer, panic and recover in go.h, with functions as converted by the C++ printer (make runtime-test)
+//
+
+#include "test.h"
+
+static std::string trace;
+
+static void record(std::string s) {
+    trace += s;
+}
+
+// func order() { defer record("a"); for i := 0; i < 3; i++ { defer record(i) }; record("-") }
+static void order() {
+    Defers _defers;
+    try {
+        {
+            Deferred _defer0(_defers, [&](auto... _a) { record(_a...); }, std::string("a"));
+            Deferred _defer1(_defers);
+            for (int i = 0; i < 3; i++) {
+                _defers.Defer([=](auto... _a) { record(_a...); }, std::to_string(i));
+            }
+            record("-");
+        }
+        _defers.Run();
+    } catch (...) {
+        _defers.Recover();
+    }
+}
+
+static void testOrder() {
+    trace = "";
+    order();
+    CHECK(trace == "-210a");
+
+    // the arguments are evaluated at the defer statement
+    trace = "";
+    {
+        Defers _defers;
+        std::string s = "x";
+        Deferred _defer0(_defers, [&](auto... _a) { record(_a...); }, s);
+        s = "y";
+        record(s);
+    }
+    CHECK(trace == "yx");
+}
+
+// func divide(a, b int) (q int, err error) { defer func() { if r := recover(); r != nil { err = ... } }(); ... }
+static std::tuple<int, error> divide(int a, int b) {
+    int q{};
+    error err{};
+    Defers _defers;
+    try {
+        {
+            Deferred _defer0(_defers, [&]() {
+                auto r = recover();
+                if (r != nullptr) {
+                    err = error("recovered: " + std::any_cast<std::string>(r));
+                }
+            });
+            if (b == 0) {
+                panic("divide by zero");
+            }
+            q = a / b;
+        }
+        _defers.Run();
+    } catch (...) {
+        _defers.Recover();
+    }
+    return std::make_tuple(q, err);
+}
+
+static void testRecover() {
+    auto [q, err] = divide(6, 3);
+    CHECK(q == 2 && err == nullptr);
+
+    std::tie(q, err) = divide(1, 0);
+    CHECK(q == 0 && err != nullptr && err.Error() == "recovered: divide by zero");
+
+    // recover returns nil when not panicking, or outside of a deferred call
+    CHECK(recover() == nullptr);
+    CHECK(panicState().panicking == false);
+}
+
+// a function that panics, with a deferred call that doesn't recover
+static void unrecovered(std::string value) {
+    Defers _defers;
+    try {
+        {
+            Deferred _defer0(_defers, [&]() { record("deferred"); });
+            panic(value);
+        }
+        _defers.Run();
+    } catch (...) {
+        _defers.Recover();
+    }
+}
+
+// a deferred call that panics while panicking
+static void replaced() {
+    Defers _defers;
+    try {
+        {
+            Deferred _defer0(_defers, [&]() { panic(std::string("second")); });
+            panic(std::string("first"));
+        }
+        _defers.Run();
+    } catch (...) {
+        _defers.Recover();
+    }
+}
+
+static void testPanic() {
+    // the deferred calls run, then the panic goes on
+    trace = "";
+    CHECK(panics([]() { unrecovered("boom"); }) == "boom");
+    CHECK(trace == "deferred");
+
+    // the last panic wins
+    CHECK(panics([]() { replaced(); }) == "second");
+
+    // runtime errors are panics
+    CHECK(panics([]() { Slice<int>()[1]; }) == "runtime error: index out of range [1] with length 0");
+
+    int zero = 0;
+    long long big = 7;
+    CHECK(panics([&]() { return 7 / checkDivisor(zero); }) == "runtime error: integer divide by zero");
+    CHECK(panics([&]() { big %= checkDivisor(zero); }) == "runtime error: integer divide by zero");
+    CHECK(big / checkDivisor(2) == 3 && big % checkDivisor(4LL) == 3);
+
+    // an error value
+    CHECK(panics([]() { panic(error("failed")); }) == "failed");
+}
+
+int main() {
+    return runTests({
+        {"Order", testOrder},
+        {"Recover", testRecover},
+        {"Panic", testPanic},
+    });
+}
@@ -0,0 +1,112 @@
+//
+// Tests for the errors in go.h and errors.h (make runtime-test)
+//
+
+#include "test.h"
+#include <fmt.h>
+#include <errors.h>
+
+// a custom error type, with a value receiver (comparable, as converted by the C++ printer)
+struct codeError {
+    static constexpr const char *_type = "main.codeError";
+    int Code;
+
+    std::string Error() const {
+        return "code " + std::to_string(Code);
+    }
+
+    bool operator==(const codeError& o) const {
+        return Code == o.Code;
+    }
+};
+
+// a custom error type that wraps another one, with a pointer receiver
+struct wrapError {
+    static constexpr const char *_type = "main.wrapError";
+    std::string Op;
+    error Err;
+
+    std::string Error() const {
+        return Op + ": " + Err.Error();
+    }
+
+    error Unwrap() const {
+        return Err;
+    }
+};
+
+static void testNew() {
+    auto a = errors::New("a");
+    auto b = errors::New("a");
+    CHECK(a.Error() == "a" && a == a && a != b);
+
+    error nil;
+    CHECK(nil == nullptr && a != nullptr && nil != a);
+    CHECK(panics([&]() { nil.Error(); }) == "runtime error: invalid memory address or nil pointer dereference");
+
+    // a value of a type with an Error method
+    error c = codeError{404};
+    CHECK(c.Error() == "code 404" && c == error(codeError{404}));
+}
+
+static void testIs() {
+    auto base = errors::New("base");
+    auto wrapped = fmt::Errorf("one: %w", base);
+    auto twice = fmt::Errorf("two: %w", wrapped);
+
+    CHECK(twice.Error() == "two: one: base");
+    CHECK(errors::Is(twice, base) && errors::Is(twice, wrapped) && !errors::Is(base, wrapped));
+    CHECK(errors::Unwrap(twice) == wrapped && errors::Unwrap(base) == nullptr);
+    CHECK(errors::Is(nullptr, nullptr) && !errors::Is(base, nullptr));
+
+    // an Unwrap method
+    error w = std::make_shared<wrapError>(wrapError{"read", base});
+    CHECK(w.Error() == "read: base" && errors::Is(w, base) && errors::Unwrap(w) == base);
+
+    // the comparable values are compared
+    CHECK(errors::Is(fmt::Errorf("%w", error(codeError{1})), codeError{1}));
+}
+
+static void testAs() {
+    auto err = fmt::Errorf("wrapped: %w", error(codeError{7}));
+
+    codeError ce{};
+    CHECK(errors::As(err, &ce) && ce.Code == 7);
+
+    wrapError *we = nullptr;
+    CHECK(!errors::As(err, &we) && we == nullptr);
+
+    error w = std::make_shared<wrapError>(wrapError{"write", err});
+    CHECK(errors::As(w, &we) && we->Op == "write");
+    CHECK(errors::As(w, &ce) && ce.Code == 7);
+
+    error any;
+    CHECK(errors::As(w, &any) && any == w);
+
+    CHECK(panics([&]() { errors::As(w, (codeError *) nullptr); }) == "errors: target cannot be nil");
+}
+
+static void testJoin() {
+    auto a = errors::New("a");
+    auto b = errors::New("b");
+
+    auto j = errors::Join(a, nullptr, b);
+    CHECK(j.Error() == "a\nb" && errors::Is(j, a) && errors::Is(j, b));
+    CHECK(errors::Join(nullptr, nullptr) == nullptr);
+
+    codeError ce{};
+    CHECK(errors::As(errors::Join(a, error(codeError{3})), &ce) && ce.Code == 3);
+
+    // %w more than once
+    auto m = fmt::Errorf("%w and %w", a, b);
+    CHECK(m.Error() == "a and b" && errors::Is(m, a) && errors::Is(m, b));
+}
+
+int main() {
+    return runTests({
+        {"New", testNew},
+        {"Is", testIs},
+        {"As", testAs},
+        {"Join", testJoin},
+    });
+}
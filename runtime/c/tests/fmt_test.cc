@@ -0,0 +1,132 @@
+//
+// Tests for fmt.h (make runtime-test)
+//
+
+#include "test.h"
+#include <fmt.h>
+#include <errors.h>
+
+// a struct as converted by the C++ printer
+struct point {
+    static constexpr const char *_type = "main.point";
+    int X;
+    int Y;
+
+    template<class F> void _fields(F f) { f("X", X); f("Y", Y); }
+};
+
+struct named {
+    static constexpr const char *_type = "main.named";
+    std::string Name;
+    Slice<int> Values;
+    point *P;
+
+    template<class F> void _fields(F f) { f("Name", Name); f("Values", Values); f("P", P); }
+};
+
+struct celsius {
+    double v;
+
+    std::string String() const {
+        return fmt::Sprintf("%.1f°C", v);
+    }
+};
+
+static void testIntegers() {
+    CHECK(fmt::Sprintf("%d|%5d|%-5d|%05d", 42, 42, 42, -42) == "42|   42|42   |-0042");
+    CHECK(fmt::Sprintf("%x %X %o %b %#x %#o", 255, 255, 8, 5, 255, 8) == "ff FF 10 101 0xff 010");
+    CHECK(fmt::Sprintf("%+d %+d", 1, -1) == "+1 -1");
+    CHECK(fmt::Sprintf("%c %q %U", 'A', 'x', 0x1F600) == "A 'x' U+1F600");
+    CHECK(fmt::Sprintf("%d %d", int64(-9223372036854775807LL - 1), uint64(18446744073709551615ULL))
+        == "-9223372036854775808 18446744073709551615");
+    CHECK(fmt::Sprint(byte(200), int8(-5)) == "200 -5");
+}
+
+static void testFloats() {
+    CHECK(fmt::Sprintf("%f %.2f %8.3f|%-8.1f|", 3.14159, 3.14159, 3.14159, 2.5) == "3.141590 3.14    3.142|2.5     |");
+    CHECK(fmt::Sprintf("%e %g %g", 1234.5678, 0.000012, 1e21) == "1.234568e+03 1.2e-05 1e+21");
+    CHECK(fmt::Sprint(1.0, 2.5, 1e20, 1e21) == "1 2.5 1e+20 1e+21");
+    CHECK(fmt::Sprint(float32(0.1)) == "0.1");
+}
+
+static void testStrings() {
+    CHECK(fmt::Sprintf("%s|%10s|%-4s|%.2s", "go", "right", "l", "abc") == "go|     right|l   |ab");
+    CHECK(fmt::Sprintf("%q", "a\"b\n") == "\"a\\\"b\\n\"");
+    CHECK(fmt::Sprintf("%x % x %X", "hi", "hi", std::string("hi")) == "6869 68 69 6869");
+    CHECK(fmt::Sprintf("%5s|", "世界") == "   世界|");
+    CHECK(fmt::Sprintf("%s", Slice<byte>(std::string("bytes"))) == "bytes");
+    CHECK(fmt::Sprintf("100%%") == "100%");
+}
+
+static void testValues() {
+    CHECK(fmt::Sprintf("%v %v %t", true, nullptr, false) == "true <nil> false");
+    CHECK(fmt::Sprint(Slice<int>{1, 2, 3}) == "[1 2 3]");
+    CHECK(fmt::Sprint(Slice<std::string>{"a", "b"}) == "[a b]");
+    CHECK(fmt::Sprintf("%v %d", Slice<int>(), Slice<int>{1}) == "[] [1]");
+
+    // the map keys are sorted
+    Map<std::string, int> m{{"b", 2}, {"a", 1}, {"c", 3}};
+    CHECK(fmt::Sprint(m) == "map[a:1 b:2 c:3]");
+    CHECK(fmt::Sprint(Map<int, bool>()) == "map[]");
+
+    // the arrays
+    int a[3] = {1, 2, 3};
+    CHECK(fmt::Sprint(a) == "[1 2 3]");
+    CHECK(fmt::Sprintf("%v %T", a, a) == "[1 2 3] [3]int");
+
+    // the structs
+    point p{1, 2};
+    CHECK(fmt::Sprintf("%v %+v", p, p) == "{1 2} {X:1 Y:2}");
+    CHECK(fmt::Sprintf("%#v", p) == "main.point{X:1, Y:2}");
+    CHECK(fmt::Sprintf("%v", &p) == "&{1 2}");
+
+    named n{"n", {1}, nullptr};
+    CHECK(fmt::Sprintf("%v", n) == "{n [1] <nil>}");
+    CHECK(fmt::Sprintf("%+v", n) == "{Name:n Values:[1] P:<nil>}");
+
+    // the Stringer and error values
+    CHECK(fmt::Sprint(celsius{21.5}) == "21.5°C");
+    CHECK(fmt::Sprintf("%v|%s", error("failed"), error()) == "failed|%!s(<nil>)");
+}
+
+static void testTypes() {
+    CHECK(fmt::Sprintf("%T %T %T %T", 1, "s", 2.5, true) == "int string float64 bool");
+    CHECK(fmt::Sprintf("%T %T %T", Slice<int>(), Map<std::string, Slice<int>>(), Chan<int>()) == "[]int map[string][]int chan int");
+    CHECK(fmt::Sprintf("%T %T", point{}, (point *) nullptr) == "main.point *main.point");
+    CHECK(fmt::Sprintf("%T %T", error("x"), error()) == "*errors.errorString <nil>");
+    CHECK(fmt::Sprintf("%T %T", std::any(1), std::any()) == "int <nil>");
+}
+
+static void testPrint() {
+    // spaces between the operands when neither is a string
+    CHECK(fmt::Sprint("a", "b", 1, 2, "c") == "ab1 2c");
+    CHECK(fmt::Sprintln("a", "b", 1) == "a b 1\n");
+    CHECK(fmt::Sprint() == "" && fmt::Sprintln() == "\n");
+
+    // multiple values
+    CHECK(fmt::Sprint(std::make_tuple(1, std::string("x"))) == "1x");
+}
+
+static void testErrors() {
+    CHECK(fmt::Sprintf("%d") == "%!d(MISSING)");
+    CHECK(fmt::Sprintf("%d", 1, 2) == "1%!(EXTRA int=2)");
+    CHECK(fmt::Sprintf("%z", 1) == "%!z(int=1)");
+    CHECK(fmt::Sprintf("%d", "s") == "%!d(string=s)");
+    CHECK(fmt::Sprintf("%*d|%-*d", 4, 1, 3, 2) == "   1|2  ");
+
+    auto err = fmt::Errorf("wrap: %w", errors::ErrUnsupported);
+    CHECK(err.Error() == "wrap: unsupported operation" && errors::Is(err, errors::ErrUnsupported));
+    CHECK(errors::Unwrap(fmt::Errorf("no wrap: %v", errors::ErrUnsupported)) == nullptr);
+}
+
+int main() {
+    return runTests({
+        {"Integers", testIntegers},
+        {"Floats", testFloats},
+        {"Strings", testStrings},
+        {"Values", testValues},
+        {"Types", testTypes},
+        {"Print", testPrint},
+        {"Errors", testErrors},
+    });
+}
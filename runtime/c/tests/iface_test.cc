@@ -0,0 +1,171 @@
+//
+// Tests for the type assertions and the type switches in go.h (make runtime-test)
+//
+
+#include "test.h"
+#include <fmt.h>
+#include <errors.h>
+#include <io.h>
+#include <os.h>
+#include <go_strings.h>
+
+struct point {
+    static constexpr const char *_type = "main.point";
+    int X, Y;
+
+    bool operator==(const point& p) const {
+        return X == p.X && Y == p.Y;
+    }
+};
+
+// an interface with methods, as converted by the C++ printer
+struct shape : Interface {
+    static constexpr const char *_type = "main.shape";
+    static inline const bool _registered = Interface::_register<shape>();
+    Method<int()> Area;
+
+    shape() {}
+    shape(std::nullptr_t) {}
+    template<class T, iface_detail::if_value<T, shape> = 0> shape(const T& v) : Interface(v) {
+        Area = _bind(v, [](auto& _v, auto... _a) { return _v.Area(_a...); });
+    }
+};
+
+// a value receiver
+struct square {
+    static constexpr const char *_type = "main.square";
+    int Side;
+
+    int Area() const {
+        return Side * Side;
+    }
+
+    std::string String() const {
+        return "square" + std::to_string(Side);
+    }
+
+    bool operator==(const square& s) const {
+        return Side == s.Side;
+    }
+};
+
+// a pointer receiver
+struct rect {
+    static constexpr const char *_type = "main.rect";
+    int W, H;
+
+    int Area() {
+        return W * H;
+    }
+};
+
+static void testAssert() {
+    std::any s = "literal", t = std::string("string"), i = 42, p = point{1, 2}, n;
+
+    CHECK(typeAssert<std::string>(s) == "literal" && typeAssert<std::string>(t) == "string");
+    CHECK(typeAssert<int>(i) == 42 && typeAssert<point>(p) == point({1, 2}));
+    CHECK(typeAssert<std::any>(i).has_value());
+
+    auto [v, ok] = typeAssertOk<int>(s);
+    CHECK(v == 0 && !ok);
+    auto [ps, ok2] = typeAssertOk<std::string>(s);
+    CHECK(ps == "literal" && ok2);
+    auto [pt, ok3] = typeAssertOk<point>(n);
+    CHECK(pt == point({0, 0}) && !ok3);
+    CHECK(!std::get<1>(typeAssertOk<long long>(i)));
+
+    CHECK(panics([&]() { typeAssert<int>(s); }) == "interface conversion: interface {} is string, not int");
+    CHECK(panics([&]() { typeAssert<point *>(n); }) == "interface conversion: interface {} is <nil>, not *main.point");
+    CHECK(panics([&]() { typeAssert<std::any>(n); }) == "interface conversion: interface {} is <nil>, not interface {}");
+    CHECK(panics([&]() { typeAssert<int>(i); }) == "");
+
+    // nil
+    std::any null = nullptr;
+    CHECK(n == nullptr && null == nullptr && i != nullptr && fmt::Sprint(null) == "<nil>");
+    CHECK(fmt::Sprintf("%T %T", null, s) == "<nil> string");
+}
+
+static void testErrors() {
+    auto [f, err] = os::Open("/nonexistent/walkngo");
+    CHECK(f == nullptr);
+
+    auto [pe, ok] = typeAssertOk<os::PathError *>(err);
+    CHECK(ok && pe->Op == "open");
+    CHECK(!std::get<1>(typeAssertOk<os::LinkError *>(err)));
+    CHECK(typeAssert<error>(err) == err && !std::get<1>(typeAssertOk<error>(error())));
+    CHECK(panics([&]() { typeAssert<os::LinkError *>(err); }) ==
+        "interface conversion: error is *fs.PathError, not *os.LinkError");
+
+    // an error in an interface{}
+    std::any e = errors::New("boom");
+    CHECK(typeAssert<error>(e).Error() == "boom" && !std::get<1>(typeAssertOk<error>(std::any(1))));
+
+    // the interfaces with As
+    io::Reader r = strings::NewReader("abc");
+    CHECK(typeAssert<strings::Reader *>(r)->Len() == 3 && !std::get<1>(typeAssertOk<os::File *>(r)));
+    CHECK(typeAssert<io::Reader>(r) == r && !std::get<1>(typeAssertOk<io::Reader>(io::Reader())));
+}
+
+static void testSwitch() {
+    typedef std::tuple<std::nullptr_t> nil;
+    typedef std::tuple<std::string> str;
+    typedef std::tuple<int, long long> integer;
+    typedef std::tuple<error> err;
+    typedef std::tuple<point *> ptr;
+
+    point p{1, 2};
+
+    CHECK((typeCase<nil, str, integer, err, ptr>(std::any()) == 0));
+    CHECK((typeCase<nil, str, integer, err, ptr>(std::any(nullptr)) == 0));
+    CHECK((typeCase<nil, str, integer, err, ptr>(std::any("s")) == 1));
+    CHECK((typeCase<nil, str, integer, err, ptr>(std::any(7LL)) == 2));
+    CHECK((typeCase<nil, str, integer, err, ptr>(std::any(error("e"))) == 3));
+    CHECK((typeCase<nil, str, integer, err, ptr>(std::any(&p)) == 4));
+    CHECK((typeCase<nil, str, integer, err, ptr>(std::any(2.5)) == -1));
+
+    // the first case that matches
+    CHECK((typeCase<std::tuple<double>, std::tuple<std::any>, std::tuple<int>>(std::any(1)) == 1));
+    CHECK((typeCase<std::tuple<double>, std::tuple<std::any>, std::tuple<int>>(std::any()) == -1));
+    CHECK((typeCase<>(std::any(1)) == -1));
+
+    // a switch on an error
+    error e = error(std::make_shared<os::PathError>(os::PathError{"op", "path", os::ErrNotExist}));
+    CHECK((typeCase<std::tuple<os::LinkError *>, std::tuple<os::PathError *>>(e) == 1));
+    CHECK((typeCase<std::tuple<os::LinkError *>, std::tuple<std::nullptr_t>>(error()) == 1));
+}
+
+static void testMethods() {
+    typedef Implements<shape, square, rect *> isShape;
+    typedef Implements<fmt::Stringer, square> isStringer;
+
+    shape s = square{3}, r = new rect{2, 5}, nil;
+    CHECK(s.Area() == 9 && r.Area() == 10 && nil == nullptr && s != nullptr);
+    CHECK(s == shape(square{3}) && s != shape(square{4}) && s != r);
+    CHECK(fmt::Sprint(s) == "square3" && fmt::Sprintf("%v %T %T", nil, s, r) == "<nil> main.square *main.rect");
+    CHECK(panics([&]() { nil.Area(); }) == "runtime error: invalid memory address or nil pointer dereference");
+
+    // an interface value in an interface{} is its dynamic value
+    std::any a = s, n = nil;
+    CHECK(n == nullptr && a != nullptr && typeAssert<square>(a).Side == 3);
+    CHECK(fmt::Sprintf("%v %T", a, a) == "square3 main.square");
+    CHECK(typeAssert<isShape>(a).Area() == 9 && typeAssert<isStringer>(a).String() == "square3");
+    CHECK(!std::get<1>(typeAssertOk<isShape>(std::any(1))) && !std::get<1>(typeAssertOk<isShape>(n)));
+    CHECK(panics([&]() { typeAssert<isShape>(std::any(1)); }) == "interface conversion: int is not main.shape");
+
+    // the interface to interface assertions
+    CHECK(typeAssert<isStringer>(s).String() == "square3" && !std::get<1>(typeAssertOk<isStringer>(r)));
+    CHECK(typeAssert<rect *>(r)->W == 2);
+
+    CHECK((typeCase<std::tuple<isStringer>, std::tuple<isShape>, std::tuple<int>>(std::any(r)) == 1));
+    CHECK((typeCase<std::tuple<isStringer>, std::tuple<isShape>, std::tuple<int>>(a) == 0));
+    CHECK((typeCase<std::tuple<isStringer>, std::tuple<isShape>, std::tuple<std::nullptr_t>>(n) == 2));
+}
+
+int main() {
+    return runTests({
+        {"Assert", testAssert},
+        {"Errors", testErrors},
+        {"Switch", testSwitch},
+        {"Methods", testMethods},
+    });
+}
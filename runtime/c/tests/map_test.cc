@@ -0,0 +1,149 @@
+//
+// Tests for the maps in go.h (make runtime-test)
+//
+
+#include "test.h"
+
+#include <algorithm>
+#include <set>
+
+// a struct as converted by the C++ printer, comparable with ==
+struct point {
+    int X;
+    int Y;
+
+    auto _tie() const { return std::tie(X, Y); }
+    bool operator==(const point& o) const { return cmp_detail::equal(_tie(), o._tie()); }
+    bool operator!=(const point& o) const { return !(*this == o); }
+    bool operator<(const point& o) const { return cmp_detail::less(_tie(), o._tie()); }
+};
+
+struct key {
+    std::string Name;
+    point P;
+
+    auto _tie() const { return std::tie(Name, P); }
+    bool operator==(const key& o) const { return cmp_detail::equal(_tie(), o._tie()); }
+    bool operator!=(const key& o) const { return !(*this == o); }
+    bool operator<(const key& o) const { return cmp_detail::less(_tie(), o._tie()); }
+};
+
+static void testNil() {
+    Map<std::string, int> m;
+    CHECK(m == nullptr && len(m) == 0 && m.Get("a") == 0);
+
+    auto [v, ok] = m.Lookup("a");
+    CHECK(v == 0 && !ok);
+
+    // a nil map can be read, deleted from and ranged over, but not written
+    m.Delete("a");
+    for (auto [k, v] : Range(m)) {
+        CHECK(k == "" && v < 0);
+    }
+    CHECK(panics([&]() { m["a"] = 1; }) == "assignment to entry in nil map");
+}
+
+static void testGetSet() {
+    Map<std::string, int> m(0);
+    m["a"] = 1;
+    m["b"] += 2;
+    CHECK(m != nullptr && len(m) == 2 && m.Get("a") == 1 && m.Get("b") == 2);
+
+    // reading a missing key doesn't insert it
+    CHECK(m.Get("c") == 0 && len(m) == 2);
+
+    auto [v, ok] = m.Lookup("b");
+    CHECK(v == 2 && ok);
+
+    m.Delete("a");
+    CHECK(!m.Contains("a") && len(m) == 1);
+
+    // a map is a reference
+    auto n = m;
+    n["d"] = 4;
+    CHECK(m.Get("d") == 4);
+
+    Map<int, Slice<int>> lit{{1, {1}}, {2, {2, 2}}};
+    CHECK(len(lit) == 2 && len(lit.Get(2)) == 2 && lit.Get(3) == nullptr);
+}
+
+static void testStructKeys() {
+    CHECK((point{1, 2} == point{1, 2}) && (point{1, 2} != point{2, 1}));
+
+    Map<point, std::string> m{{{1, 2}, "a"}, {{2, 1}, "b"}};
+    m[point{1, 2}] = "c";
+    CHECK(len(m) == 2 && m.Get(point{1, 2}) == "c" && m.Get(point{2, 1}) == "b" && m.Get(point{0, 0}) == "");
+
+    // nested structs
+    Map<key, int> k(0);
+    k[key{"a", {1, 2}}] = 1;
+    k[key{"a", {1, 3}}] = 2;
+    k[key{"a", {1, 2}}] += 10;
+    CHECK(len(k) == 2 && k.Get(key{"a", {1, 2}}) == 11);
+
+    // the arrays in a tuple
+    Map<std::tuple<int, std::string>, int> t(0);
+    t[std::make_tuple(1, std::string("x"))] = 1;
+    CHECK(t.Get(std::make_tuple(1, std::string("x"))) == 1 && t.Get(std::make_tuple(2, std::string("x"))) == 0);
+}
+
+static void testInterfaceKeys() {
+    Map<std::any, int> m(0);
+    m[std::any(1)] = 1;
+    m[std::any(std::string("x"))] = 2;
+    m[std::any()] = 3;
+    m[std::any(1)] += 10;
+    m[std::any(int64(1))] = 4; // a different type
+    CHECK(len(m) == 4 && m.Get(std::any(1)) == 11 && m.Get(std::any()) == 3 && m.Get(std::any(std::string("x"))) == 2);
+
+    // a string literal is a string
+    CHECK(m.Get(std::any("x")) == 2);
+
+    // the values of an uncomparable type can't be keys
+    m[std::any(Slice<int>{1})] = 5;
+    std::string msg = panics([&]() { m[std::any(Slice<int>{2})] = 6; });
+    CHECK(msg.find("runtime error: comparing uncomparable type") == 0);
+
+    Map<error, int> e(0);
+    error a("a");
+    e[a] = 1;
+    e[error("a")] = 2; // a different error with the same message
+    e[nullptr] = 3;
+    CHECK(len(e) == 3 && e.Get(a) == 1 && e.Get(nullptr) == 3);
+}
+
+static void testRange() {
+    Map<int, int> m(0);
+    for (int i = 0; i < 10; i++) {
+        m[i] = i * i;
+    }
+
+    int sum = 0;
+    std::set<int> keys;
+    for (auto [k, v] : Range(m)) {
+        keys.insert(k);
+        sum += v;
+    }
+    CHECK(keys.size() == 10 && sum == 285);
+
+    // the iteration order changes
+    std::set<std::vector<int>> orders;
+    for (int i = 0; i < 20; i++) {
+        orders.insert(m.Keys());
+    }
+    CHECK(orders.size() > 1);
+
+    auto all = m.Keys();
+    std::sort(all.begin(), all.end());
+    CHECK(all.size() == 10 && all[0] == 0 && all[9] == 9);
+}
+
+int main() {
+    return runTests({
+        {"Nil", testNil},
+        {"GetSet", testGetSet},
+        {"StructKeys", testStructKeys},
+        {"InterfaceKeys", testInterfaceKeys},
+        {"Range", testRange},
+    });
+}
@@ -0,0 +1,246 @@
+//
+// Tests for os.h, io.h and bufio.h (make runtime-test)
+//
+
+#include "test.h"
+#include <os.h>
+#include <io.h>
+#include <bufio.h>
+#include <errors.h>
+#include <go_strings.h>
+#include <bytes.h>
+
+// a reader that returns at most n bytes per Read
+struct slowReader {
+    io::Reader r;
+    int n;
+
+    std::tuple<int, error> Read(const Slice<byte>& p) {
+        return r.Read(p(0, std::min(n, p.len())));
+    }
+};
+
+static std::string tempName(const std::string& name) {
+    return os::TempDir() + "/walkngo_os_test_" + std::to_string(os::Getpid()) + "_" + name;
+}
+
+static void testFile() {
+    auto name = tempName("file");
+
+    auto [f, err] = os::Create(name);
+    CHECK(err == nullptr && f->Name() == name);
+    auto [n, werr] = f->WriteString("hello, world\n");
+    CHECK(n == 13 && werr == nullptr);
+    CHECK(f->Close() == nullptr);
+
+    // closed
+    auto [n2, cerr] = f->Write(Slice<byte>(std::string("x")));
+    CHECK(n2 == 0 && cerr.Error() == "write " + name + ": file already closed" && errors::Is(cerr, os::ErrClosed));
+    CHECK(f->Close().Error() == "close " + name + ": file already closed");
+    delete f;
+
+    auto [data, rerr] = os::ReadFile(name);
+    CHECK(rerr == nullptr && std::string(data) == "hello, world\n");
+
+    // append, seek, read until EOF
+    auto [g, oerr] = os::OpenFile(name, O_RDWR | O_APPEND, 0);
+    CHECK(oerr == nullptr);
+    g->WriteString("bye\n");
+    auto [pos, serr] = g->Seek(7, io::SeekStart);
+    CHECK(pos == 7 && serr == nullptr);
+    Slice<byte> buf(5);
+    auto [nr, err1] = io::ReadFull(g, buf);
+    CHECK(nr == 5 && err1 == nullptr && std::string(buf) == "world");
+    auto [rest, err2] = io::ReadAll(g);
+    CHECK(err2 == nullptr && std::string(rest) == "\nbye\n");
+    auto [n3, err3] = g->Read(buf);
+    CHECK(n3 == 0 && err3 == io::EOF_);
+    g->Close();
+    delete g;
+
+    CHECK(os::WriteFile(name, Slice<byte>(std::string("new")), 0644) == nullptr);
+    CHECK(std::string(std::get<0>(os::ReadFile(name))) == "new");
+    CHECK(os::Remove(name) == nullptr);
+
+    // the errors
+    auto [h, nerr] = os::Open(name);
+    CHECK(h == nullptr && nerr.Error() == "open " + name + ": no such file or directory");
+    CHECK(nerr._iface()->typeName() == "*fs.PathError");
+    CHECK(errors::Is(nerr, os::ErrNotExist) && os::IsNotExist(nerr) && !os::IsExist(nerr));
+    os::PathError *pe = nullptr;
+    CHECK(errors::As(nerr, &pe) && pe->Op == "open" && pe->Err == os::Errno{ENOENT});
+
+    auto rerr2 = os::Remove(name);
+    CHECK(rerr2.Error() == "remove " + name + ": no such file or directory");
+    CHECK(os::Rename(name, name + ".new").Error() == "rename " + name + " " + name + ".new: no such file or directory");
+
+    auto [x, xerr] = os::OpenFile(os::TempDir(), O_WRONLY | O_CREAT | O_EXCL, 0644);
+    CHECK(x == nullptr && os::IsExist(xerr) && errors::Is(xerr, os::ErrExist));
+
+    // directories
+    auto dir = tempName("dir");
+    CHECK(os::MkdirAll(dir + "/a/b/", 0755) == nullptr && os::MkdirAll(dir + "/a/b", 0755) == nullptr);
+    CHECK(os::IsExist(os::Mkdir(dir + "/a", 0755)));
+    CHECK(os::Remove(dir + "/a/b") == nullptr && os::Remove(dir + "/a") == nullptr && os::Remove(dir) == nullptr);
+}
+
+static void testEnv() {
+    CHECK(os::Setenv("WALKNGO_TEST", "value") == nullptr && os::Getenv("WALKNGO_TEST") == "value");
+    CHECK(std::get<1>(os::LookupEnv("WALKNGO_TEST")));
+    os::Unsetenv("WALKNGO_TEST");
+    CHECK(os::Getenv("WALKNGO_TEST") == "" && !std::get<1>(os::LookupEnv("WALKNGO_TEST")));
+
+    auto [wd, err] = os::Getwd();
+    CHECK(err == nullptr && !wd.empty());
+}
+
+static void testIO() {
+    // the interfaces
+    auto b = bytes::NewBufferString("abc");
+    io::ReadWriter rw = b;
+    io::Reader r = rw;
+    io::Writer w = rw;
+    CHECK(r != nullptr && r == rw && io::Reader() == nullptr && *r.As<bytes::Buffer *>() == b);
+    CHECK(w.As<strings::Builder *>() == nullptr);
+
+    io::WriteString(w, "def");
+    auto [all, err] = io::ReadAll(slowReader{r, 2});
+    CHECK(err == nullptr && std::string(all) == "abcdef");
+
+    // Copy and CopyN
+    strings::Builder sb;
+    auto [n, cerr] = io::Copy(&sb, slowReader{strings::NewReader("0123456789"), 3});
+    CHECK(n == 10 && cerr == nullptr && sb.String() == "0123456789");
+
+    auto [n2, nerr] = io::CopyN(&sb, strings::NewReader("xyz"), 2);
+    CHECK(n2 == 2 && nerr == nullptr && sb.String() == "0123456789xy");
+    auto [n3, eerr] = io::CopyN(&sb, strings::NewReader("xyz"), 5);
+    CHECK(n3 == 3 && eerr == io::EOF_);
+
+    auto [n4, ferr] = io::ReadFull(strings::NewReader("ab"), Slice<byte>(3));
+    CHECK(n4 == 2 && ferr == io::ErrUnexpectedEOF);
+    auto [n5, ferr2] = io::ReadFull(strings::NewReader(""), Slice<byte>(3));
+    CHECK(n5 == 0 && ferr2 == io::EOF_);
+
+    CHECK(std::get<0>(io::Copy(io::Discard, strings::NewReader("discarded"))) == 9);
+    CHECK(io::NopCloser(r).Close() == nullptr);
+}
+
+static void testScanner() {
+    // lines, with a last line without \n and \r\n
+    auto s = bufio::NewScanner(slowReader{strings::NewReader("one\ntwo\r\n\nthree"), 2});
+    std::vector<std::string> lines;
+    while (s->Scan()) {
+        lines.push_back(s->Text());
+    }
+    CHECK(s->Err() == nullptr && lines == std::vector<std::string>({"one", "two", "", "three"}));
+    delete s;
+
+    // words and runes
+    s = bufio::NewScanner(strings::NewReader("  héllo\t wörld \n"));
+    s->Split(bufio::ScanWords);
+    std::string words;
+    while (s->Scan()) {
+        words += "[" + s->Text() + "]";
+    }
+    CHECK(words == "[héllo][wörld]");
+    delete s;
+
+    s = bufio::NewScanner(strings::NewReader("aé\xff"));
+    s->Split(bufio::ScanRunes);
+    std::string runes;
+    while (s->Scan()) {
+        runes += "[" + s->Text() + "]";
+    }
+    CHECK(runes == "[a][é][\xef\xbf\xbd]");
+    delete s;
+
+    // a token longer than the maximum
+    s = bufio::NewScanner(strings::NewReader(std::string(100, 'x') + "\n"));
+    s->Buffer(Slice<byte>(0, 10), 50);
+    CHECK(!s->Scan() && s->Err() == bufio::ErrTooLong);
+    delete s;
+
+    // a long line grows the buffer
+    s = bufio::NewScanner(strings::NewReader(std::string(10000, 'y') + "\nz"));
+    CHECK(s->Scan() && s->Text().size() == 10000 && s->Scan() && s->Text() == "z" && !s->Scan());
+    delete s;
+}
+
+static void testReaderWriter() {
+    auto r = bufio::NewReaderSize(slowReader{strings::NewReader("first line\r\nsecond\nhé"), 3}, 16);
+    auto [line, err] = r->ReadString('\n');
+    CHECK(line == "first line\r\n" && err == nullptr);
+    auto [l2, prefix, err2] = r->ReadLine();
+    CHECK(std::string(l2) == "second" && !prefix && err2 == nullptr);
+
+    auto [c, cerr] = r->ReadByte();
+    CHECK(c == 'h' && cerr == nullptr && r->UnreadByte() == nullptr && std::get<0>(r->ReadByte()) == 'h');
+    auto [ru, size, rerr] = r->ReadRune();
+    CHECK(ru == 0xe9 && size == 2 && rerr == nullptr);
+    auto [last, eof] = r->ReadString('\n');
+    CHECK(last == "" && eof == io::EOF_);
+    delete r;
+
+    // a line longer than the buffer
+    r = bufio::NewReaderSize(strings::NewReader(std::string(40, 'x') + "\n"), 16);
+    auto [p1, isPrefix, perr] = r->ReadLine();
+    CHECK(p1.len() == 16 && isPrefix && perr == nullptr);
+    auto [all, aerr] = r->ReadBytes('\n');
+    CHECK(all.len() == 25 && aerr == nullptr);
+    delete r;
+
+    // the writer flushes when the buffer is full, or with Flush
+    strings::Builder sb;
+    auto w = bufio::NewWriterSize(&sb, 8);
+    w->WriteString("abc");
+    w->WriteByte('d');
+    w->WriteRune(0x4e16);
+    CHECK(sb.Len() == 0 && w->Buffered() == 7 && w->Available() == 1);
+    w->WriteString("01");
+    CHECK(sb.String() == "abcd\xe4\xb8\x96" "0" && w->Buffered() == 1);
+    CHECK(w->Flush() == nullptr && sb.String() == "abcd\xe4\xb8\x96" "01" && w->Buffered() == 0);
+
+    // a large write with an empty buffer goes directly to the writer
+    w->Write(Slice<byte>(std::string(20, 'z')));
+    CHECK(sb.Len() == 29 && w->Buffered() == 0);
+    delete w;
+
+    // Fprintf on a bufio.Writer over a file
+    auto name = tempName("bufio");
+    auto [f, ferr] = os::Create(name);
+    CHECK(ferr == nullptr);
+    auto fw = bufio::NewWriter(f);
+    for (int i = 0; i < 1000; i++) {
+        fw->WriteString(std::to_string(i) + "\n");
+    }
+    CHECK(fw->Flush() == nullptr && f->Close() == nullptr);
+    delete fw;
+    delete f;
+
+    auto [g, gerr] = os::Open(name);
+    auto s = bufio::NewScanner(g);
+    int count = 0, sum = 0;
+    while (s->Scan()) {
+        count++;
+        sum += std::stoi(s->Text());
+    }
+    CHECK(gerr == nullptr && count == 1000 && sum == 999 * 1000 / 2);
+    g->Close();
+    delete s;
+    delete g;
+    os::Remove(name);
+}
+
+int main(int argc, char **argv) {
+    os::Args = os::detail::args(argc, argv);
+    CHECK(os::Args.len() == 1 && os::Args[0] == argv[0]);
+
+    return runTests({
+        {"File", testFile},
+        {"Env", testEnv},
+        {"IO", testIO},
+        {"Scanner", testScanner},
+        {"ReaderWriter", testReaderWriter},
+    });
+}
@@ -0,0 +1,142 @@
+//
+// Tests for the reference counted pointers in go.h (make runtime-test)
+//
+
+#include "test.h"
+#include <fmt.h>
+#include <errors.h>
+#include <io.h>
+#include <os.h>
+#include <go_strings.h>
+#include <go_atomic.h>
+
+struct node {
+    static constexpr const char *_type = "main.node";
+    int Value;
+    Ptr<node> Next;
+
+    template<class F> void _fields(F f) {
+        f("Value", Value);
+        f("Next", Next);
+    }
+};
+
+// counts the live instances
+struct counted {
+    static int live;
+
+    counted() {
+        live++;
+    }
+
+    counted(const counted&) {
+        live++;
+    }
+
+    ~counted() {
+        live--;
+    }
+};
+
+int counted::live = 0;
+
+static void testNew() {
+    auto p = New<int>();
+    CHECK(*p == 0);
+    *p = 42;
+    auto q = p;
+    CHECK(*q == 42 && p == q && p != nullptr);
+
+    auto s = New("text");
+    CHECK(*s == "text");
+
+    Ptr<int> n;
+    CHECK(n == nullptr && !(n != nullptr) && n != p);
+    CHECK(panics([&]() { *n = 1; }) == "runtime error: invalid memory address or nil pointer dereference");
+
+    // the field of a nil pointer
+    Ptr<node> nn;
+    CHECK(panics([&]() { nn->Value = 1; }) == "runtime error: invalid memory address or nil pointer dereference");
+}
+
+static void testLifetime() {
+    {
+        auto a = New(counted());
+        CHECK(counted::live == 1);
+        {
+            auto b = a;
+            CHECK(counted::live == 1);
+        }
+        CHECK(counted::live == 1);
+    }
+    CHECK(counted::live == 0);
+
+    // a list, deleted when the head is
+    {
+        auto head = New(node{1, nullptr});
+        head->Next = New(node{2, nullptr});
+        head->Next->Next = New(node{3, nullptr});
+
+        int sum = 0;
+        for (auto p = head; p != nullptr; p = p->Next) {
+            sum += p->Value;
+        }
+        CHECK(sum == 6);
+    }
+
+    // a pointer to a field keeps alive the struct that contains it
+    Ptr<int> field;
+    {
+        auto n = New(node{7, nullptr});
+        field = Ref(n, &n->Value);
+    }
+    CHECK(*field == 7);
+}
+
+static void testRef() {
+    static int global = 5;
+    auto p = Ref(&global);
+    CHECK(*p == 5 && p.shared().use_count() == 0 && p.get() == &global);
+    *p = 6;
+    CHECK(global == 6);
+
+    // a raw pointer converts to and from a Ptr
+    int *raw = p;
+    CHECK(raw == &global && p == raw && raw == p);
+
+    // the runtime functions that take a raw pointer
+    auto n = New<long long>();
+    atomic::AddInt64(n, 3);
+    CHECK(*n == 3);
+}
+
+static void testInterfaces() {
+    Ptr<strings::Reader> r = strings::NewReader("abc");
+    io::Reader ir = r;
+
+    auto [sr, ok] = typeAssertOk<Ptr<strings::Reader>>(ir);
+    CHECK(ok && sr->Len() == 3);
+
+    // a Ptr in an error, and the raw pointer of an error as a Ptr
+    auto [f, err] = os::Open("/nonexistent/walkngo");
+    CHECK(f == nullptr);
+
+    auto pe = typeAssert<Ptr<os::PathError>>(err);
+    CHECK(pe->Op == "open");
+    CHECK((typeCase<std::tuple<Ptr<os::LinkError>>, std::tuple<Ptr<os::PathError>>>(err) == 1));
+
+    auto pt = New<Ptr<os::PathError>>();
+    CHECK(errors::As(err, pt) && (*pt)->Path == "/nonexistent/walkngo");
+
+    // %v of a pointer to a struct
+    CHECK(fmt::Sprintf("%v", New(node{1, nullptr})) == "&{1 <nil>}");
+}
+
+int main() {
+    return runTests({
+        {"New", testNew},
+        {"Lifetime", testLifetime},
+        {"Ref", testRef},
+        {"Interfaces", testInterfaces},
+    });
+}
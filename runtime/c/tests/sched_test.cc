@@ -0,0 +1,69 @@
+//
+// Tests for the goroutine scheduler in go.h (make runtime-test)
+//
+
+#include "test.h"
+#include <sync.h>
+#include <go_atomic.h>
+
+// n goroutines blocked on a channel use at most the thread limit, and they all run
+static void blockMany(int n, int limit) {
+    auto& s = Scheduler::Get();
+    int prev = s.SetMaxThreads(limit);
+
+    Chan<int> start(0);
+    sync::WaitGroup wg;
+    atomic::Int32 done;
+
+    for (int i = 0; i < n; i++) {
+        wg.Add(1);
+        Goroutine([&]() {
+            start.Receive();
+            done.Add(1);
+            wg.Done();
+        });
+    }
+
+    std::this_thread::sleep_for(std::chrono::milliseconds(200));
+    CHECK(s.Threads() <= limit);
+    CHECK(done.Load() == 0);
+
+    start.Close();
+    wg.Wait();
+    CHECK(done.Load() == n);
+    CHECK(s.Threads() <= limit);
+
+    s.SetMaxThreads(prev);
+}
+
+static void testThreadLimit() {
+    blockMany(2000, 50);
+}
+
+static void testManyGoroutines() {
+    blockMany(20000, 1000); // the default limit
+}
+
+// go f(x): the arguments are evaluated when the goroutine is started
+static void testArguments() {
+    Chan<int> ch(1);
+    int x = 1;
+    Goroutine([](Chan<int> c, int v) { c.Send(v); }, ch, x);
+    x = 2;
+    CHECK(ch.Receive() == 1 && x == 2);
+
+    // a goroutine has its own id
+    Chan<int> ids(2);
+    Goroutine([ids]() { ids.Send(goroutineId()); });
+    Goroutine([ids]() { ids.Send(goroutineId()); });
+    int a = ids.Receive(), b = ids.Receive();
+    CHECK(a != b && a != goroutineId() && b != goroutineId());
+}
+
+int main() {
+    return runTests({
+        {"Arguments", testArguments},
+        {"ThreadLimit", testThreadLimit},
+        {"ManyGoroutines", testManyGoroutines},
+    });
+}
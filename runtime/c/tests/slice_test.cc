@@ -0,0 +1,140 @@
+//
+// Tests for the slices in go.h (make runtime-test)
+//
+
+#include "test.h"
+
+static void testMake() {
+    Slice<int> nil;
+    CHECK(nil == nullptr && len(nil) == 0 && cap(nil) == 0);
+
+    Slice<int> s(3, 10);
+    CHECK(s != nullptr && len(s) == 3 && cap(s) == 10 && s[0] == 0 && s[2] == 0);
+
+    Slice<int> lit{1, 2, 3};
+    CHECK(len(lit) == 3 && cap(lit) == 3 && lit[1] == 2);
+
+    // an empty slice is not nil
+    CHECK(Slice<int>(0) != nullptr);
+
+    CHECK(panics([]() { Slice<int>(-1); }) == "runtime error: makeslice: len out of range");
+    CHECK(panics([]() { Slice<int>(2, 1); }) == "runtime error: makeslice: len out of range");
+}
+
+static void testIndex() {
+    Slice<int> s{1, 2, 3};
+    s[1] = 5;
+    CHECK(s[1] == 5);
+
+    CHECK(panics([&]() { s[3] = 0; }) == "runtime error: index out of range [3] with length 3");
+    CHECK(panics([&]() { Slice<int>()[0]; }) == "runtime error: index out of range [0] with length 0");
+}
+
+static void testSliceExpr() {
+    Slice<int> s{0, 1, 2, 3, 4};
+
+    auto t = SliceExpr(s, 1, 3);
+    CHECK(len(t) == 2 && cap(t) == 4 && t[0] == 1);
+
+    // the slices share the backing array
+    t[0] = 10;
+    CHECK(s[1] == 10);
+
+    auto u = SliceExpr(s, 1, 2, 3);
+    CHECK(len(u) == 1 && cap(u) == 2);
+
+    // up to the capacity, not the length
+    CHECK(len(SliceExpr(t, 0, 4)) == 4 && SliceExpr(t, 0, 4)[3] == 4);
+    CHECK(len(SliceExpr(s, 5)) == 0);
+
+    CHECK(panics([&]() { SliceExpr(t, 0, 5); }) == "runtime error: slice bounds out of range [0:5] with capacity 4");
+    CHECK(panics([&]() { SliceExpr(s, 3, 2); }) != "");
+
+    // a slice of an array
+    int a[3] = {1, 2, 3};
+    auto as = SliceExpr(a, 1);
+    as[0] = 9;
+    CHECK(a[1] == 9 && len(as) == 2 && cap(as) == 2);
+
+    // a slice of a string
+    CHECK(SliceExpr(std::string("hello"), 1, 3) == "el" && SliceExpr(std::string("hello"), 3) == "lo");
+}
+
+static void testAppend() {
+    Slice<int> s;
+    for (int i = 0; i < 10; i++) {
+        s = append(s, i);
+    }
+    CHECK(len(s) == 10 && cap(s) >= 10 && s[0] == 0 && s[9] == 9);
+
+    // append writes in the backing array if there is room
+    Slice<int> a(2, 4);
+    auto b = append(a, 1);
+    auto c = append(a, 2);
+    CHECK(len(a) == 2 && b[2] == 2 && c[2] == 2);
+
+    // and copies it if there isn't
+    auto d = append(SliceExpr(a, 0, 2, 2), 3);
+    d[0] = 7;
+    CHECK(a[0] == 0 && d[2] == 3 && len(d) == 3);
+
+    auto e = appendSlice(s, Slice<int>{10, 11});
+    CHECK(len(e) == 12 && e[11] == 11);
+
+    auto bs = appendSlice(Slice<byte>(), std::string("hi"));
+    CHECK(len(bs) == 2 && bs[0] == 'h' && bs[1] == 'i');
+
+    CHECK(len(append(Slice<int>(), 1, 2, 3)) == 3);
+}
+
+static void testCopy() {
+    Slice<int> s{1, 2, 3};
+    Slice<int> d(2);
+    CHECK(copy(d, s) == 2 && d[0] == 1 && d[1] == 2);
+
+    // overlapping slices
+    CHECK(copy(SliceExpr(s, 1), s) == 2);
+    CHECK(s[0] == 1 && s[1] == 1 && s[2] == 2);
+
+    Slice<int> t{1, 2, 3};
+    CHECK(copy(t, SliceExpr(t, 1)) == 2);
+    CHECK(t[0] == 2 && t[1] == 3 && t[2] == 3);
+
+    Slice<byte> b(3);
+    CHECK(copy(b, std::string("abcd")) == 3 && b[2] == 'c');
+
+    CHECK(copy(Slice<int>(), s) == 0);
+}
+
+static void testRange() {
+    Slice<int> s{5, 6};
+    int keys = 0, sum = 0;
+    for (auto [i, v] : Range(s)) {
+        keys += i;
+        sum += v;
+    }
+    CHECK(keys == 1 && sum == 11);
+
+    int a[3] = {1, 2, 3};
+    sum = 0;
+    for (auto [i, v] : Range(a)) {
+        sum += i * v;
+    }
+    CHECK(sum == 8);
+
+    // a nil slice has no elements
+    for (auto [i, v] : Range(Slice<int>())) {
+        CHECK(i < 0 && v < 0);
+    }
+}
+
+int main() {
+    return runTests({
+        {"Make", testMake},
+        {"Index", testIndex},
+        {"SliceExpr", testSliceExpr},
+        {"Append", testAppend},
+        {"Copy", testCopy},
+        {"Range", testRange},
+    });
+}
@@ -0,0 +1,115 @@
+//
+// Tests for strconv.h (make runtime-test)
+//
+
+#include "test.h"
+#include <fmt.h>
+#include <errors.h>
+#include <strconv.h>
+
+static void testAtoi() {
+    auto [n, err] = strconv::Atoi("-42");
+    CHECK(n == -42 && err == nullptr);
+
+    std::tie(n, err) = strconv::Atoi("+7");
+    CHECK(n == 7 && err == nullptr);
+
+    std::tie(n, err) = strconv::Atoi("4x");
+    CHECK(n == 0 && err != nullptr);
+    CHECK(err.Error() == "strconv.Atoi: parsing \"4x\": invalid syntax");
+    CHECK(errors::Is(err, strconv::ErrSyntax));
+
+    std::tie(n, err) = strconv::Atoi("");
+    CHECK(err != nullptr && errors::Is(err, strconv::ErrSyntax));
+
+    CHECK(strconv::Itoa(-123) == "-123" && strconv::Itoa(0) == "0");
+}
+
+static void testParseInt() {
+    auto [i, err] = strconv::ParseInt("ff", 16, 64);
+    CHECK(i == 255 && err == nullptr);
+
+    // base 0 uses the prefix
+    std::tie(i, err) = strconv::ParseInt("0x1F", 0, 64);
+    CHECK(i == 31 && err == nullptr);
+    std::tie(i, err) = strconv::ParseInt("0b101", 0, 8);
+    CHECK(i == 5 && err == nullptr);
+    std::tie(i, err) = strconv::ParseInt("1_000", 0, 64);
+    CHECK(i == 1000 && err == nullptr);
+
+    // out of range: the closest value
+    std::tie(i, err) = strconv::ParseInt("300", 10, 8);
+    CHECK(i == 127 && errors::Is(err, strconv::ErrRange));
+    CHECK(err.Error() == "strconv.ParseInt: parsing \"300\": value out of range");
+
+    // the *NumError
+    strconv::NumError *ne = nullptr;
+    CHECK(errors::As(err, &ne) && ne->Func == "ParseInt" && ne->Num == "300" && ne->Err == strconv::ErrRange);
+
+    std::tie(i, err) = strconv::ParseInt("-9223372036854775808", 10, 64);
+    CHECK(i == -9223372036854775807LL - 1 && err == nullptr);
+
+    auto [u, uerr] = strconv::ParseUint("-1", 10, 64);
+    CHECK(u == 0 && errors::Is(uerr, strconv::ErrSyntax));
+
+    CHECK(strconv::FormatInt(-255, 16) == "-ff" && strconv::FormatInt(35, 36) == "z");
+    CHECK(strconv::FormatUint(5, 2) == "101");
+    CHECK(std::string(strconv::AppendInt(Slice<byte>(std::string("n=")), 42, 10)) == "n=42");
+}
+
+static void testParseBool() {
+    auto [b, err] = strconv::ParseBool("true");
+    CHECK(b && err == nullptr);
+
+    std::tie(b, err) = strconv::ParseBool("F");
+    CHECK(!b && err == nullptr);
+
+    std::tie(b, err) = strconv::ParseBool("yes");
+    CHECK(!b && errors::Is(err, strconv::ErrSyntax));
+
+    CHECK(strconv::FormatBool(true) == "true" && strconv::FormatBool(false) == "false");
+}
+
+static void testFloat() {
+    auto [f, err] = strconv::ParseFloat("3.25", 64);
+    CHECK(f == 3.25 && err == nullptr);
+
+    std::tie(f, err) = strconv::ParseFloat("1e400", 64);
+    CHECK(std::isinf(f) && errors::Is(err, strconv::ErrRange));
+
+    std::tie(f, err) = strconv::ParseFloat("1.5x", 64);
+    CHECK(f == 0 && errors::Is(err, strconv::ErrSyntax));
+
+    CHECK(strconv::FormatFloat(3.14159, 'f', 2, 64) == "3.14");
+    CHECK(strconv::FormatFloat(0.1, 'g', -1, 64) == "0.1");
+    CHECK(strconv::FormatFloat(1234.5, 'e', 3, 64) == "1.234e+03");
+    CHECK(strconv::FormatFloat(100, 'f', -1, 64) == "100");
+    CHECK(strconv::FormatFloat(float64(float32(0.1)), 'g', -1, 32) == "0.1");
+}
+
+static void testQuote() {
+    CHECK(strconv::Quote("a\"b\n") == "\"a\\\"b\\n\"");
+    CHECK(strconv::Quote("世界") == "\"世界\"" && strconv::QuoteToASCII("世界") == "\"\\u4e16\\u754c\"");
+    CHECK(strconv::QuoteRune('x') == "'x'" && strconv::QuoteRune('\'') == "'\\''");
+
+    auto [s, err] = strconv::Unquote("\"a\\tb\"");
+    CHECK(s == "a\tb" && err == nullptr);
+
+    std::tie(s, err) = strconv::Unquote("`raw\\n`");
+    CHECK(s == "raw\\n" && err == nullptr);
+
+    std::tie(s, err) = strconv::Unquote("\"open");
+    CHECK(s == "" && errors::Is(err, strconv::ErrSyntax));
+
+    CHECK(strconv::CanBackquote("ok") && !strconv::CanBackquote("no`"));
+}
+
+int main() {
+    return runTests({
+        {"Atoi", testAtoi},
+        {"ParseInt", testParseInt},
+        {"ParseBool", testParseBool},
+        {"Float", testFloat},
+        {"Quote", testQuote},
+    });
+}
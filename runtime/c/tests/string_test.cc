@@ -0,0 +1,76 @@
+//
+// Tests for the UTF-8 strings in go.h: range, []rune(s), string(r) (make runtime-test)
+//
+
+#include "test.h"
+#include <fmt.h>
+
+static void testRange() {
+    std::string s = "aé世😀";
+    std::vector<std::pair<int, rune>> got;
+    for (auto [i, r] : RangeRunes(s)) {
+        got.push_back({i, r});
+    }
+    CHECK((got == std::vector<std::pair<int, rune>>{{0, 'a'}, {1, 0xE9}, {3, 0x4E16}, {6, 0x1F600}}));
+
+    // an invalid encoding is RuneError, one byte at a time
+    got.clear();
+    for (auto [i, r] : RangeRunes("a\xff\xe4\xb8" "b")) {
+        got.push_back({i, r});
+    }
+    CHECK((got == std::vector<std::pair<int, rune>>{{0, 'a'}, {1, 0xFFFD}, {2, 0xFFFD}, {3, 0xFFFD}, {4, 'b'}}));
+
+    // the string is evaluated once
+    int n = 0;
+    rune last = 0;
+    for (auto [i, r] : RangeRunes(s)) {
+        s = "";
+        n = i;
+        last = r;
+    }
+    CHECK(n == 6 && last == 0x1F600 && s == "");
+
+    for (auto [i, r] : RangeRunes("")) {
+        CHECK(i < 0 && r < 0);
+    }
+}
+
+static void testRunes() {
+    std::string s = "héllo, 世界";
+    auto rs = Slice<rune>(s);
+    CHECK(len(rs) == 9 && len(s) == 14 && rs[1] == 0xE9 && rs[7] == 0x4E16);
+
+    rs[0] = 'H';
+    CHECK(std::string(rs) == "Héllo, 世界" && s == "héllo, 世界");
+    CHECK(std::string(SliceExpr(rs, 7)) == "世界");
+    CHECK(len(Slice<rune>(std::string("a\xff"))) == 2 && Slice<rune>(std::string("a\xff"))[1] == 0xFFFD);
+    CHECK(len(Slice<rune>(std::string())) == 0 && std::string(Slice<rune>()) == "");
+
+    // the bytes are not decoded
+    auto b = Slice<byte>(s);
+    CHECK(len(b) == 14 && b[1] == 0xC3 && std::string(b) == s);
+}
+
+static void testRuneString() {
+    CHECK(runeString('a') == "a" && runeString(rune(0xE9)) == "é" && runeString(0x1F600) == "😀");
+
+    // a byte is a rune, not a byte of the encoding
+    CHECK(runeString(byte(0xFF)) == "ÿ");
+
+    // the invalid runes
+    CHECK(runeString(-1) == "�" && runeString(0x110000) == "�" && runeString(0xD800) == "�");
+    CHECK(runeString(int64(1LL << 32 | 'a')) == "�" && runeString(uint64(-1)) == "�");
+
+    // s[i] is a byte
+    std::string s = "é";
+    CHECK(byte(s[0]) == 0xC3 && runeString(byte(s[0])) == "Ã");
+    CHECK(fmt::Sprint(byte(s[0])) == "195");
+}
+
+int main() {
+    return runTests({
+        {"Range", testRange},
+        {"Runes", testRunes},
+        {"RuneString", testRuneString},
+    });
+}
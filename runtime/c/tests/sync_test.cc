@@ -0,0 +1,409 @@
+//
+// Tests for sync.h and go_atomic.h, under contention (make runtime-test)
+//
+
+#include "test.h"
+#include <sync.h>
+#include <go_atomic.h>
+
+static const int N = 16;   // goroutines
+static const int M = 2000; // iterations per goroutine
+
+// spawn runs f(i) in n goroutines and waits for them
+template<class F> void spawn(int n, F f) {
+    sync::WaitGroup wg;
+    for (int i = 0; i < n; i++) {
+        wg.Add(1);
+        Goroutine([&wg, f, i]() {
+            f(i);
+            wg.Done();
+        });
+    }
+    wg.Wait();
+}
+
+static void sleepMs(int ms) {
+    std::this_thread::sleep_for(std::chrono::milliseconds(ms));
+}
+
+static void testMutex() {
+    sync::Mutex mu;
+    int count = 0;
+
+    spawn(N, [&](int) {
+        for (int j = 0; j < M; j++) {
+            mu.Lock();
+            count++;
+            mu.Unlock();
+        }
+    });
+    CHECK(count == N * M);
+
+    CHECK(mu.TryLock());
+    CHECK(!mu.TryLock());
+
+    // a Mutex can be unlocked by another goroutine
+    std::thread([&]() { mu.Unlock(); }).join();
+    CHECK(mu.TryLock());
+    mu.Unlock();
+}
+
+static void testRWMutex() {
+    sync::RWMutex rw;
+    int a = 0, b = 0;
+    atomic::Int32 bad, readers, maxReaders;
+
+    spawn(N, [&](int i) {
+        for (int j = 0; j < M / 4; j++) {
+            if (i % 4 == 0) {
+                rw.Lock();
+                a++;
+                b++;
+                rw.Unlock();
+            } else {
+                rw.RLock();
+                int r = readers.Add(1);
+                for (int m = maxReaders.Load(); r > m && !maxReaders.CompareAndSwap(m, r); m = maxReaders.Load()) {
+                }
+                if (a != b) {
+                    bad.Add(1);
+                }
+                readers.Add(-1);
+                rw.RUnlock();
+            }
+        }
+    });
+    CHECK(bad.Load() == 0);
+    CHECK(a == (N / 4) * (M / 4) && a == b);
+
+    // the readers share the lock
+    rw.RLock();
+    CHECK(rw.TryRLock());
+    CHECK(!rw.TryLock());
+    rw.RUnlock();
+
+    // a pending writer excludes the new readers
+    atomic::Bool locked;
+    std::thread writer([&]() {
+        rw.Lock();
+        locked.Store(true);
+        rw.Unlock();
+    });
+    sleepMs(50);
+    CHECK(!rw.TryRLock());
+    CHECK(!locked.Load());
+    rw.RUnlock();
+    writer.join();
+    CHECK(locked.Load());
+
+    // RLocker
+    sync::Locker *l = rw.RLocker();
+    l->Lock();
+    CHECK(rw.TryRLock());
+    rw.RUnlock();
+    l->Unlock();
+    CHECK(rw.TryLock());
+    rw.Unlock();
+}
+
+static void testCond() {
+    sync::Mutex mu;
+    sync::Cond *c = sync::NewCond(&mu);
+    std::deque<int> queue;
+    int received = 0;
+    long sum = 0;
+
+    // N consumers, N producers
+    sync::WaitGroup wg;
+    for (int i = 0; i < N; i++) {
+        wg.Add(1);
+        Goroutine([&]() {
+            for (int j = 0; j < M / 10; j++) {
+                mu.Lock();
+                while (queue.empty()) {
+                    c->Wait();
+                }
+                sum += queue.front();
+                queue.pop_front();
+                received++;
+                mu.Unlock();
+            }
+            wg.Done();
+        });
+    }
+
+    spawn(N, [&](int) {
+        for (int j = 0; j < M / 10; j++) {
+            mu.Lock();
+            queue.push_back(j);
+            c->Signal();
+            mu.Unlock();
+        }
+    });
+    wg.Wait();
+
+    CHECK(received == N * (M / 10));
+    CHECK(sum == long(N) * (M / 10) * (M / 10 - 1) / 2);
+
+    // Broadcast wakes all the waiters
+    bool ready = false;
+    int woken = 0;
+    sync::WaitGroup waiters;
+    for (int i = 0; i < N; i++) {
+        waiters.Add(1);
+        Goroutine([&]() {
+            mu.Lock();
+            while (!ready) {
+                c->Wait();
+            }
+            woken++;
+            mu.Unlock();
+            waiters.Done();
+        });
+    }
+
+    sleepMs(50);
+    mu.Lock();
+    ready = true;
+    c->Broadcast();
+    mu.Unlock();
+    waiters.Wait();
+    CHECK(woken == N);
+
+    delete c;
+}
+
+static void testWaitGroup() {
+    sync::WaitGroup wg;
+    atomic::Int64 count;
+
+    for (int i = 0; i < N; i++) {
+        wg.Go([&]() {
+            for (int j = 0; j < M; j++) {
+                count.Add(1);
+            }
+        });
+    }
+    wg.Wait();
+    CHECK(count.Load() == N * M);
+
+    // Wait returns immediately if the counter is zero
+    wg.Wait();
+
+    CHECK(panics([&]() { wg.Done(); }) == "sync: negative WaitGroup counter");
+    wg.Add(1);
+    wg.Done();
+}
+
+static void testOnce() {
+    sync::Once once;
+    atomic::Int32 calls;
+
+    spawn(N, [&](int) {
+        once.Do([&]() {
+            sleepMs(10);
+            calls.Add(1);
+        });
+        CHECK(calls.Load() == 1); // Do returns after the call completes
+    });
+    CHECK(calls.Load() == 1);
+
+    // a panic counts as a call
+    sync::Once p;
+    CHECK(panics([&]() { p.Do([]() { panic("once"); }); }) == "once");
+    bool called = false;
+    p.Do([&]() { called = true; });
+    CHECK(!called);
+
+    calls.Store(0);
+    auto f = sync::OnceFunc([&]() { calls.Add(1); });
+    spawn(N, [&](int) { f(); });
+    CHECK(calls.Load() == 1);
+
+    // the panic is repeated by every call
+    auto g = sync::OnceFunc([&]() {
+        calls.Add(1);
+        panic("oncefunc");
+    });
+    CHECK(panics(g) == "oncefunc");
+    CHECK(panics(g) == "oncefunc");
+    CHECK(calls.Load() == 2);
+
+    calls.Store(0);
+    auto v = sync::OnceValue([&]() {
+        calls.Add(1);
+        return std::string("value");
+    });
+    spawn(N, [&](int) { CHECK(v() == "value"); });
+    CHECK(calls.Load() == 1);
+
+    auto vs = sync::OnceValues([]() { return std::make_tuple(42, error()); });
+    auto [n, err] = vs();
+    CHECK(n == 42 && err == nullptr);
+}
+
+static void testPool() {
+    atomic::Int32 created;
+    sync::Pool pool{[&]() -> std::any {
+        created.Add(1);
+        return std::make_shared<std::string>();
+    }};
+
+    spawn(N, [&](int) {
+        for (int j = 0; j < M / 10; j++) {
+            auto s = std::any_cast<std::shared_ptr<std::string>>(pool.Get());
+            s->assign("x");
+            pool.Put(s);
+        }
+    });
+    CHECK(created.Load() >= 1 && created.Load() <= N);
+
+    sync::Pool empty;
+    CHECK(!empty.Get().has_value());
+    empty.Put(1);
+    CHECK(std::any_cast<int>(empty.Get()) == 1);
+}
+
+static void testMap() {
+    sync::Map m;
+
+    spawn(N, [&](int i) {
+        for (int j = 0; j < M / 10; j++) {
+            m.Store(i * M + j, j);
+            auto [v, ok] = m.Load(i * M + j);
+            CHECK(ok && std::any_cast<int>(v) == j);
+        }
+
+        // a single goroutine stores the value
+        auto [v, loaded] = m.LoadOrStore("shared", i);
+        if (!loaded) {
+            CHECK(std::any_cast<int>(v) == i);
+        }
+    });
+
+    int count = 0;
+    m.Range([&](std::any k, std::any) {
+        count++;
+        return true;
+    });
+    CHECK(count == N * (M / 10) + 1);
+
+    // keys of different types are different
+    m.Store(1, "int");
+    m.Store("1", "string");
+    CHECK(std::any_cast<std::string>(std::get<0>(m.Load(1))) == "int");
+    CHECK(std::any_cast<std::string>(std::get<0>(m.Load(std::string("1")))) == "string");
+    CHECK(!std::get<1>(m.Load(1L)));
+
+    // concurrent CompareAndSwap: every increment is counted once
+    m.Store("counter", 0);
+    spawn(N, [&](int) {
+        for (int j = 0; j < M / 10; j++) {
+            for (;;) {
+                int n = std::any_cast<int>(std::get<0>(m.Load("counter")));
+                if (m.CompareAndSwap("counter", n, n + 1)) {
+                    break;
+                }
+            }
+        }
+    });
+    CHECK(std::any_cast<int>(std::get<0>(m.Load("counter"))) == N * (M / 10));
+
+    auto [previous, loaded] = m.Swap("counter", -1);
+    CHECK(loaded && std::any_cast<int>(previous) == N * (M / 10));
+    CHECK(!m.CompareAndDelete("counter", 0));
+    CHECK(m.CompareAndDelete("counter", -1));
+    CHECK(!std::get<1>(m.LoadAndDelete("counter")));
+
+    // Range can modify the map
+    m.Range([&](std::any k, std::any) {
+        m.Delete(k);
+        return true;
+    });
+    count = 0;
+    m.Range([&](std::any, std::any) {
+        count++;
+        return true;
+    });
+    CHECK(count == 0);
+}
+
+static void testAtomic() {
+    long long n64 = 0;
+    int n32 = 0;
+    uint32 u32 = 0;
+
+    spawn(N, [&](int) {
+        for (int j = 0; j < M; j++) {
+            atomic::AddInt64(&n64, 2);
+            atomic::AddInt32(&n32, 1);
+            atomic::AddUint32(&u32, 1);
+        }
+    });
+    CHECK(atomic::LoadInt64(&n64) == 2LL * N * M);
+    CHECK(atomic::LoadInt32(&n32) == N * M);
+    CHECK(atomic::LoadUint32(&u32) == uint32(N * M));
+
+    // decrement an unsigned value
+    CHECK(atomic::AddUint32(&u32, ~uint32(0)) == uint32(N * M - 1));
+
+    CHECK(atomic::SwapInt32(&n32, 5) == N * M);
+    CHECK(!atomic::CompareAndSwapInt32(&n32, 4, 6));
+    CHECK(atomic::CompareAndSwapInt32(&n32, 5, 6) && n32 == 6);
+    CHECK(atomic::OrInt32(&n32, 1) == 6 && n32 == 7);
+    CHECK(atomic::AndInt32(&n32, 3) == 7 && n32 == 3);
+
+    // a spin lock with CompareAndSwap
+    atomic::Int32 lock;
+    int count = 0;
+    spawn(N, [&](int) {
+        for (int j = 0; j < M; j++) {
+            while (!lock.CompareAndSwap(0, 1)) {
+                std::this_thread::yield();
+            }
+            count++;
+            lock.Store(0);
+        }
+    });
+    CHECK(count == N * M);
+
+    atomic::Uint64 u64;
+    CHECK(u64.Add(10) == 10 && u64.Swap(3) == 10 && u64.Load() == 3);
+
+    atomic::Bool b;
+    CHECK(!b.Load() && b.CompareAndSwap(false, true) && b.Load() && b.Swap(false));
+
+    int x = 1, y = 2;
+    atomic::Pointer<int> p;
+    CHECK(p.Load() == nullptr);
+    p.Store(&x);
+    CHECK(p.CompareAndSwap(&x, &y) && *p.Load() == 2);
+
+    atomic::Value v;
+    CHECK(!v.Load().has_value());
+    spawn(N, [&](int i) {
+        for (int j = 0; j < M / 10; j++) {
+            v.Store(i);
+            int n = std::any_cast<int>(v.Load());
+            CHECK(n >= 0 && n < N);
+        }
+    });
+    CHECK(panics([&]() { v.Store("x"); }) == "sync/atomic: store of inconsistently typed value into Value");
+    CHECK(panics([&]() { v.Store(std::any()); }) == "sync/atomic: store of nil value into Value");
+    v.Store(7);
+    CHECK(std::any_cast<int>(v.Swap(8)) == 7);
+    CHECK(!v.CompareAndSwap(7, 9) && v.CompareAndSwap(8, 9) && std::any_cast<int>(v.Load()) == 9);
+}
+
+int main() {
+    return runTests({
+        {"Mutex", testMutex},
+        {"RWMutex", testRWMutex},
+        {"Cond", testCond},
+        {"WaitGroup", testWaitGroup},
+        {"Once", testOnce},
+        {"Pool", testPool},
+        {"Map", testMap},
+        {"Atomic", testAtomic},
+    });
+}
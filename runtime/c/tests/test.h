@@ -0,0 +1,51 @@
+//
+// The harness of the runtime tests (make runtime-test): CHECK records a failure and goes on,
+// main runs the tests with runTests, that prints "ok" or "FAIL" for each one.
+//
+
+#ifndef WALKNGO_TEST_H
+#define WALKNGO_TEST_H
+
+#include <go.h>
+
+#include <initializer_list>
+#include <iostream>
+#include <string>
+
+static int failures = 0;
+
+#define CHECK(cond) do { \
+    if (!(cond)) { \
+        std::cerr << __FILE__ << ":" << __LINE__ << ": failed: " #cond << std::endl; \
+        failures++; \
+    } \
+} while (0)
+
+struct Test {
+    const char *name;
+    void (*run)();
+};
+
+// runTests runs the tests and returns the exit status of the program
+inline int runTests(std::initializer_list<Test> tests) {
+    for (auto& t : tests) {
+        int before = failures;
+        t.run();
+        std::cout << (failures == before ? "ok   " : "FAIL ") << t.name << std::endl;
+    }
+
+    return failures == 0 ? 0 : 1;
+}
+
+// panics returns the message of the panic of f ("" if it doesn't panic)
+template<class F> std::string panics(F f) {
+    try {
+        f();
+    } catch (const Panic& p) {
+        panicState() = PanicState();
+        return p.message;
+    }
+    return "";
+}
+
+#endif
@@ -0,0 +1,226 @@
+//
+// Tests for go_time.h and select (make runtime-test)
+//
+
+#include "test.h"
+#include <go_time.h>
+#include <sync.h>
+#include <go_atomic.h>
+
+static const int N = 16;   // goroutines
+static const int M = 500;  // values per goroutine
+
+using go_time::Duration;
+using go_time::Millisecond;
+using go_time::Second;
+
+// spawn runs f(i) in n goroutines, and returns a channel closed when they are done
+template<class F> Chan<bool> spawn(int n, F f) {
+    Chan<bool> done(0);
+    auto wg = std::make_shared<sync::WaitGroup>();
+    wg->Add(n);
+    for (int i = 0; i < n; i++) {
+        Goroutine([wg, f, i]() {
+            f(i);
+            wg->Done();
+        });
+    }
+    Goroutine([wg, done]() {
+        wg->Wait();
+        done.Close();
+    });
+    return done;
+}
+
+static void testDuration() {
+    CHECK(Duration(0).String() == "0s");
+    CHECK(Duration(1).String() == "1ns");
+    CHECK(Duration(1100).String() == "1.1µs");
+    CHECK((2200 * go_time::Microsecond).String() == "2.2ms");
+    CHECK((3300 * Millisecond).String() == "3.3s");
+    CHECK((4 * go_time::Minute + 5 * Second).String() == "4m5s");
+    CHECK((-(5 * go_time::Hour + 6 * go_time::Minute + 7001 * Millisecond)).String() == "-5h6m7.001s");
+    CHECK(Duration(-9223372036854775807LL - 1).String() == "-2562047h47m16.854775808s");
+
+    Duration d = 1500 * Millisecond;
+    d += Second;
+    CHECK(d == 2500 * Millisecond && d.Seconds() == 2.5 && d.Milliseconds() == 2500);
+    CHECK(d.Round(Second) == 3 * Second && d.Truncate(Second) == 2 * Second);
+    CHECK((d / 2).String() == "1.25s" && (d % Second).String() == "500ms");
+}
+
+static void testFormat() {
+    auto t = go_time::Date(2009, go_time::November, 10, 23, 4, 5, 123456789, go_time::UTC);
+
+    CHECK(t.String() == "2009-11-10 23:04:05.123456789 +0000 UTC");
+    CHECK(t.Format(go_time::RFC3339Nano) == "2009-11-10T23:04:05.123456789Z");
+    CHECK(t.Format(go_time::Kitchen) == "11:04PM");
+    CHECK(t.Format("Mon Jan _2 2006 .000 -07:00") == "Tue Nov 10 2009 .123 +00:00");
+    CHECK(t.In(go_time::FixedZone("X", -(3 * 3600 + 1800))).Format(go_time::RFC1123Z) == "Tue, 10 Nov 2009 19:34:05 -0330");
+    CHECK(t.AddDate(0, 1, 22).Format(go_time::DateOnly) == "2010-01-01");
+    CHECK(t.Weekday() == go_time::Tuesday && t.YearDay() == 314 && t.Month().String() == "November");
+    CHECK(go_time::Time().IsZero() && go_time::Time().String() == "0001-01-01 00:00:00 +0000 UTC");
+
+    // the monotonic clock
+    auto start = go_time::Now();
+    go_time::Sleep(10 * Millisecond);
+    CHECK(go_time::Since(start) >= 10 * Millisecond && go_time::Now().After(start));
+    CHECK(start.String().find(" m=+") != std::string::npos);
+}
+
+static void testTimer() {
+    auto start = go_time::Now();
+    auto t = go_time::NewTimer(20 * Millisecond);
+    auto fired = t->C.Receive();
+    CHECK(fired.Sub(start) >= 20 * Millisecond);
+    CHECK(!t->Stop());
+
+    // a stopped timer doesn't fire
+    CHECK(t->Reset(10 * Millisecond) == false);
+    CHECK(t->Stop());
+    go_time::Sleep(20 * Millisecond);
+    CHECK(t->C.len() == 0);
+
+    // a reset timer fires once, at the new time
+    t->Reset(10 * Millisecond);
+    CHECK(t->Reset(30 * Millisecond));
+    start = go_time::Now();
+    t->C.Receive();
+    CHECK(go_time::Since(start) >= 25 * Millisecond);
+    delete t;
+
+    atomic::Int32 calls;
+    Chan<bool> done(0);
+    auto f = go_time::AfterFunc(10 * Millisecond, [&]() {
+        calls.Add(1);
+        done.Close();
+    });
+    done.Receive();
+    CHECK(calls.Load() == 1 && !f->Stop());
+    delete f;
+
+    // many timers, expiring in any order
+    Chan<int> fires(N);
+    spawn(N, [=](int i) {
+        fires.Send(go_time::After(Duration((N - i) * Millisecond)).Receive().IsZero() ? -1 : i);
+    }).Receive();
+    int sum = 0;
+    for (int i = 0; i < N; i++) {
+        sum += fires.Receive();
+    }
+    CHECK(sum == N * (N - 1) / 2);
+}
+
+static void testTicker() {
+    auto start = go_time::Now();
+    auto t = go_time::NewTicker(10 * Millisecond);
+    for (int i = 0; i < 5; i++) {
+        t->C.Receive();
+    }
+    CHECK(go_time::Since(start) >= 50 * Millisecond);
+
+    // a slow receiver gets one tick, the others are dropped
+    go_time::Sleep(50 * Millisecond);
+    CHECK(t->C.len() == 1);
+
+    t->Stop();
+    CHECK(t->C.len() == 0);
+    go_time::Sleep(20 * Millisecond);
+    CHECK(t->C.len() == 0);
+
+    t->Reset(5 * Millisecond);
+    t->C.Receive();
+    t->Stop();
+    delete t;
+
+    CHECK(panics([]() { go_time::NewTicker(0); }) == "non-positive interval for NewTicker");
+    CHECK(go_time::Tick(-1) == nullptr);
+}
+
+static void testSelect() {
+    // the senders, on unbuffered and buffered channels, and a receiver selecting on both
+    Chan<int> a(0), b(3);
+    auto done = spawn(N, [=](int i) {
+        for (int j = 0; j < M; j++) {
+            (i % 2 == 0 ? a : b).Send(1);
+        }
+    });
+
+    long total = 0;
+    for (bool running = true; running;) {
+        switch (auto _select = Select{SelectRecv(a), SelectRecv(b), SelectRecv(done)}; _select.Wait(false)) {
+        case 0:
+            total += std::get<0>(_select).Receive();
+            break;
+        case 1:
+            total += std::get<1>(_select).Receive();
+            break;
+        case 2:
+            running = false;
+            break;
+        }
+    }
+    while (b.len() > 0) {
+        total += b.Receive();
+    }
+    CHECK(total == N * M);
+
+    // the senders selecting on the same unbuffered channel, and the receivers selecting with a timeout
+    Chan<int> c(0);
+    atomic::Int64 sent, received;
+    auto senders = spawn(N / 2, [=, &sent](int i) {
+        for (int j = 0; j < M; j++) {
+            switch (auto _select = Select{SelectSend(c, j), SelectRecv(go_time::After(5 * Second))}; _select.Wait(false)) {
+            case 0:
+                sent.Add(j);
+                break;
+            }
+        }
+    });
+    auto receivers = spawn(N / 2, [=, &received](int i) {
+        for (;;) {
+            switch (auto _select = Select{SelectRecv(c), SelectRecv(go_time::After(100 * Millisecond))}; _select.Wait(false)) {
+            case 0:
+                received.Add(std::get<0>(_select).Receive());
+                continue;
+            }
+            return;
+        }
+    });
+    senders.Receive();
+    receivers.Receive();
+    CHECK(sent.Load() == long(N / 2) * M * (M - 1) / 2 && received.Load() == sent.Load());
+
+    // default, closed and nil channels
+    Chan<int> empty(1), closed(0), nil;
+    closed.Close();
+    CHECK((Select{SelectRecv(empty), SelectRecv(nil)}.Wait(true)) == -1);
+    CHECK((Select{SelectSend(empty, 1), SelectSend(nil, 2)}.Wait(true)) == 0);
+    CHECK((Select{SelectSend(empty, 1)}.Wait(true)) == -1);
+
+    auto s = Select{SelectRecv(closed), SelectRecv(nil)};
+    CHECK(s.Wait(false) == 0 && std::get<0>(s).Lookup() == std::make_tuple(0, false));
+    CHECK(panics([&]() { Select{SelectSend(closed, 1)}.Wait(false); }) == "send on closed channel");
+
+    // the cases that are ready are chosen at random
+    Chan<int> x(M), y(M);
+    for (int i = 0; i < M; i++) {
+        x.Send(i);
+        y.Send(i);
+    }
+    int fromX = 0;
+    for (int i = 0; i < M; i++) {
+        fromX += Select{SelectRecv(x), SelectRecv(y)}.Wait(false) == 0;
+    }
+    CHECK(fromX > M / 4 && fromX < 3 * M / 4);
+}
+
+int main() {
+    return runTests({
+        {"Duration", testDuration},
+        {"Format", testFormat},
+        {"Timer", testTimer},
+        {"Ticker", testTicker},
+        {"Select", testSelect},
+    });
+}
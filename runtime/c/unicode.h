@@ -0,0 +1,217 @@
+#ifndef _GO_RUNTIME_UNICODE_H
+#define _GO_RUNTIME_UNICODE_H 1
+
+#include <go.h>
+
+//
+// unicode: the classification and case mapping of the runes, with the tables of the Go unicode
+// package (see mkunicode.go).
+//
+
+namespace unicode {
+
+    const rune MaxRune = 0x10FFFF;
+    const rune ReplacementChar = 0xFFFD;
+    const rune MaxASCII = 0x7F;
+    const rune MaxLatin1 = 0xFF;
+
+    const int UpperCase = 0;
+    const int LowerCase = 1;
+    const int TitleCase = 2;
+
+    namespace detail {
+
+        struct Range {
+            rune lo;
+            rune hi;
+            rune stride;
+        };
+
+        struct CaseRange {
+            rune lo;
+            rune hi;
+            rune delta[3];
+        };
+
+        struct FoldPair {
+            rune from;
+            rune to;
+        };
+
+        // the delta of a CaseRange where upper and lower case letters alternate
+        const rune UpperLower = MaxRune + 1;
+    }
+}
+
+#include <unicode_tables.h>
+
+namespace unicode {
+
+    namespace detail {
+
+        template<size_t N> bool is(const Range (&table)[N], rune r) {
+            size_t lo = 0, hi = N;
+            while (lo < hi) {
+                size_t m = lo + (hi - lo) / 2;
+                const Range& rg = table[m];
+                if (rg.lo <= r && r <= rg.hi) {
+                    return rg.stride == 1 || (r - rg.lo) % rg.stride == 0;
+                }
+                if (r < rg.lo) {
+                    hi = m;
+                } else {
+                    lo = m + 1;
+                }
+            }
+            return false;
+        }
+
+        // to maps r to the case c, and reports whether r has a mapping
+        inline std::tuple<rune, bool> to(int c, rune r) {
+            if (c < 0 || c > TitleCase) {
+                return {ReplacementChar, false};
+            }
+
+            size_t lo = 0, hi = sizeof(CaseRanges) / sizeof(CaseRanges[0]);
+            while (lo < hi) {
+                size_t m = lo + (hi - lo) / 2;
+                const CaseRange& cr = CaseRanges[m];
+                if (cr.lo <= r && r <= cr.hi) {
+                    rune delta = cr.delta[c];
+                    if (delta > MaxRune) {
+                        // the letters at even offsets are upper case, the ones at odd offsets are lower case
+                        return {cr.lo + (((r - cr.lo) & ~1) | rune(c & 1)), true};
+                    }
+                    return {r + delta, delta != 0};
+                }
+                if (r < cr.lo) {
+                    hi = m;
+                } else {
+                    lo = m + 1;
+                }
+            }
+            return {r, false};
+        }
+    }
+
+    inline bool IsLetter(rune r) {
+        if (r <= MaxASCII) {
+            return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z');
+        }
+        return detail::is(detail::Letter, r);
+    }
+
+    inline bool IsUpper(rune r) {
+        if (r <= MaxASCII) {
+            return r >= 'A' && r <= 'Z';
+        }
+        return detail::is(detail::Upper, r);
+    }
+
+    inline bool IsLower(rune r) {
+        if (r <= MaxASCII) {
+            return r >= 'a' && r <= 'z';
+        }
+        return detail::is(detail::Lower, r);
+    }
+
+    inline bool IsTitle(rune r) {
+        if (r <= MaxLatin1) {
+            return false;
+        }
+        return detail::is(detail::Title, r);
+    }
+
+    inline bool IsDigit(rune r) {
+        if (r <= MaxLatin1) {
+            return r >= '0' && r <= '9';
+        }
+        return detail::is(detail::Digit, r);
+    }
+
+    inline bool IsNumber(rune r) {
+        return detail::is(detail::Number, r);
+    }
+
+    inline bool IsMark(rune r) {
+        return detail::is(detail::Mark, r);
+    }
+
+    inline bool IsPunct(rune r) {
+        return detail::is(detail::Punct, r);
+    }
+
+    inline bool IsSymbol(rune r) {
+        return detail::is(detail::Symbol, r);
+    }
+
+    inline bool IsControl(rune r) {
+        return (r >= 0 && r < 0x20) || (r >= 0x7F && r <= 0x9F);
+    }
+
+    inline bool IsSpace(rune r) {
+        if (r <= MaxLatin1) {
+            switch (r) {
+            case '\t': case '\n': case '\v': case '\f': case '\r': case ' ': case 0x85: case 0xA0:
+                return true;
+            }
+            return false;
+        }
+        return detail::is(detail::WhiteSpace, r);
+    }
+
+    // IsGraphic reports whether r is a letter, mark, number, punctuation, symbol or space (category Zs)
+    inline bool IsGraphic(rune r) {
+        return IsLetter(r) || IsMark(r) || IsNumber(r) || IsPunct(r) || IsSymbol(r) || detail::is(detail::Space, r);
+    }
+
+    // IsPrint is IsGraphic, except that the only space is ' '
+    inline bool IsPrint(rune r) {
+        return r == ' ' || IsLetter(r) || IsMark(r) || IsNumber(r) || IsPunct(r) || IsSymbol(r);
+    }
+
+    inline rune To(int c, rune r) {
+        return std::get<0>(detail::to(c, r));
+    }
+
+    inline rune ToUpper(rune r) {
+        if (r <= MaxASCII) {
+            return r >= 'a' && r <= 'z' ? r - ('a' - 'A') : r;
+        }
+        return To(UpperCase, r);
+    }
+
+    inline rune ToLower(rune r) {
+        if (r <= MaxASCII) {
+            return r >= 'A' && r <= 'Z' ? r + ('a' - 'A') : r;
+        }
+        return To(LowerCase, r);
+    }
+
+    inline rune ToTitle(rune r) {
+        if (r <= MaxASCII) {
+            return r >= 'a' && r <= 'z' ? r - ('a' - 'A') : r;
+        }
+        return To(TitleCase, r);
+    }
+
+    // SimpleFold returns the next rune (in increasing order, wrapping around) that is equivalent to r under case folding
+    inline rune SimpleFold(rune r) {
+        if (r < 0 || r > MaxRune) {
+            return r;
+        }
+
+        for (auto& p : detail::FoldOrbit) {
+            if (p.from == r) {
+                return p.to;
+            }
+        }
+
+        if (rune l = ToLower(r); l != r) {
+            return l;
+        }
+        return ToUpper(r);
+    }
+}
+
+#endif
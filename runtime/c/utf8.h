@@ -0,0 +1,163 @@
+#ifndef _GO_RUNTIME_UTF8_H
+#define _GO_RUNTIME_UTF8_H 1
+
+#include <go.h>
+
+//
+// unicode/utf8: the UTF-8 encoding of the runes in Go strings and byte slices.
+//
+// An invalid encoding (a wrong, overlong or truncated sequence, a surrogate half or a value
+// beyond MaxRune) decodes as (RuneError, 1), as in Go. The constants, decode and encode are in go.h
+// (for range and the rune conversions).
+//
+
+namespace utf8 {
+
+    namespace detail {
+
+        // decodeLast returns the last rune in p[0:n] and its size
+        inline std::tuple<rune, int> decodeLast(const byte *p, size_t n) {
+            if (n == 0) {
+                return {RuneError, 0};
+            }
+
+            size_t start = n - 1;
+            if (p[start] < 0x80) {
+                return {p[start], 1};
+            }
+
+            // back up to the start of the sequence (at most UTFMax bytes)
+            size_t lim = n > UTFMax ? n - UTFMax : 0;
+            while (start > lim && (p[start] & 0xC0) == 0x80) {
+                start--;
+            }
+
+            auto [r, size] = decode(p + start, n - start);
+            if (start + size != n) {
+                return {RuneError, 1};
+            }
+            return {r, size};
+        }
+
+        inline int count(const byte *p, size_t n) {
+            int count = 0;
+            for (size_t i = 0; i < n; count++) {
+                i += std::get<1>(decode(p + i, n - i));
+            }
+            return count;
+        }
+
+        inline bool valid(const byte *p, size_t n) {
+            for (size_t i = 0; i < n;) {
+                auto [r, size] = decode(p + i, n - i);
+                if (r == RuneError && size == 1) {
+                    return false;
+                }
+                i += size;
+            }
+            return true;
+        }
+
+        inline bool full(const byte *p, size_t n) {
+            if (n == 0) {
+                return false;
+            }
+            auto [r, size] = decode(p, n);
+            if (size > 1 || r != RuneError) {
+                return true;
+            }
+
+            // a truncated sequence is not full: check the expected length and the continuation bytes
+            int want = p[0] >= 0xF0 ? 4 : p[0] >= 0xE0 ? 3 : p[0] >= 0xC2 ? 2 : 1;
+            if (want == 1 || p[0] > 0xF4 || (int) n >= want) {
+                return true;
+            }
+            for (size_t i = 1; i < n; i++) {
+                if ((p[i] & 0xC0) != 0x80) {
+                    return true;
+                }
+            }
+            return false;
+        }
+    }
+
+    inline bool ValidRune(rune r) {
+        return (r >= 0 && r < 0xD800) || (r > 0xDFFF && r <= MaxRune);
+    }
+
+    inline int RuneLen(rune r) {
+        if (r < 0) {
+            return -1;
+        } else if (r < 0x80) {
+            return 1;
+        } else if (r < 0x800) {
+            return 2;
+        } else if (r >= 0xD800 && r <= 0xDFFF) {
+            return -1;
+        } else if (r < 0x10000) {
+            return 3;
+        } else if (r <= MaxRune) {
+            return 4;
+        }
+        return -1;
+    }
+
+    inline bool RuneStart(byte b) {
+        return (b & 0xC0) != 0x80;
+    }
+
+    inline std::tuple<rune, int> DecodeRuneInString(const std::string& s) {
+        return detail::decode((const byte *) s.data(), s.size());
+    }
+
+    inline std::tuple<rune, int> DecodeRune(const Slice<byte>& p) {
+        return detail::decode(p.data(), p.len());
+    }
+
+    inline std::tuple<rune, int> DecodeLastRuneInString(const std::string& s) {
+        return detail::decodeLast((const byte *) s.data(), s.size());
+    }
+
+    inline std::tuple<rune, int> DecodeLastRune(const Slice<byte>& p) {
+        return detail::decodeLast(p.data(), p.len());
+    }
+
+    // EncodeRune writes the encoding of r into p (that must be large enough) and returns the number of bytes
+    inline int EncodeRune(const Slice<byte>& p, rune r) {
+        std::string s = detail::encode(r);
+        for (size_t i = 0; i < s.size(); i++) {
+            p[i] = byte(s[i]);
+        }
+        return s.size();
+    }
+
+    inline Slice<byte> AppendRune(const Slice<byte>& p, rune r) {
+        return appendSlice(p, detail::encode(r));
+    }
+
+    inline int RuneCountInString(const std::string& s) {
+        return detail::count((const byte *) s.data(), s.size());
+    }
+
+    inline int RuneCount(const Slice<byte>& p) {
+        return detail::count(p.data(), p.len());
+    }
+
+    inline bool ValidString(const std::string& s) {
+        return detail::valid((const byte *) s.data(), s.size());
+    }
+
+    inline bool Valid(const Slice<byte>& p) {
+        return detail::valid(p.data(), p.len());
+    }
+
+    inline bool FullRuneInString(const std::string& s) {
+        return detail::full((const byte *) s.data(), s.size());
+    }
+
+    inline bool FullRune(const Slice<byte>& p) {
+        return detail::full(p.data(), p.len());
+    }
+}
+
+#endif
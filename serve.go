@@ -0,0 +1,148 @@
+package main
+
+//
+// A local HTTP "playground" to convert Go sources from the browser
+//
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/scanner"
+	"go/types"
+	"net/http"
+	"os"
+
+	"github.com/raff/walkngo/walker"
+)
+
+//go:embed playground.html
+var playground []byte
+
+// maxSource is the maximum size of a source file accepted by the translate endpoint
+const maxSource = 1 << 20
+
+// TranslateRequest is the request for the translate endpoint
+type TranslateRequest struct {
+	Lang   string `json:"lang"`
+	Source string `json:"source"`
+}
+
+// Position is a position in the Go source
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Diagnostic is a parsing or type checking error
+type Diagnostic struct {
+	Position
+	Message string `json:"message"`
+}
+
+// UnsupportedNode is an AST node that could not be converted
+type UnsupportedNode struct {
+	Position
+	Node string `json:"node"`
+}
+
+// TranslateResponse is the response of the translate endpoint
+type TranslateResponse struct {
+	Code        string            `json:"code"`
+	Diagnostics []Diagnostic      `json:"diagnostics"`
+	Unsupported []UnsupportedNode `json:"unsupported"`
+}
+
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	flags.Parse(args)
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(playground)
+	})
+
+	http.HandleFunc("/translate", translateHandler)
+
+	fmt.Fprintf(os.Stderr, "serving on http://%s/\n", *addr)
+	fatal(http.ListenAndServe(*addr, nil))
+}
+
+func translateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TranslateRequest
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSource)).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, _ := newPrinter(req.Lang)
+	if p == nil {
+		http.Error(w, "unsupported language "+req.Lang, http.StatusBadRequest)
+		return
+	}
+
+	var out bytes.Buffer
+
+	// printers and walkers keep state, so each request gets its own
+	gw := walkngo.NewWalker(p, &out, false)
+
+	// the source is being edited, so convert it even if it doesn't type check
+	// (i.e. "declared and not used") and report the errors as diagnostics
+	gw.SetBestEffort(true)
+	err := func() (err error) {
+		// the printers are not very robust, report a panic as a diagnostic
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("internal error: %v", r)
+			}
+		}()
+
+		return gw.WalkSource("main.go", req.Source)
+	}()
+	gw.Flush()
+
+	resp := TranslateResponse{Code: out.String(), Diagnostics: []Diagnostic{}, Unsupported: []UnsupportedNode{}}
+
+	if list, ok := err.(scanner.ErrorList); ok {
+		for _, e := range list {
+			resp.Diagnostics = append(resp.Diagnostics, Diagnostic{Position{e.Pos.Line, e.Pos.Column}, e.Msg})
+		}
+	} else if errs := gw.Errors(); len(errs) > 0 {
+		for _, e := range errs {
+			resp.Diagnostics = append(resp.Diagnostics, diagnostic(e))
+		}
+	} else if err != nil {
+		resp.Diagnostics = append(resp.Diagnostics, Diagnostic{Message: err.Error()})
+	}
+
+	for _, u := range gw.Unsupported() {
+		resp.Unsupported = append(resp.Unsupported, UnsupportedNode{Position{u.Pos.Line, u.Pos.Column}, u.Node})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// diagnostic converts a type checking error
+func diagnostic(err error) Diagnostic {
+	if te, ok := err.(types.Error); ok {
+		pos := te.Fset.Position(te.Pos)
+		return Diagnostic{Position{pos.Line, pos.Column}, te.Msg}
+	}
+
+	return Diagnostic{Message: err.Error()}
+}
@@ -0,0 +1,80 @@
+//source: testdata/golden/errors.go
+//package main
+#include <go.h>
+
+//import  "errors"
+#include <errors.h>
+//import  "fmt"
+#include <fmt.h>
+//import  "strconv"
+#include <strconv.h>
+
+
+std::tuple<int, error> parse(std::string s);
+std::tuple<int /* q */, error /* err */> safeDivide(int a, int b);
+
+ auto ErrNegative = errors::New("negative value");
+
+std::tuple<int, error> parse(std::string s) {
+  auto [n, err] = strconv::Atoi(s);
+
+  if ( err != nullptr ) {
+    return std::make_tuple(0, fmt::Errorf("parse %q: %w", s, err));
+  }
+
+  if ( n < 0 ) {
+    return std::make_tuple(0, fmt::Errorf("parse %q: %w", s, ErrNegative));
+  }
+  return std::make_tuple(n, nullptr);
+}
+
+std::tuple<int /* q */, error /* err */> safeDivide(int a, int b) {
+  int q{};error err{};
+  Defers _defers;
+  try {
+    {
+      Deferred _defer0(_defers, [&]() { [&]() -> void {
+
+        auto r = recover(); if ( r != nullptr ) {
+          err = fmt::Errorf("recovered: %v", r);
+        }
+      }(); });
+
+      if ( b == 0 ) {
+        panic("division by zero");
+      }
+      std::tie(q, err) = std::make_tuple(a / checkDivisor(b), nullptr);
+      goto _return;
+    }
+    _return:
+    _defers.Run();
+  } catch (...) {
+    _defers.Recover();
+  }
+  return std::make_tuple(q, err);
+}
+
+int main(int argc, char **argv) {
+  os::Args = os::detail::args(argc, argv);
+  Defers _defers;
+  try {
+    {
+
+      for (auto [_, s] : Range(Slice<std::string>{"42", "-1", "x"}))       {
+        auto [n, err] = parse(s);
+        fmt::Println(n, err, errors::Is(err, ErrNegative));
+      }
+      Deferred _defer0(_defers);
+
+      for (int i = 0; i < 3; i++)       {
+        _defers.Defer([=](auto... _a) { fmt::Println(_a...); }, "deferred", i);
+      }
+      fmt::Println(safeDivide(6, 3));
+      fmt::Println(safeDivide(1, 0));
+    }
+    _return:
+    _defers.Run();
+  } catch (...) {
+    _defers.Recover();
+  }
+}
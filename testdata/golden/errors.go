@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var ErrNegative = errors.New("negative value")
+
+func parse(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("parse %q: %w", s, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("parse %q: %w", s, ErrNegative)
+	}
+	return n, nil
+}
+
+func safeDivide(a, b int) (q int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered: %v", r)
+		}
+	}()
+
+	if b == 0 {
+		panic("division by zero")
+	}
+	return a / b, nil
+}
+
+func main() {
+	for _, s := range []string{"42", "-1", "x"} {
+		n, err := parse(s)
+		fmt.Println(n, err, errors.Is(err, ErrNegative))
+	}
+
+	for i := 0; i < 3; i++ {
+		defer fmt.Println("deferred", i)
+	}
+
+	fmt.Println(safeDivide(6, 3))
+	fmt.Println(safeDivide(1, 0))
+}
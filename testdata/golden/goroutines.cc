@@ -0,0 +1,66 @@
+//source: testdata/golden/goroutines.go
+//package main
+#include <go.h>
+
+//import  "fmt"
+#include <fmt.h>
+//import  "sync"
+#include <sync.h>
+
+
+void worker(int id, ReceiveChan<int> jobs, SendChan<int> results, sync::WaitGroup* wg);
+
+void worker(int id, ReceiveChan<int> jobs, SendChan<int> results, sync::WaitGroup* wg) {
+  Defers _defers;
+  try {
+    {
+      Deferred _defer0(_defers, [&]() { wg->Done(); });
+
+      for (auto [j, _] : Range(jobs))       {
+        results.Send(j * j);
+      }
+    }
+    _return:
+    _defers.Run();
+  } catch (...) {
+    _defers.Recover();
+  }
+}
+
+int main(int argc, char **argv) {
+  os::Args = os::detail::args(argc, argv);
+  auto jobs = Chan<int>(10);
+  auto results = Chan<int>(10);
+
+   sync::WaitGroup wg{};
+
+  for (int i = 0; i < 3; i++)   {
+    wg.Add(1);
+    Goroutine([=](auto... _a) { worker(_a...); }, i, jobs, results, &wg);
+  }
+
+  for (int i = 1; i <= 5; i++)   {
+    jobs.Send(i);
+  }
+  jobs.Close();
+  wg.Wait();
+  results.Close();
+  int sum = 0;
+
+  for (auto [r, _] : Range(results))   {
+    sum += r;
+  }
+  fmt::Println("sum:", sum);
+  auto done = Chan<bool>(0);
+  Goroutine([_f = [&]() -> void {
+    done.Send(true);
+  }](auto... _a) { _f(_a...); });
+
+  switch (auto _select = Select{SelectRecv(done)}; _select.Wait(false))  {
+    case 0: {
+      auto v = std::get<0>(_select).Receive();
+      fmt::Println("done:", v);
+      break;
+    }
+  }
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+func worker(id int, jobs <-chan int, results chan<- int, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for j := range jobs {
+		results <- j * j
+	}
+}
+
+func main() {
+	jobs := make(chan int, 10)
+	results := make(chan int, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go worker(i, jobs, results, &wg)
+	}
+
+	for i := 1; i <= 5; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(results)
+
+	sum := 0
+	for r := range results {
+		sum += r
+	}
+	fmt.Println("sum:", sum)
+
+	done := make(chan bool)
+	go func() {
+		done <- true
+	}()
+
+	select {
+	case v := <-done:
+		fmt.Println("done:", v)
+	}
+}
@@ -0,0 +1,22 @@
+//source: testdata/golden/hello.go
+//package main
+#include <go.h>
+
+//import  "fmt"
+#include <fmt.h>
+//import  "strings"
+#include <go_strings.h>
+
+
+const std::string greeting = "hello";
+
+
+int main(int argc, char **argv) {
+  os::Args = os::detail::args(argc, argv);
+  Slice<std::string> names = Slice<std::string>{"world", "gopher"};
+
+  for (auto [i, name] : Range(names))   {
+    fmt::Printf("%d: %s, %s!\n", i, greeting, name);
+  }
+  fmt::Println(strings::ToUpper(greeting), len(names));
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const greeting = "hello"
+
+func main() {
+	names := []string{"world", "gopher"}
+	for i, name := range names {
+		fmt.Printf("%d: %s, %s!\n", i, greeting, name)
+	}
+
+	fmt.Println(strings.ToUpper(greeting), len(names))
+}
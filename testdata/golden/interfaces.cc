@@ -0,0 +1,120 @@
+//source: testdata/golden/interfaces.go
+//package main
+#include <go.h>
+
+//import  "fmt"
+#include <fmt.h>
+
+typedef struct _Shape Shape;
+typedef struct _Sq Sq;
+typedef struct _Rect Rect;
+
+struct _Shape : Interface {
+  static constexpr const char *_type = "main.Shape";
+  static inline const bool _registered = Interface::_register<_Shape>();
+  Method<int()> Area;
+
+  _Shape() {}
+  _Shape(std::nullptr_t) {}
+  template<class T, iface_detail::if_value<T, _Shape> = 0> _Shape(const T& v) : Interface(v) {
+    Area = _bind(v, [](auto& _v, auto... _a) { return _v.Area(_a...); });
+  }
+};
+
+struct _Sq {
+  int S;
+  int Area();
+  std::string String();
+  static constexpr const char *_type = "main.Sq";
+  template<class F> void _fields(F f) { f("S", S); }
+  auto _tie() const { return std::tie(S); }
+  bool operator==(const _Sq& o) const { return cmp_detail::equal(_tie(), o._tie()); }
+  bool operator!=(const _Sq& o) const { return !(*this == o); }
+  bool operator<(const _Sq& o) const { return cmp_detail::less(_tie(), o._tie()); }
+};
+
+struct _Rect {
+  int W;
+  int H;
+  int Area();
+  static constexpr const char *_type = "main.Rect";
+  template<class F> void _fields(F f) { f("W", W); f("H", H); }
+  auto _tie() const { return std::tie(W, H); }
+  bool operator==(const _Rect& o) const { return cmp_detail::equal(_tie(), o._tie()); }
+  bool operator!=(const _Rect& o) const { return !(*this == o); }
+  bool operator<(const _Rect& o) const { return cmp_detail::less(_tie(), o._tie()); }
+};
+
+std::string describe(std::any x);
+
+int /* s */ Sq::Area() {
+  return this->S * this->S;
+}
+
+int /* r */ Rect::Area() {
+  return this->W * this->H;
+}
+
+std::string /* s */ Sq::String() {
+  return fmt::Sprint("Sq", this->S);
+}
+
+std::string describe(std::any x) {
+
+  switch (const auto& _switch = x; typeCase<std::tuple<Implements<fmt::Stringer, Sq, Sq*>>, std::tuple<Implements<Shape, Rect*, Sq, Sq*>>, std::tuple<int>>(_switch))  {
+    case 0: {
+      auto v = typeAssert<Implements<fmt::Stringer, Sq, Sq*>>(_switch);
+      return "stringer " + v.String();
+      break;
+    }
+    case 1: {
+      auto v = typeAssert<Implements<Shape, Rect*, Sq, Sq*>>(_switch);
+      return fmt::Sprint("shape ", v.Area());
+      break;
+    }
+    case 2: {
+      return "int";
+      break;
+    }
+  }
+  return "other";
+}
+
+int main(int argc, char **argv) {
+  os::Args = os::detail::args(argc, argv);
+
+   Shape s = Sq{2};
+  fmt::Println(s.Area());
+  s = new Rect{2, 3};
+  fmt::Println(s.Area());
+  fmt::Println(describe(Sq{3}), describe(new Rect{1, 2}), describe(1), describe("x"));
+
+   std::any x = Sq{4};
+
+  auto [sh, ok] = typeAssertOk<Implements<Shape, Rect*, Sq, Sq*>>(x); if ( ok ) {
+    fmt::Println("ok", sh.Area());
+  }
+  auto sh2 = typeAssert<Implements<Shape, Rect*, Sq, Sq*>>(x);
+  fmt::Println(sh2.Area(), sh2);
+
+  auto [_0, isSq] = typeAssertOk<Sq>(s); if ( !isSq ) {
+    fmt::Println("not Sq");
+  }
+
+  auto [r, isRect] = typeAssertOk<Rect*>(s); if ( isRect ) {
+    fmt::Println("rect", r->W);
+  }
+
+   Shape none{};
+  fmt::Println(none == nullptr, s != nullptr, describe(none));
+  Slice<Shape> shapes = Slice<Shape>{Sq{1}, new Rect{1, 1}};
+  int total = 0;
+
+  for (auto [_, sh] : Range(shapes))   {
+    total += sh.Area();
+  }
+  fmt::Println(total, len(shapes));
+
+   fmt::Stringer st = Sq{7};
+  fmt::Println(st.String(), st);
+}
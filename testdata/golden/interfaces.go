@@ -0,0 +1,68 @@
+package main
+
+import "fmt"
+
+type Shape interface {
+	Area() int
+}
+
+type Sq struct {
+	S int
+}
+
+func (s Sq) Area() int { return s.S * s.S }
+
+type Rect struct {
+	W, H int
+}
+
+func (r *Rect) Area() int { return r.W * r.H }
+
+func (s Sq) String() string { return fmt.Sprint("Sq", s.S) }
+
+func describe(x interface{}) string {
+	switch v := x.(type) {
+	case fmt.Stringer:
+		return "stringer " + v.String()
+	case Shape:
+		return fmt.Sprint("shape ", v.Area())
+	case int:
+		return "int"
+	}
+	return "other"
+}
+
+func main() {
+	var s Shape = Sq{2}
+	fmt.Println(s.Area())
+	s = &Rect{2, 3}
+	fmt.Println(s.Area())
+
+	fmt.Println(describe(Sq{3}), describe(&Rect{1, 2}), describe(1), describe("x"))
+
+	var x interface{} = Sq{4}
+	if sh, ok := x.(Shape); ok {
+		fmt.Println("ok", sh.Area())
+	}
+	sh2 := x.(Shape)
+	fmt.Println(sh2.Area(), sh2)
+	if _, isSq := s.(Sq); !isSq {
+		fmt.Println("not Sq")
+	}
+	if r, isRect := s.(*Rect); isRect {
+		fmt.Println("rect", r.W)
+	}
+
+	var none Shape
+	fmt.Println(none == nil, s != nil, describe(none))
+	shapes := []Shape{Sq{1}, &Rect{1, 1}}
+	total := 0
+	for _, sh := range shapes {
+		total += sh.Area()
+	}
+	fmt.Println(total, len(shapes))
+
+	var st fmt.Stringer = Sq{7}
+	fmt.Println(st.String(), st)
+
+}
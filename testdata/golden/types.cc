@@ -0,0 +1,57 @@
+//source: testdata/golden/types.go
+//package main
+#include <go.h>
+
+//import  "fmt"
+#include <fmt.h>
+
+typedef struct _Point Point;
+
+struct _Point {
+  int X;
+  int Y;
+  Point Add(Point q);
+  void Scale(int n);
+  std::string String();
+  static constexpr const char *_type = "main.Point";
+  template<class F> void _fields(F f) { f("X", X); f("Y", Y); }
+  auto _tie() const { return std::tie(X, Y); }
+  bool operator==(const _Point& o) const { return cmp_detail::equal(_tie(), o._tie()); }
+  bool operator!=(const _Point& o) const { return !(*this == o); }
+  bool operator<(const _Point& o) const { return cmp_detail::less(_tie(), o._tie()); }
+};
+
+
+Point /* p */ Point::Add(Point q) {
+  return Point{this->X + q.X, this->Y + q.Y};
+}
+
+void /* p */ Point::Scale(int n) {
+  this->X *= n;
+  this->Y *= n;
+}
+
+std::string /* p */ Point::String() {
+  return fmt::Sprintf("(%d,%d)", this->X, this->Y);
+}
+
+int main(int argc, char **argv) {
+  os::Args = os::detail::args(argc, argv);
+  Point p = Point{1, 2};
+  p.Scale(3);
+  fmt::Println(p, p.Add(Point{1, 1}));
+  Map<std::string, int> counts = Map<std::string, int>(0);
+
+  for (auto [_, w] : Range(Slice<std::string>{"b", "a", "b", "c", "b"}))   {
+    counts[w]++;
+  }
+  fmt::Println(counts);
+  auto keys = Slice<std::string>(0, 4);
+
+  for (auto [_, k] : Range(Slice<std::string>{"a", "b", "c"}))   {
+    keys = append(keys, fmt::Sprint(k, counts.Get(k)));
+  }
+  fmt::Println(keys, len(keys), cap(keys));
+  Map<Point, bool> visited = Map<Point, bool>{{p, true}};
+  fmt::Println(visited.Get(Point{3, 6}), visited.Get(Point{}));
+}
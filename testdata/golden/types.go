@@ -0,0 +1,41 @@
+package main
+
+import "fmt"
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+func (p *Point) Scale(n int) {
+	p.X *= n
+	p.Y *= n
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+}
+
+func main() {
+	p := Point{1, 2}
+	p.Scale(3)
+	fmt.Println(p, p.Add(Point{1, 1}))
+
+	counts := map[string]int{}
+	for _, w := range []string{"b", "a", "b", "c", "b"} {
+		counts[w]++
+	}
+	fmt.Println(counts)
+
+	keys := make([]string, 0, 4)
+	for _, k := range []string{"a", "b", "c"} {
+		keys = append(keys, fmt.Sprint(k, counts[k]))
+	}
+	fmt.Println(keys, len(keys), cap(keys))
+
+	visited := map[Point]bool{p: true}
+	fmt.Println(visited[Point{3, 6}], visited[Point{}])
+}
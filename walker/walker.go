@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"sort"
@@ -22,10 +27,40 @@ type GoWalker struct {
 	parent      ast.Node
 	parentExpr  ast.Expr
 	flush       bool
+	store       bool // the expression being parsed is the target of an assignment
 	buffer      bytes.Buffer
 	writer      io.Writer
 	debug       bool
 	sortStructs bool
+	bestEffort  bool // convert the files that don't type check
+	pkgFiles    bool // type check the files with the other files of their package
+
+	idents    map[string]string // identifiers overrides
+	selectors map[string]string // pkg.Name overrides
+
+	pkg     string // the package name of the last walked file
+	hasMain bool   // true if the last walked file defines func main
+
+	methods map[string][]methodDecl // receiver type -> methods, when printing a declaration file
+
+	received map[*ast.UnaryExpr]string // receive operations of the select cases -> received values
+
+	deferScopes map[ast.Stmt]bool // the statements of the function bodies with deferred calls in their blocks
+
+	heap        map[types.Object]bool // the local variables allocated on the heap (see HeapAllocator)
+	rawPointers bool                  // print the pointer types with FormatStar (i.e. for the receivers)
+
+	bctx    *build.Context // build constraints for the package files
+	modPath string         // the module path, to resolve the imports of the module packages
+	modDir  string         // the module root folder
+
+	pkgDirs  func(srcdir string) (string, bool) // source folder -> output folder of a converted package
+	filename string                             // the last walked file
+
+	fset        *token.FileSet
+	tpkg        *types.Package // the type checked package of the last walked file
+	errors      []error        // type checking errors for the last walked file
+	unsupported []Unsupported  // nodes that could not be converted in the last walked file
 
 	info types.Info
 }
@@ -43,31 +78,694 @@ func (w *GoWalker) SetWriter(writer io.Writer) (old io.Writer) {
 	return
 }
 
+// Unsupported describes an AST node that the walker doesn't know how to convert
+type Unsupported struct {
+	Pos  token.Position
+	Node string // the node type
+}
+
 func (w *GoWalker) WalkFile(filename string) error {
+	return w.WalkSource(filename, nil)
+}
+
+// WalkSource converts the source for filename, that can be a string, []byte or io.Reader
+// (if src is nil, the source is read from filename)
+func (w *GoWalker) WalkSource(filename string, src interface{}) error {
 	w.p.Reset()
 	w.p.Print(fmt.Sprintf("//source: %s\n", filename))
 
-	fset := token.NewFileSet() // positions are relative to fset
+	files, err := w.check(filename, src, src == nil && w.pkgFiles)
+	if err != nil && (files == nil || !w.bestEffort) {
+		w.buffer.Reset()
+		return err
+	}
+
+	ast.Walk(w, files[0])
+	return err
+}
+
+// SetPackageFiles enables the type checking of the files read from disk together with the other files
+// of their package in the same folder (i.e. when converting a whole package, whose files refer to each other).
+// Otherwise each file is checked alone, as a standalone program.
+func (w *GoWalker) SetPackageFiles(pkgFiles bool) {
+	w.pkgFiles = pkgFiles
+}
+
+// SetBestEffort enables the conversion of the files with type checking errors
+// (i.e. while they are being edited): WalkSource prints what it can and returns the first error
+func (w *GoWalker) SetBestEffort(bestEffort bool) {
+	w.bestEffort = bestEffort
+}
+
+// methodDecl is a method declared in the receiver type definition
+type methodDecl struct {
+	name, params, results string
+}
+
+// WalkHeader prints the declaration file (i.e. a C++ header) for the package in dir:
+// the imports, the types (with the method declarations), the function prototypes
+// and the package constants and variables
+func (w *GoWalker) WalkHeader(dir string) error {
+	dp, ok := printer.As[printer.DeclPrinter](w.p)
+	if !ok {
+		return fmt.Errorf("declaration files not supported by the printer")
+	}
+
+	filename := w.packageFile(dir)
+	if len(filename) == 0 {
+		return fmt.Errorf("%s: no Go files", dir)
+	}
+
+	w.p.Reset()
 
-	f, err := parser.ParseFile(fset, filename, nil, 0)
+	files, err := w.check(filename, nil, true)
 	if err != nil {
+		w.buffer.Reset()
 		return err
 	}
 
+	dp.SetDeclMode(true)
+	defer dp.SetDeclMode(false)
+
+	dp.PrintDeclStart(w.pkg)
+	w.walkOrdered(dp, files, false)
+	dp.PrintDeclEnd(w.pkg)
+	w.Flush()
+	return nil
+}
+
+// heapPointers returns true if the printer has reference counted pointers
+func (w *GoWalker) heapPointers() bool {
+	ha, ok := printer.As[printer.HeapAllocator](w.p)
+	return ok && ha.HeapPointers()
+}
+
+// pointerType returns the type of a pointer to t (reference counted, if the printer allocates them on the heap)
+func (w *GoWalker) pointerType(t string) string {
+	if ha, ok := printer.As[printer.HeapAllocator](w.p); ok && ha.HeapPointers() && !w.rawPointers {
+		return ha.FormatPointerType(t)
+	}
+	return w.p.FormatStar(t)
+}
+
+// walkOrdered visits the declarations of files in the order required by the languages that need
+// a declaration before use: imports, forward declarations, types and constants (sorted by their dependencies),
+// function prototypes, variables (in initialization order) and functions (if defs is true)
+func (w *GoWalker) walkOrdered(dp printer.DeclPrinter, files []*ast.File, defs bool) {
+	w.methods = map[string][]methodDecl{}
+	defer func() { w.methods = nil }()
+
+	tdecls, vdecls, funcs := w.sortDecls(files)
+
+	for _, fd := range funcs {
+		if fd.Recv == nil {
+			continue
+		}
+
+		if rtype := recvType(fd.Recv); len(rtype) > 0 {
+			w.p.PushContext(printer.FUNCONTEXT)
+			w.methods[rtype] = append(w.methods[rtype], methodDecl{
+				w.ident(fd.Name.Name),
+				w.parseFieldList(fd.Type.Params, printer.PARAM),
+				w.parseFieldList(fd.Type.Results, printer.RESULT)})
+			w.p.PopContext()
+		}
+	}
+
+	imported := map[string]bool{}
+
+	w.p.PushContext(printer.GENCONTEXT)
+	for _, f := range files {
+		for _, spec := range f.Imports {
+			if !imported[spec.Path.Value] {
+				if len(imported) == 0 {
+					w.p.Print("\n")
+				}
+
+				imported[spec.Path.Value] = true
+				w.Visit(spec)
+			}
+		}
+	}
+	w.p.PopContext()
+
+	dp.PrintScopeStart(w.pkg)
+
+	// forward declarations, for types that refer to each other and for the function prototypes
+	w.p.Print("\n")
+	for _, d := range tdecls {
+		for _, spec := range d.(*ast.GenDecl).Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				if _, ok := ts.Type.(*ast.StructType); ok || w.isNamedValue(ts) || isMethodSet(ts) {
+					dp.PrintTypeDecl(w.ident(ts.Name.Name))
+				}
+			}
+		}
+	}
+
+	for _, d := range tdecls {
+		w.Visit(d)
+	}
+
+	w.p.Print("\n")
+	for _, fd := range funcs {
+		// all the functions are declared, since they can be called from the other files of the package
+		if fd.Recv == nil && fd.Name.Name != "main" && fd.Name.Name != "init" {
+			w.p.PushContext(printer.FUNCONTEXT)
+			dp.PrintFuncDecl(w.ident(fd.Name.Name),
+				w.parseFieldList(fd.Type.Params, printer.PARAM),
+				w.parseFieldList(fd.Type.Results, printer.RESULT))
+			w.p.PopContext()
+		}
+	}
+
+	for _, d := range vdecls {
+		w.Visit(d)
+	}
+
+	if defs {
+		for _, fd := range funcs {
+			w.Visit(fd)
+		}
+	}
+
+	dp.PrintScopeEnd(w.pkg)
+}
+
+// declUnit is a type or a constant declaration, sorted by its dependencies
+type declUnit struct {
+	decl  ast.Decl
+	deps  []*declUnit
+	state int // 0: not visited, 1: visiting, 2: done
+}
+
+// sortDecls returns the type and constant declarations of files, sorted so that a type or constant
+// is declared before it's used by value, the variable declarations in initialization order
+// and the function declarations in source order.
+// Type and variable declarations are split in single spec declarations, constant groups are kept (for iota).
+func (w *GoWalker) sortDecls(files []*ast.File) (tdecls, vdecls []ast.Decl, funcs []*ast.FuncDecl) {
+	var units []*declUnit
+
+	byObj := map[types.Object]*declUnit{}
+
+	for _, f := range files {
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				funcs = append(funcs, d)
+
+			case *ast.GenDecl:
+				switch d.Tok {
+				case token.CONST:
+					u := &declUnit{decl: d}
+					units = append(units, u)
+
+					for _, spec := range d.Specs {
+						for _, name := range spec.(*ast.ValueSpec).Names {
+							if obj := w.info.Defs[name]; obj != nil {
+								byObj[obj] = u
+							}
+						}
+					}
+
+				case token.TYPE:
+					for _, spec := range d.Specs {
+						u := &declUnit{decl: &ast.GenDecl{Tok: d.Tok, Specs: []ast.Spec{spec}}}
+						units = append(units, u)
+
+						if obj := w.info.Defs[spec.(*ast.TypeSpec).Name]; obj != nil {
+							byObj[obj] = u
+						}
+					}
+
+				case token.VAR:
+					for _, spec := range d.Specs {
+						vdecls = append(vdecls, &ast.GenDecl{Tok: d.Tok, Specs: []ast.Spec{spec}})
+					}
+				}
+			}
+		}
+	}
+
+	for _, u := range units {
+		ast.Inspect(u.decl, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.StarExpr, *ast.FuncType, *ast.InterfaceType, *ast.ChanType:
+				return false // a declaration is enough
+
+			case *ast.ArrayType:
+				return n.Len != nil // slices can refer to incomplete types
+
+			case *ast.Ident:
+				if dep, ok := byObj[w.info.Uses[n]]; ok && dep != u {
+					u.deps = append(u.deps, dep)
+				}
+			}
+
+			return true
+		})
+	}
+
+	var visit func(u *declUnit)
+
+	visit = func(u *declUnit) {
+		if u.state != 0 {
+			return // done, or a cycle
+		}
+
+		u.state = 1
+		for _, dep := range u.deps {
+			visit(dep)
+		}
+		u.state = 2
+
+		tdecls = append(tdecls, u.decl)
+	}
+
+	for _, u := range units {
+		visit(u)
+	}
+
+	order := map[types.Object]int{}
+	for i, init := range w.info.InitOrder {
+		for _, v := range init.Lhs {
+			order[v] = i + 1
+		}
+	}
+
+	// variables without initialization come first
+	sort.SliceStable(vdecls, func(i, j int) bool {
+		return order[w.info.Defs[vdecls[i].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Names[0]]] <
+			order[w.info.Defs[vdecls[j].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Names[0]]]
+	})
+
+	return
+}
+
+// isNamedValue returns true if ts declares a package level type with methods that is not a struct
+// (or an interface or a function type), that a DeclPrinter defines with FormatNamedType
+func (w *GoWalker) isNamedValue(ts *ast.TypeSpec) bool {
+	if w.methods == nil || len(w.methods[ts.Name.Name]) == 0 || ts.Assign.IsValid() {
+		return false
+	}
+
+	obj := w.info.Defs[ts.Name]
+	if obj == nil || obj.Pkg() == nil || obj.Parent() != obj.Pkg().Scope() {
+		return false
+	}
+
+	switch obj.Type().Underlying().(type) {
+	case *types.Struct, *types.Interface, *types.Signature:
+		return false
+	}
+
+	return true
+}
+
+// isMethodSet returns true if ts declares an interface with methods (not interface{})
+func isMethodSet(ts *ast.TypeSpec) bool {
+	it, ok := ts.Type.(*ast.InterfaceType)
+	return ok && !ts.Assign.IsValid() && it.Methods.NumFields() > 0
+}
+
+// recvType returns the name of the receiver type of a method
+func recvType(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+
+	t := recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name
+	}
+
+	return ""
+}
+
+// packageFile returns the first file in dir that matches the build constraints (skipping the tests)
+func (w *GoWalker) packageFile(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		if match, err := w.buildContext().MatchFile(dir, name); err == nil && match {
+			return filepath.Join(dir, name)
+		}
+	}
+
+	return ""
+}
+
+// check parses and type checks filename. If siblings is true, the other files of the same package
+// in the same folder are also checked (but only the errors that involve filename are reported),
+// unless they redeclare the names of a main package (i.e. a folder of standalone programs).
+// The first returned file is the one for filename.
+func (w *GoWalker) check(filename string, src interface{}, siblings bool) ([]*ast.File, error) {
+	w.fset = token.NewFileSet() // positions are relative to fset
+	w.filename = filename
+	w.errors = nil
+	w.unsupported = nil
+
+	f, err := parser.ParseFile(w.fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	w.pkg = f.Name.Name
+	w.hasMain = false
+
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == "main" {
+			w.hasMain = w.pkg == "main"
+		}
+	}
+
+	files := []*ast.File{f}
+	if siblings {
+		files = append(files, w.siblings(filename, f.Name.Name)...)
+	}
+
+	w.errors = w.typeCheck(filename, files)
+	if len(files) > 1 && w.pkg == "main" && redeclared(w.errors) {
+		// the other files are not part of the same program (i.e. a folder of standalone programs)
+		files = files[:1]
+		w.errors = w.typeCheck(filename, files)
+	}
+
+	if len(w.errors) > 0 {
+		return files, w.errors[0]
+	}
+	return files, nil
+}
+
+// typeCheck type checks files, the first one being filename, and returns the errors that involve filename:
+// the errors in filename and the errors in the other files with a continuation in filename
+// (i.e. "x redeclared in this block", followed by "other declaration of x")
+func (w *GoWalker) typeCheck(filename string, files []*ast.File) []error {
 	w.info = types.Info{
-		Types: make(map[ast.Expr]types.TypeAndValue),
-		//Defs:  make(map[*ast.Ident]types.Object),
-		//Uses:  make(map[*ast.Ident]types.Object),
+		Types:     make(map[ast.Expr]types.TypeAndValue),
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+		Implicits: make(map[ast.Node]types.Object),
+	}
+
+	var groups [][]error // an error and its continuations
+
+	conf := types.Config{
+		Importer: w.importer(),
+		Error: func(err error) {
+			if te, ok := err.(types.Error); ok && strings.HasPrefix(te.Msg, "\t") && len(groups) > 0 {
+				groups[len(groups)-1] = append(groups[len(groups)-1], err)
+			} else {
+				groups = append(groups, []error{err})
+			}
+		},
+	}
+
+	w.tpkg, _ = conf.Check(files[0].Name.Name, w.fset, files, &w.info)
+
+	var errs []error
+	for _, g := range groups {
+		for _, err := range g {
+			if te, ok := err.(types.Error); !ok || te.Fset.Position(te.Pos).Filename == filename {
+				errs = append(errs, g...)
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// redeclared returns true if one of the errors is a redeclaration
+func redeclared(errs []error) bool {
+	for _, err := range errs {
+		if te, ok := err.(types.Error); ok && strings.HasSuffix(te.Msg, "redeclared in this block") {
+			return true
+		}
+	}
+	return false
+}
+
+// siblings parses the other files of package pkg in the same folder as filename,
+// that match the build constraints
+func (w *GoWalker) siblings(filename, pkg string) (files []*ast.File) {
+	dir, name := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
 	}
 
-	conf := types.Config{Importer: importer.Default()}
-	_, err = conf.Check(f.Name.String(), fset, []*ast.File{f}, &w.info)
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return err
+		return
 	}
 
-	ast.Walk(w, f)
-	return nil
+	for _, e := range entries {
+		sname := e.Name()
+
+		if e.IsDir() || sname == name || !strings.HasSuffix(sname, ".go") {
+			continue
+		}
+
+		if strings.HasSuffix(sname, "_test.go") && !strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		if match, err := w.buildContext().MatchFile(dir, sname); err != nil || !match {
+			continue
+		}
+
+		sf, err := parser.ParseFile(w.fset, filepath.Join(dir, sname), nil, 0)
+		if err != nil || sf.Name.Name != pkg {
+			continue
+		}
+
+		files = append(files, sf)
+	}
+
+	return
+}
+
+// SetBuildContext sets the build constraints used to select the other files of a package
+func (w *GoWalker) SetBuildContext(ctx build.Context) {
+	w.bctx = &ctx
+}
+
+func (w *GoWalker) buildContext() *build.Context {
+	if w.bctx == nil {
+		return &build.Default
+	}
+
+	return w.bctx
+}
+
+// SetModule sets the module path and root folder, so that the imports of the module packages
+// are type checked from source
+func (w *GoWalker) SetModule(path, dir string) {
+	w.modPath = path
+	w.modDir = dir
+}
+
+// SetPackageDirs sets the function that returns the output folder (slash separated, relative to the output root)
+// for a package source folder, or false if the package is not converted.
+// The imports of the converted packages are printed as references to the converted modules.
+func (w *GoWalker) SetPackageDirs(fn func(srcdir string) (string, bool)) {
+	w.pkgDirs = fn
+}
+
+// moduleDir returns the source folder for an import path, if the package is in the module
+func (w *GoWalker) moduleDir(ipath string) (string, bool) {
+	if len(w.modPath) == 0 {
+		return "", false
+	}
+
+	if ipath == w.modPath {
+		return w.modDir, true
+	}
+
+	if rel := strings.TrimPrefix(ipath, w.modPath+"/"); rel != ipath {
+		return filepath.Join(w.modDir, filepath.FromSlash(rel)), true
+	}
+
+	return "", false
+}
+
+// localImport returns the description of an import of a converted package
+func (w *GoWalker) localImport(spec *ast.ImportSpec) (imp printer.LocalImport, ok bool) {
+	if w.pkgDirs == nil {
+		return
+	}
+
+	ipath, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return
+	}
+
+	srcdir, ok := w.moduleDir(ipath)
+	if !ok {
+		return
+	}
+
+	if imp.Dir, ok = w.pkgDirs(srcdir); !ok {
+		return
+	}
+
+	if imp.From, ok = w.pkgDirs(filepath.Dir(w.filename)); !ok {
+		return
+	}
+
+	imp.Path = spec.Path.Value
+	imp.Package = path.Base(ipath)
+
+	obj := w.info.Implicits[spec]
+	if spec.Name != nil {
+		imp.Name = spec.Name.Name
+		obj = w.info.Defs[spec.Name]
+	}
+
+	if pn, ok := obj.(*types.PkgName); ok {
+		imp.Package = pn.Imported().Name()
+	}
+
+	return imp, true
+}
+
+// importer returns the importer for the type checker: the module packages are type checked from source
+func (w *GoWalker) importer() types.Importer {
+	if len(w.modPath) == 0 {
+		return importer.Default()
+	}
+
+	return &moduleImporter{w: w, fallback: importer.Default(), pkgs: map[string]*types.Package{}}
+}
+
+// moduleImporter type checks the packages of the module from source, and uses the default importer for the others
+type moduleImporter struct {
+	w        *GoWalker
+	fallback types.Importer
+	pkgs     map[string]*types.Package
+}
+
+func (mi *moduleImporter) Import(ipath string) (*types.Package, error) {
+	dir, ok := mi.w.moduleDir(ipath)
+	if !ok {
+		return mi.fallback.Import(ipath)
+	}
+
+	if pkg, ok := mi.pkgs[ipath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle: %s", ipath)
+		}
+
+		return pkg, nil
+	}
+
+	mi.pkgs[ipath] = nil
+
+	var files []*ast.File
+
+	if filename := mi.w.packageFile(dir); len(filename) > 0 {
+		f, err := parser.ParseFile(mi.w.fset, filename, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append([]*ast.File{f}, mi.w.siblings(filename, f.Name.Name)...)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: no Go files in %s", ipath, dir)
+	}
+
+	// the errors are reported when converting the package itself
+	conf := types.Config{Importer: mi, Error: func(error) {}}
+	pkg, _ := conf.Check(ipath, mi.w.fset, files, nil)
+
+	mi.pkgs[ipath] = pkg
+	return pkg, nil
+}
+
+// SetSortStructs enables sorting of struct fields
+func (w *GoWalker) SetSortStructs(sort bool) {
+	w.sortStructs = sort
+}
+
+// SetOverrides sets the replacements for identifiers and selectors (pkg.Name),
+// that take precedence over the printer conversions
+func (w *GoWalker) SetOverrides(idents, selectors map[string]string) {
+	w.idents = idents
+	w.selectors = selectors
+}
+
+// ident returns the override for a declared name, or the name itself
+// (renamed if it's a reserved word in the target language)
+func (w *GoWalker) ident(name string) string {
+	if r, ok := w.idents[name]; ok {
+		return r
+	}
+
+	if e, ok := printer.As[printer.Escaper](w.p); ok {
+		return e.EscapeIdent(name)
+	}
+
+	return name
+}
+
+// escape renames an identifier that is a reserved word in the target language,
+// unless it refers to a predeclared object (int, len, nil, etc.) or a package
+func (w *GoWalker) escape(id *ast.Ident) string {
+	e, ok := printer.As[printer.Escaper](w.p)
+	if !ok {
+		return id.Name
+	}
+
+	if obj := w.info.ObjectOf(id); obj != nil {
+		if _, ok := obj.(*types.PkgName); ok || obj.Parent() == types.Universe {
+			return id.Name
+		}
+	}
+
+	return e.EscapeIdent(id.Name)
+}
+
+// Printer returns the printer used to convert the files
+func (w *GoWalker) Printer() printer.Printer {
+	return w.p
+}
+
+// PackageName returns the package name of the last walked file
+func (w *GoWalker) PackageName() string {
+	return w.pkg
+}
+
+// Errors returns all the type checking errors for the last walked file
+func (w *GoWalker) Errors() []error {
+	return w.errors
+}
+
+// Unsupported returns the nodes that could not be converted in the last walked file
+func (w *GoWalker) Unsupported() []Unsupported {
+	return w.unsupported
+}
+
+func (w *GoWalker) addUnsupported(node ast.Node) {
+	w.unsupported = append(w.unsupported, Unsupported{w.fset.Position(node.Pos()), fmt.Sprintf("%T", node)})
+}
+
+// HasMain returns true if the last walked file is in package main and defines func main
+func (w *GoWalker) HasMain() bool {
+	return w.hasMain
 }
 
 // Implement the Visitor interface for GoWalker
@@ -86,20 +784,49 @@ func (w *GoWalker) Visit(node ast.Node) (ret ast.Visitor) {
 	switch n := node.(type) {
 	case *ast.File:
 		w.p.PrintPackage(n.Name.String())
-		for _, d := range n.Decls {
-			w.Visit(d)
+		if dp, ok := printer.As[printer.DeclPrinter](w.p); ok {
+			w.walkOrdered(dp, []*ast.File{n}, true)
+		} else {
+			for _, d := range n.Decls {
+				w.Visit(d)
+			}
 		}
 
 	case *ast.ImportSpec:
-		w.p.PrintImport(w.parseExpr(n.Name), n.Path.Value)
+		li, isLocal := printer.As[printer.LocalImporter](w.p)
+		if imp, ok := w.localImport(n); ok && isLocal {
+			li.PrintLocalImport(imp)
+		} else {
+			w.p.PrintImport(w.parseExpr(n.Name), n.Path.Value)
+		}
 
 	case *ast.TypeSpec:
-		w.p.PrintType(n.Name.String(), w.parseExpr(n.Type))
+		typedef := w.parseExpr(n.Type)
+		if w.isNamedValue(n) {
+			dp, _ := printer.As[printer.DeclPrinter](w.p)
+			w.p.UpdateLevel(printer.UP)
+			var methods string
+			for _, m := range w.methods[n.Name.Name] {
+				methods += dp.FormatMethodDecl(m.name, m.params, m.results)
+			}
+			typedef = dp.FormatNamedType(w.ident(n.Name.Name), typedef, methods)
+			w.p.UpdateLevel(printer.DOWN)
+		}
+		w.p.PrintType(w.ident(n.Name.Name), typedef)
 
 	case *ast.ValueSpec:
 		vtype := (pparent.(*ast.GenDecl)).Tok.String()
 		typedef := w.parseExpr(n.Type)
-		w.p.PrintValue(vtype, typedef, w.parseNames(n.Names), w.parseExprList(n.Values), len(n.Names) > 1, len(n.Values) > 1)
+		names := w.parseNames(n.Names)
+		values := w.parseExprList(n.Values)
+		if len(n.Names) == 1 && w.heap[w.info.Defs[n.Names[0]]] {
+			ha, _ := printer.As[printer.HeapAllocator](w.p)
+			values = ha.FormatHeapValue(values, typedef)
+			if len(typedef) > 0 {
+				typedef = ha.FormatPointerType(typedef)
+			}
+		}
+		w.p.PrintValue(vtype, typedef, names, values, len(n.Names) > 1, len(n.Values) > 1)
 
 	case *ast.GenDecl:
 		w.p.Print("\n")
@@ -112,17 +839,28 @@ func (w *GoWalker) Visit(node ast.Node) (ret ast.Visitor) {
 	case *ast.FuncDecl:
 		w.p.PushContext(printer.FUNCONTEXT)
 		w.p.Print("\n")
-		w.p.PrintFunc(w.parseFieldList(n.Recv, printer.RECEIVER),
-			n.Name.String(),
+		if w.heapPointers() && n.Body != nil {
+			w.heap = w.heapVars(n.Body)
+		}
+		w.rawPointers = true // the receiver is this
+		recv := w.parseFieldList(n.Recv, printer.RECEIVER)
+		w.rawPointers = false
+		w.p.PrintFunc(recv,
+			w.ident(n.Name.Name),
 			w.parseFieldList(n.Type.Params, printer.PARAM),
 			w.parseFieldList(n.Type.Results, printer.RESULT))
+		w.setDefer(n.Body)
 		w.Visit(n.Body)
 		w.p.Print("\n")
+		w.heap = nil
 		w.p.PopContext()
 
 	case *ast.BlockStmt:
 		w.p.PrintBlockStart(printer.CODE, len(n.List) == 0)
 		for _, i := range n.List {
+			if dp, ok := printer.As[printer.DeferPrinter](w.p); ok && w.deferScopes[i] {
+				dp.PrintDeferScope()
+			}
 			w.Visit(i)
 		}
 		w.p.PrintBlockEnd(printer.CODE)
@@ -159,6 +897,11 @@ func (w *GoWalker) Visit(node ast.Node) (ret ast.Visitor) {
 	case *ast.TypeSwitchStmt:
 		w.p.PushContext(printer.TYPESWITCHCONTEXT)
 		w.p.Print("\n")
+		if ta, ok := printer.As[printer.TypeAsserter](w.p); ok {
+			w.walkTypeSwitch(ta, n)
+			w.p.PopContext()
+			break
+		}
 		w.p.PrintSwitch(w.BufferVisit(n.Init), w.BufferVisit(n.Assign))
 		w.Visit(n.Body)
 		w.p.Print("\n")
@@ -173,9 +916,23 @@ func (w *GoWalker) Visit(node ast.Node) (ret ast.Visitor) {
 		w.p.PrintEndCase()
 		w.p.UpdateLevel(printer.DOWN)
 
+	case *ast.SelectStmt:
+		sp, ok := printer.As[printer.SelectPrinter](w.p)
+		if !ok {
+			w.unsupportedNode(n)
+			ret = w
+			break
+		}
+
+		w.walkSelect(sp, n)
+
 	case *ast.RangeStmt:
 		w.p.Print("\n")
-		w.p.PrintRange(w.parseExpr(n.Key), w.parseExpr(n.Value), w.parseExpr(n.X))
+		if sc, ok := printer.As[printer.StringConverter](w.p); ok && w.isString(n.X) {
+			sc.PrintStringRange(w.parseExpr(n.Key), w.parseExpr(n.Value), w.parseExpr(n.X))
+		} else {
+			w.p.PrintRange(w.parseExpr(n.Key), w.parseExpr(n.Value), w.parseExpr(n.X))
+		}
 		w.Visit(n.Body)
 		w.p.Print("\n")
 
@@ -198,10 +955,25 @@ func (w *GoWalker) Visit(node ast.Node) (ret ast.Visitor) {
 		w.Visit(n.Decl)
 
 	case *ast.AssignStmt:
-		w.p.PrintAssignment(w.parseExprList(n.Lhs), n.Tok.String(), w.parseExprList(n.Rhs), len(n.Lhs) > 1, len(n.Rhs) > 1)
+		if r, ok := printer.As[printer.Redeclarer](w.p); ok && n.Tok == token.DEFINE {
+			if isNew, mixed := w.newVars(n.Lhs); mixed {
+				r.PrintRedeclare(strings.Split(w.parseStoreList(n.Lhs), ", "), isNew, w.parseExprList(n.Rhs), len(n.Rhs) > 1)
+				break
+			}
+		}
+		lhs := w.parseStoreList(n.Lhs)
+		rhs := w.parseExprList(n.Rhs)
+		if id, ok := n.Lhs[0].(*ast.Ident); ok && n.Tok == token.DEFINE && len(n.Lhs) == 1 && w.heap[w.info.Defs[id]] {
+			ha, _ := printer.As[printer.HeapAllocator](w.p)
+			rhs = ha.FormatHeapValue(rhs, "")
+		}
+		if (n.Tok == token.QUO_ASSIGN || n.Tok == token.REM_ASSIGN) && w.isInteger(n.Lhs[0]) {
+			rhs = w.divisor(n.Rhs[0], rhs)
+		}
+		w.p.PrintAssignment(lhs, n.Tok.String(), rhs, len(n.Lhs) > 1, len(n.Rhs) > 1)
 
 	case *ast.IncDecStmt:
-		w.p.PrintStmt("", w.parseExpr(n.X)+n.Tok.String())
+		w.p.PrintStmt("", w.parseStoreList([]ast.Expr{n.X})+n.Tok.String())
 
 	case *ast.SendStmt:
 		w.p.PrintSend(w.parseExpr(n.Chan), w.parseExpr(n.Value))
@@ -210,9 +982,7 @@ func (w *GoWalker) Visit(node ast.Node) (ret ast.Visitor) {
 		w.p.PrintEmpty()
 
 	default:
-		if !w.debug {
-			w.p.Print(fmt.Sprintf("/* Node: %#v */\n", n))
-		}
+		w.unsupportedNode(n)
 		ret = w
 	}
 
@@ -221,6 +991,101 @@ func (w *GoWalker) Visit(node ast.Node) (ret ast.Visitor) {
 	return
 }
 
+// unsupportedNode prints a placeholder for a node that can't be converted, and records it
+func (w *GoWalker) unsupportedNode(n ast.Node) {
+	if !w.debug {
+		w.p.Print(fmt.Sprintf("/* Node: %#v */\n", n))
+	}
+	w.addUnsupported(n)
+}
+
+// walkSelect prints a select statement: the channels (and the values to send) of the cases,
+// then the cases, with the received values assigned at the start of the case body
+func (w *GoWalker) walkSelect(sp printer.SelectPrinter, n *ast.SelectStmt) {
+	var chans, values []string
+	var recv []*ast.UnaryExpr
+
+	hasDefault := false
+
+	for _, s := range n.Body.List {
+		switch comm := s.(*ast.CommClause).Comm.(type) {
+		case nil:
+			hasDefault = true
+
+		case *ast.SendStmt:
+			chans = append(chans, w.parseExpr(comm.Chan))
+			values = append(values, w.parseExpr(comm.Value))
+			recv = append(recv, nil)
+
+		default:
+			op := commRecv(comm)
+			chans = append(chans, w.parseExpr(op.X))
+			values = append(values, "")
+			recv = append(recv, op)
+		}
+	}
+
+	w.p.Print("\n")
+	received := sp.PrintSelect(chans, values, hasDefault)
+	w.p.PrintBlockStart(printer.CODE, len(n.Body.List) == 0)
+
+	if w.received == nil {
+		w.received = map[*ast.UnaryExpr]string{}
+	}
+
+	i := 0
+	for _, s := range n.Body.List {
+		cc := s.(*ast.CommClause)
+
+		if cc.Comm == nil {
+			sp.PrintSelectCase(-1)
+			w.p.UpdateLevel(printer.UP)
+		} else {
+			sp.PrintSelectCase(i)
+			w.p.UpdateLevel(printer.UP)
+
+			if _, ok := cc.Comm.(*ast.AssignStmt); ok {
+				w.received[recv[i]] = received[i]
+				w.Visit(cc.Comm)
+				delete(w.received, recv[i])
+			}
+			i++
+		}
+
+		for _, stmt := range cc.Body {
+			w.Visit(stmt)
+		}
+
+		sp.PrintEndSelectCase()
+		w.p.UpdateLevel(printer.DOWN)
+	}
+
+	w.p.PrintBlockEnd(printer.CODE)
+	w.p.Print("\n")
+}
+
+// commRecv returns the receive operation of a select case (<-ch, v := <-ch or v, ok = <-ch)
+func commRecv(comm ast.Stmt) *ast.UnaryExpr {
+	var x ast.Expr
+
+	switch comm := comm.(type) {
+	case *ast.ExprStmt:
+		x = comm.X
+	case *ast.AssignStmt:
+		x = comm.Rhs[0]
+	}
+
+	for {
+		p, ok := x.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		x = p.X
+	}
+
+	return x.(*ast.UnaryExpr)
+}
+
 func (w *GoWalker) Flush() {
 	if w.flush && w.buffer.Len() > 0 {
 		w.buffer.WriteTo(w.writer)
@@ -228,36 +1093,37 @@ func (w *GoWalker) Flush() {
 	}
 }
 
-func (w *GoWalker) BufferVisit(node ast.Node) (ret string) {
+func (w *GoWalker) BufferVisit(node ast.Node) string {
 	w.Flush()
 
 	prev := w.flush
 	w.flush = false
 
+	// the output of an enclosing BufferVisit (i.e. a function literal in a function literal)
+	outer := w.buffer.String()
+	w.buffer.Reset()
+
 	w.Visit(node)
 
 	w.flush = prev
 
-	ret = w.buffer.String()
+	ret := w.buffer.String()
 	w.buffer.Reset()
+	w.buffer.WriteString(outer)
 
-	if prev == true {
-		ret = strings.TrimSpace(ret)
-	}
-
-	return
+	return strings.TrimSpace(ret)
 }
 
 func (w *GoWalker) parseExpr(expr ast.Expr) string {
+	// only the outer expression is the target of an assignment
+	store := w.store
+	w.store = false
+
 	if expr == nil {
 		return ""
 	}
 
 	etype := w.info.Types[expr].Type
-	stype := ""
-	if etype != nil {
-		stype = etype.String()
-	}
 
 	if w.debug {
 		w.p.Print(fmt.Sprintf("/* Expr: %#v - %v */\n", expr, etype))
@@ -270,10 +1136,17 @@ func (w *GoWalker) parseExpr(expr ast.Expr) string {
 		if expr == nil {
 			return ""
 		}
-		return w.p.FormatIdent(expr.Name, stype)
+		if w.heap[w.info.Uses[expr]] {
+			ha, _ := printer.As[printer.HeapAllocator](w.p)
+			return ha.FormatHeapVar(w.identName(expr))
+		}
+		return w.identName(expr)
 
 		// *thing
 	case *ast.StarExpr:
+		if w.info.Types[expr].IsType() {
+			return w.pointerType(w.parseExpr(expr.X))
+		}
 		return w.p.FormatStar(w.parseExpr(expr.X))
 
 		// [len]type
@@ -288,7 +1161,7 @@ func (w *GoWalker) parseExpr(expr ast.Expr) string {
 	case *ast.InterfaceType:
 		var name string
 		if t, ok := w.parent.(*ast.TypeSpec); ok {
-			name = t.Name.String()
+			name = w.ident(t.Name.Name)
 		}
 		w.p.UpdateLevel(printer.UP)
 		ret := w.p.FormatInterface(name, w.parseFieldList(expr.Methods, printer.METHOD))
@@ -299,10 +1172,17 @@ func (w *GoWalker) parseExpr(expr ast.Expr) string {
 	case *ast.StructType:
 		var name string
 		if t, ok := w.parent.(*ast.TypeSpec); ok {
-			name = t.Name.String()
+			name = w.ident(t.Name.Name)
 		}
 		w.p.UpdateLevel(printer.UP)
-		ret := w.p.FormatStruct(name, w.parseFieldList(expr.Fields, printer.FIELD))
+		fields := w.parseFieldList(expr.Fields, printer.FIELD)
+		if t, ok := w.parent.(*ast.TypeSpec); ok && w.methods != nil {
+			dp, _ := printer.As[printer.DeclPrinter](w.p)
+			for _, m := range w.methods[t.Name.Name] {
+				fields += dp.FormatMethodDecl(m.name, m.params, m.results)
+			}
+		}
+		ret := w.p.FormatStruct(name, fields)
 		w.p.UpdateLevel(printer.DOWN)
 		return ret
 
@@ -341,11 +1221,25 @@ func (w *GoWalker) parseExpr(expr ast.Expr) string {
 
 		// -3
 	case *ast.UnaryExpr:
+		if cr, ok := printer.As[printer.ChanReceiver](w.p); ok && expr.Op == token.ARROW {
+			_, check := etype.(*types.Tuple)
+			if r, ok := w.received[expr]; ok {
+				return cr.FormatReceive(r, check)
+			}
+			return cr.FormatReceive(w.parseExpr(expr.X), check)
+		}
+		if ha, ok := printer.As[printer.HeapAllocator](w.p); ok && expr.Op == token.AND {
+			return w.formatAddress(ha, expr.X)
+		}
 		return w.p.FormatUnary(expr.Op.String(), w.parseExpr(expr.X))
 
 		// 3 + 2
 	case *ast.BinaryExpr:
-		return w.p.FormatBinary(w.parseExpr(expr.X), expr.Op.String(), w.parseExpr(expr.Y))
+		y := w.parseExpr(expr.Y)
+		if (expr.Op == token.QUO || expr.Op == token.REM) && w.isInteger(expr) {
+			y = w.divisor(expr.Y, y)
+		}
+		return w.p.FormatBinary(w.parseExpr(expr.X), expr.Op.String(), y)
 
 		// array[index]
 		// map[key]
@@ -358,7 +1252,12 @@ func (w *GoWalker) parseExpr(expr ast.Expr) string {
 				etype = t.At(0).Type()
 				check = true
 			}
+			if ms, ok := printer.As[printer.MapStorer](w.p); ok && store {
+				return ms.FormatMapStore(w.parseExpr(expr.X), w.parseExpr(expr.Index))
+			}
 			return w.p.FormatMapIndex(w.parseExpr(expr.X), w.parseExpr(expr.Index), etype.String(), check)
+		} else if sc, ok := printer.As[printer.StringConverter](w.p); ok && w.isString(expr.X) {
+			return sc.FormatStringByte(w.parseExpr(expr.X), w.parseExpr(expr.Index))
 		} else {
 			return w.p.FormatArrayIndex(w.parseExpr(expr.X), w.parseExpr(expr.Index), etype.String())
 		}
@@ -374,23 +1273,51 @@ func (w *GoWalker) parseExpr(expr ast.Expr) string {
 
 		// package.member
 	case *ast.SelectorExpr:
-		var isObj bool
+		if x, ok := expr.X.(*ast.Ident); ok {
+			if r, ok := w.selectors[x.Name+"."+expr.Sel.Name]; ok {
+				return r
+			}
+		}
+
+		// only package names are not objects (calls, index expressions, etc. are)
+		isObj := true
 
 		if ident, ok := expr.X.(*ast.Ident); ok {
-			isObj = ident.Obj != nil
-		} else if sel, ok := expr.X.(*ast.SelectorExpr); ok {
-			isObj = sel.Sel != nil
+			if obj := w.info.Uses[ident]; obj != nil {
+				_, isPkg := obj.(*types.PkgName)
+				isObj = !isPkg
+			} else {
+				isObj = ident.Obj != nil
+			}
+		}
+
+		if ps, ok := printer.As[printer.PointerSelector](w.p); ok && isObj && w.isPointer(expr.X) {
+			return ps.FormatPointerSelector(w.parseExpr(expr.X), w.parseExpr(expr.Sel))
 		}
 
 		return w.p.FormatSelector(w.parseExpr(expr.X), w.parseExpr(expr.Sel), isObj)
 
 		// funcname(args)
 	case *ast.CallExpr:
+		if sc, ok := printer.As[printer.StringConverter](w.p); ok && len(expr.Args) == 1 && w.info.Types[expr.Fun].IsType() &&
+			w.isString(expr.Fun) && w.isInteger(expr.Args[0]) {
+			// string(r)
+			return sc.FormatRuneString(w.parseExpr(expr.Args[0]))
+		}
+
 		_, funclit := expr.Fun.(*ast.FuncLit)
 		return w.p.FormatCall(w.parseExpr(expr.Fun), w.parseExprList(expr.Args)+printer.IfTrue("...", expr.Ellipsis > 0), funclit)
 
 		// name.(type)
 	case *ast.TypeAssertExpr:
+		if _, check := etype.(*types.Tuple); check && expr.Type != nil {
+			if ta, ok := printer.As[printer.TypeAsserter](w.p); ok {
+				return ta.FormatTypeAssertOk(w.parseExpr(expr.X), w.assertType(ta, expr.X, expr.Type))
+			}
+		}
+		if ta, ok := printer.As[printer.TypeAsserter](w.p); ok && expr.Type != nil {
+			return w.p.FormatTypeAssert(w.parseExpr(expr.X), w.assertType(ta, expr.X, expr.Type))
+		}
 		return w.p.FormatTypeAssert(w.parseExpr(expr.X), w.exprOr(expr.Type, "type"))
 
 		// (expr)
@@ -399,9 +1326,15 @@ func (w *GoWalker) parseExpr(expr ast.Expr) string {
 
 		// func(params) (ret) { body }
 	case *ast.FuncLit:
-		return w.p.FormatFuncLit(w.parseExpr(expr.Type), w.BufferVisit(expr.Body))
+		w.p.PushContext(printer.FUNCONTEXT)
+		defer w.p.PopContext()
+
+		ftype := w.parseExpr(expr.Type)
+		w.setDefer(expr.Body)
+		return w.p.FormatFuncLit(ftype, w.BufferVisit(expr.Body))
 	}
 
+	w.addUnsupported(expr)
 	return fmt.Sprintf("/* Expr: %#v */", expr)
 }
 
@@ -413,6 +1346,366 @@ func (w *GoWalker) parseExprList(l []ast.Expr) string {
 	return strings.Join(exprs, ", ")
 }
 
+// newVars returns which variables of a short variable declaration are new (blank identifiers are not),
+// and if some of them were already declared
+func (w *GoWalker) newVars(lhs []ast.Expr) (isNew []bool, mixed bool) {
+	for _, e := range lhs {
+		id, ok := e.(*ast.Ident)
+		n := ok && id.Name != "_" && w.info.Defs[id] != nil
+		if ok && id.Name != "_" && !n {
+			mixed = true
+		}
+		isNew = append(isNew, n)
+	}
+
+	return
+}
+
+// identName returns the name of an identifier (with the overrides and the escaped reserved words)
+func (w *GoWalker) identName(id *ast.Ident) string {
+	if r, ok := w.idents[id.Name]; ok {
+		return r
+	}
+
+	stype := ""
+	if t := w.info.Types[id].Type; t != nil {
+		stype = t.String()
+	}
+	return w.p.FormatIdent(w.escape(id), stype)
+}
+
+// formatAddress formats &x: a composite literal is allocated on the heap and, if the pointers are reference counted,
+// a variable allocated on the heap is its own address and the address of a part of a value (&p.f)
+// refers to the pointer to the value
+func (w *GoWalker) formatAddress(ha printer.HeapAllocator, x ast.Expr) string {
+	if lit, ok := unparen(x).(*ast.CompositeLit); ok {
+		return ha.FormatHeapValue(w.parseExpr(lit), "")
+	}
+
+	if !ha.HeapPointers() {
+		return w.p.FormatUnary("&", w.parseExpr(x))
+	}
+
+	if id, ok := unparen(x).(*ast.Ident); ok && w.heap[w.info.Uses[id]] {
+		return w.identName(id)
+	}
+
+	root, owner := w.addressOf(x)
+	if owner != nil {
+		return ha.FormatAddress(w.parseExpr(x), w.parseExpr(owner))
+	} else if root != nil && w.heap[w.info.Uses[root]] {
+		return ha.FormatAddress(w.parseExpr(x), w.identName(root))
+	}
+	return ha.FormatAddress(w.parseExpr(x), "")
+}
+
+// addressOf returns the variable that holds the value addressed by &x (x, x.f or x[i] for an array x),
+// or the pointer to the value that contains it (p.f, (*p).f or p[i] for a pointer to an array p)
+func (w *GoWalker) addressOf(x ast.Expr) (*ast.Ident, ast.Expr) {
+	for {
+		switch e := x.(type) {
+		case *ast.Ident:
+			return e, nil
+
+		case *ast.ParenExpr:
+			x = e.X
+
+		case *ast.StarExpr:
+			return nil, e.X
+
+		case *ast.SelectorExpr:
+			if id, ok := e.X.(*ast.Ident); ok {
+				if _, ok := w.info.Uses[id].(*types.PkgName); ok {
+					return nil, nil // a package variable
+				}
+			}
+			if w.isPointer(e.X) {
+				return nil, e.X
+			}
+			x = e.X
+
+		case *ast.IndexExpr:
+			if w.isPointer(e.X) {
+				return nil, e.X
+			}
+			if t := w.info.Types[e.X].Type; t == nil {
+				return nil, nil
+			} else if _, ok := t.Underlying().(*types.Array); !ok {
+				return nil, nil // a slice element
+			}
+			x = e.X
+
+		default:
+			return nil, nil
+		}
+	}
+}
+
+// heapVars returns the local variables of a function body whose address is taken, that are allocated on the heap
+// if they are declared alone (x := v, var x T = v), and are not arrays
+func (w *GoWalker) heapVars(body *ast.BlockStmt) map[types.Object]bool {
+	taken := map[types.Object]bool{}
+	ast.Inspect(body, func(node ast.Node) bool {
+		if u, ok := node.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			if root, _ := w.addressOf(u.X); root != nil {
+				taken[w.info.Uses[root]] = true
+			}
+		}
+		return true
+	})
+
+	heap := map[types.Object]bool{}
+	promote := func(id *ast.Ident) {
+		if obj := w.info.Defs[id]; obj != nil && taken[obj] {
+			if _, ok := obj.Type().Underlying().(*types.Array); !ok {
+				heap[obj] = true
+			}
+		}
+	}
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			if id, ok := n.Lhs[0].(*ast.Ident); ok && n.Tok == token.DEFINE && len(n.Lhs) == 1 && len(n.Rhs) == 1 {
+				promote(id)
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) == 1 && len(n.Values) <= 1 {
+				promote(n.Names[0])
+			}
+		}
+		return true
+	})
+
+	return heap
+}
+
+// unparen returns the expression in parentheses
+func unparen(x ast.Expr) ast.Expr {
+	for {
+		p, ok := x.(*ast.ParenExpr)
+		if !ok {
+			return x
+		}
+		x = p.X
+	}
+}
+
+// walkTypeSwitch visits a type switch, for a TypeAsserter: the cases are visited in order
+// (the default case can be anywhere) and the variable is declared only in the cases that use it
+func (w *GoWalker) walkTypeSwitch(ta printer.TypeAsserter, n *ast.TypeSwitchStmt) {
+	var assert *ast.TypeAssertExpr
+	var name string
+
+	switch s := n.Assign.(type) {
+	case *ast.AssignStmt: // v := x.(type)
+		assert = s.Rhs[0].(*ast.TypeAssertExpr)
+		name = w.ident(s.Lhs[0].(*ast.Ident).Name)
+	case *ast.ExprStmt: // x.(type)
+		assert = s.X.(*ast.TypeAssertExpr)
+	}
+
+	var cases [][]string
+	for _, s := range n.Body.List {
+		if cc := s.(*ast.CaseClause); cc.List != nil {
+			cases = append(cases, w.caseTypes(ta, assert.X, cc))
+		}
+	}
+
+	ta.PrintTypeSwitch(w.BufferVisit(n.Init), w.parseExpr(assert.X), cases)
+	w.p.PrintBlockStart(printer.CODE, len(n.Body.List) == 0)
+
+	i := 0
+	for _, s := range n.Body.List {
+		cc := s.(*ast.CaseClause)
+
+		vname := ""
+		if name != "" && w.usesImplicit(cc) {
+			vname = name
+		}
+
+		if cc.List == nil {
+			ta.PrintTypeCase(-1, nil, vname)
+		} else {
+			ta.PrintTypeCase(i, cases[i], vname)
+			i++
+		}
+		w.p.UpdateLevel(printer.UP)
+
+		for _, stmt := range cc.Body {
+			w.Visit(stmt)
+		}
+
+		ta.PrintEndTypeCase()
+		w.p.UpdateLevel(printer.DOWN)
+	}
+
+	w.p.PrintBlockEnd(printer.CODE)
+	w.p.Print("\n")
+}
+
+// caseTypes returns the types of a case of a type switch ("nil" for nil)
+func (w *GoWalker) caseTypes(ta printer.TypeAsserter, x ast.Expr, cc *ast.CaseClause) []string {
+	types := make([]string, len(cc.List))
+	for i, t := range cc.List {
+		if tv, ok := w.info.Types[t]; ok && tv.IsNil() {
+			types[i] = "nil"
+		} else {
+			types[i] = w.assertType(ta, x, t)
+		}
+	}
+	return types
+}
+
+// assertType returns the type t of an assertion on x: an interface with methods (other than the type of x)
+// is formatted with the types of the package that implement it (see TypeAsserter.FormatImplements)
+func (w *GoWalker) assertType(ta printer.TypeAsserter, x, t ast.Expr) string {
+	ttype := w.info.TypeOf(t)
+	if ttype == nil || w.tpkg == nil || !types.IsInterface(ttype) || types.Identical(ttype, w.info.TypeOf(x)) {
+		return w.parseExpr(t)
+	}
+
+	iface := ttype.Underlying().(*types.Interface)
+	if iface.NumMethods() == 0 {
+		return w.parseExpr(t)
+	}
+
+	var impls []string
+
+	scope := w.tpkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() || types.IsInterface(tn.Type()) {
+			continue
+		}
+		if named, ok := tn.Type().(*types.Named); !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+
+		// the method set of *T includes the methods of T
+		if types.Implements(tn.Type(), iface) {
+			impls = append(impls, w.ident(name))
+		}
+		if types.Implements(types.NewPointer(tn.Type()), iface) {
+			impls = append(impls, w.pointerType(w.ident(name)))
+		}
+	}
+
+	return ta.FormatImplements(w.parseExpr(t), impls)
+}
+
+// usesImplicit returns true if the body of a case of a type switch uses the switch variable
+func (w *GoWalker) usesImplicit(cc *ast.CaseClause) bool {
+	obj := w.info.Implicits[cc]
+	if obj == nil {
+		return false
+	}
+
+	used := false
+	for _, stmt := range cc.Body {
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			if id, ok := node.(*ast.Ident); ok && w.info.Uses[id] == obj {
+				used = true
+			}
+			return !used
+		})
+	}
+	return used
+}
+
+// isPointer returns true if the expression is a pointer value (not a pointer type, as in (*T).Method)
+func (w *GoWalker) isPointer(expr ast.Expr) bool {
+	tv, ok := w.info.Types[expr]
+	if !ok || tv.Type == nil || tv.IsType() {
+		return false
+	}
+
+	_, ok = tv.Type.Underlying().(*types.Pointer)
+	return ok
+}
+
+// divisor returns the formatted divisor of an integer division, checked at runtime if the printer
+// needs it and the divisor is not a constant (the type checker rejects a constant division by zero)
+func (w *GoWalker) divisor(expr ast.Expr, formatted string) string {
+	if d, ok := printer.As[printer.IntegerDivider](w.p); ok && w.info.Types[expr].Value == nil {
+		return d.FormatDivisor(formatted)
+	}
+	return formatted
+}
+
+// isString returns true if the type of expr (or the type expr) is a string
+func (w *GoWalker) isString(expr ast.Expr) bool {
+	return w.isBasic(expr, types.IsString)
+}
+
+// isInteger returns true if the type of expr is an integer (a rune, a byte, etc.)
+func (w *GoWalker) isInteger(expr ast.Expr) bool {
+	return w.isBasic(expr, types.IsInteger)
+}
+
+func (w *GoWalker) isBasic(expr ast.Expr, info types.BasicInfo) bool {
+	tv, ok := w.info.Types[expr]
+	if !ok || tv.Type == nil {
+		return false
+	}
+
+	b, ok := tv.Type.Underlying().(*types.Basic)
+	return ok && b.Info()&info != 0
+}
+
+// setDefer tells the printer if the function body has deferred calls,
+// and collects the statements of the body with deferred calls in their blocks
+func (w *GoWalker) setDefer(body *ast.BlockStmt) {
+	dp, ok := printer.As[printer.DeferPrinter](w.p)
+	if !ok || body == nil {
+		return
+	}
+
+	found := false
+	for _, stmt := range body.List {
+		if !hasDefer(stmt) {
+			continue
+		}
+
+		found = true
+
+		if _, ok := stmt.(*ast.DeferStmt); !ok {
+			if w.deferScopes == nil {
+				w.deferScopes = map[ast.Stmt]bool{}
+			}
+			w.deferScopes[stmt] = true
+		}
+	}
+
+	dp.SetDefer(found)
+}
+
+// hasDefer returns true if the statement has deferred calls (of the current function)
+func hasDefer(stmt ast.Stmt) bool {
+	found := false
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.DeferStmt:
+			found = true
+		case *ast.FuncLit:
+			return false // a different function
+		}
+		return !found
+	})
+
+	return found
+}
+
+// parseStoreList parses the targets of an assignment
+func (w *GoWalker) parseStoreList(l []ast.Expr) string {
+	exprs := []string{}
+	for _, e := range l {
+		w.store = true
+		exprs = append(exprs, w.parseExpr(e))
+	}
+	return strings.Join(exprs, ", ")
+}
+
 func (w *GoWalker) parseFieldList(l *ast.FieldList, ftype printer.FieldType) string {
 	var ll []string
 
@@ -430,7 +1723,7 @@ func (w *GoWalker) parseFieldList(l *ast.FieldList, ftype printer.FieldType) str
 			}
 
 			for _, n := range f.Names {
-				ll = append(ll, w.p.FormatPair(printer.Pair{n.Name, ptype}, ftype))
+				ll = append(ll, w.p.FormatPair(printer.Pair{w.ident(n.Name), ptype}, ftype))
 			}
 		}
 	}
@@ -446,7 +1739,7 @@ func (w *GoWalker) parseNames(v []*ast.Ident) string {
 	names := make([]string, len(v))
 
 	for i, n := range v {
-		names[i] = n.Name
+		names[i] = w.ident(n.Name)
 	}
 
 	return strings.Join(names, ", ")
@@ -5,9 +5,12 @@ package main
 //
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"go/build"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -21,6 +24,18 @@ type Walker struct {
 	outdir string
 	prefix string
 	ext    string
+	roots  []string // the input paths
+
+	ctx   build.Context // build constraints used to select the files to convert
+	tests bool          // convert _test.go files
+	watch bool          // keep running after the first pass (the conversion errors are not fatal)
+
+	project *printer.Project  // if not nil, collect the converted packages to generate the build files
+	headers map[string]string // if not nil, package folder -> output folder, to generate the package headers
+
+	include  []Glob            // if not empty, only convert the matching files
+	exclude  []Glob            // skip the matching files and folders
+	packages map[string]string // package folder -> output folder
 }
 
 func fatal(err error) {
@@ -33,99 +48,578 @@ func (w Walker) Walk(path string, info os.FileInfo, err error) error {
 		fatal(err)
 	}
 
-	var outpath string
-
-	if len(w.outdir) > 0 {
-		outpath = filepath.Join(w.outdir, path[len(w.prefix):])
-	}
-
 	if info.IsDir() {
-		if strings.HasPrefix(info.Name(), ".") && info.Name() != "." { // assume we want to skip hidden folders
+		if w.skipDir(path, info) {
 			return filepath.SkipDir
 		}
 
-		if len(outpath) > 0 {
-			if err := os.MkdirAll(outpath, 0755); err != nil {
+		if len(w.outdir) > 0 {
+			if err := os.MkdirAll(filepath.Join(w.outdir, filepath.FromSlash(w.relPath(path))), 0755); err != nil {
 				fatal(err)
 			}
 		}
-	} else if strings.HasSuffix(path, ".go") {
-		if len(outpath) > 0 {
-			outpath = outpath[:len(outpath)-2] + w.ext
-			f, err := os.Create(outpath)
-			if err != nil {
-				fatal(err)
-			} else {
-				w.SetWriter(f)
-				defer f.Close()
-			}
+	} else if w.selected(path) {
+		if _, err := w.convert(path); err != nil && w.watch {
+			// the file may be in the middle of an edit: keep going and convert it again when it changes
+			fmt.Fprintln(os.Stderr, err)
+		} else if err != nil {
+			fatal(err)
+		}
+	}
+
+	return nil
+}
+
+// skipDir returns true if the folder should not be walked
+func (w Walker) skipDir(path string, info os.FileInfo) bool {
+	if strings.HasPrefix(info.Name(), ".") && info.Name() != "." { // assume we want to skip hidden folders
+		return true
+	}
+
+	if path != w.prefix && (strings.HasPrefix(info.Name(), "_") || info.Name() == "testdata") { // same as go build
+		return true
+	}
+
+	return path != w.prefix && matchAny(w.exclude, w.relPath(path))
+}
+
+// setRoot sets the input path being walked. The files of a folder, or of a project or of the packages
+// with a header, are type checked with the other files of their package, a single file is checked alone
+// (i.e. one of many standalone programs in the same folder).
+func (w *Walker) setRoot(root string) {
+	w.prefix = root
+
+	info, err := os.Stat(root)
+	w.SetPackageFiles(w.headers != nil || w.project != nil || (err == nil && info.IsDir()))
+}
+
+// relPath returns the slash separated path relative to the input folder ("" for the folder itself)
+func (w Walker) relPath(path string) string {
+	rel, err := filepath.Rel(w.prefix, path)
+	if err != nil || rel == "." {
+		return ""
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// selected returns true if the file should be converted
+func (w Walker) selected(path string) bool {
+	if !strings.HasSuffix(path, ".go") {
+		return false
+	}
+
+	if path == w.prefix { // explicitly listed files are always converted
+		return true
+	}
+
+	if !w.configured(w.relPath(path)) {
+		return false
+	}
+
+	return w.match(path)
+}
+
+// configured returns true if the file (slash separated, relative to the input folder)
+// is not filtered out by the include and exclude patterns of the configuration
+func (w Walker) configured(rel string) bool {
+	return !matchAny(w.exclude, rel) && (len(w.include) == 0 || matchAny(w.include, rel))
+}
+
+// converted returns true if some of the Go files in dir (in the input folder root) are converted,
+// that is the folder is not excluded and its files are not all filtered out
+func (w Walker) converted(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return false
+	}
+
+	rel = filepath.ToSlash(rel)
+	for d := rel; d != "."; d = path.Dir(d) {
+		if matchAny(w.exclude, d) {
+			return false
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+
+		if w.configured(path.Join(rel, e.Name())) && w.match(filepath.Join(dir, e.Name())) {
+			return true
 		}
+	}
+
+	return false
+}
 
+// outPath returns the path of the converted file (empty if writing to stdout)
+// and, if generating a project, the path relative to outdir
+func (w Walker) outPath(path string) (outpath, srcpath string) {
+	if len(w.outdir) == 0 {
+		return
+	}
+
+	outpath = filepath.Join(w.outdir, filepath.FromSlash(w.relPath(path)))
+	if dir, ok := w.packages[filepath.ToSlash(filepath.Dir(w.relPath(path)))]; ok { // "." for the root folder
+		outpath = filepath.Join(w.outdir, dir, filepath.Base(path))
+	}
+	outpath = outpath[:len(outpath)-2] + w.ext
+
+	if w.project != nil {
+		srcpath = w.sourcePath(outpath)
+		outpath = filepath.Join(w.outdir, srcpath)
+	}
+
+	return
+}
+
+// convert converts a single file and returns the path of the converted file
+func (w Walker) convert(path string) (string, error) {
+	outpath, srcpath := w.outPath(path)
+
+	if len(outpath) == 0 {
 		if err := w.WalkFile(path); err != nil {
+			return "", err
+		}
+	} else {
+		// the converted file is only replaced if the conversion succeeds
+		// (i.e. in watch mode, a file in the middle of an edit keeps the last good conversion)
+		var buf bytes.Buffer
+
+		old := w.SetWriter(&buf)
+		err := w.WalkFile(path)
+		w.SetWriter(old)
+
+		if err != nil {
+			return "", err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(outpath), 0755); err != nil {
+			return "", err
+		}
+
+		if err := os.WriteFile(outpath, buf.Bytes(), 0644); err != nil {
+			return "", err
+		}
+	}
+
+	if len(srcpath) > 0 {
+		w.project.AddFile(w.PackageName(), srcpath, w.HasMain())
+	}
+
+	if w.headers != nil && len(outpath) > 0 {
+		w.headers[filepath.Dir(path)] = filepath.Dir(outpath)
+	}
+
+	return outpath, nil
+}
+
+// writeHeaders writes the header (declaration file) for each converted package
+func (w Walker) writeHeaders() {
+	for dir, outdir := range w.headers {
+		var buf bytes.Buffer
+
+		old := w.SetWriter(&buf)
+		err := w.WalkHeader(dir)
+		w.SetWriter(old)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+
+		if err := os.WriteFile(filepath.Join(outdir, w.PackageName()+".h"), buf.Bytes(), 0644); err != nil {
 			fatal(err)
 		}
 	}
+}
 
-	return nil
+// packageDir returns the output folder (slash separated, relative to outdir) for a source folder,
+// if it's in one of the input folders and it's converted
+func (w Walker) packageDir(srcdir string) (string, bool) {
+	abs, err := filepath.Abs(srcdir)
+	if err != nil {
+		return "", false
+	}
+
+	for _, root := range w.roots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+
+		rabs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(rabs, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		if !w.converted(rabs, abs) {
+			return "", false
+		}
+
+		rel = filepath.ToSlash(rel)
+		if dir, ok := w.packages[rel]; ok { // "." for the root folder
+			rel = path.Clean(filepath.ToSlash(dir))
+		}
+		if rel == "." {
+			rel = ""
+		}
+
+		return rel, true
+	}
+
+	return "", false
 }
 
-func main() {
-	debug := flag.Bool("debug", false, "print AST nodes")
-	pdebug := flag.Bool("debug-printer", false, "print Printer calls")
-	outd := flag.String("outdir", "", "create converted files in outdir")
-	lang := flag.String("lang", "go", "convert to specified language (go, c, rust, swift, python)")
+// findModule looks for go.mod in dir and its parents and returns the module path and root folder
+func findModule(dir string) (string, string) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", ""
+	}
 
-	flag.Parse()
+	for {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+					return strings.Trim(fields[1], `"`), dir
+				}
+			}
+
+			return "", ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// sourcePath returns the path of the converted file, relative to outdir, according to the project layout
+func (w Walker) sourcePath(outpath string) string {
+	rel, err := filepath.Rel(w.outdir, outpath)
+	if err != nil {
+		fatal(err)
+	}
 
-	var p printer.Printer
+	rel = filepath.ToSlash(rel)
 
-	switch *lang {
+	if pp, ok := printer.As[printer.ProjectPrinter](w.Printer()); ok {
+		rel = pp.SourcePath(rel)
+	}
+
+	return rel
+}
+
+// writeProject writes the build files for the converted packages
+func (w Walker) writeProject() {
+	pp, ok := printer.As[printer.ProjectPrinter](w.Printer())
+	if !ok {
+		fmt.Fprintln(os.Stderr, "project files not supported for", w.ext)
+		return
+	}
+
+	for name, content := range pp.ProjectFiles(w.project) {
+		fpath := filepath.Join(w.outdir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+			fatal(err)
+		}
+
+		if err := os.WriteFile(fpath, []byte(content), 0644); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+// match returns true if the file should be converted,
+// according to the build constraints and the "tests" option
+func (w Walker) match(path string) bool {
+	if strings.HasSuffix(path, "_test.go") && !w.tests {
+		return false
+	}
+
+	dir, name := filepath.Split(path)
+	match, err := w.ctx.MatchFile(dir, name)
+	if os.IsNotExist(err) { // removed while walking
+		return false
+	} else if err != nil {
+		fatal(err)
+	}
+
+	return match
+}
+
+// newPrinter returns the printer for the specified language and the extension of the converted files
+func newPrinter(lang string) (p printer.Printer, ext string) {
+	switch lang {
 	case "c", "cc":
 		p = &printer.CPrinter{}
-		*lang = "cc"
+		ext = "cc"
 
 	case "zig":
 		p = &printer.ZigPrinter{}
-		*lang = "zig"
+		ext = "zig"
 
 	case "go":
 		p = &printer.GoPrinter{}
-		*lang = "go"
+		ext = "go"
 		/*
 			case "js":
 				p = &printer.JsPrinter{}
-				*lang = "js"
+				ext = "js"
 		*/
 	case "java":
 		p = &printer.JavaPrinter{}
-		*lang = "java"
+		ext = "java"
 
 	case "rust", "rs":
 		p = &printer.RustPrinter{}
-		*lang = "rs"
+		ext = "rs"
 
 	case "swift":
 		p = &printer.SwiftPrinter{}
-		*lang = "swift"
+		ext = "swift"
 
 	case "python":
 		p = &printer.PythonPrinter{}
-		*lang = "py"
+		ext = "py"
+	}
+
+	return
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(os.Args[2:])
+		return
+	}
+
+	debug := flag.Bool("debug", false, "print AST nodes")
+	pdebug := flag.Bool("debug-printer", false, "print Printer calls")
+	outd := flag.String("outdir", "", "create converted files in outdir")
+	lang := flag.String("lang", "go", "convert to specified language (go, c, rust, swift, python)")
+	goos := flag.String("goos", build.Default.GOOS, "target operating system for build constraints")
+	goarch := flag.String("goarch", build.Default.GOARCH, "target architecture for build constraints")
+	tags := flag.String("tags", "", "comma separated list of additional build tags")
+	tests := flag.Bool("tests", false, "also convert _test.go files")
+	project := flag.Bool("project", false, "generate the build files for the target language in outdir")
+	runtime := flag.String("runtime", "", "path of the walkngo runtime for the target language (used by --project)")
+	watch := flag.Bool("watch", false, "keep running and convert the files again when they change")
+	mapfiles := flag.String("mappings", "", "comma separated list of library mapping files (JSON), merged with the default mappings")
+	header := flag.Bool("header", false, "C++ only: generate a header per package with the declarations (requires --outdir)")
+	group := flag.String("java-group", "", "Java only: package prefix of the converted packages (i.e. com.example)")
+	shared := flag.Bool("shared-pointers", false, "C++ only: reference counted pointers (the local variables whose address is taken are allocated on the heap)")
+	config := flag.String("config", "", "configuration file (default: walkngo.json or .walkngo.toml in the input folder or in the current folder)")
+
+	flag.Parse()
+
+	// the flags explicitly set override the configuration file
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if len(*config) == 0 {
+		if flag.NArg() > 0 {
+			if info, err := os.Stat(flag.Arg(0)); err == nil && info.IsDir() {
+				*config = findConfig(flag.Arg(0))
+			}
+		}
+
+		if len(*config) == 0 {
+			*config = findConfig(".")
+		}
+	}
+
+	var opts Options
+
+	if len(*config) > 0 {
+		cfg, err := loadConfig(*config)
+		if err != nil {
+			fatal(err)
+		}
+
+		if !set["lang"] && len(cfg.Lang) > 0 {
+			*lang = cfg.Lang
+		}
+
+		_, ext := newPrinter(*lang)
+		opts = cfg.ForLang(*lang, ext)
+
+		if !set["outdir"] && len(opts.Outdir) > 0 {
+			// relative to the configuration file
+			*outd = opts.Outdir
+			if !filepath.IsAbs(*outd) {
+				*outd = filepath.Join(filepath.Dir(*config), *outd)
+			}
+		}
+
+		if !set["debug"] && opts.Debug != nil {
+			*debug = *opts.Debug
+		}
+
+		if !set["java-group"] && len(opts.JavaGroup) > 0 {
+			*group = opts.JavaGroup
+		}
+
+		for _, m := range opts.Mappings {
+			if !filepath.IsAbs(m) {
+				m = filepath.Join(filepath.Dir(*config), m)
+			}
 
-	default:
+			if err := printer.LoadMappings(m); err != nil {
+				fatal(err)
+			}
+		}
+	}
+
+	if len(*mapfiles) > 0 {
+		for _, m := range strings.Split(*mapfiles, ",") {
+			if err := printer.LoadMappings(m); err != nil {
+				fatal(err)
+			}
+		}
+	}
+
+	p, ext := newPrinter(*lang)
+	if p == nil {
 		fmt.Println("unsupported language", *lang, "use c, go, js, java, rust, swift, python")
 		return
 	}
 
+	if _, ok := p.(*printer.CPrinter); ok && *project {
+		// the converted packages include the headers of the packages they import
+		*header = true
+	}
+
+	if *header {
+		cp, ok := p.(*printer.CPrinter)
+		if !ok {
+			fatal(fmt.Errorf("--header is only supported for C++"))
+		}
+		if len(*outd) == 0 {
+			fatal(fmt.Errorf("--header requires --outdir"))
+		}
+
+		cp.Header = true
+	}
+
+	if len(*group) > 0 {
+		jp, ok := p.(*printer.JavaPrinter)
+		if !ok && set["java-group"] {
+			fatal(fmt.Errorf("--java-group is only supported for Java"))
+		}
+
+		if ok {
+			jp.Group = *group
+		}
+	}
+
+	if *shared {
+		cp, ok := p.(*printer.CPrinter)
+		if !ok {
+			fatal(fmt.Errorf("--shared-pointers is only supported for C++"))
+		}
+
+		cp.SharedPointers = true
+	}
+
 	if *pdebug {
 		p = &printer.DebugPrinter{p}
 	}
 
-	walker := Walker{walkngo.NewWalker(p, os.Stdout, *debug), *outd, "", *lang}
+	ctx := build.Default
+	ctx.GOOS = *goos
+	ctx.GOARCH = *goarch
+	if len(*tags) > 0 {
+		ctx.BuildTags = strings.Split(*tags, ",")
+	}
+
+	walker := Walker{GoWalker: walkngo.NewWalker(p, os.Stdout, *debug), outdir: *outd, ext: ext, ctx: ctx, tests: *tests, watch: *watch}
+	walker.SetBuildContext(ctx)
+	walker.roots = flag.Args()
+
+	if flag.NArg() > 0 {
+		dir := flag.Arg(0)
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+
+		if mpath, mdir := findModule(dir); len(mpath) > 0 {
+			walker.SetModule(mpath, mdir)
+		}
+	}
+
+	if *header {
+		walker.headers = map[string]string{}
+	}
+
+	if opts.SortStructs != nil {
+		walker.SetSortStructs(*opts.SortStructs)
+	}
+
+	walker.SetOverrides(opts.Idents, opts.Selectors)
+	walker.packages = opts.Packages
+
+	var err error
+
+	if walker.include, err = compileGlobs(opts.Include); err != nil {
+		fatal(err)
+	}
+	if walker.exclude, err = compileGlobs(opts.Exclude); err != nil {
+		fatal(err)
+	}
+
+	if len(*outd) > 0 {
+		// the imports of the converted packages refer to the converted modules
+		walker.SetPackageDirs(walker.packageDir)
+	}
+
+	if *project {
+		if len(*outd) == 0 {
+			fatal(fmt.Errorf("--project requires --outdir"))
+		}
+
+		name := "main"
+		if flag.NArg() > 0 {
+			if abs, err := filepath.Abs(flag.Arg(0)); err == nil {
+				if !strings.HasSuffix(abs, ".go") {
+					name = filepath.Base(abs)
+				} else {
+					name = filepath.Base(filepath.Dir(abs))
+				}
+			}
+		}
+
+		walker.project = &printer.Project{Name: name, Runtime: *runtime}
+	}
 
 	for _, f := range flag.Args() {
-		walker.prefix = f
+		walker.setRoot(f)
 
 		filepath.Walk(f, walker.Walk)
 	}
+
+	if walker.headers != nil {
+		walker.writeHeaders()
+	}
+
+	if walker.project != nil {
+		walker.writeProject()
+	}
+
+	if *watch {
+		NewWatcher(walker, flag.Args()).Watch()
+	}
 }
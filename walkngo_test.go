@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRelPath(t *testing.T) {
+	tests := []struct {
+		prefix, path, want string
+	}{
+		{".", "geom/x.go", "geom/x.go"},
+		{".", "x.go", "x.go"},
+		{".", ".", ""},
+		{"src", "src/geom/x.go", "geom/x.go"},
+		{"src/", "src/geom/x.go", "geom/x.go"},
+		{"./src", "src/x.go", "x.go"},
+		{"src", "src", ""},
+	}
+
+	for _, tt := range tests {
+		w := Walker{prefix: filepath.FromSlash(tt.prefix)}
+		if got := w.relPath(filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("relPath(%q) with prefix %q = %q, want %q", tt.path, tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+//
+// Watch mode: poll the input paths and convert the files that changed
+//
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const pollInterval = time.Second
+
+// watchedFile is the state of a converted file
+type watchedFile struct {
+	modTime time.Time
+	outpath string // the converted file (empty if writing to stdout)
+	srcpath string // the converted file, relative to outdir (for projects)
+}
+
+// Watcher keeps track of the converted files
+type Watcher struct {
+	Walker
+
+	roots []string
+	files map[string]watchedFile
+}
+
+func NewWatcher(w Walker, roots []string) *Watcher {
+	return &Watcher{Walker: w, roots: roots, files: map[string]watchedFile{}}
+}
+
+// Watch polls the input paths forever, converting new and modified files
+// and removing the converted files when the sources are deleted
+func (w *Watcher) Watch() {
+	// the first pass has already been done, just collect the current state
+	w.scan(func(path string, info os.FileInfo) {
+		outpath, srcpath := w.outPath(path)
+		w.files[path] = watchedFile{info.ModTime(), outpath, srcpath}
+	})
+
+	for {
+		time.Sleep(pollInterval)
+
+		changed := false
+		seen := map[string]bool{}
+
+		w.scan(func(path string, info os.FileInfo) {
+			seen[path] = true
+
+			if f, ok := w.files[path]; ok && f.modTime.Equal(info.ModTime()) {
+				return
+			}
+
+			outpath, srcpath := w.outPath(path)
+			if _, err := w.convert(path); err != nil {
+				// keep watching: the file may be in the middle of an edit
+				fmt.Fprintln(os.Stderr, err)
+			} else {
+				fmt.Fprintln(os.Stderr, "converted", path)
+			}
+
+			// update the state even on failure, so that the error is reported once per change
+			w.files[path] = watchedFile{info.ModTime(), outpath, srcpath}
+			changed = true
+		})
+
+		for path, f := range w.files {
+			if seen[path] {
+				continue
+			}
+
+			delete(w.files, path)
+			changed = true
+
+			if len(f.outpath) > 0 {
+				if err := os.Remove(f.outpath); err != nil && !os.IsNotExist(err) {
+					fmt.Fprintln(os.Stderr, err)
+				} else {
+					fmt.Fprintln(os.Stderr, "removed", f.outpath)
+				}
+			}
+
+			if w.project != nil && len(f.srcpath) > 0 {
+				w.project.RemoveFile(f.srcpath)
+			}
+		}
+
+		if changed && w.headers != nil {
+			w.writeHeaders()
+		}
+
+		if changed && w.project != nil {
+			w.writeProject()
+		}
+	}
+}
+
+// scan walks the input paths and calls fn for each file that should be converted
+func (w *Watcher) scan(fn func(path string, info os.FileInfo)) {
+	for _, root := range w.roots {
+		w.setRoot(root)
+
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				// the file may have been removed while walking
+				return nil
+			}
+
+			if info.IsDir() {
+				if w.skipDir(path, info) {
+					return filepath.SkipDir
+				}
+			} else if w.selected(path) {
+				fn(path, info)
+			}
+
+			return nil
+		})
+	}
+}